@@ -42,6 +42,8 @@ struct SearchInfo {
     tracer::TraceContext trace_ctx_;
     bool materialized_view_involved = false;
     bool iterative_filter_execution = false;
+    // scan the raw vectors instead of searching by the index, for the ground truth searches
+    bool brute_force_execution = false;
     std::optional<SearchIteratorV2Info> iterator_v2_info_ = std::nullopt;
 };
 
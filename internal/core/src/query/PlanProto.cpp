@@ -60,6 +60,8 @@ ProtoParser::PlanNodeFromProto(const planpb::PlanNode& plan_node_proto) {
                     search_info.iterative_filter_execution = false;
                 } else if (query_info_proto.hints() == ITERATIVE_FILTER) {
                     search_info.iterative_filter_execution = true;
+                } else if (query_info_proto.hints() == BRUTE_FORCE) {
+                    search_info.brute_force_execution = true;
                 } else {
                     // check if hints is valid
                     ThrowInfo(ConfigInvalid,
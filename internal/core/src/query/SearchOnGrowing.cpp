@@ -96,6 +96,9 @@ SearchOnGrowing(const segcore::SegmentGrowingImpl& segment,
 
     // step 2: small indexing search
     if (segment.get_indexing_record().SyncDataWithIndex(field.get_id())) {
+        AssertInfo(!info.brute_force_execution,
+                   "brute force search is not supported after the raw "
+                   "vectors are moved into the interim index");
         FloatSegmentIndexSearch(
             segment, info, query_data, num_queries, bitset, search_result);
     } else {
@@ -103,6 +106,9 @@ SearchOnGrowing(const segcore::SegmentGrowingImpl& segment,
             segment.get_chunk_mutex());
         // check SyncDataWithIndex() again, in case the vector chunks has been removed.
         if (segment.get_indexing_record().SyncDataWithIndex(field.get_id())) {
+            AssertInfo(!info.brute_force_execution,
+                       "brute force search is not supported after the raw "
+                       "vectors are moved into the interim index");
             return FloatSegmentIndexSearch(
                 segment, info, query_data, num_queries, bitset, search_result);
         }
@@ -11,6 +11,7 @@
 
 #include <algorithm>
 #include <cmath>
+#include <numeric>
 #include <string>
 
 #include "bitset/detail/element_wise.h"
@@ -18,6 +19,7 @@
 #include "common/BitsetView.h"
 #include "common/QueryInfo.h"
 #include "common/Types.h"
+#include "common/Utils.h"
 #include "query/CachedSearchIterator.h"
 #include "query/SearchBruteForce.h"
 #include "query/SearchOnSealed.h"
@@ -86,6 +88,66 @@ SearchOnSealedIndex(const Schema& schema,
     search_result.unity_topK_ = topK;
 }
 
+// the number of the raw vectors fetched from the index at a time by the brute force search
+constexpr int64_t kBruteForceBatchSize = 8192;
+
+void
+SearchOnSealedIndexByBruteForce(const Schema& schema,
+                                const segcore::SealedIndexingRecord& record,
+                                const SearchInfo& search_info,
+                                const void* query_data,
+                                int64_t num_queries,
+                                int64_t row_count,
+                                const BitsetView& bitset,
+                                SearchResult& result) {
+    auto field_id = search_info.field_id_;
+    auto& field = schema[field_id];
+    auto data_type = field.get_data_type();
+    AssertInfo(data_type != DataType::VECTOR_SPARSE_FLOAT,
+               "brute force search by the index is not supported for the "
+               "sparse vectors");
+    AssertInfo(record.is_ready(field_id),
+               "[SearchOnSealedIndexByBruteForce]Record isn't ready");
+    auto field_indexing = record.get_field_indexing(field_id);
+    auto accessor = SemiInlineGet(field_indexing->indexing_->PinCells({0}));
+    auto vec_index =
+        dynamic_cast<index::VectorIndex*>(accessor->get_cell_of(0));
+    AssertInfo(vec_index != nullptr && vec_index->HasRawData(),
+               "brute force search requires the raw vectors, but the index "
+               "of field {} has none",
+               field_id.get());
+    CheckBruteForceSearchParam(field, search_info);
+
+    auto dim = field.get_dim();
+    query::dataset::SearchDataset query_dataset{search_info.metric_type_,
+                                                num_queries,
+                                                search_info.topk_,
+                                                search_info.round_decimal_,
+                                                dim,
+                                                query_data};
+    SubSearchResult final_qr(num_queries,
+                             search_info.topk_,
+                             search_info.metric_type_,
+                             search_info.round_decimal_);
+    std::vector<int64_t> offsets;
+    for (int64_t begin = 0; begin < row_count; begin += kBruteForceBatchSize) {
+        auto batch_size = std::min(kBruteForceBatchSize, row_count - begin);
+        offsets.resize(batch_size);
+        std::iota(offsets.begin(), offsets.end(), begin);
+        auto vectors =
+            vec_index->GetVector(GenIdsDataset(batch_size, offsets.data()));
+        auto raw_dataset =
+            query::dataset::RawDataset{begin, dim, batch_size, vectors.data()};
+        auto sub_qr = BruteForceSearch(
+            query_dataset, raw_dataset, search_info, {}, bitset, data_type);
+        final_qr.merge(sub_qr);
+    }
+    result.distances_ = std::move(final_qr.mutable_distances());
+    result.seg_offsets_ = std::move(final_qr.mutable_seg_offsets());
+    result.unity_topK_ = query_dataset.topk;
+    result.total_nq_ = query_dataset.num_queries;
+}
+
 void
 SearchOnSealedColumn(const Schema& schema,
                      ChunkedColumnInterface* column,
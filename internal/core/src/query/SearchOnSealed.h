@@ -27,6 +27,16 @@ SearchOnSealedIndex(const Schema& schema,
                     const BitsetView& view,
                     SearchResult& search_result);
 
+void
+SearchOnSealedIndexByBruteForce(const Schema& schema,
+                                const segcore::SealedIndexingRecord& record,
+                                const SearchInfo& search_info,
+                                const void* query_data,
+                                int64_t num_queries,
+                                int64_t row_count,
+                                const BitsetView& bitset,
+                                SearchResult& result);
+
 void
 SearchOnSealedColumn(const Schema& schema,
                      ChunkedColumnInterface* column,
@@ -730,7 +730,24 @@ ChunkedSegmentSealedImpl::vector_search(SearchInfo& search_info,
     AssertInfo(field_meta.is_vector(),
                "The meta type of vector field is not vector type");
 
-    if (get_bit(binlog_index_bitset_, field_id)) {
+    // the ground truth searches scan the raw vectors, the ones of the index
+    // are scanned if the field data is not loaded
+    auto brute_force = search_info.brute_force_execution;
+    if (brute_force && !get_bit(field_data_ready_bitset_, field_id)) {
+        AssertInfo(vector_indexings_.is_ready(field_id),
+                   "vector indexes isn't ready for field " +
+                       std::to_string(field_id.get()));
+        AssertInfo(num_rows_.has_value(), "Can't get row count value");
+        query::SearchOnSealedIndexByBruteForce(*schema_,
+                                               vector_indexings_,
+                                               search_info,
+                                               query_data,
+                                               query_count,
+                                               num_rows_.value(),
+                                               bitset,
+                                               output);
+        milvus::tracer::AddEvent("finish_searching_vector_index_brute_force");
+    } else if (!brute_force && get_bit(binlog_index_bitset_, field_id)) {
         AssertInfo(
             vec_binlog_config_.find(field_id) != vec_binlog_config_.end(),
             "The binlog params is not generate.");
@@ -749,7 +766,7 @@ ChunkedSegmentSealedImpl::vector_search(SearchInfo& search_info,
                                    output);
         milvus::tracer::AddEvent(
             "finish_searching_vector_temperate_binlog_index");
-    } else if (get_bit(index_ready_bitset_, field_id)) {
+    } else if (!brute_force && get_bit(index_ready_bitset_, field_id)) {
         AssertInfo(vector_indexings_.is_ready(field_id),
                    "vector indexes isn't ready for field " +
                        std::to_string(field_id.get()));
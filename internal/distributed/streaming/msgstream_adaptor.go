@@ -12,6 +12,7 @@ import (
 	"github.com/milvus-io/milvus/pkg/v2/streaming/util/message/adaptor"
 	"github.com/milvus-io/milvus/pkg/v2/streaming/util/options"
 	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
 )
 
 var (
@@ -103,6 +104,7 @@ func (m *delegatorMsgstreamAdaptor) Seek(ctx context.Context, msgPositions []*ms
 		zap.Uint64("timestamp", position.GetTimestamp()),
 	)
 	handler := adaptor.NewMsgPackAdaptorHandler()
+	handler.SetMaxMessagesPerPack(paramtable.Get().StreamingCfg.MsgPackAdaptorMaxMessagesPerPack.GetAsInt())
 	pchannel := funcutil.ToPhysicalChannel(position.GetChannelName())
 	m.scanner = WAL().Read(ctx, ReadOption{
 		PChannel:      pchannel,
@@ -49,3 +49,17 @@ func parseDummyQueryRequest(str string) (*dummyQueryRequest, error) {
 	}
 	return dr, nil
 }
+
+// dummyCancelSearchRequest cancels the in-flight search of the current user with the client supplied request id.
+type dummyCancelSearchRequest struct {
+	RequestType string `json:"request_type"`
+	RequestID   string `json:"request_id"`
+}
+
+func parseDummyCancelSearchRequest(str string) (*dummyCancelSearchRequest, error) {
+	dr := &dummyCancelSearchRequest{}
+	if err := json.Unmarshal([]byte(str), &dr); err != nil {
+		return nil, err
+	}
+	return dr, nil
+}
@@ -2964,13 +2964,22 @@ func (node *Proxy) Search(ctx context.Context, request *milvuspb.SearchRequest)
 	rsp := &milvuspb.SearchResults{
 		Status: merr.Success(),
 	}
+	if collectionNames, err := parseUnionCollections(request); err != nil {
+		rsp.Status = merr.Status(err)
+		return rsp, nil
+	} else if len(collectionNames) > 0 {
+		return node.unionSearch(ctx, request, collectionNames)
+	}
 
 	optimizedSearch := true
 	resultSizeInsufficient := false
 	isTopkReduce := false
 	isRecallEvaluation := false
+	// the search is validated by the search task, the ground truth search is issued even if it is not sampled then.
+	selfRecallCheck, _ := getBoolSearchParam(request.GetSearchParams(), SelfRecallCheckKey)
 	err2 := retry.Handle(ctx, func() (bool, error) {
 		rsp, resultSizeInsufficient, isTopkReduce, isRecallEvaluation, err = node.search(ctx, request, optimizedSearch, false)
+		isRecallEvaluation = isRecallEvaluation || selfRecallCheck
 		if merr.Ok(rsp.GetStatus()) && optimizedSearch && resultSizeInsufficient && isTopkReduce && paramtable.Get().AutoIndexConfig.EnableResultLimitCheck.GetAsBool() {
 			// without optimize search
 			optimizedSearch = false
@@ -2989,12 +2998,14 @@ func (node *Proxy) Search(ctx context.Context, request *milvuspb.SearchRequest)
 				).Inc()
 			}
 		}
-		if errors.Is(merr.Error(rsp.GetStatus()), merr.ErrInconsistentRequery) {
+		if errors.Is(merr.Error(rsp.GetStatus()), merr.ErrInconsistentRequery) ||
+			errors.Is(merr.Error(rsp.GetStatus()), merr.ErrCollectionSchemaMismatch) {
 			return true, merr.Error(rsp.GetStatus())
 		}
 		// search for ground truth and compute recall
 		if isRecallEvaluation && merr.Ok(rsp.GetStatus()) {
 			var rspGT *milvuspb.SearchResults
+			// the ground truth search of self_recall_check is forced to be a brute force search by the search task.
 			rspGT, _, _, _, err = node.search(ctx, request, false, true)
 			metrics.ProxyRecallSearchCount.WithLabelValues(
 				strconv.FormatInt(paramtable.GetNodeID(), 10),
@@ -3080,6 +3091,7 @@ func (node *Proxy) search(ctx context.Context, request *milvuspb.SearchRequest,
 		lb:                     node.lbPolicy,
 		enableMaterializedView: node.enableMaterializedView,
 		mustUsePartitionKey:    Params.ProxyCfg.MustUsePartitionKey.GetAsBool(),
+		dqQueueLen:             node.dqQueueLen,
 	}
 
 	log := log.Ctx(ctx).With( // TODO: it might cause some cpu consumption
@@ -3254,7 +3266,8 @@ func (node *Proxy) HybridSearch(ctx context.Context, request *milvuspb.HybridSea
 				).Inc()
 			}
 		}
-		if errors.Is(merr.Error(rsp.GetStatus()), merr.ErrInconsistentRequery) {
+		if errors.Is(merr.Error(rsp.GetStatus()), merr.ErrInconsistentRequery) ||
+			errors.Is(merr.Error(rsp.GetStatus()), merr.ErrCollectionSchemaMismatch) {
 			return true, merr.Error(rsp.GetStatus())
 		}
 		return false, nil
@@ -3322,6 +3335,7 @@ func (node *Proxy) hybridSearch(ctx context.Context, request *milvuspb.HybridSea
 		node:                node,
 		lb:                  node.lbPolicy,
 		mustUsePartitionKey: Params.ProxyCfg.MustUsePartitionKey.GetAsBool(),
+		dqQueueLen:          node.dqQueueLen,
 	}
 
 	log := log.Ctx(ctx).With(
@@ -4489,6 +4503,29 @@ func (node *Proxy) Dummy(ctx context.Context, req *milvuspb.DummyRequest) (*milv
 		}, nil
 	}
 
+	if drt.RequestType == "cancel_search" {
+		dcr, err := parseDummyCancelSearchRequest(req.RequestType)
+		if err != nil || dcr.RequestID == "" {
+			log.Warn("Failed to parse dummy cancel search request",
+				zap.Error(err))
+			return failedResponse, nil
+		}
+
+		// only the searches of the current user could be canceled. The in-flight searches are registered on the proxy
+		// serving them, the cancel shall be routed to the same proxy, e.g. by the session of the client, a search not
+		// found here may be served by another proxy, or already finished.
+		if !cancelInFlightSearch(GetCurUserFromContextOrDefault(ctx), dcr.RequestID) {
+			log.Info("no in-flight search to cancel on this proxy", zap.String("requestID", dcr.RequestID))
+			return &milvuspb.DummyResponse{
+				Response: `{"status": "not_found"}`,
+			}, nil
+		}
+
+		return &milvuspb.DummyResponse{
+			Response: `{"status": "success"}`,
+		}, nil
+	}
+
 	log.Debug("cannot find specify dummy request type")
 	return failedResponse, nil
 }
@@ -1928,3 +1928,35 @@ func TestProxy_ListFileResources(t *testing.T) {
 		assert.Error(t, merr.Error(resp.GetStatus()))
 	})
 }
+
+func TestProxy_DummyCancelSearch(t *testing.T) {
+	paramtable.Init()
+	node := &Proxy{}
+	searchCtx, cancel := context.WithCancelCause(context.Background())
+	require.NoError(t, registerInFlightSearch("alice", "search_1", cancel))
+	defer unregisterInFlightSearch("alice", "search_1")
+
+	req := &milvuspb.DummyRequest{RequestType: `{"request_type": "cancel_search", "request_id": "search_1"}`}
+	// the search of another user is not visible
+	resp, err := node.Dummy(GetContext(context.Background(), "bob:123456"), req)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status": "not_found"}`, resp.GetResponse())
+	assert.NoError(t, searchCtx.Err())
+
+	// the search served by another proxy, or already finished, is not found on this proxy
+	resp, err = node.Dummy(GetContext(context.Background(), "alice:123456"),
+		&milvuspb.DummyRequest{RequestType: `{"request_type": "cancel_search", "request_id": "search_2"}`})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status": "not_found"}`, resp.GetResponse())
+	assert.NoError(t, searchCtx.Err())
+
+	resp, err = node.Dummy(GetContext(context.Background(), "alice:123456"), req)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status": "success"}`, resp.GetResponse())
+	assert.ErrorIs(t, context.Cause(searchCtx), context.Canceled)
+
+	req = &milvuspb.DummyRequest{RequestType: `{"request_type": "cancel_search"}`}
+	resp, err = node.Dummy(GetContext(context.Background(), "alice:123456"), req)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status": "fail"}`, resp.GetResponse())
+}
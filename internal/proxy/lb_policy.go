@@ -43,6 +43,10 @@ type ChannelWorkload struct {
 	channel        string
 	nq             int64
 	exec           executeFunc
+	// the workload is only executed on these nodes if specified, bypassing the balancer.
+	pinnedNodes []int64
+	// the workload is only executed on these nodes if specified, selected by the balancer among them.
+	allowedNodes []int64
 }
 
 type CollectionWorkLoad struct {
@@ -51,6 +55,10 @@ type CollectionWorkLoad struct {
 	collectionID   int64
 	nq             int64
 	exec           executeFunc
+	// the workload is only executed on these nodes if specified, bypassing the balancer.
+	pinnedNodes []int64
+	// the workload is only executed on these nodes if specified, selected by the balancer among them.
+	allowedNodes []int64
 }
 
 type LBPolicy interface {
@@ -185,6 +193,24 @@ func (lb *LBPolicyImpl) selectNode(ctx context.Context, balancer LBBalancer, wor
 				candidateNodes[node.nodeID] = node
 			}
 		}
+		if len(workload.allowedNodes) > 0 {
+			candidateNodes = lo.PickByKeys(candidateNodes, workload.allowedNodes)
+			serviceableNodes = lo.PickByKeys(serviceableNodes, workload.allowedNodes)
+			if len(candidateNodes) == 0 {
+				err = merr.WrapErrChannelNotAvailable(workload.channel, fmt.Sprintf("no available shard leader among the allowed nodes %v", workload.allowedNodes))
+				return nodeInfo{}, err
+			}
+		}
+		if len(workload.pinnedNodes) > 0 {
+			// the first pinned node leading the shard is selected, in the order they are specified
+			for _, nodeID := range workload.pinnedNodes {
+				if node, ok := candidateNodes[nodeID]; ok {
+					return node, nil
+				}
+			}
+			err = merr.WrapErrChannelNotAvailable(workload.channel, fmt.Sprintf("no available shard leader among the pinned nodes %v", workload.pinnedNodes))
+			return nodeInfo{}, err
+		}
 		if len(candidateNodes) == 0 {
 			err = merr.WrapErrChannelNotAvailable(workload.channel, "no available shard leaders")
 			return nodeInfo{}, err
@@ -247,8 +273,10 @@ func (lb *LBPolicyImpl) ExecuteWithRetry(ctx context.Context, workload ChannelWo
 			}
 			return true, err
 		}
-		// cancel work load which assign to the target node
-		defer balancer.CancelWorkload(targetNode.nodeID, workload.nq)
+		if len(workload.pinnedNodes) == 0 {
+			// cancel work load which assign to the target node
+			defer balancer.CancelWorkload(targetNode.nodeID, workload.nq)
+		}
 
 		client, err := lb.clientMgr.GetClient(ctx, targetNode)
 		if err != nil {
@@ -317,6 +345,8 @@ func (lb *LBPolicyImpl) Execute(ctx context.Context, workload CollectionWorkLoad
 				channel:        channel,
 				nq:             workload.nq,
 				exec:           workload.exec,
+				pinnedNodes:    workload.pinnedNodes,
+				allowedNodes:   workload.allowedNodes,
 			})
 		})
 	}
@@ -340,6 +370,8 @@ func (lb *LBPolicyImpl) ExecuteOneChannel(ctx context.Context, workload Collecti
 			channel:        channel,
 			nq:             workload.nq,
 			exec:           workload.exec,
+			pinnedNodes:    workload.pinnedNodes,
+			allowedNodes:   workload.allowedNodes,
 		})
 	}
 	return fmt.Errorf("no acitvate sheard leader exist for collection: %s", workload.collectionName)
@@ -469,6 +469,88 @@ func (s *LBPolicySuite) TestExecute() {
 	s.ErrorIs(err, mockErr)
 }
 
+func (s *LBPolicySuite) TestExecuteWithPinnedNodes() {
+	ctx := context.Background()
+	// the balancer is bypassed, no expectation is set on it
+	s.mgr.EXPECT().GetClient(mock.Anything, mock.Anything).Return(s.qn, nil)
+	executed := typeutil.NewConcurrentMap[string, int64]()
+	err := s.lbPolicy.Execute(ctx, CollectionWorkLoad{
+		db:             dbName,
+		collectionName: s.collectionName,
+		collectionID:   s.collectionID,
+		nq:             1,
+		exec: func(ctx context.Context, nodeID UniqueID, qn types.QueryNodeClient, channel string) error {
+			executed.Insert(channel, nodeID)
+			return nil
+		},
+		pinnedNodes: []int64{100, 3, 4},
+	})
+	s.NoError(err)
+	for _, channel := range s.channels {
+		nodeID, ok := executed.Get(channel)
+		s.True(ok)
+		s.Equal(int64(3), nodeID)
+	}
+
+	// none of the pinned nodes leads the shard
+	err = s.lbPolicy.Execute(ctx, CollectionWorkLoad{
+		db:             dbName,
+		collectionName: s.collectionName,
+		collectionID:   s.collectionID,
+		nq:             1,
+		exec: func(ctx context.Context, nodeID UniqueID, qn types.QueryNodeClient, channel string) error {
+			return nil
+		},
+		pinnedNodes: []int64{100},
+	})
+	s.ErrorIs(err, merr.ErrChannelNotAvailable)
+	s.ErrorContains(err, "pinned nodes [100]")
+}
+
+func (s *LBPolicySuite) TestExecuteWithAllowedNodes() {
+	ctx := context.Background()
+	s.mgr.EXPECT().GetClient(mock.Anything, mock.Anything).Return(s.qn, nil)
+	s.lbBalancer.EXPECT().RegisterNodeInfo(mock.Anything)
+	s.lbBalancer.EXPECT().SelectNode(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, availableNodes []int64, nq int64) (int64, error) {
+		// the balancer selects among the allowed nodes only
+		s.Equal([]int64{4}, availableNodes)
+		return availableNodes[0], nil
+	})
+	s.lbBalancer.EXPECT().CancelWorkload(mock.Anything, mock.Anything)
+	executed := typeutil.NewConcurrentMap[string, int64]()
+	err := s.lbPolicy.Execute(ctx, CollectionWorkLoad{
+		db:             dbName,
+		collectionName: s.collectionName,
+		collectionID:   s.collectionID,
+		nq:             1,
+		exec: func(ctx context.Context, nodeID UniqueID, qn types.QueryNodeClient, channel string) error {
+			executed.Insert(channel, nodeID)
+			return nil
+		},
+		allowedNodes: []int64{100, 4},
+	})
+	s.NoError(err)
+	for _, channel := range s.channels {
+		nodeID, ok := executed.Get(channel)
+		s.True(ok)
+		s.Equal(int64(4), nodeID)
+	}
+
+	// none of the allowed nodes leads the shard
+	err = s.lbPolicy.Execute(ctx, CollectionWorkLoad{
+		db:             dbName,
+		collectionName: s.collectionName,
+		collectionID:   s.collectionID,
+		nq:             1,
+		exec: func(ctx context.Context, nodeID UniqueID, qn types.QueryNodeClient, channel string) error {
+			return nil
+		},
+		allowedNodes: []int64{100},
+	})
+	s.ErrorIs(err, merr.ErrChannelNotAvailable)
+	s.ErrorContains(err, "allowed nodes [100]")
+}
+
 func (s *LBPolicySuite) TestUpdateCostMetrics() {
 	s.lbBalancer.EXPECT().UpdateCostMetrics(mock.Anything, mock.Anything)
 	s.lbPolicy.UpdateCostMetrics(1, &internalpb.CostAggregation{})
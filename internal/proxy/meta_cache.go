@@ -23,8 +23,10 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/samber/lo"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
@@ -36,6 +38,7 @@ import (
 	"github.com/milvus-io/milvus/pkg/v2/common"
 	"github.com/milvus-io/milvus/pkg/v2/log"
 	"github.com/milvus-io/milvus/pkg/v2/metrics"
+	"github.com/milvus-io/milvus/pkg/v2/proto/indexpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/querypb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/rootcoordpb"
@@ -91,6 +94,11 @@ type Cache interface {
 	RemoveDatabase(ctx context.Context, database string)
 	HasDatabase(ctx context.Context, database string) bool
 	GetDatabaseInfo(ctx context.Context, database string) (*databaseInfo, error)
+	// GetIndexMetricTypes returns the metric type of each indexed field of the collection.
+	GetIndexMetricTypes(ctx context.Context, collectionID int64) (map[int64]string, error)
+	RemoveIndexMetricTypes(collectionID int64)
+	// GetResourceGroupInfo returns the info of the resource group, such as the nodes and the loaded replicas.
+	GetResourceGroupInfo(ctx context.Context, resourceGroup string) (*querypb.ResourceGroupInfo, error)
 	// AllocID is only using on requests that need to skip timestamp allocation, don't overuse it.
 	AllocID(ctx context.Context) (int64, error)
 }
@@ -368,8 +376,20 @@ type MetaCache struct {
 	IDLock  sync.RWMutex
 
 	collectionCacheVersion map[UniqueID]uint64 // collectionID -> cacheVersion
+
+	indexMetricTypes *expirable.LRU[UniqueID, map[int64]string] // collectionID -> fieldID -> metric type of the index
+
+	resourceGroupInfos *expirable.LRU[string, *querypb.ResourceGroupInfo] // resource group name -> info
 }
 
+// indexMetricTypesTTL bounds how long the metric types of the indexes are cached. The cache is invalidated by the
+// index DDLs through this proxy, but the ones through the other proxies are not notified, so the cache expires soon.
+const indexMetricTypesTTL = 10 * time.Second
+
+// resourceGroupInfoTTL bounds how long the info of the resource groups is cached, the proxies are not notified of
+// the changes of the resource groups, such as the nodes transferred and the replicas loaded.
+const resourceGroupInfoTTL = 10 * time.Second
+
 // globalMetaCache is singleton instance of Cache
 var globalMetaCache Cache
 
@@ -405,6 +425,8 @@ func NewMetaCache(mixCoord types.MixCoordClient, shardMgr shardClientMgr) (*Meta
 		privilegeInfos:         map[string]struct{}{},
 		userToRoles:            map[string]map[string]struct{}{},
 		collectionCacheVersion: make(map[UniqueID]uint64),
+		indexMetricTypes:       expirable.NewLRU[UniqueID, map[int64]string](1024, nil, indexMetricTypesTTL),
+		resourceGroupInfos:     expirable.NewLRU[string, *querypb.ResourceGroupInfo](1024, nil, resourceGroupInfoTTL),
 	}, nil
 }
 
@@ -891,6 +913,7 @@ func (m *MetaCache) RemoveCollectionsByID(ctx context.Context, collectionID Uniq
 			}
 		}
 	}
+	m.indexMetricTypes.Remove(collectionID)
 	if removeVersion {
 		delete(m.collectionCacheVersion, collectionID)
 	} else if version != 0 {
@@ -902,6 +925,48 @@ func (m *MetaCache) RemoveCollectionsByID(ctx context.Context, collectionID Uniq
 	return collNames
 }
 
+// GetIndexMetricTypes returns the metric type of each indexed field of the collection, the indexes without
+// metric type are skipped.
+func (m *MetaCache) GetIndexMetricTypes(ctx context.Context, collectionID int64) (map[int64]string, error) {
+	if metricTypes, ok := m.indexMetricTypes.Get(collectionID); ok {
+		return metricTypes, nil
+	}
+	resp, err := m.mixCoord.DescribeIndex(ctx, &indexpb.DescribeIndexRequest{CollectionID: collectionID})
+	err = merr.CheckRPCCall(resp, err)
+	if err != nil && !errors.Is(err, merr.ErrIndexNotFound) {
+		return nil, err
+	}
+	metricTypes := make(map[int64]string)
+	for _, index := range resp.GetIndexInfos() {
+		metricType, err := funcutil.GetAttrByKeyFromRepeatedKV(common.MetricTypeKey, index.GetIndexParams())
+		if err == nil {
+			metricTypes[index.GetFieldID()] = metricType
+		}
+	}
+	m.indexMetricTypes.Add(collectionID, metricTypes)
+	return metricTypes, nil
+}
+
+// RemoveIndexMetricTypes invalidates the cached metric types of the indexes of the collection.
+func (m *MetaCache) RemoveIndexMetricTypes(collectionID int64) {
+	m.indexMetricTypes.Remove(collectionID)
+}
+
+// GetResourceGroupInfo returns the info of the resource group, the info is cached for resourceGroupInfoTTL.
+func (m *MetaCache) GetResourceGroupInfo(ctx context.Context, resourceGroup string) (*querypb.ResourceGroupInfo, error) {
+	if info, ok := m.resourceGroupInfos.Get(resourceGroup); ok {
+		return info, nil
+	}
+	resp, err := m.mixCoord.DescribeResourceGroup(ctx, &querypb.DescribeResourceGroupRequest{
+		ResourceGroup: resourceGroup,
+	})
+	if err := merr.CheckRPCCall(resp, err); err != nil {
+		return nil, err
+	}
+	m.resourceGroupInfos.Add(resourceGroup, resp.GetResourceGroup())
+	return resp.GetResourceGroup(), nil
+}
+
 // GetCredentialInfo returns the credential related to provided username
 // If the cache missed, proxy will try to fetch from storage
 func (m *MetaCache) GetCredentialInfo(ctx context.Context, username string) (*internalpb.CredentialInfo, error) {
@@ -46,6 +46,7 @@ import (
 	"github.com/milvus-io/milvus/pkg/v2/util/crypto"
 	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
 	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
 	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
 	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
 )
@@ -2322,3 +2323,71 @@ func TestMetaCache_GetShardLeaderList(t *testing.T) {
 		assert.Empty(t, channels)
 	})
 }
+
+func TestMetaCache_GetIndexMetricTypes(t *testing.T) {
+	ctx := context.Background()
+	mixCoord := NewMixCoordMock()
+	mixCoord.DescribeIndexFunc = func(ctx context.Context, request *indexpb.DescribeIndexRequest, opts ...grpc.CallOption) (*indexpb.DescribeIndexResponse, error) {
+		return &indexpb.DescribeIndexResponse{
+			Status: merr.Success(),
+			IndexInfos: []*indexpb.IndexInfo{
+				{FieldID: 101, IndexParams: []*commonpb.KeyValuePair{{Key: common.MetricTypeKey, Value: metric.COSINE}}},
+				{FieldID: 102, IndexParams: []*commonpb.KeyValuePair{{Key: common.IndexTypeKey, Value: "INVERTED"}}},
+			},
+		}, nil
+	}
+	cache, err := NewMetaCache(mixCoord, nil)
+	require.NoError(t, err)
+
+	metricTypes, err := cache.GetIndexMetricTypes(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{101: metric.COSINE}, metricTypes)
+
+	// cached
+	mixCoord.DescribeIndexFunc = func(ctx context.Context, request *indexpb.DescribeIndexRequest, opts ...grpc.CallOption) (*indexpb.DescribeIndexResponse, error) {
+		return nil, errors.New("mock error")
+	}
+	metricTypes, err = cache.GetIndexMetricTypes(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]string{101: metric.COSINE}, metricTypes)
+	_, err = cache.GetIndexMetricTypes(ctx, 2)
+	assert.Error(t, err)
+
+	// invalidated by the index ddl and the collection invalidation
+	mixCoord.DescribeIndexFunc = func(ctx context.Context, request *indexpb.DescribeIndexRequest, opts ...grpc.CallOption) (*indexpb.DescribeIndexResponse, error) {
+		return &indexpb.DescribeIndexResponse{Status: merr.Status(merr.WrapErrIndexNotFound("idx"))}, nil
+	}
+	cache.RemoveIndexMetricTypes(1)
+	metricTypes, err = cache.GetIndexMetricTypes(ctx, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, metricTypes)
+
+	cache.indexMetricTypes.Add(1, map[int64]string{101: metric.L2})
+	cache.RemoveCollectionsByID(ctx, 1, 0, false)
+	metricTypes, err = cache.GetIndexMetricTypes(ctx, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, metricTypes)
+}
+
+func TestMetaCache_GetResourceGroupInfo(t *testing.T) {
+	ctx := context.Background()
+	mixCoord := mocks.NewMockMixCoordClient(t)
+	mixCoord.EXPECT().DescribeResourceGroup(mock.Anything, mock.Anything).Return(&querypb.DescribeResourceGroupResponse{
+		Status:        merr.Success(),
+		ResourceGroup: &querypb.ResourceGroupInfo{Name: "rg1", Nodes: []*commonpb.NodeInfo{{NodeId: 3}}},
+	}, nil).Once()
+	mixCoord.EXPECT().DescribeResourceGroup(mock.Anything, mock.Anything).Return(&querypb.DescribeResourceGroupResponse{
+		Status: merr.Status(merr.WrapErrResourceGroupNotFound("rg2")),
+	}, nil).Once()
+	cache, err := NewMetaCache(mixCoord, nil)
+	require.NoError(t, err)
+
+	// described only once
+	for i := 0; i < 2; i++ {
+		info, err := cache.GetResourceGroupInfo(ctx, "rg1")
+		require.NoError(t, err)
+		assert.Equal(t, "rg1", info.GetName())
+	}
+	_, err = cache.GetResourceGroupInfo(ctx, "rg2")
+	assert.ErrorIs(t, err, merr.ErrResourceGroupNotFound)
+}
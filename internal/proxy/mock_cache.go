@@ -8,6 +8,8 @@ import (
 	internalpb "github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
 	mock "github.com/stretchr/testify/mock"
 
+	querypb "github.com/milvus-io/milvus/pkg/v2/proto/querypb"
+
 	typeutil "github.com/milvus-io/milvus/pkg/v2/util/typeutil"
 )
 
@@ -469,6 +471,65 @@ func (_c *MockCache_GetDatabaseInfo_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// GetIndexMetricTypes provides a mock function with given fields: ctx, collectionID
+func (_m *MockCache) GetIndexMetricTypes(ctx context.Context, collectionID int64) (map[int64]string, error) {
+	ret := _m.Called(ctx, collectionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIndexMetricTypes")
+	}
+
+	var r0 map[int64]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (map[int64]string, error)); ok {
+		return rf(ctx, collectionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) map[int64]string); ok {
+		r0 = rf(ctx, collectionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int64]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, collectionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCache_GetIndexMetricTypes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetIndexMetricTypes'
+type MockCache_GetIndexMetricTypes_Call struct {
+	*mock.Call
+}
+
+// GetIndexMetricTypes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - collectionID int64
+func (_e *MockCache_Expecter) GetIndexMetricTypes(ctx interface{}, collectionID interface{}) *MockCache_GetIndexMetricTypes_Call {
+	return &MockCache_GetIndexMetricTypes_Call{Call: _e.mock.On("GetIndexMetricTypes", ctx, collectionID)}
+}
+
+func (_c *MockCache_GetIndexMetricTypes_Call) Run(run func(ctx context.Context, collectionID int64)) *MockCache_GetIndexMetricTypes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockCache_GetIndexMetricTypes_Call) Return(_a0 map[int64]string, _a1 error) *MockCache_GetIndexMetricTypes_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCache_GetIndexMetricTypes_Call) RunAndReturn(run func(context.Context, int64) (map[int64]string, error)) *MockCache_GetIndexMetricTypes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetPartitionID provides a mock function with given fields: ctx, database, collectionName, partitionName
 func (_m *MockCache) GetPartitionID(ctx context.Context, database string, collectionName string, partitionName string) (int64, error) {
 	ret := _m.Called(ctx, database, collectionName, partitionName)
@@ -757,6 +818,65 @@ func (_c *MockCache_GetPrivilegeInfo_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// GetResourceGroupInfo provides a mock function with given fields: ctx, resourceGroup
+func (_m *MockCache) GetResourceGroupInfo(ctx context.Context, resourceGroup string) (*querypb.ResourceGroupInfo, error) {
+	ret := _m.Called(ctx, resourceGroup)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetResourceGroupInfo")
+	}
+
+	var r0 *querypb.ResourceGroupInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*querypb.ResourceGroupInfo, error)); ok {
+		return rf(ctx, resourceGroup)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *querypb.ResourceGroupInfo); ok {
+		r0 = rf(ctx, resourceGroup)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*querypb.ResourceGroupInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, resourceGroup)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCache_GetResourceGroupInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResourceGroupInfo'
+type MockCache_GetResourceGroupInfo_Call struct {
+	*mock.Call
+}
+
+// GetResourceGroupInfo is a helper method to define mock.On call
+//   - ctx context.Context
+//   - resourceGroup string
+func (_e *MockCache_Expecter) GetResourceGroupInfo(ctx interface{}, resourceGroup interface{}) *MockCache_GetResourceGroupInfo_Call {
+	return &MockCache_GetResourceGroupInfo_Call{Call: _e.mock.On("GetResourceGroupInfo", ctx, resourceGroup)}
+}
+
+func (_c *MockCache_GetResourceGroupInfo_Call) Run(run func(ctx context.Context, resourceGroup string)) *MockCache_GetResourceGroupInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockCache_GetResourceGroupInfo_Call) Return(_a0 *querypb.ResourceGroupInfo, _a1 error) *MockCache_GetResourceGroupInfo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCache_GetResourceGroupInfo_Call) RunAndReturn(run func(context.Context, string) (*querypb.ResourceGroupInfo, error)) *MockCache_GetResourceGroupInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetShard provides a mock function with given fields: ctx, withCache, database, collectionName, collectionID, channel
 func (_m *MockCache) GetShard(ctx context.Context, withCache bool, database string, collectionName string, collectionID int64, channel string) ([]nodeInfo, error) {
 	ret := _m.Called(ctx, withCache, database, collectionName, collectionID, channel)
@@ -1290,6 +1410,39 @@ func (_c *MockCache_RemoveDatabase_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// RemoveIndexMetricTypes provides a mock function with given fields: collectionID
+func (_m *MockCache) RemoveIndexMetricTypes(collectionID int64) {
+	_m.Called(collectionID)
+}
+
+// MockCache_RemoveIndexMetricTypes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveIndexMetricTypes'
+type MockCache_RemoveIndexMetricTypes_Call struct {
+	*mock.Call
+}
+
+// RemoveIndexMetricTypes is a helper method to define mock.On call
+//   - collectionID int64
+func (_e *MockCache_Expecter) RemoveIndexMetricTypes(collectionID interface{}) *MockCache_RemoveIndexMetricTypes_Call {
+	return &MockCache_RemoveIndexMetricTypes_Call{Call: _e.mock.On("RemoveIndexMetricTypes", collectionID)}
+}
+
+func (_c *MockCache_RemoveIndexMetricTypes_Call) Run(run func(collectionID int64)) *MockCache_RemoveIndexMetricTypes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *MockCache_RemoveIndexMetricTypes_Call) Return() *MockCache_RemoveIndexMetricTypes_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockCache_RemoveIndexMetricTypes_Call) RunAndReturn(run func(int64)) *MockCache_RemoveIndexMetricTypes_Call {
+	_c.Run(run)
+	return _c
+}
+
 // UpdateCredential provides a mock function with given fields: credInfo
 func (_m *MockCache) UpdateCredential(credInfo *internalpb.CredentialInfo) {
 	_m.Called(credInfo)
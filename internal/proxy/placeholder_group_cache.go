@@ -0,0 +1,110 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+var (
+	placeholderGroupCacheOnce sync.Once
+	placeholderGroupCache     *tokenPlaceholderGroupCache
+)
+
+// getPlaceholderGroupCache returns the global placeholder group cache, nil is returned if the cache is disabled.
+func getPlaceholderGroupCache() *tokenPlaceholderGroupCache {
+	placeholderGroupCacheOnce.Do(func() {
+		size := Params.ProxyCfg.PlaceholderGroupCacheSize.GetAsInt()
+		if size <= 0 {
+			return
+		}
+		placeholderGroupCache = newTokenPlaceholderGroupCache(size,
+			Params.ProxyCfg.PlaceholderGroupCacheMaxBytes.GetAsSize(),
+			Params.ProxyCfg.PlaceholderGroupCacheTTL.GetAsDuration(time.Second))
+	})
+	return placeholderGroupCache
+}
+
+type cachedPlaceholderGroup struct {
+	username         string
+	collectionID     int64
+	placeholderGroup []byte
+}
+
+// tokenPlaceholderGroupCache keeps the placeholder groups uploaded by searches, so that the following searches
+// could reference them by token instead of sending the vectors again. It is bounded by both the number of the
+// placeholder groups and their total size, the least recently used ones are evicted first.
+type tokenPlaceholderGroupCache struct {
+	mu       sync.Mutex
+	groups   *expirable.LRU[string, *cachedPlaceholderGroup]
+	maxBytes int64
+	// usedBytes is decreased by the eviction callback, which may run in the expiration goroutine of the lru.
+	usedBytes atomic.Int64
+}
+
+func newTokenPlaceholderGroupCache(size int, maxBytes int64, ttl time.Duration) *tokenPlaceholderGroupCache {
+	c := &tokenPlaceholderGroupCache{maxBytes: maxBytes}
+	c.groups = expirable.NewLRU[string, *cachedPlaceholderGroup](size, func(_ string, cached *cachedPlaceholderGroup) {
+		c.usedBytes.Add(-int64(len(cached.placeholderGroup)))
+	}, ttl)
+	return c
+}
+
+// add caches the placeholder group and returns its token, the placeholder group larger than the whole cache is rejected.
+func (c *tokenPlaceholderGroupCache) add(username string, collectionID int64, placeholderGroup []byte) (string, error) {
+	size := int64(len(placeholderGroup))
+	if size > c.maxBytes {
+		return "", merr.WrapErrParameterTooLarge(CachePlaceholderGroupKey,
+			fmt.Sprintf("the placeholder group size %d exceeds the cache size %d", size, c.maxBytes))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	token := uuid.NewString()
+	c.usedBytes.Add(size)
+	c.groups.Add(token, &cachedPlaceholderGroup{
+		username:         username,
+		collectionID:     collectionID,
+		placeholderGroup: placeholderGroup,
+	})
+	for c.usedBytes.Load() > c.maxBytes {
+		if _, _, ok := c.groups.RemoveOldest(); !ok {
+			break
+		}
+	}
+	return token, nil
+}
+
+// get returns the placeholder group referenced by the token, the token is only valid for the user and the collection
+// it is created by.
+func (c *tokenPlaceholderGroupCache) get(username string, collectionID int64, token string) ([]byte, error) {
+	cached, ok := c.groups.Get(token)
+	if !ok || cached.username != username {
+		return nil, merr.WrapErrParameterExpired(token, "placeholder group token is expired or not found")
+	}
+	if cached.collectionID != collectionID {
+		return nil, merr.WrapErrParameterInvalidMsg("placeholder group token %s does not belong to collection %d", token, collectionID)
+	}
+	return cached.placeholderGroup, nil
+}
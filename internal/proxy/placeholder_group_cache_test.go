@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+func TestTokenPlaceholderGroupCache(t *testing.T) {
+	cache := newTokenPlaceholderGroupCache(2, 1024, time.Minute)
+
+	token, err := cache.add("alice", 1, []byte("placeholder"))
+	assert.NoError(t, err)
+	placeholderGroup, err := cache.get("alice", 1, token)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("placeholder"), placeholderGroup)
+
+	_, err = cache.get("alice", 2, token)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	// the token is not visible to the other users
+	_, err = cache.get("bob", 1, token)
+	assert.ErrorIs(t, err, merr.ErrParameterExpired)
+
+	_, err = cache.get("alice", 1, "unknown")
+	assert.ErrorIs(t, err, merr.ErrParameterExpired)
+
+	// evicted by newer ones
+	cache.add("alice", 1, []byte("a"))
+	cache.add("alice", 1, []byte("b"))
+	_, err = cache.get("alice", 1, token)
+	assert.ErrorIs(t, err, merr.ErrParameterExpired)
+
+	cache = newTokenPlaceholderGroupCache(2, 1024, 10*time.Millisecond)
+	token, err = cache.add("alice", 1, []byte("placeholder"))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, err := cache.get("alice", 1, token)
+		return errors.Is(err, merr.ErrParameterExpired)
+	}, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return cache.usedBytes.Load() == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTokenPlaceholderGroupCache_MaxBytes(t *testing.T) {
+	cache := newTokenPlaceholderGroupCache(100, 10, time.Minute)
+
+	_, err := cache.add("alice", 1, make([]byte, 11))
+	assert.ErrorIs(t, err, merr.ErrParameterTooLarge)
+
+	first, err := cache.add("alice", 1, make([]byte, 6))
+	assert.NoError(t, err)
+	second, err := cache.add("alice", 1, make([]byte, 4))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, cache.usedBytes.Load())
+
+	// the least recently used one is evicted to make room
+	third, err := cache.add("alice", 1, make([]byte, 5))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 9, cache.usedBytes.Load())
+	_, err = cache.get("alice", 1, first)
+	assert.ErrorIs(t, err, merr.ErrParameterExpired)
+	for _, token := range []string{second, third} {
+		_, err = cache.get("alice", 1, token)
+		assert.NoError(t, err)
+	}
+}
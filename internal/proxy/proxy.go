@@ -381,3 +381,11 @@ func (node *Proxy) GetRateLimiter() (types.Limiter, error) {
 	}
 	return node.simpleLimiter, nil
 }
+
+// dqQueueLen returns the number of queued search and query tasks, which indicates the load of proxy.
+func (node *Proxy) dqQueueLen() int {
+	if node.sched == nil {
+		return 0
+	}
+	return node.sched.dqQueue.utLen()
+}
@@ -0,0 +1,99 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+// searchMaxResultAge drops the hits older than the max age, set by max_result_age.
+type searchMaxResultAge struct {
+	// the field of the unix timestamps in seconds the ages of the hits are computed by
+	field  *schemapb.FieldSchema
+	maxAge time.Duration
+	// the field is not one of the output fields, it is fetched only for filtering.
+	fetched bool
+}
+
+// parseMaxResultAge resolves the max age of the hits, set by max_result_age in the form of a duration, e.g. 24h,
+// and the field of the unix timestamps in seconds set by max_result_age_field. Unlike the collection TTL, it works
+// per search. The grouping search is not supported, since a group may be partially dropped.
+func (t *searchTask) parseMaxResultAge() (*searchMaxResultAge, error) {
+	maxAgeStr, err := funcutil.GetAttrByKeyFromRepeatedKV(MaxResultAgeKey, t.request.GetSearchParams())
+	if err != nil || maxAgeStr == "" {
+		return nil, nil
+	}
+	maxAge, err := time.ParseDuration(maxAgeStr)
+	if err != nil || maxAge <= 0 {
+		return nil, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a positive duration, e.g. 24h", MaxResultAgeKey, maxAgeStr)
+	}
+	isIterator, _ := getBoolSearchParam(t.request.GetSearchParams(), IteratorField)
+	groupByField, _ := funcutil.GetAttrByKeyFromRepeatedKV(GroupByFieldKey, t.request.GetSearchParams())
+	switch {
+	case isIterator:
+		return nil, merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator", MaxResultAgeKey)
+	case groupByField != "":
+		return nil, merr.WrapErrParameterInvalidMsg("%s is not supported by grouping search", MaxResultAgeKey)
+	}
+
+	fieldName, err := funcutil.GetAttrByKeyFromRepeatedKV(MaxResultAgeFieldKey, t.request.GetSearchParams())
+	if err != nil || fieldName == "" {
+		return nil, merr.WrapErrParameterMissing(MaxResultAgeFieldKey, fmt.Sprintf("%s is required by %s", MaxResultAgeFieldKey, MaxResultAgeKey))
+	}
+	field := typeutil.GetFieldByName(t.schema.CollectionSchema, fieldName)
+	if field == nil {
+		return nil, merr.WrapErrFieldNotFound(fieldName, fmt.Sprintf("%s not found in schema", MaxResultAgeFieldKey))
+	}
+	if !typeutil.IsArithmetic(field.GetDataType()) {
+		return nil, merr.WrapErrParameterInvalidMsg("%s %s should be numeric, but got %s",
+			MaxResultAgeFieldKey, fieldName, field.GetDataType().String())
+	}
+	age := &searchMaxResultAge{field: field, maxAge: maxAge}
+	if !field.GetIsPrimaryKey() && !lo.Contains(t.translatedOutputFields, fieldName) {
+		t.translatedOutputFields = append(t.translatedOutputFields, fieldName)
+		t.SearchRequest.OutputFieldsId = append(t.SearchRequest.OutputFieldsId, field.GetFieldID())
+		age.fetched = true
+	}
+	return age, nil
+}
+
+// filterSearchResultDataByAge drops the hits whose timestamps are before now minus the max age,
+// the hits with null timestamps are dropped as well since their ages are unknown.
+func filterSearchResultDataByAge(data *schemapb.SearchResultData, age *searchMaxResultAge, now time.Time) {
+	getTimestamp := sortKeyGetter(data, age.field)
+	cutoff := now.Add(-age.maxAge).Unix()
+	var idx int64
+	filterSearchResultData(data, func(_ int, _ float32) bool {
+		value, ok := getTimestamp(idx)
+		idx++
+		switch value := value.(type) {
+		case int64:
+			return ok && value >= cutoff
+		case float64:
+			return ok && value >= float64(cutoff)
+		}
+		return false
+	})
+}
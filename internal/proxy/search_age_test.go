@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+func TestSearchTask_ParseMaxResultAge(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "created_at", DataType: schemapb.DataType_Int64},
+			{FieldID: 102, Name: "title", DataType: schemapb.DataType_VarChar},
+		},
+	})
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			schema:                 schema,
+			translatedOutputFields: []string{"title"},
+			SearchRequest:          &internalpb.SearchRequest{OutputFieldsId: []int64{102}},
+			request:                &milvuspb.SearchRequest{SearchParams: params},
+		}
+	}
+
+	age, err := newTask().parseMaxResultAge()
+	assert.NoError(t, err)
+	assert.Nil(t, age)
+
+	// the timestamp field is fetched if it is not one of the output fields
+	task := newTask(MaxResultAgeKey, "24h", MaxResultAgeFieldKey, "created_at")
+	age, err = task.parseMaxResultAge()
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, age.maxAge)
+	assert.Equal(t, "created_at", age.field.GetName())
+	assert.True(t, age.fetched)
+	assert.Equal(t, []string{"title", "created_at"}, task.translatedOutputFields)
+	assert.Equal(t, []int64{102, 101}, task.SearchRequest.GetOutputFieldsId())
+
+	for _, kvs := range [][]string{
+		{MaxResultAgeKey, "a day", MaxResultAgeFieldKey, "created_at"},
+		{MaxResultAgeKey, "-1h", MaxResultAgeFieldKey, "created_at"},
+		{MaxResultAgeKey, "1h", MaxResultAgeFieldKey, "title"},
+		{MaxResultAgeKey, "1h", MaxResultAgeFieldKey, "created_at", IteratorField, "true"},
+		{MaxResultAgeKey, "1h", MaxResultAgeFieldKey, "created_at", GroupByFieldKey, "title"},
+	} {
+		_, err = newTask(kvs...).parseMaxResultAge()
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid, kvs)
+	}
+	_, err = newTask(MaxResultAgeKey, "1h").parseMaxResultAge()
+	assert.ErrorIs(t, err, merr.ErrParameterMissing)
+	_, err = newTask(MaxResultAgeKey, "1h", MaxResultAgeFieldKey, "updated_at").parseMaxResultAge()
+	assert.ErrorIs(t, err, merr.ErrFieldNotFound)
+}
+
+func TestFilterSearchResultDataByAge(t *testing.T) {
+	now := time.Unix(10000, 0)
+	data := &schemapb.SearchResultData{
+		NumQueries: 2,
+		TopK:       3,
+		Topks:      []int64{3, 2},
+		Scores:     []float32{0.9, 0.8, 0.7, 0.6, 0.5},
+		Ids: &schemapb.IDs{
+			IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 4, 5}}},
+		},
+		FieldsData: []*schemapb.FieldData{{
+			Type:      schemapb.DataType_Int64,
+			FieldName: "created_at",
+			ValidData: []bool{true, true, true, false, true},
+			Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+				Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: []int64{9000, 6000, 6400, 0, 9999}}},
+			}},
+		}},
+	}
+	age := &searchMaxResultAge{
+		field:  &schemapb.FieldSchema{FieldID: 101, Name: "created_at", DataType: schemapb.DataType_Int64},
+		maxAge: time.Hour,
+	}
+	filterSearchResultDataByAge(data, age, now)
+	// the null timestamps are dropped as well
+	assert.Equal(t, []int64{2, 1}, data.GetTopks())
+	assert.Equal(t, []int64{1, 3, 5}, data.GetIds().GetIntId().GetData())
+	assert.Equal(t, []float32{0.9, 0.7, 0.5}, data.GetScores())
+	assert.Equal(t, []int64{9000, 6400, 9999}, data.GetFieldsData()[0].GetScalars().GetLongData().GetData())
+}
@@ -0,0 +1,269 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+const (
+	searchResultFormatProtobuf = "protobuf"
+	searchResultFormatArrow    = "arrow"
+
+	// arrowResultField is the only field data returned if the results are encoded in arrow,
+	// it carries the arrow IPC stream of all the output fields.
+	arrowResultField = "$arrow"
+	// arrowDataTypeMetaKey is the field metadata telling the milvus data type of an arrow column.
+	arrowDataTypeMetaKey = "milvus.data_type"
+)
+
+// parseSearchResultFormat returns the encoding of the result field data, protobuf by default.
+func parseSearchResultFormat(params []*commonpb.KeyValuePair) (string, error) {
+	format, err := funcutil.GetAttrByKeyFromRepeatedKV(SearchResultFormatKey, params)
+	if err != nil || format == "" {
+		return searchResultFormatProtobuf, nil
+	}
+	if format != searchResultFormatProtobuf && format != searchResultFormatArrow {
+		return "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be %s or %s",
+			SearchResultFormatKey, format, searchResultFormatProtobuf, searchResultFormatArrow)
+	}
+	return format, nil
+}
+
+// arrowFieldType returns the arrow type of the field data.
+// Vectors are fixed size columns except the sparse ones, arrays are serialized scalar fields as they are stored.
+func arrowFieldType(field *schemapb.FieldData) (arrow.DataType, error) {
+	dim := int(field.GetVectors().GetDim())
+	switch field.GetType() {
+	case schemapb.DataType_Bool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case schemapb.DataType_Int8:
+		return arrow.PrimitiveTypes.Int8, nil
+	case schemapb.DataType_Int16:
+		return arrow.PrimitiveTypes.Int16, nil
+	case schemapb.DataType_Int32:
+		return arrow.PrimitiveTypes.Int32, nil
+	case schemapb.DataType_Int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case schemapb.DataType_Float:
+		return arrow.PrimitiveTypes.Float32, nil
+	case schemapb.DataType_Double:
+		return arrow.PrimitiveTypes.Float64, nil
+	case schemapb.DataType_String, schemapb.DataType_VarChar, schemapb.DataType_Text:
+		return arrow.BinaryTypes.String, nil
+	case schemapb.DataType_Array, schemapb.DataType_JSON, schemapb.DataType_Geometry, schemapb.DataType_SparseFloatVector:
+		return arrow.BinaryTypes.Binary, nil
+	case schemapb.DataType_FloatVector:
+		return arrow.FixedSizeListOf(int32(dim), arrow.PrimitiveTypes.Float32), nil
+	case schemapb.DataType_BinaryVector:
+		return &arrow.FixedSizeBinaryType{ByteWidth: (dim + 7) / 8}, nil
+	case schemapb.DataType_Float16Vector, schemapb.DataType_BFloat16Vector:
+		return &arrow.FixedSizeBinaryType{ByteWidth: dim * 2}, nil
+	case schemapb.DataType_Int8Vector:
+		return &arrow.FixedSizeBinaryType{ByteWidth: dim}, nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("field %s of type %s could not be returned in arrow format",
+			field.GetFieldName(), field.GetType().String())
+	}
+}
+
+// splitBytes splits the packed vectors into rows of the width.
+func splitBytes(data []byte, width int) [][]byte {
+	if width <= 0 {
+		return nil
+	}
+	return lo.Chunk(data, width)
+}
+
+// appendArrowColumn appends the rows of the field data to the column builder.
+// The field carries no data if it is a placeholder of the skipped vector fields, the column is filled with nulls then.
+func appendArrowColumn(builder array.Builder, field *schemapb.FieldData, numRows int) error {
+	var valid []bool
+	if len(field.GetValidData()) > 0 {
+		valid = field.GetValidData()
+	}
+	count := 0
+	switch b := builder.(type) {
+	case *array.BooleanBuilder:
+		data := field.GetScalars().GetBoolData().GetData()
+		b.AppendValues(data, valid)
+		count = len(data)
+	case *array.Int8Builder:
+		data := field.GetScalars().GetIntData().GetData()
+		b.AppendValues(lo.Map(data, func(v int32, _ int) int8 { return int8(v) }), valid)
+		count = len(data)
+	case *array.Int16Builder:
+		data := field.GetScalars().GetIntData().GetData()
+		b.AppendValues(lo.Map(data, func(v int32, _ int) int16 { return int16(v) }), valid)
+		count = len(data)
+	case *array.Int32Builder:
+		data := field.GetScalars().GetIntData().GetData()
+		b.AppendValues(data, valid)
+		count = len(data)
+	case *array.Int64Builder:
+		data := field.GetScalars().GetLongData().GetData()
+		b.AppendValues(data, valid)
+		count = len(data)
+	case *array.Float32Builder:
+		data := field.GetScalars().GetFloatData().GetData()
+		b.AppendValues(data, valid)
+		count = len(data)
+	case *array.Float64Builder:
+		data := field.GetScalars().GetDoubleData().GetData()
+		b.AppendValues(data, valid)
+		count = len(data)
+	case *array.StringBuilder:
+		data := field.GetScalars().GetStringData().GetData()
+		b.AppendValues(data, valid)
+		count = len(data)
+	case *array.BinaryBuilder:
+		var data [][]byte
+		switch field.GetType() {
+		case schemapb.DataType_JSON:
+			data = field.GetScalars().GetJsonData().GetData()
+		case schemapb.DataType_Geometry:
+			data = field.GetScalars().GetGeometryData().GetData()
+		case schemapb.DataType_SparseFloatVector:
+			data = field.GetVectors().GetSparseFloatVector().GetContents()
+		case schemapb.DataType_Array:
+			for _, element := range field.GetScalars().GetArrayData().GetData() {
+				bs, err := proto.Marshal(element)
+				if err != nil {
+					return err
+				}
+				data = append(data, bs)
+			}
+		}
+		b.AppendValues(data, valid)
+		count = len(data)
+	case *array.FixedSizeBinaryBuilder:
+		var data []byte
+		switch field.GetType() {
+		case schemapb.DataType_BinaryVector:
+			data = field.GetVectors().GetBinaryVector()
+		case schemapb.DataType_Float16Vector:
+			data = field.GetVectors().GetFloat16Vector()
+		case schemapb.DataType_BFloat16Vector:
+			data = field.GetVectors().GetBfloat16Vector()
+		case schemapb.DataType_Int8Vector:
+			data = field.GetVectors().GetInt8Vector()
+		}
+		rows := splitBytes(data, b.Type().(*arrow.FixedSizeBinaryType).ByteWidth)
+		b.AppendValues(rows, nil)
+		count = len(rows)
+	case *array.FixedSizeListBuilder:
+		dim := int(field.GetVectors().GetDim())
+		data := field.GetVectors().GetFloatVector().GetData()
+		values := b.ValueBuilder().(*array.Float32Builder)
+		for start := 0; dim > 0 && start+dim <= len(data); start += dim {
+			b.Append(true)
+			values.AppendValues(data[start:start+dim], nil)
+			count++
+		}
+	default:
+		return merr.WrapErrServiceInternal(fmt.Sprintf("unexpected arrow builder %T of field %s", builder, field.GetFieldName()))
+	}
+
+	if count == 0 && numRows > 0 {
+		builder.AppendNulls(numRows)
+		return nil
+	}
+	if count != numRows {
+		return merr.WrapErrServiceInternal(fmt.Sprintf("field %s has %d rows, but %d hits are returned", field.GetFieldName(), count, numRows))
+	}
+	return nil
+}
+
+// encodeFieldsDataToArrow encodes the field data of the hits into an arrow IPC stream with a single record batch,
+// the columns are in the same order as the field data.
+func encodeFieldsDataToArrow(fieldsData []*schemapb.FieldData, numRows int) ([]byte, error) {
+	fields := make([]arrow.Field, 0, len(fieldsData))
+	for _, fieldData := range fieldsData {
+		dataType, err := arrowFieldType(fieldData)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, arrow.Field{
+			Name:     fieldData.GetFieldName(),
+			Type:     dataType,
+			Nullable: true,
+			Metadata: arrow.NewMetadata([]string{arrowDataTypeMetaKey}, []string{fieldData.GetType().String()}),
+		})
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	mem := memory.NewGoAllocator()
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	for i, fieldData := range fieldsData {
+		if err := appendArrowColumn(builder.Field(i), fieldData, numRows); err != nil {
+			return nil, err
+		}
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(schema), ipc.WithAllocator(mem))
+	if err := writer.Write(record); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fillArrowResult replaces the field data of the result with the arrow encoded one, the output fields are kept as they are.
+func (t *searchTask) fillArrowResult() error {
+	results := t.result.GetResults()
+	if results == nil {
+		return nil
+	}
+	numRows := 0
+	for _, topk := range results.GetTopks() {
+		numRows += int(topk)
+	}
+	bs, err := encodeFieldsDataToArrow(results.GetFieldsData(), numRows)
+	if err != nil {
+		return err
+	}
+	results.FieldsData = []*schemapb.FieldData{{
+		Type:      schemapb.DataType_None,
+		FieldName: arrowResultField,
+		Field: &schemapb.FieldData_Scalars{
+			Scalars: &schemapb.ScalarField{
+				Data: &schemapb.ScalarField_BytesData{BytesData: &schemapb.BytesArray{Data: [][]byte{bs}}},
+			},
+		},
+	}}
+	setSearchResultExtraInfo(t.result, searchResultFormatKey, searchResultFormatArrow)
+	return nil
+}
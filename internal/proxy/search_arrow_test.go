@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+func TestParseSearchResultFormat(t *testing.T) {
+	format, err := parseSearchResultFormat(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, searchResultFormatProtobuf, format)
+
+	format, err = parseSearchResultFormat([]*commonpb.KeyValuePair{{Key: SearchResultFormatKey, Value: "arrow"}})
+	assert.NoError(t, err)
+	assert.Equal(t, searchResultFormatArrow, format)
+
+	_, err = parseSearchResultFormat([]*commonpb.KeyValuePair{{Key: SearchResultFormatKey, Value: "parquet"}})
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestSearchTask_FillArrowResult(t *testing.T) {
+	task := &searchTask{
+		result: &milvuspb.SearchResults{
+			Status: merr.Success(),
+			Results: &schemapb.SearchResultData{
+				NumQueries: 2,
+				TopK:       2,
+				Topks:      []int64{2, 1},
+				Scores:     []float32{0.9, 0.8, 0.7},
+				Ids: &schemapb.IDs{
+					IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3}}},
+				},
+				OutputFields: []string{"age", "name", "vec", "skipped"},
+				FieldsData: []*schemapb.FieldData{
+					{
+						Type:      schemapb.DataType_Int16,
+						FieldName: "age",
+						ValidData: []bool{true, false, true},
+						Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+							Data: &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: []int32{10, 0, 30}}},
+						}},
+					},
+					{
+						Type:      schemapb.DataType_VarChar,
+						FieldName: "name",
+						Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+							Data: &schemapb.ScalarField_StringData{StringData: &schemapb.StringArray{Data: []string{"a", "b", "c"}}},
+						}},
+					},
+					{
+						Type:      schemapb.DataType_FloatVector,
+						FieldName: "vec",
+						Field: &schemapb.FieldData_Vectors{Vectors: &schemapb.VectorField{
+							Dim:  2,
+							Data: &schemapb.VectorField_FloatVector{FloatVector: &schemapb.FloatArray{Data: []float32{1, 2, 3, 4, 5, 6}}},
+						}},
+					},
+					genPlaceholderVectorFieldData(&schemapb.FieldSchema{
+						Name:       "skipped",
+						DataType:   schemapb.DataType_BinaryVector,
+						TypeParams: []*commonpb.KeyValuePair{{Key: "dim", Value: "16"}},
+					}),
+				},
+			},
+		},
+	}
+	require.NoError(t, task.fillArrowResult())
+
+	results := task.result.GetResults()
+	assert.Equal(t, []string{"age", "name", "vec", "skipped"}, results.GetOutputFields())
+	require.Len(t, results.GetFieldsData(), 1)
+	assert.Equal(t, arrowResultField, results.GetFieldsData()[0].GetFieldName())
+	assert.Equal(t, searchResultFormatArrow, task.result.GetStatus().GetExtraInfo()[searchResultFormatKey])
+
+	reader, err := ipc.NewReader(bytes.NewReader(results.GetFieldsData()[0].GetScalars().GetBytesData().GetData()[0]))
+	require.NoError(t, err)
+	defer reader.Release()
+	require.True(t, reader.Next())
+	record := reader.Record()
+	assert.Equal(t, int64(3), record.NumRows())
+	assert.Equal(t, "age", record.ColumnName(0))
+
+	age := record.Column(0).(*array.Int16)
+	assert.Equal(t, int16(10), age.Value(0))
+	assert.True(t, age.IsNull(1))
+	assert.Equal(t, int16(30), age.Value(2))
+	assert.Equal(t, "c", record.Column(1).(*array.String).Value(2))
+
+	vec := record.Column(2).(*array.FixedSizeList)
+	assert.Equal(t, arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Float32).String(), vec.DataType().String())
+	assert.Equal(t, []float32{5, 6}, vec.ListValues().(*array.Float32).Float32Values()[4:6])
+	assert.Equal(t, 3, record.Column(3).NullN())
+	metadata := record.Schema().Field(3).Metadata
+	assert.Equal(t, schemapb.DataType_BinaryVector.String(), metadata.Values()[metadata.FindKey(arrowDataTypeMetaKey)])
+	assert.False(t, reader.Next())
+
+	t.Run("mismatched rows", func(t *testing.T) {
+		_, err := encodeFieldsDataToArrow([]*schemapb.FieldData{{
+			Type:      schemapb.DataType_Int64,
+			FieldName: "pk",
+			Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+				Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: []int64{1, 2}}},
+			}},
+		}}, 3)
+		assert.ErrorIs(t, err, merr.ErrServiceInternal)
+	})
+}
@@ -0,0 +1,253 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/util/function"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+// maxExplainBM25TopK is the max number of hits explained if explain_bm25 is enabled,
+// since the texts of all the hits are analyzed again on proxy.
+const maxExplainBM25TopK = 100
+
+// getBM25FunctionByOutputField returns the BM25 function generating the sparse field searched.
+func getBM25FunctionByOutputField(schema *schemapb.CollectionSchema, fieldID int64) (*schemapb.FunctionSchema, bool) {
+	return lo.Find(schema.GetFunctions(), func(fn *schemapb.FunctionSchema) bool {
+		return fn.GetType() == schemapb.FunctionType_BM25 && lo.Contains(fn.GetOutputFieldIds(), fieldID) &&
+			len(fn.GetInputFieldNames()) > 0
+	})
+}
+
+// parseTextQueries returns the query texts of the full text search, one for each query.
+func parseTextQueries(placeholderGroupBytes []byte, key string) ([]string, error) {
+	placeholderGroup := &commonpb.PlaceholderGroup{}
+	if err := proto.Unmarshal(placeholderGroupBytes, placeholderGroup); err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("failed to unmarshal placeholder group: %s", err.Error())
+	}
+	if len(placeholderGroup.GetPlaceholders()) != 1 || placeholderGroup.GetPlaceholders()[0].GetType() != commonpb.PlaceholderType_VarChar {
+		return nil, merr.WrapErrParameterInvalidMsg("%s requires the queries to be texts", key)
+	}
+	return funcutil.GetVarCharFromPlaceholder(placeholderGroup.GetPlaceholders()[0]), nil
+}
+
+// bm25TextAnalyzer analyzes the texts on proxy with the same analyzer as the BM25 function,
+// the multi analyzer is given the analyzer name of the search.
+type bm25TextAnalyzer struct {
+	runner        function.FunctionRunner
+	analyzer      function.Analyzer
+	multiAnalyzer bool
+	analyzerName  string
+}
+
+func newBM25TextAnalyzer(schema *schemapb.CollectionSchema, fn *schemapb.FunctionSchema, analyzerName string) (*bm25TextAnalyzer, error) {
+	runner, err := function.NewFunctionRunner(schema, fn)
+	if err != nil {
+		return nil, err
+	}
+	analyzer, ok := runner.(function.Analyzer)
+	if !ok {
+		runner.Close()
+		return nil, merr.WrapErrServiceInternal(fmt.Sprintf("function %s could not analyze texts", fn.GetName()))
+	}
+	_, multiAnalyzer := runner.(*function.MultiAnalyzerBM25FunctionRunner)
+	return &bm25TextAnalyzer{
+		runner:        runner,
+		analyzer:      analyzer,
+		multiAnalyzer: multiAnalyzer,
+		analyzerName:  analyzerName,
+	}, nil
+}
+
+func (a *bm25TextAnalyzer) analyze(withDetail bool, texts []string) ([][]*milvuspb.AnalyzerToken, error) {
+	if a.multiAnalyzer {
+		return a.analyzer.BatchAnalyze(withDetail, false, texts, lo.RepeatBy(len(texts), func(int) string { return a.analyzerName }))
+	}
+	return a.analyzer.BatchAnalyze(withDetail, false, texts)
+}
+
+func (a *bm25TextAnalyzer) Close() {
+	a.runner.Close()
+}
+
+// searchBM25Explain explains the BM25 scores of the hits by the term frequencies of the query terms in their texts.
+type searchBM25Explain struct {
+	field        *schemapb.FieldSchema
+	function     *schemapb.FunctionSchema
+	analyzerName string
+	query        string
+}
+
+// bm25HitExplain is the explanation of a hit returned in the extra info of the result.
+type bm25HitExplain struct {
+	ID any `json:"id"`
+	// the number of tokens of the text
+	DocLength int `json:"doc_length"`
+	// the occurrences of each query term in the text
+	TermFrequencies map[string]int `json:"term_frequencies"`
+}
+
+// parseExplainBM25 resolves the text field to explain, set by explain_bm25.
+// The texts of the hits are analyzed on proxy, so only the single query searches with small topk are allowed,
+// and the text field shall be one of the output fields.
+func (t *searchTask) parseExplainBM25(queryInfo *planpb.QueryInfo) (*searchBM25Explain, error) {
+	enabled, err := getBoolSearchParam(t.request.GetSearchParams(), ExplainBM25Key)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	if queryInfo.GetMetricType() != metric.BM25 {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is only supported by full text search", ExplainBM25Key)
+	}
+	if t.request.GetNq() != 1 {
+		return nil, merr.WrapErrParameterInvalidMsg("%s only supports searching with a single query, got nq %d", ExplainBM25Key, t.request.GetNq())
+	}
+	if queryInfo.GetTopk() > maxExplainBM25TopK {
+		return nil, merr.WrapErrParameterInvalidMsg("%s only supports topk (including offset) no more than %d, got %d",
+			ExplainBM25Key, maxExplainBM25TopK, queryInfo.GetTopk())
+	}
+	fn, ok := getBM25FunctionByOutputField(t.schema.CollectionSchema, queryInfo.GetQueryFieldId())
+	if !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is only supported by full text search", ExplainBM25Key)
+	}
+	fieldName := fn.GetInputFieldNames()[0]
+	if !lo.Contains(t.translatedOutputFields, fieldName) {
+		return nil, merr.WrapErrParameterInvalidMsg("%s requires the text field %s to be one of the output fields", ExplainBM25Key, fieldName)
+	}
+	queries, err := parseTextQueries(t.request.GetPlaceholderGroup(), ExplainBM25Key)
+	if err != nil {
+		return nil, err
+	}
+	return &searchBM25Explain{
+		field:        typeutil.GetFieldByName(t.schema.CollectionSchema, fieldName),
+		function:     fn,
+		analyzerName: t.SearchRequest.GetAnalyzerName(),
+		query:        queries[0],
+	}, nil
+}
+
+// explainBM25Hits counts the query terms in the tokens of each hit.
+func explainBM25Hits(ids *schemapb.IDs, queryTokens []*milvuspb.AnalyzerToken, hitTokens [][]*milvuspb.AnalyzerToken) []*bm25HitExplain {
+	terms := typeutil.NewSet(lo.Map(queryTokens, func(token *milvuspb.AnalyzerToken, _ int) string { return token.GetToken() })...)
+	explains := make([]*bm25HitExplain, 0, len(hitTokens))
+	for i, tokens := range hitTokens {
+		explain := &bm25HitExplain{
+			ID:              typeutil.GetPK(ids, int64(i)),
+			DocLength:       len(tokens),
+			TermFrequencies: make(map[string]int, terms.Len()),
+		}
+		for term := range terms {
+			explain.TermFrequencies[term] = 0
+		}
+		for _, token := range tokens {
+			if terms.Contain(token.GetToken()) {
+				explain.TermFrequencies[token.GetToken()]++
+			}
+		}
+		explains = append(explains, explain)
+	}
+	return explains
+}
+
+// fillBM25Explain returns the term frequencies of the query terms in the text of each hit,
+// which shall be run before the texts are highlighted.
+func (t *searchTask) fillBM25Explain() error {
+	results := t.result.GetResults()
+	fieldData, ok := lo.Find(results.GetFieldsData(), func(fieldData *schemapb.FieldData) bool {
+		return fieldData.GetFieldName() == t.explainBM25.field.GetName()
+	})
+	if !ok {
+		return nil
+	}
+	texts := fieldData.GetScalars().GetStringData().GetData()
+	numHits := min(int(sumInt64(results.GetTopks())), len(texts))
+
+	analyzer, err := newBM25TextAnalyzer(t.schema.CollectionSchema, t.explainBM25.function, t.explainBM25.analyzerName)
+	if err != nil {
+		return err
+	}
+	defer analyzer.Close()
+	queryTokens, err := analyzer.analyze(false, []string{t.explainBM25.query})
+	if err != nil {
+		return err
+	}
+	hitTokens, err := analyzer.analyze(false, texts[:numHits])
+	if err != nil {
+		return err
+	}
+
+	bs, err := json.Marshal(explainBM25Hits(results.GetIds(), queryTokens[0], hitTokens))
+	if err != nil {
+		return err
+	}
+	setSearchResultExtraInfo(t.result, searchResultBM25ExplainKey, string(bs))
+	return nil
+}
+
+// parsePreviewTokens analyzes the query texts of the full text search with the analyzer resolved for the search,
+// set by preview_tokens, so that the tokenization could be checked without executing the search.
+func (t *searchTask) parsePreviewTokens(queryInfo *planpb.QueryInfo) ([][]string, error) {
+	enabled, err := getBoolSearchParam(t.request.GetSearchParams(), PreviewTokensKey)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	fn, ok := getBM25FunctionByOutputField(t.schema.CollectionSchema, queryInfo.GetQueryFieldId())
+	if !ok || queryInfo.GetMetricType() != metric.BM25 {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is only supported by full text search on the output field of a BM25 function", PreviewTokensKey)
+	}
+	queries, err := parseTextQueries(t.request.GetPlaceholderGroup(), PreviewTokensKey)
+	if err != nil {
+		return nil, err
+	}
+
+	analyzer, err := newBM25TextAnalyzer(t.schema.CollectionSchema, fn, t.SearchRequest.GetAnalyzerName())
+	if err != nil {
+		return nil, err
+	}
+	defer analyzer.Close()
+	queryTokens, err := analyzer.analyze(false, queries)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(queryTokens, func(tokens []*milvuspb.AnalyzerToken, _ int) []string {
+		return lo.Map(tokens, func(token *milvuspb.AnalyzerToken, _ int) string { return token.GetToken() })
+	}), nil
+}
+
+// fillPreviewTokensResult returns no hit but the analyzed tokens of each query text.
+func (t *searchTask) fillPreviewTokensResult() error {
+	bs, err := json.Marshal(t.previewTokens)
+	if err != nil {
+		return err
+	}
+	t.result = fillInEmptyResult(t.SearchRequest.GetNq())
+	t.result.CollectionName = t.collectionName
+	setSearchResultExtraInfo(t.result, searchResultPreviewTokensKey, string(bs))
+	return nil
+}
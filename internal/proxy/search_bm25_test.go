@@ -0,0 +1,136 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+)
+
+func TestExplainBM25Hits(t *testing.T) {
+	ids := &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{7, 8}}}}
+	queryTokens := []*milvuspb.AnalyzerToken{{Token: "vector"}, {Token: "database"}, {Token: "vector"}}
+	hitTokens := [][]*milvuspb.AnalyzerToken{
+		{{Token: "vector"}, {Token: "search"}, {Token: "vector"}},
+		{{Token: "relational"}, {Token: "database"}},
+	}
+	explains := explainBM25Hits(ids, queryTokens, hitTokens)
+	require.Len(t, explains, 2)
+	assert.Equal(t, int64(7), explains[0].ID)
+	assert.Equal(t, 3, explains[0].DocLength)
+	assert.Equal(t, map[string]int{"vector": 2, "database": 0}, explains[0].TermFrequencies)
+	assert.Equal(t, int64(8), explains[1].ID)
+	assert.Equal(t, 2, explains[1].DocLength)
+	assert.Equal(t, map[string]int{"vector": 0, "database": 1}, explains[1].TermFrequencies)
+}
+
+func TestSearchTask_ParseExplainBM25(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "text", DataType: schemapb.DataType_VarChar},
+			{FieldID: 102, Name: "sparse", DataType: schemapb.DataType_SparseFloatVector, IsFunctionOutput: true},
+		},
+		Functions: []*schemapb.FunctionSchema{{
+			Name:             "bm25",
+			Type:             schemapb.FunctionType_BM25,
+			InputFieldNames:  []string{"text"},
+			InputFieldIds:    []int64{101},
+			OutputFieldNames: []string{"sparse"},
+			OutputFieldIds:   []int64{102},
+		}},
+	})
+	placeholderGroup, err := proto.Marshal(&commonpb.PlaceholderGroup{
+		Placeholders: []*commonpb.PlaceholderValue{{
+			Tag:    "$0",
+			Type:   commonpb.PlaceholderType_VarChar,
+			Values: [][]byte{[]byte("vector database")},
+		}},
+	})
+	require.NoError(t, err)
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			schema:                 schema,
+			translatedOutputFields: []string{"text"},
+			SearchRequest:          &internalpb.SearchRequest{},
+			request:                &milvuspb.SearchRequest{SearchParams: params, PlaceholderGroup: placeholderGroup, Nq: 1},
+		}
+	}
+	queryInfo := &planpb.QueryInfo{MetricType: metric.BM25, QueryFieldId: 102, Topk: 10}
+
+	explain, err := newTask().parseExplainBM25(queryInfo)
+	assert.NoError(t, err)
+	assert.Nil(t, explain)
+
+	explain, err = newTask(ExplainBM25Key, "true").parseExplainBM25(queryInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, "text", explain.field.GetName())
+	assert.Equal(t, "bm25", explain.function.GetName())
+	assert.Equal(t, "vector database", explain.query)
+
+	_, err = newTask(ExplainBM25Key, "yes").parseExplainBM25(queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = newTask(ExplainBM25Key, "true").parseExplainBM25(&planpb.QueryInfo{MetricType: metric.IP, QueryFieldId: 102, Topk: 10})
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = newTask(ExplainBM25Key, "true").parseExplainBM25(&planpb.QueryInfo{MetricType: metric.BM25, QueryFieldId: 102, Topk: maxExplainBM25TopK + 1})
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	task := newTask(ExplainBM25Key, "true")
+	task.request.Nq = 2
+	_, err = task.parseExplainBM25(queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	task = newTask(ExplainBM25Key, "true")
+	task.translatedOutputFields = []string{"pk"}
+	_, err = task.parseExplainBM25(queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestSearchTask_PreviewTokens(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "text", DataType: schemapb.DataType_VarChar},
+			{FieldID: 102, Name: "sparse", DataType: schemapb.DataType_SparseFloatVector},
+		},
+	})
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			schema:        schema,
+			SearchRequest: &internalpb.SearchRequest{Nq: 2},
+			request:       &milvuspb.SearchRequest{SearchParams: params, Nq: 2},
+		}
+	}
+	queryInfo := &planpb.QueryInfo{MetricType: metric.BM25, QueryFieldId: 102, Topk: 10}
+
+	tokens, err := newTask().parsePreviewTokens(queryInfo)
+	assert.NoError(t, err)
+	assert.Nil(t, tokens)
+
+	// the sparse field is not generated by a BM25 function
+	_, err = newTask(PreviewTokensKey, "true").parsePreviewTokens(queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(PreviewTokensKey, "yes").parsePreviewTokens(queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	task := newTask(PreviewTokensKey, "true")
+	task.collectionName = "docs"
+	task.previewTokens = [][]string{{"vector", "database"}, {}}
+	require.NoError(t, task.fillPreviewTokensResult())
+	assert.Equal(t, "docs", task.result.GetCollectionName())
+	assert.Equal(t, []int64{0, 0}, task.result.GetResults().GetTopks())
+	assert.Equal(t, `[["vector","database"],[]]`, task.result.GetStatus().GetExtraInfo()[searchResultPreviewTokensKey])
+}
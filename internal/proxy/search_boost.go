@@ -0,0 +1,202 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+	"github.com/expr-lang/expr/vm"
+	"github.com/samber/lo"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+const (
+	boostModeMultiply = "multiply"
+	boostModeAdd      = "add"
+)
+
+// boostFunctions are the math functions available in the boost expression.
+var boostFunctions = map[string]any{
+	"exp":  math.Exp,
+	"log":  math.Log,
+	"sqrt": math.Sqrt,
+	"pow":  math.Pow,
+}
+
+// searchBoost adjusts the score of each hit by a formula over its scalar fields, set by boost.
+type searchBoost struct {
+	program *vm.Program
+	fields  []*schemapb.FieldSchema
+	add     bool
+	// the fields not in the output fields, which are fetched only for boosting.
+	fetchedFields []string
+}
+
+// boostIdentifierCollector collects the identifiers referenced by the boost expression, the functions called excluded.
+type boostIdentifierCollector struct {
+	names []string
+	funcs []string
+}
+
+func (c *boostIdentifierCollector) Visit(node *ast.Node) {
+	switch node := (*node).(type) {
+	case *ast.IdentifierNode:
+		c.names = append(c.names, node.Value)
+	case *ast.CallNode:
+		if callee, ok := node.Callee.(*ast.IdentifierNode); ok {
+			c.funcs = append(c.funcs, callee.Value)
+		}
+	}
+}
+
+// parseBoost compiles the boost expression, e.g. exp(-age_days/30), the score of each hit is multiplied by
+// or added with its value depending on boost_mode. Only the numeric scalar fields could be referenced,
+// which are fetched along with the search if they are not output fields.
+// The hits are re-ranked by the boosted scores, so grouping search and offset, where the hits out of the page
+// may outrank the ones in it, are not supported.
+func (t *searchTask) parseBoost(offset int64, isIterator bool, groupByFieldID int64) (*searchBoost, error) {
+	code, err := funcutil.GetAttrByKeyFromRepeatedKV(BoostKey, t.request.GetSearchParams())
+	if err != nil || code == "" {
+		return nil, nil
+	}
+	switch {
+	case isIterator:
+		return nil, merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator", BoostKey)
+	case groupByFieldID >= 0:
+		return nil, merr.WrapErrParameterInvalidMsg("%s is not supported by grouping search", BoostKey)
+	case offset > 0:
+		return nil, merr.WrapErrParameterInvalidMsg("%s is not supported with offset", BoostKey)
+	}
+
+	boost := &searchBoost{}
+	mode, err := funcutil.GetAttrByKeyFromRepeatedKV(BoostModeKey, t.request.GetSearchParams())
+	switch {
+	case err != nil || mode == boostModeMultiply:
+	case mode == boostModeAdd:
+		boost.add = true
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be %s or %s", BoostModeKey, mode, boostModeMultiply, boostModeAdd)
+	}
+
+	tree, err := parser.Parse(code)
+	if err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, %s", BoostKey, code, err.Error())
+	}
+	collector := &boostIdentifierCollector{}
+	ast.Walk(&tree.Node, collector)
+	env := lo.Assign(boostFunctions)
+	for _, name := range lo.Uniq(collector.names) {
+		if lo.Contains(collector.funcs, name) {
+			continue
+		}
+		field := typeutil.GetFieldByName(t.schema.CollectionSchema, name)
+		if field == nil {
+			return nil, merr.WrapErrFieldNotFound(name, fmt.Sprintf("%s field not found in schema", BoostKey))
+		}
+		if !typeutil.IsArithmetic(field.GetDataType()) {
+			return nil, merr.WrapErrParameterInvalidMsg("%s field %s should be numeric, but got %s", BoostKey, name, field.GetDataType().String())
+		}
+		boost.fields = append(boost.fields, field)
+		env[name] = float64(0)
+		if !lo.Contains(t.translatedOutputFields, name) {
+			t.translatedOutputFields = append(t.translatedOutputFields, name)
+			t.SearchRequest.OutputFieldsId = append(t.SearchRequest.OutputFieldsId, field.GetFieldID())
+			boost.fetchedFields = append(boost.fetchedFields, name)
+		}
+	}
+	if boost.program, err = expr.Compile(code, expr.Env(env), expr.AsFloat64()); err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, %s", BoostKey, code, err.Error())
+	}
+	return boost, nil
+}
+
+// boostSearchResultData applies the boost to the score of each hit and re-ranks the hits of each query,
+// the hits with null values in any of the fields referenced keep their scores. A larger boost always makes a hit
+// rank higher, so the distances of the metrics not positively related, e.g. L2, are divided by or subtracted with
+// the boost instead, and the ones with non-positive multipliers are ranked last.
+func boostSearchResultData(data *schemapb.SearchResultData, boost *searchBoost, positivelyRelated bool) error {
+	if data == nil || len(data.GetScores()) == 0 {
+		return nil
+	}
+	getters := lo.Map(boost.fields, func(field *schemapb.FieldSchema, _ int) func(int64) (any, bool) {
+		return sortKeyGetter(data, field)
+	})
+	env := lo.Assign(boostFunctions)
+	scores := data.GetScores()
+	for i := range scores {
+		valid := true
+		for j, field := range boost.fields {
+			value, ok := getters[j](int64(i))
+			if !ok {
+				valid = false
+				break
+			}
+			switch value := value.(type) {
+			case int64:
+				env[field.GetName()] = float64(value)
+			case float64:
+				env[field.GetName()] = value
+			}
+		}
+		if !valid {
+			continue
+		}
+		output, err := expr.Run(boost.program, env)
+		if err != nil {
+			return merr.WrapErrParameterInvalidMsg("failed to evaluate %s, %s", BoostKey, err.Error())
+		}
+		value := float32(output.(float64))
+		switch {
+		case boost.add && positivelyRelated:
+			scores[i] += value
+		case boost.add:
+			scores[i] -= value
+		case positivelyRelated:
+			scores[i] *= value
+		case value > 0:
+			scores[i] /= value
+		default:
+			scores[i] = math.MaxFloat32
+		}
+	}
+
+	order := make([]int64, 0, len(scores))
+	var offset int64
+	for _, topk := range data.GetTopks() {
+		hits := lo.RangeFrom(offset, int(topk))
+		slices.SortStableFunc(hits, func(i, j int64) int {
+			if positivelyRelated {
+				return cmp.Compare(scores[j], scores[i])
+			}
+			return cmp.Compare(scores[i], scores[j])
+		})
+		order = append(order, hits...)
+		offset += topk
+	}
+	reorderSearchResultData(data, order)
+	return nil
+}
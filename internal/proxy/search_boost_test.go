@@ -0,0 +1,143 @@
+package proxy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+func TestSearchTask_ParseBoost(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "age_days", DataType: schemapb.DataType_Int64},
+			{FieldID: 102, Name: "rating", DataType: schemapb.DataType_Float},
+			{FieldID: 103, Name: "title", DataType: schemapb.DataType_VarChar},
+		},
+	})
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			schema:                 schema,
+			translatedOutputFields: []string{"rating"},
+			SearchRequest:          &internalpb.SearchRequest{OutputFieldsId: []int64{102}},
+			request:                &milvuspb.SearchRequest{SearchParams: params},
+		}
+	}
+
+	boost, err := newTask().parseBoost(0, false, -1)
+	assert.NoError(t, err)
+	assert.Nil(t, boost)
+
+	// the fields not in the output fields are fetched
+	task := newTask(BoostKey, "exp(-age_days/30) * rating")
+	boost, err = task.parseBoost(0, false, -1)
+	assert.NoError(t, err)
+	assert.False(t, boost.add)
+	assert.ElementsMatch(t, []string{"age_days", "rating"}, lo.Map(boost.fields, func(field *schemapb.FieldSchema, _ int) string { return field.GetName() }))
+	assert.Equal(t, []string{"age_days"}, boost.fetchedFields)
+	assert.Equal(t, []string{"rating", "age_days"}, task.translatedOutputFields)
+	assert.Equal(t, []int64{102, 101}, task.SearchRequest.GetOutputFieldsId())
+
+	boost, err = newTask(BoostKey, "rating / 10", BoostModeKey, "add").parseBoost(0, false, -1)
+	assert.NoError(t, err)
+	assert.True(t, boost.add)
+	assert.Empty(t, boost.fetchedFields)
+
+	for _, kvs := range [][]string{
+		{BoostKey, "rating +"},
+		{BoostKey, "title"},
+		{BoostKey, "rating", BoostModeKey, "replace"},
+		{BoostKey, "unknown_func(rating)"},
+	} {
+		_, err = newTask(kvs...).parseBoost(0, false, -1)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid, kvs)
+	}
+	_, err = newTask(BoostKey, "unknown * 2").parseBoost(0, false, -1)
+	assert.ErrorIs(t, err, merr.ErrFieldNotFound)
+
+	_, err = newTask(BoostKey, "rating").parseBoost(10, false, -1)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(BoostKey, "rating").parseBoost(0, true, -1)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(BoostKey, "rating").parseBoost(0, false, 101)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestBoostSearchResultData(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "rating", DataType: schemapb.DataType_Float, Nullable: true},
+		},
+	})
+	newData := func() *schemapb.SearchResultData {
+		return &schemapb.SearchResultData{
+			NumQueries: 2,
+			TopK:       3,
+			Topks:      []int64{3, 2},
+			Scores:     []float32{0.9, 0.8, 0.7, 0.6, 0.5},
+			Ids: &schemapb.IDs{
+				IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 4, 5}}},
+			},
+			FieldsData: []*schemapb.FieldData{{
+				Type:      schemapb.DataType_Float,
+				FieldName: "rating",
+				ValidData: []bool{true, true, false, true, true},
+				Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+					Data: &schemapb.ScalarField_FloatData{FloatData: &schemapb.FloatArray{Data: []float32{1, 2, 0, 1, 4}}},
+				}},
+			}},
+		}
+	}
+	newBoost := func(kvs ...string) *searchBoost {
+		params := newSearchParams(kvs...)
+		task := &searchTask{
+			schema:                 schema,
+			translatedOutputFields: []string{"rating"},
+			SearchRequest:          &internalpb.SearchRequest{},
+			request:                &milvuspb.SearchRequest{SearchParams: params},
+		}
+		boost, err := task.parseBoost(0, false, -1)
+		require.NoError(t, err)
+		return boost
+	}
+
+	data := newData()
+	assert.NoError(t, boostSearchResultData(data, newBoost(BoostKey, "rating"), true))
+	// the null rows keep their scores
+	assert.Equal(t, []int64{2, 1, 3, 5, 4}, data.GetIds().GetIntId().GetData())
+	assert.InDeltaSlice(t, []float32{1.6, 0.9, 0.7, 2.0, 0.6}, data.GetScores(), 1e-6)
+	assert.Equal(t, []float32{2, 1, 0, 4, 1}, data.GetFieldsData()[0].GetScalars().GetFloatData().GetData())
+	assert.Equal(t, []bool{true, true, false, true, true}, data.GetFieldsData()[0].GetValidData())
+
+	data = newData()
+	assert.NoError(t, boostSearchResultData(data, newBoost(BoostKey, "rating", BoostModeKey, "add"), true))
+	assert.Equal(t, []int64{2, 1, 3, 5, 4}, data.GetIds().GetIntId().GetData())
+	assert.InDeltaSlice(t, []float32{2.8, 1.9, 0.7, 4.5, 1.6}, data.GetScores(), 1e-6)
+
+	// the smaller the better for distances, which are divided by the boost
+	data = newData()
+	assert.NoError(t, boostSearchResultData(data, newBoost(BoostKey, "rating"), false))
+	assert.Equal(t, []int64{2, 3, 1, 5, 4}, data.GetIds().GetIntId().GetData())
+	assert.InDeltaSlice(t, []float32{0.4, 0.7, 0.9, 0.125, 0.6}, data.GetScores(), 1e-6)
+
+	data = newData()
+	assert.NoError(t, boostSearchResultData(data, newBoost(BoostKey, "rating", BoostModeKey, "add"), false))
+	assert.Equal(t, []int64{2, 1, 3, 5, 4}, data.GetIds().GetIntId().GetData())
+	assert.InDeltaSlice(t, []float32{-1.2, -0.1, 0.7, -3.5, -0.4}, data.GetScores(), 1e-6)
+
+	// the non-positive multipliers rank the distances last
+	data = newData()
+	assert.NoError(t, boostSearchResultData(data, newBoost(BoostKey, "rating - 1"), false))
+	assert.Equal(t, []int64{3, 2, 1, 5, 4}, data.GetIds().GetIntId().GetData())
+	assert.Equal(t, float32(math.MaxFloat32), data.GetScores()[2])
+}
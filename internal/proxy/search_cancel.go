@@ -0,0 +1,64 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+// inFlightSearchKey identifies an in-flight search by the user issuing it and the client supplied request id, so that
+// a search could only be canceled by its own user, and the request ids of different users never collide.
+type inFlightSearchKey struct {
+	username  string
+	requestID string
+}
+
+// inFlightSearches maps the in-flight searches on this proxy to their cancel functions. It's local to the proxy,
+// so a search could only be canceled through the proxy serving it.
+var inFlightSearches = typeutil.NewConcurrentMap[inFlightSearchKey, context.CancelCauseFunc]()
+
+// registerInFlightSearch registers the cancel function of the search, the request id shall be unique among the
+// in-flight searches of the user.
+func registerInFlightSearch(username, requestID string, cancel context.CancelCauseFunc) error {
+	if _, loaded := inFlightSearches.GetOrInsert(inFlightSearchKey{username, requestID}, cancel); loaded {
+		return merr.WrapErrParameterInvalidMsg("request_id %s is already used by another in-flight search", requestID)
+	}
+	return nil
+}
+
+// unregisterInFlightSearch removes the search from the in-flight searches and releases its context.
+func unregisterInFlightSearch(username, requestID string) {
+	if cancel, ok := inFlightSearches.GetAndRemove(inFlightSearchKey{username, requestID}); ok {
+		cancel(nil)
+	}
+}
+
+// cancelInFlightSearch cancels the in-flight search of the user with the request id, returns false if no such search.
+// The search is still registered until it finishes, so the request id could not be reused before that.
+func cancelInFlightSearch(username, requestID string) bool {
+	cancel, ok := inFlightSearches.Get(inFlightSearchKey{username, requestID})
+	if !ok {
+		return false
+	}
+	cancel(errors.Wrapf(context.Canceled, "search %s is canceled by client", requestID))
+	return true
+}
@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInFlightSearches(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	assert.NoError(t, registerInFlightSearch("alice", "req", cancel))
+
+	// request id is unique among the in-flight searches of the user
+	_, cancel2 := context.WithCancelCause(context.Background())
+	defer cancel2(nil)
+	assert.Error(t, registerInFlightSearch("alice", "req", cancel2))
+	assert.NoError(t, registerInFlightSearch("bob", "req", cancel2))
+	unregisterInFlightSearch("bob", "req")
+
+	// the search could only be canceled by its own user
+	assert.False(t, cancelInFlightSearch("alice", "other"))
+	assert.False(t, cancelInFlightSearch("bob", "req"))
+	assert.NoError(t, ctx.Err())
+	assert.True(t, cancelInFlightSearch("alice", "req"))
+	assert.ErrorIs(t, context.Cause(ctx), context.Canceled)
+	// still registered until the search finishes
+	assert.Error(t, registerInFlightSearch("alice", "req", cancel2))
+
+	unregisterInFlightSearch("alice", "req")
+	assert.False(t, cancelInFlightSearch("alice", "req"))
+	assert.NoError(t, registerInFlightSearch("alice", "req", cancel2))
+	unregisterInFlightSearch("alice", "req")
+}
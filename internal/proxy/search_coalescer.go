@@ -0,0 +1,117 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus/pkg/v2/log"
+	"github.com/milvus-io/milvus/pkg/v2/metrics"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/conc"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+// searchCoalescer coalesces the identical searches running concurrently, only the first one fans out to query nodes.
+var searchCoalescer conc.Singleflight[*coalescedSearchResult]
+
+// coalescedSearchResult is the snapshot of the shard results shared by the coalesced searches, it is read-only.
+type coalescedSearchResult struct {
+	results  []*internalpb.SearchResults
+	channels []string
+}
+
+// coalescedSearchKey generates the key of the search request, the fields identifying a single request
+// are excluded, so that the requests only differ in them share the same key.
+// The guarantee timestamp is part of the key, searches are only coalesced when they see the same data.
+func coalescedSearchKey(request *internalpb.SearchRequest) (string, error) {
+	request = typeutil.Clone(request)
+	request.Base = nil
+	request.ReqID = 0
+	request.TimeoutTimestamp = 0
+	request.Username = ""
+
+	bs, err := proto.MarshalOptions{Deterministic: true}.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(bs)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// executeCoalesced executes the search with the identical searches running concurrently.
+// The search which fans out to query nodes keeps its own results, the others get clones of them.
+func (t *searchTask) executeCoalesced(ctx context.Context) error {
+	key, err := coalescedSearchKey(t.SearchRequest)
+	if err != nil {
+		return err
+	}
+
+	leader := false
+	ch := searchCoalescer.DoChan(key, func() (*coalescedSearchResult, error) {
+		leader = true
+		if err := t.executeShards(ctx); err != nil {
+			return nil, err
+		}
+		result := &coalescedSearchResult{
+			results: lo.Map(t.resultBuf.Collect(), func(result *internalpb.SearchResults, _ int) *internalpb.SearchResults {
+				return typeutil.Clone(result)
+			}),
+		}
+		if t.queriedChannels != nil {
+			result.channels = t.queriedChannels.Collect()
+		}
+		return result, nil
+	})
+
+	var res conc.SingleflightResult[*coalescedSearchResult]
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res = <-ch:
+	}
+	if leader {
+		return res.Err
+	}
+
+	if res.Err != nil {
+		// the shared search is canceled by its own caller, search by ourselves
+		if (errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, context.DeadlineExceeded)) && ctx.Err() == nil {
+			log.Ctx(ctx).Info("coalesced search is canceled, search again", zap.Error(res.Err))
+			return t.executeShards(ctx)
+		}
+		return res.Err
+	}
+
+	metrics.ProxyCoalescedSearchCount.WithLabelValues(fmt.Sprint(paramtable.GetNodeID()), t.collectionName).Inc()
+	for _, result := range res.Val.results {
+		t.resultBuf.Insert(typeutil.Clone(result))
+	}
+	if t.queriedChannels != nil {
+		t.queriedChannels.Upsert(res.Val.channels...)
+	}
+	return nil
+}
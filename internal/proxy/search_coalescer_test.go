@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+func TestCoalescedSearchKey(t *testing.T) {
+	request := &internalpb.SearchRequest{
+		Base:               &commonpb.MsgBase{MsgID: 1},
+		ReqID:              1,
+		CollectionID:       100,
+		Dsl:                "a > 1",
+		PlaceholderGroup:   []byte("vectors"),
+		GuaranteeTimestamp: 1000,
+		TimeoutTimestamp:   2000,
+		Nq:                 1,
+		Topk:               10,
+	}
+	key, err := coalescedSearchKey(request)
+	assert.NoError(t, err)
+
+	other := typeutil.Clone(request)
+	other.Base = &commonpb.MsgBase{MsgID: 2}
+	other.ReqID = 2
+	other.TimeoutTimestamp = 3000
+	otherKey, err := coalescedSearchKey(other)
+	assert.NoError(t, err)
+	assert.Equal(t, key, otherKey)
+	// the request itself shall not be modified
+	assert.Equal(t, int64(1), request.GetBase().GetMsgID())
+
+	other = typeutil.Clone(request)
+	other.GuaranteeTimestamp = 1001
+	otherKey, err = coalescedSearchKey(other)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, otherKey)
+
+	other = typeutil.Clone(request)
+	other.PlaceholderGroup = []byte("other vectors")
+	otherKey, err = coalescedSearchKey(other)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, otherKey)
+}
+
+func TestSearchTask_ExecuteCoalesced(t *testing.T) {
+	paramtable.Init()
+
+	newTask := func(lb LBPolicy) *searchTask {
+		return &searchTask{
+			SearchRequest: &internalpb.SearchRequest{
+				Base:               &commonpb.MsgBase{MsgID: 1},
+				CollectionID:       100,
+				Dsl:                "a > 1",
+				PlaceholderGroup:   []byte("vectors"),
+				GuaranteeTimestamp: 1000,
+				Nq:                 1,
+				Topk:               10,
+			},
+			request:         &milvuspb.SearchRequest{},
+			collectionName:  "test_collection",
+			resultBuf:       typeutil.NewConcurrentSet[*internalpb.SearchResults](),
+			queriedChannels: typeutil.NewConcurrentSet[string](),
+			lb:              lb,
+		}
+	}
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	leaderLB := NewMockLBPolicy(t)
+	leader := newTask(leaderLB)
+	leaderLB.EXPECT().Execute(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, workload CollectionWorkLoad) error {
+		close(entered)
+		<-release
+		leader.resultBuf.Insert(&internalpb.SearchResults{NumQueries: 1, TopK: 10})
+		leader.queriedChannels.Insert("dml_0")
+		return nil
+	})
+
+	followerLB := NewMockLBPolicy(t)
+	followerLB.EXPECT().Execute(mock.Anything, mock.Anything).Return(merr.WrapErrServiceInternal("shall be coalesced")).Maybe()
+	follower := newTask(followerLB)
+	follower.Base = &commonpb.MsgBase{MsgID: 2}
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		leaderErr <- leader.executeCoalesced(context.Background())
+	}()
+	<-entered
+
+	followerErr := make(chan error, 1)
+	go func() {
+		followerErr <- follower.executeCoalesced(context.Background())
+	}()
+	// wait for the follower to join the in-flight search
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	assert.NoError(t, <-leaderErr)
+	assert.NoError(t, <-followerErr)
+	assert.Len(t, leader.resultBuf.Collect(), 1)
+	followerResults := follower.resultBuf.Collect()
+	assert.Len(t, followerResults, 1)
+	assert.NotSame(t, leader.resultBuf.Collect()[0], followerResults[0])
+	assert.Equal(t, int64(10), followerResults[0].GetTopK())
+	assert.ElementsMatch(t, []string{"dml_0"}, follower.queriedChannels.Collect())
+}
@@ -0,0 +1,95 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+// searchModeDissimilar searches the vectors most unlike the query vectors, set by mode.
+const searchModeDissimilar = "dissimilar"
+
+// parseDissimilarMode parses mode=dissimilar, which returns the farthest vectors first. The indexes only find
+// the nearest vectors, so the query vectors are negated instead, which inverts the inner products. Only the
+// metrics of inner products are supported then, and the scores returned are the negated similarities, i.e.
+// the larger the more dissimilar, so are the radius and range_filter of range search.
+func (t *searchTask) parseDissimilarMode(queryInfo *planpb.QueryInfo, isIterator bool) (bool, error) {
+	mode, err := funcutil.GetAttrByKeyFromRepeatedKV(SearchModeKey, t.request.GetSearchParams())
+	if err != nil || mode == "" {
+		return false, nil
+	}
+	if mode != searchModeDissimilar {
+		return false, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, only %s is supported", SearchModeKey, mode, searchModeDissimilar)
+	}
+	if isIterator {
+		// the bounds of the iterator would be of the negated similarities, which is confusing.
+		return false, merr.WrapErrParameterInvalidMsg("%s %s is not supported by search iterator", SearchModeKey, searchModeDissimilar)
+	}
+	metricType := queryInfo.GetMetricType()
+	if !strings.EqualFold(metricType, metric.IP) && !strings.EqualFold(metricType, metric.COSINE) {
+		return false, merr.WrapErrParameterInvalidMsg("%s %s only supports metric type %s or %s, got %s",
+			SearchModeKey, searchModeDissimilar, metric.IP, metric.COSINE, metricType)
+	}
+	field := typeutil.GetField(t.schema.CollectionSchema, queryInfo.GetQueryFieldId())
+	switch field.GetDataType() {
+	case schemapb.DataType_FloatVector, schemapb.DataType_Float16Vector, schemapb.DataType_BFloat16Vector:
+	default:
+		return false, merr.WrapErrParameterInvalidMsg("%s %s does not support searching field %s of type %s",
+			SearchModeKey, searchModeDissimilar, field.GetName(), field.GetDataType().String())
+	}
+	return true, nil
+}
+
+// negateQueryVectors negates the dense float vectors of the placeholder group by flipping the sign bits,
+// which are the highest bits of the little-endian float32, float16 and bfloat16 values.
+func negateQueryVectors(placeholderGroupBytes []byte) ([]byte, error) {
+	placeholderGroup := &commonpb.PlaceholderGroup{}
+	if err := proto.Unmarshal(placeholderGroupBytes, placeholderGroup); err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("failed to unmarshal placeholder group: %s", err.Error())
+	}
+	for _, placeholder := range placeholderGroup.GetPlaceholders() {
+		var bytesPerElem int
+		switch placeholder.GetType() {
+		case commonpb.PlaceholderType_FloatVector:
+			bytesPerElem = 4
+		case commonpb.PlaceholderType_Float16Vector, commonpb.PlaceholderType_BFloat16Vector:
+			bytesPerElem = 2
+		default:
+			return nil, merr.WrapErrParameterInvalidMsg("%s %s does not support query vectors of type %s",
+				SearchModeKey, searchModeDissimilar, placeholder.GetType().String())
+		}
+		for i, value := range placeholder.GetValues() {
+			negated := make([]byte, len(value))
+			copy(negated, value)
+			for j := bytesPerElem - 1; j < len(negated); j += bytesPerElem {
+				negated[j] ^= 0x80
+			}
+			placeholder.Values[i] = negated
+		}
+	}
+	return proto.Marshal(placeholderGroup)
+}
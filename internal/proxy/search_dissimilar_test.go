@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+)
+
+func TestSearchTask_ParseDissimilarMode(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "dense", DataType: schemapb.DataType_FloatVector},
+			{FieldID: 102, Name: "binary", DataType: schemapb.DataType_BinaryVector},
+		},
+	})
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{schema: schema, request: &milvuspb.SearchRequest{SearchParams: params}}
+	}
+	queryInfo := &planpb.QueryInfo{MetricType: metric.COSINE, QueryFieldId: 101}
+
+	dissimilar, err := newTask().parseDissimilarMode(queryInfo, false)
+	assert.NoError(t, err)
+	assert.False(t, dissimilar)
+
+	dissimilar, err = newTask(SearchModeKey, searchModeDissimilar).parseDissimilarMode(queryInfo, false)
+	assert.NoError(t, err)
+	assert.True(t, dissimilar)
+
+	_, err = newTask(SearchModeKey, "opposite").parseDissimilarMode(queryInfo, false)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(SearchModeKey, searchModeDissimilar).parseDissimilarMode(queryInfo, true)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(SearchModeKey, searchModeDissimilar).parseDissimilarMode(&planpb.QueryInfo{MetricType: metric.L2, QueryFieldId: 101}, false)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(SearchModeKey, searchModeDissimilar).parseDissimilarMode(&planpb.QueryInfo{MetricType: metric.IP, QueryFieldId: 102}, false)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestNegateQueryVectors(t *testing.T) {
+	vector := []float32{1.5, -2, 0}
+	value := make([]byte, 0, len(vector)*4)
+	for _, v := range vector {
+		value = binary.LittleEndian.AppendUint32(value, math.Float32bits(v))
+	}
+	placeholderGroup, err := proto.Marshal(&commonpb.PlaceholderGroup{
+		Placeholders: []*commonpb.PlaceholderValue{{
+			Tag:    "$0",
+			Type:   commonpb.PlaceholderType_FloatVector,
+			Values: [][]byte{value},
+		}},
+	})
+	require.NoError(t, err)
+
+	negatedBytes, err := negateQueryVectors(placeholderGroup)
+	require.NoError(t, err)
+	negated := &commonpb.PlaceholderGroup{}
+	require.NoError(t, proto.Unmarshal(negatedBytes, negated))
+	negatedValue := negated.GetPlaceholders()[0].GetValues()[0]
+	for i, v := range vector {
+		assert.Equal(t, -v, math.Float32frombits(binary.LittleEndian.Uint32(negatedValue[i*4:])))
+	}
+	// the original query vectors are untouched
+	assert.Equal(t, float32(1.5), math.Float32frombits(binary.LittleEndian.Uint32(value)))
+
+	sparseGroup, err := proto.Marshal(&commonpb.PlaceholderGroup{
+		Placeholders: []*commonpb.PlaceholderValue{{Tag: "$0", Type: commonpb.PlaceholderType_SparseFloatVector}},
+	})
+	require.NoError(t, err)
+	_, err = negateQueryVectors(sparseGroup)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
@@ -0,0 +1,138 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/samber/lo"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+// approxDistinctPrecision is the precision of the HyperLogLog counting the distinct values,
+// 2^14 registers give a standard error of about 0.8%.
+const approxDistinctPrecision = 14
+
+// parseApproxDistinctField returns the field to count the distinct values of, set by approx_distinct_field.
+// The values are counted on the hits, so the field shall be either the primary key or one of the output fields.
+func (t *searchTask) parseApproxDistinctField() (*schemapb.FieldSchema, error) {
+	fieldName, err := funcutil.GetAttrByKeyFromRepeatedKV(ApproxDistinctFieldKey, t.request.GetSearchParams())
+	if err != nil || fieldName == "" {
+		return nil, nil
+	}
+	field := typeutil.GetFieldByName(t.schema.CollectionSchema, fieldName)
+	if field == nil {
+		return nil, merr.WrapErrFieldNotFound(fieldName, fmt.Sprintf("%s field not found in schema", ApproxDistinctFieldKey))
+	}
+	switch field.GetDataType() {
+	case schemapb.DataType_Bool, schemapb.DataType_Int8, schemapb.DataType_Int16, schemapb.DataType_Int32, schemapb.DataType_Int64,
+		schemapb.DataType_Float, schemapb.DataType_Double, schemapb.DataType_String, schemapb.DataType_VarChar, schemapb.DataType_JSON:
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("%s field %s of type %s is not supported",
+			ApproxDistinctFieldKey, fieldName, field.GetDataType().String())
+	}
+	if !field.GetIsPrimaryKey() && !lo.Contains(t.translatedOutputFields, fieldName) {
+		return nil, merr.WrapErrParameterInvalidMsg("%s field %s should be one of the output fields", ApproxDistinctFieldKey, fieldName)
+	}
+	return field, nil
+}
+
+// approxDistinctValues returns the serialized values of the field of the first rows hits, the nulls are skipped.
+func approxDistinctValues(results *schemapb.SearchResultData, field *schemapb.FieldSchema, rows int) [][]byte {
+	values := make([][]byte, 0, rows)
+	if field.GetIsPrimaryKey() {
+		// the pk is not fetched as field data but returned as the ids
+		for i := 0; i < rows; i++ {
+			switch pk := typeutil.GetPK(results.GetIds(), int64(i)).(type) {
+			case int64:
+				values = append(values, binary.LittleEndian.AppendUint64(nil, uint64(pk)))
+			case string:
+				values = append(values, []byte(pk))
+			}
+		}
+		return values
+	}
+
+	fieldData, ok := lo.Find(results.GetFieldsData(), func(fieldData *schemapb.FieldData) bool {
+		return fieldData.GetFieldName() == field.GetName()
+	})
+	if !ok {
+		return values
+	}
+	validData := fieldData.GetValidData()
+	scalars := fieldData.GetScalars()
+	for i := 0; i < rows; i++ {
+		if len(validData) > i && !validData[i] {
+			continue
+		}
+		switch fieldData.GetType() {
+		case schemapb.DataType_Bool:
+			if data := scalars.GetBoolData().GetData(); i < len(data) {
+				values = append(values, []byte(strconv.FormatBool(data[i])))
+			}
+		case schemapb.DataType_Int8, schemapb.DataType_Int16, schemapb.DataType_Int32:
+			if data := scalars.GetIntData().GetData(); i < len(data) {
+				values = append(values, binary.LittleEndian.AppendUint64(nil, uint64(data[i])))
+			}
+		case schemapb.DataType_Int64:
+			if data := scalars.GetLongData().GetData(); i < len(data) {
+				values = append(values, binary.LittleEndian.AppendUint64(nil, uint64(data[i])))
+			}
+		case schemapb.DataType_Float:
+			if data := scalars.GetFloatData().GetData(); i < len(data) {
+				values = append(values, binary.LittleEndian.AppendUint32(nil, math.Float32bits(data[i])))
+			}
+		case schemapb.DataType_Double:
+			if data := scalars.GetDoubleData().GetData(); i < len(data) {
+				values = append(values, binary.LittleEndian.AppendUint64(nil, math.Float64bits(data[i])))
+			}
+		case schemapb.DataType_String, schemapb.DataType_VarChar:
+			if data := scalars.GetStringData().GetData(); i < len(data) {
+				values = append(values, []byte(data[i]))
+			}
+		case schemapb.DataType_JSON:
+			if data := scalars.GetJsonData().GetData(); i < len(data) {
+				values = append(values, data[i])
+			}
+		}
+	}
+	return values
+}
+
+// fillApproxDistinctCount estimates the number of distinct values of the field over the hits of all the queries.
+// At most proxy.approxDistinctMaxRows hits are aggregated to bound the cost, the number of hits aggregated is returned along with the count.
+func (t *searchTask) fillApproxDistinctCount() {
+	results := t.result.GetResults()
+	rows := int(sumInt64(results.GetTopks()))
+	if maxRows := Params.ProxyCfg.ApproxDistinctMaxRows.GetAsInt(); maxRows > 0 {
+		rows = min(rows, maxRows)
+	}
+
+	hll := typeutil.NewHyperLogLog(approxDistinctPrecision)
+	for _, value := range approxDistinctValues(results, t.approxDistinctField, rows) {
+		hll.Add(value)
+	}
+	setSearchResultExtraInfo(t.result, searchResultApproxDistinctCountKey, strconv.FormatUint(hll.Count(), 10))
+	setSearchResultExtraInfo(t.result, searchResultApproxDistinctRowsKey, strconv.Itoa(rows))
+}
@@ -0,0 +1,102 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+func TestSearchTask_ParseApproxDistinctField(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "category", DataType: schemapb.DataType_VarChar},
+			{FieldID: 102, Name: "vec", DataType: schemapb.DataType_FloatVector},
+		},
+	})
+	newTask := func(fieldName string) *searchTask {
+		return &searchTask{
+			schema:                 schema,
+			translatedOutputFields: []string{"category", "vec"},
+			request: &milvuspb.SearchRequest{
+				SearchParams: []*commonpb.KeyValuePair{{Key: ApproxDistinctFieldKey, Value: fieldName}},
+			},
+		}
+	}
+
+	field, err := newTask("").parseApproxDistinctField()
+	assert.NoError(t, err)
+	assert.Nil(t, field)
+
+	field, err = newTask("category").parseApproxDistinctField()
+	assert.NoError(t, err)
+	assert.Equal(t, "category", field.GetName())
+
+	// pk is returned as the ids, no need to be an output field
+	field, err = newTask("pk").parseApproxDistinctField()
+	assert.NoError(t, err)
+	assert.Equal(t, "pk", field.GetName())
+
+	_, err = newTask("vec").parseApproxDistinctField()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = newTask("unknown").parseApproxDistinctField()
+	assert.ErrorIs(t, err, merr.ErrFieldNotFound)
+
+	task := newTask("category")
+	task.translatedOutputFields = nil
+	_, err = task.parseApproxDistinctField()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestSearchTask_FillApproxDistinctCount(t *testing.T) {
+	newTask := func(field *schemapb.FieldSchema) *searchTask {
+		return &searchTask{
+			approxDistinctField: field,
+			result: &milvuspb.SearchResults{
+				Status: merr.Success(),
+				Results: &schemapb.SearchResultData{
+					NumQueries: 2,
+					TopK:       3,
+					Topks:      []int64{3, 2},
+					Ids: &schemapb.IDs{
+						IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 1, 4}}},
+					},
+					FieldsData: []*schemapb.FieldData{{
+						Type:      schemapb.DataType_VarChar,
+						FieldName: "category",
+						ValidData: []bool{true, true, false, true, true},
+						Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+							Data: &schemapb.ScalarField_StringData{StringData: &schemapb.StringArray{Data: []string{"a", "b", "", "a", "c"}}},
+						}},
+					}},
+				},
+			},
+		}
+	}
+
+	task := newTask(&schemapb.FieldSchema{Name: "category", DataType: schemapb.DataType_VarChar})
+	task.fillApproxDistinctCount()
+	extraInfo := task.result.GetStatus().GetExtraInfo()
+	assert.Equal(t, "3", extraInfo[searchResultApproxDistinctCountKey])
+	assert.Equal(t, "5", extraInfo[searchResultApproxDistinctRowsKey])
+
+	task = newTask(&schemapb.FieldSchema{Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true})
+	task.fillApproxDistinctCount()
+	assert.Equal(t, "4", task.result.GetStatus().GetExtraInfo()[searchResultApproxDistinctCountKey])
+
+	Params.Save(Params.ProxyCfg.ApproxDistinctMaxRows.Key, "2")
+	defer Params.Reset(Params.ProxyCfg.ApproxDistinctMaxRows.Key)
+	task = newTask(&schemapb.FieldSchema{Name: "category", DataType: schemapb.DataType_VarChar})
+	task.fillApproxDistinctCount()
+	extraInfo = task.result.GetStatus().GetExtraInfo()
+	require.NotNil(t, extraInfo)
+	assert.Equal(t, "2", extraInfo[searchResultApproxDistinctCountKey])
+	assert.Equal(t, "2", extraInfo[searchResultApproxDistinctRowsKey])
+}
@@ -0,0 +1,116 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/json"
+	"github.com/milvus-io/milvus/pkg/v2/log"
+	"github.com/milvus-io/milvus/pkg/v2/util/distance"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+// maxDiversityHits bounds the leading hits of each query the diversity is computed among, as the cost is quadratic.
+const maxDiversityHits = 256
+
+// parseComputeDiversity parses compute_diversity, which reports how diverse the hits of each query are. It is computed
+// from the vectors of the anns field returned, so the anns field shall be a float vector field in the output fields.
+func (t *searchTask) parseComputeDiversity() (bool, error) {
+	enabled, err := getBoolSearchParam(t.request.GetSearchParams(), ComputeDiversityKey)
+	if err != nil || !enabled {
+		return false, err
+	}
+	if t.SearchRequest.GetIsAdvanced() {
+		return false, merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", ComputeDiversityKey)
+	}
+	field := typeutil.GetField(t.schema.CollectionSchema, t.SearchRequest.GetFieldId())
+	if field.GetDataType() != schemapb.DataType_FloatVector {
+		return false, merr.WrapErrParameterInvalidMsg("%s only works with the float vector fields", ComputeDiversityKey)
+	}
+	skipped := lo.ContainsBy(t.skippedVectorOutputFields, func(skippedField *schemapb.FieldSchema) bool {
+		return skippedField.GetFieldID() == field.GetFieldID()
+	})
+	if t.countOnly || skipped || !lo.Contains(t.translatedOutputFields, field.GetName()) {
+		return false, merr.WrapErrParameterInvalidMsg("%s requires the vectors of the anns field %s in the output fields", ComputeDiversityKey, field.GetName())
+	}
+	return true, nil
+}
+
+// pairwiseDistance returns the distance between the two vectors, the larger the more different. It is the euclidean
+// distance for the metric L2, and the cosine distance otherwise.
+func pairwiseDistance(a, b []float32, metricType string) float64 {
+	if strings.EqualFold(metricType, metric.L2) {
+		return math.Sqrt(float64(distance.L2Impl(a, b)))
+	}
+	similarity := float64(distance.CosineImpl(a, b))
+	// the cosine of the zero vectors is undefined, regard them as unrelated
+	if math.IsNaN(similarity) {
+		similarity = 0
+	}
+	return 1 - similarity
+}
+
+// computeDiversity returns the average pairwise distance among the first maxDiversityHits hits of each query,
+// the queries with less than two hits are 0.
+func computeDiversity(vectors []float32, dim int, topks []int64, metricType string) []float64 {
+	diversity := make([]float64, len(topks))
+	offset := 0
+	for i, topk := range topks {
+		n := min(int(topk), maxDiversityHits)
+		if n >= 2 {
+			sum := 0.0
+			for a := offset; a < offset+n; a++ {
+				for b := a + 1; b < offset+n; b++ {
+					sum += pairwiseDistance(vectors[a*dim:(a+1)*dim], vectors[b*dim:(b+1)*dim], metricType)
+				}
+			}
+			diversity[i] = sum / float64(n*(n-1)/2)
+		}
+		offset += int(topk)
+	}
+	return diversity
+}
+
+// fillDiversity reports the diversity of the hits of each query as a JSON array. It shall be called before the vectors
+// are downcast or encoded.
+func (t *searchTask) fillDiversity(ctx context.Context, metricType string) {
+	fieldData, ok := lo.Find(t.result.GetResults().GetFieldsData(), func(fieldData *schemapb.FieldData) bool {
+		return fieldData.GetFieldId() == t.SearchRequest.GetFieldId()
+	})
+	vectors := fieldData.GetVectors().GetFloatVector().GetData()
+	dim := int(fieldData.GetVectors().GetDim())
+	topks := t.result.GetResults().GetTopks()
+	if !ok || dim <= 0 || int64(len(vectors)) != sumInt64(topks)*int64(dim) {
+		log.Ctx(ctx).Warn("no vectors of the anns field to compute diversity", zap.Int64("fieldID", t.SearchRequest.GetFieldId()))
+		return
+	}
+	bs, err := json.Marshal(computeDiversity(vectors, dim, topks, metricType))
+	if err != nil {
+		log.Ctx(ctx).Warn("failed to marshal search diversity", zap.Error(err))
+		return
+	}
+	setSearchResultExtraInfo(t.result, searchResultDiversityKey, string(bs))
+}
@@ -0,0 +1,62 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/common"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+)
+
+func TestComputeDiversity(t *testing.T) {
+	vectors := []float32{
+		0, 0, 3, 4, 0, 4, // the first query
+		1, 0, // the second query
+		1, 0, 0, 1, // the third query
+	}
+	topks := []int64{3, 1, 0, 2}
+
+	diversity := computeDiversity(vectors, 2, topks, metric.L2)
+	assert.InDeltaSlice(t, []float64{(5.0 + 4.0 + 3.0) / 3, 0, 0, 1.4142135}, diversity, 1e-6)
+
+	// the zero vector is regarded as unrelated to the others
+	diversity = computeDiversity(vectors, 2, topks, metric.COSINE)
+	assert.InDeltaSlice(t, []float64{(1.0 + 1.0 + 0.2) / 3, 0, 0, 1}, diversity, 1e-6)
+}
+
+func TestSearchTask_ParseComputeDiversity(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "vec", DataType: schemapb.DataType_FloatVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "2"}}},
+			{FieldID: 102, Name: "bvec", DataType: schemapb.DataType_BinaryVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "8"}}},
+		},
+	})
+	newTask := func(fieldID int64, outputFields ...string) *searchTask {
+		return &searchTask{
+			schema:                 schema,
+			SearchRequest:          &internalpb.SearchRequest{FieldId: fieldID},
+			request:                &milvuspb.SearchRequest{SearchParams: []*commonpb.KeyValuePair{{Key: ComputeDiversityKey, Value: "true"}}},
+			translatedOutputFields: outputFields,
+		}
+	}
+
+	enabled, err := newTask(101, "vec").parseComputeDiversity()
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	_, err = newTask(101).parseComputeDiversity()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(102, "bvec").parseComputeDiversity()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	task := newTask(101, "vec")
+	task.skippedVectorOutputFields = schema.GetFields()[1:2]
+	_, err = task.parseComputeDiversity()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
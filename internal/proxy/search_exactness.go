@@ -0,0 +1,110 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/json"
+	"github.com/milvus-io/milvus/pkg/v2/common"
+	"github.com/milvus-io/milvus/pkg/v2/log"
+	"github.com/milvus-io/milvus/pkg/v2/proto/indexpb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+const (
+	searchExactnessExact       = "exact"
+	searchExactnessApproximate = "approximate"
+)
+
+// exactIndexTypes are the vector index types searched exhaustively, the hits from the segments indexed by them are exact.
+var exactIndexTypes = []string{"FLAT", "BIN_FLAT"}
+
+// isSearchApproximate tells whether any segment searched may return approximate hits, i.e. the sealed segments
+// with approximate indexes on the anns fields, or the growing segments if querynodes build interim indexes for them.
+// The segments without index are searched by brute force, which is exact.
+func (t *searchTask) isSearchApproximate(ctx context.Context, toReduceResults []*internalpb.SearchResults) (bool, error) {
+	searchedGrowing := !t.SearchRequest.GetIgnoreGrowing() && lo.SomeBy(toReduceResults, func(result *internalpb.SearchResults) bool {
+		return len(result.GetChannelIDsSearched()) > 0
+	})
+	if searchedGrowing && paramtable.Get().QueryNodeCfg.EnableInterminSegmentIndex.GetAsBool() {
+		return true, nil
+	}
+
+	segmentIDs := typeutil.NewUniqueSet()
+	for _, result := range toReduceResults {
+		segmentIDs.Insert(result.GetSealedSegmentIDsSearched()...)
+	}
+	if segmentIDs.Len() == 0 {
+		return false, nil
+	}
+	resp, err := t.mixCoord.GetIndexInfos(ctx, &indexpb.GetIndexInfoRequest{
+		CollectionID: t.GetCollectionID(),
+		SegmentIDs:   segmentIDs.Collect(),
+	})
+	if err := merr.CheckRPCCall(resp, err); err != nil {
+		return false, err
+	}
+
+	annsFieldIDs := []int64{t.SearchRequest.GetFieldId()}
+	if t.SearchRequest.GetIsAdvanced() {
+		annsFieldIDs = lo.Map(t.SearchRequest.GetSubReqs(), func(subReq *internalpb.SubSearchRequest, _ int) int64 { return subReq.GetFieldId() })
+	}
+	for _, segment := range resp.GetSegmentInfo() {
+		for _, index := range segment.GetIndexInfos() {
+			if !lo.Contains(annsFieldIDs, index.GetFieldID()) {
+				continue
+			}
+			indexType, _ := funcutil.GetAttrByKeyFromRepeatedKV(common.IndexTypeKey, index.GetIndexParams())
+			if !lo.ContainsBy(exactIndexTypes, func(exactType string) bool { return strings.EqualFold(exactType, indexType) }) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// fillExactness reports whether the hits of each query are exact or approximate as a JSON array. The segments contributing
+// to the hits of each query are not reported by querynodes, so the queries with hits share the verdict of the whole search,
+// and the queries without hits are exact.
+func (t *searchTask) fillExactness(ctx context.Context, toReduceResults []*internalpb.SearchResults) {
+	approximate, err := t.isSearchApproximate(ctx, toReduceResults)
+	if err != nil {
+		log.Ctx(ctx).Warn("failed to get the indexes of the segments searched", zap.Error(err))
+		return
+	}
+	exactness := lo.Map(t.result.GetResults().GetTopks(), func(topk int64, _ int) string {
+		if approximate && topk > 0 {
+			return searchExactnessApproximate
+		}
+		return searchExactnessExact
+	})
+	bs, err := json.Marshal(exactness)
+	if err != nil {
+		log.Ctx(ctx).Warn("failed to marshal search exactness", zap.Error(err))
+		return
+	}
+	setSearchResultExtraInfo(t.result, searchResultExactnessKey, string(bs))
+}
@@ -0,0 +1,65 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/mocks"
+	"github.com/milvus-io/milvus/pkg/v2/common"
+	"github.com/milvus-io/milvus/pkg/v2/proto/indexpb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+)
+
+func TestSearchTask_FillExactness(t *testing.T) {
+	newIndexInfo := func(fieldID int64, indexType string) *indexpb.IndexFilePathInfo {
+		return &indexpb.IndexFilePathInfo{
+			FieldID:     fieldID,
+			IndexParams: []*commonpb.KeyValuePair{{Key: common.IndexTypeKey, Value: indexType}},
+		}
+	}
+	mixCoord := mocks.NewMockMixCoordClient(t)
+	mixCoord.EXPECT().GetIndexInfos(mock.Anything, mock.Anything).Return(&indexpb.GetIndexInfoResponse{
+		Status: merr.Success(),
+		SegmentInfo: map[int64]*indexpb.SegmentInfo{
+			1: {SegmentID: 1, IndexInfos: []*indexpb.IndexFilePathInfo{newIndexInfo(101, "FLAT"), newIndexInfo(102, "HNSW")}},
+			2: {SegmentID: 2, IndexInfos: []*indexpb.IndexFilePathInfo{newIndexInfo(101, "HNSW")}},
+		},
+	}, nil)
+	newTask := func(fieldID int64) *searchTask {
+		return &searchTask{
+			mixCoord:      mixCoord,
+			SearchRequest: &internalpb.SearchRequest{CollectionID: 1, FieldId: fieldID, IgnoreGrowing: true},
+			result: &milvuspb.SearchResults{
+				Results: &schemapb.SearchResultData{Topks: []int64{2, 0}},
+			},
+		}
+	}
+	ctx := context.Background()
+
+	// the hnsw index on the other field makes no difference
+	task := newTask(101)
+	task.fillExactness(ctx, []*internalpb.SearchResults{{SealedSegmentIDsSearched: []int64{1}}})
+	assert.Equal(t, `["exact","exact"]`, task.result.GetStatus().GetExtraInfo()[searchResultExactnessKey])
+
+	// the queries without hits are exact
+	task = newTask(101)
+	task.fillExactness(ctx, []*internalpb.SearchResults{{SealedSegmentIDsSearched: []int64{1}}, {SealedSegmentIDsSearched: []int64{2}}})
+	assert.Equal(t, `["approximate","exact"]`, task.result.GetStatus().GetExtraInfo()[searchResultExactnessKey])
+
+	// the growing segments are approximate if the interim index is enabled
+	paramtable.Get().Save(paramtable.Get().QueryNodeCfg.EnableInterminSegmentIndex.Key, "true")
+	defer paramtable.Get().Reset(paramtable.Get().QueryNodeCfg.EnableInterminSegmentIndex.Key)
+	task = newTask(101)
+	task.SearchRequest.IgnoreGrowing = false
+	approximate, err := task.isSearchApproximate(ctx, []*internalpb.SearchResults{{ChannelIDsSearched: []string{"dml_0"}}})
+	assert.NoError(t, err)
+	assert.True(t, approximate)
+}
@@ -0,0 +1,167 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+const (
+	defaultHighlightPreTag  = "<em>"
+	defaultHighlightPostTag = "</em>"
+)
+
+// searchHighlight marks the query terms in the text field of the hits of a BM25 search.
+type searchHighlight struct {
+	field *schemapb.FieldSchema
+	// the BM25 function analyzing the text field, the hits are analyzed by the same analyzer as the queries.
+	function     *schemapb.FunctionSchema
+	analyzerName string
+	// the query texts, one for each query.
+	queries []string
+	preTag  string
+	postTag string
+}
+
+// parseHighlight resolves the text field to highlight, set by highlight.
+// Only the input field of the BM25 function searched could be highlighted, and it shall be one of the output fields.
+func (t *searchTask) parseHighlight(queryInfo *planpb.QueryInfo) (*searchHighlight, error) {
+	params := t.request.GetSearchParams()
+	fieldName, err := funcutil.GetAttrByKeyFromRepeatedKV(HighlightKey, params)
+	if err != nil || fieldName == "" {
+		return nil, nil
+	}
+	if queryInfo.GetMetricType() != metric.BM25 {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is only supported by full text search", HighlightKey)
+	}
+	fn, ok := getBM25FunctionByOutputField(t.schema.CollectionSchema, queryInfo.GetQueryFieldId())
+	if !ok || fn.GetInputFieldNames()[0] != fieldName {
+		return nil, merr.WrapErrParameterInvalidMsg("%s field %s is not the text field of the full text search", HighlightKey, fieldName)
+	}
+	if !lo.Contains(t.translatedOutputFields, fieldName) {
+		return nil, merr.WrapErrParameterInvalidMsg("%s field %s should be one of the output fields", HighlightKey, fieldName)
+	}
+	queries, err := parseTextQueries(t.request.GetPlaceholderGroup(), HighlightKey)
+	if err != nil {
+		return nil, err
+	}
+
+	highlight := &searchHighlight{
+		field:        typeutil.GetFieldByName(t.schema.CollectionSchema, fieldName),
+		function:     fn,
+		analyzerName: t.SearchRequest.GetAnalyzerName(),
+		queries:      queries,
+		preTag:       defaultHighlightPreTag,
+		postTag:      defaultHighlightPostTag,
+	}
+	if preTag, err := funcutil.GetAttrByKeyFromRepeatedKV(HighlightPreTagKey, params); err == nil {
+		highlight.preTag = preTag
+	}
+	if postTag, err := funcutil.GetAttrByKeyFromRepeatedKV(HighlightPostTagKey, params); err == nil {
+		highlight.postTag = postTag
+	}
+	return highlight, nil
+}
+
+// highlightText wraps the tokens of the text matching the terms with the tags,
+// the overlapping tokens are skipped.
+func highlightText(text string, tokens []*milvuspb.AnalyzerToken, terms typeutil.Set[string], preTag, postTag string) string {
+	matched := lo.Filter(tokens, func(token *milvuspb.AnalyzerToken, _ int) bool {
+		return terms.Contain(token.GetToken())
+	})
+	if len(matched) == 0 {
+		return text
+	}
+	slices.SortStableFunc(matched, func(a, b *milvuspb.AnalyzerToken) int {
+		return int(a.GetStartOffset() - b.GetStartOffset())
+	})
+
+	var builder strings.Builder
+	var last int64
+	for _, token := range matched {
+		start, end := token.GetStartOffset(), token.GetEndOffset()
+		if start < last || start >= end || end > int64(len(text)) {
+			continue
+		}
+		builder.WriteString(text[last:start])
+		builder.WriteString(preTag)
+		builder.WriteString(text[start:end])
+		builder.WriteString(postTag)
+		last = end
+	}
+	builder.WriteString(text[last:])
+	return builder.String()
+}
+
+// fillHighlight marks the query terms in the text field of the hits, the text field data is replaced in place.
+func (t *searchTask) fillHighlight() error {
+	results := t.result.GetResults()
+	fieldData, ok := lo.Find(results.GetFieldsData(), func(fieldData *schemapb.FieldData) bool {
+		return fieldData.GetFieldName() == t.highlight.field.GetName()
+	})
+	if !ok || sumInt64(results.GetTopks()) == 0 {
+		return nil
+	}
+	if len(results.GetTopks()) != len(t.highlight.queries) {
+		return merr.WrapErrServiceInternal(fmt.Sprintf("%d queries are searched, but the results of %d queries are returned",
+			len(t.highlight.queries), len(results.GetTopks())))
+	}
+
+	analyzer, err := newBM25TextAnalyzer(t.schema.CollectionSchema, t.highlight.function, t.highlight.analyzerName)
+	if err != nil {
+		return err
+	}
+	defer analyzer.Close()
+
+	queryTokens, err := analyzer.analyze(false, t.highlight.queries)
+	if err != nil {
+		return err
+	}
+	texts := fieldData.GetScalars().GetStringData().GetData()
+	// the truncation marker of max_field_bytes is not a part of the text, never highlight it
+	bodies := lo.Map(texts, func(text string, _ int) string { return strings.TrimSuffix(text, truncatedFieldMarker) })
+	hitTokens, err := analyzer.analyze(true, bodies)
+	if err != nil {
+		return err
+	}
+
+	validData := fieldData.GetValidData()
+	var offset int64
+	for i, topk := range results.GetTopks() {
+		terms := typeutil.NewSet(lo.Map(queryTokens[i], func(token *milvuspb.AnalyzerToken, _ int) string { return token.GetToken() })...)
+		for j := offset; j < offset+topk && j < int64(len(texts)); j++ {
+			if len(validData) > int(j) && !validData[j] {
+				continue
+			}
+			texts[j] = highlightText(bodies[j], hitTokens[j], terms, t.highlight.preTag, t.highlight.postTag) + texts[j][len(bodies[j]):]
+		}
+		offset += topk
+	}
+	return nil
+}
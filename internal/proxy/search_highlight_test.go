@@ -0,0 +1,104 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+func TestHighlightText(t *testing.T) {
+	text := "Milvus is a vector database, milvus scales"
+	tokens := []*milvuspb.AnalyzerToken{
+		{Token: "milvus", StartOffset: 0, EndOffset: 6},
+		{Token: "vector", StartOffset: 12, EndOffset: 18},
+		{Token: "database", StartOffset: 19, EndOffset: 27},
+		{Token: "milvus", StartOffset: 29, EndOffset: 35},
+		{Token: "scales", StartOffset: 36, EndOffset: 42},
+	}
+	terms := typeutil.NewSet("milvus", "database")
+	assert.Equal(t, "<em>Milvus</em> is a vector <em>database</em>, <em>milvus</em> scales",
+		highlightText(text, tokens, terms, defaultHighlightPreTag, defaultHighlightPostTag))
+	assert.Equal(t, "[vector] database", highlightText("vector database",
+		[]*milvuspb.AnalyzerToken{{Token: "vector", StartOffset: 0, EndOffset: 6}}, typeutil.NewSet("vector"), "[", "]"))
+	assert.Equal(t, text, highlightText(text, tokens, typeutil.NewSet("search"), defaultHighlightPreTag, defaultHighlightPostTag))
+
+	// overlapping tokens are skipped
+	tokens = []*milvuspb.AnalyzerToken{
+		{Token: "vector database", StartOffset: 0, EndOffset: 15},
+		{Token: "database", StartOffset: 7, EndOffset: 15},
+	}
+	assert.Equal(t, "<em>vector database</em>", highlightText("vector database", tokens,
+		typeutil.NewSet("vector database", "database"), defaultHighlightPreTag, defaultHighlightPostTag))
+}
+
+func TestSearchTask_ParseHighlight(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "text", DataType: schemapb.DataType_VarChar},
+			{FieldID: 102, Name: "sparse", DataType: schemapb.DataType_SparseFloatVector, IsFunctionOutput: true},
+			{FieldID: 103, Name: "title", DataType: schemapb.DataType_VarChar},
+		},
+		Functions: []*schemapb.FunctionSchema{{
+			Name:             "bm25",
+			Type:             schemapb.FunctionType_BM25,
+			InputFieldNames:  []string{"text"},
+			InputFieldIds:    []int64{101},
+			OutputFieldNames: []string{"sparse"},
+			OutputFieldIds:   []int64{102},
+		}},
+	})
+	placeholderGroup, err := proto.Marshal(&commonpb.PlaceholderGroup{
+		Placeholders: []*commonpb.PlaceholderValue{{
+			Tag:    "$0",
+			Type:   commonpb.PlaceholderType_VarChar,
+			Values: [][]byte{[]byte("vector database")},
+		}},
+	})
+	require.NoError(t, err)
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			schema:                 schema,
+			translatedOutputFields: []string{"text", "title"},
+			SearchRequest:          &internalpb.SearchRequest{AnalyzerName: "en"},
+			request:                &milvuspb.SearchRequest{SearchParams: params, PlaceholderGroup: placeholderGroup},
+		}
+	}
+	queryInfo := &planpb.QueryInfo{MetricType: metric.BM25, QueryFieldId: 102}
+
+	highlight, err := newTask().parseHighlight(queryInfo)
+	assert.NoError(t, err)
+	assert.Nil(t, highlight)
+
+	highlight, err = newTask(HighlightKey, "text", HighlightPreTagKey, "<b>").parseHighlight(queryInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, "text", highlight.field.GetName())
+	assert.Equal(t, "bm25", highlight.function.GetName())
+	assert.Equal(t, "en", highlight.analyzerName)
+	assert.Equal(t, []string{"vector database"}, highlight.queries)
+	assert.Equal(t, "<b>", highlight.preTag)
+	assert.Equal(t, defaultHighlightPostTag, highlight.postTag)
+
+	_, err = newTask(HighlightKey, "title").parseHighlight(queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = newTask(HighlightKey, "text").parseHighlight(&planpb.QueryInfo{MetricType: metric.IP, QueryFieldId: 102})
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	task := newTask(HighlightKey, "text")
+	task.translatedOutputFields = []string{"title"}
+	_, err = task.parseHighlight(queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
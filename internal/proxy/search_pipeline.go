@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 
 	"github.com/samber/lo"
 	"go.opentelemetry.io/otel"
@@ -33,11 +35,13 @@ import (
 	"github.com/milvus-io/milvus/internal/types"
 	"github.com/milvus-io/milvus/internal/util/function/rerank"
 	"github.com/milvus-io/milvus/pkg/v2/log"
+	"github.com/milvus-io/milvus/pkg/v2/metrics"
 	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
 	"github.com/milvus-io/milvus/pkg/v2/util/commonpbutil"
 	"github.com/milvus-io/milvus/pkg/v2/util/merr"
 	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/v2/util/timerecord"
 	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
 )
 
@@ -260,12 +264,24 @@ type rerankOperator struct {
 	strictGroupSize bool
 	groupScorerStr  string
 
+	returnOriginalDistances bool
+
 	functionScore *rerank.FunctionScore
+
+	// fall back to the scores before rerank if the rerank fails, set by rerank_best_effort.
+	bestEffort bool
+	// fuses the results of hybrid search by their ranks if the rerank fails, nil for the search of a single field.
+	fallbackScore *rerank.FunctionScore
+	// marked if the rerank failed and was skipped
+	skipped *bool
+	// collects the ranks of the hits in the results of the sub search requests if it is set, by with_fusion_provenance.
+	fusionContributions *[]map[any][]fusionContribution
 }
 
 func newRerankOperator(t *searchTask, _ map[string]any) (operator, error) {
+	var op *rerankOperator
 	if t.SearchRequest.GetIsAdvanced() {
-		return &rerankOperator{
+		op = &rerankOperator{
 			nq:              t.GetNq(),
 			topK:            t.rankParams.limit,
 			offset:          t.rankParams.offset,
@@ -275,19 +291,40 @@ func newRerankOperator(t *searchTask, _ map[string]any) (operator, error) {
 			strictGroupSize: t.rankParams.strictGroupSize,
 			groupScorerStr:  getGroupScorerStr(t.request.GetSearchParams()),
 			functionScore:   t.functionScore,
-		}, nil
-	}
-	return &rerankOperator{
-		nq:              t.SearchRequest.GetNq(),
-		topK:            t.SearchRequest.GetTopk(),
-		offset:          0, // Search performs Offset in the reduce phase
-		roundDecimal:    t.queryInfos[0].RoundDecimal,
-		groupByFieldId:  t.queryInfos[0].GroupByFieldId,
-		groupSize:       t.queryInfos[0].GroupSize,
-		strictGroupSize: t.queryInfos[0].StrictGroupSize,
-		groupScorerStr:  getGroupScorerStr(t.request.GetSearchParams()),
-		functionScore:   t.functionScore,
-	}, nil
+
+			returnOriginalDistances: t.returnOriginalDistances,
+		}
+		if t.withFusionProvenance {
+			op.fusionContributions = &t.fusionContributions
+		}
+	} else {
+		op = &rerankOperator{
+			nq:              t.SearchRequest.GetNq(),
+			topK:            t.SearchRequest.GetTopk(),
+			offset:          0, // Search performs Offset in the reduce phase
+			roundDecimal:    t.queryInfos[0].RoundDecimal,
+			groupByFieldId:  t.queryInfos[0].GroupByFieldId,
+			groupSize:       t.queryInfos[0].GroupSize,
+			strictGroupSize: t.queryInfos[0].StrictGroupSize,
+			groupScorerStr:  getGroupScorerStr(t.request.GetSearchParams()),
+			functionScore:   t.functionScore,
+
+			returnOriginalDistances: t.returnOriginalDistances,
+		}
+	}
+	if t.rerankBestEffort {
+		op.bestEffort = true
+		op.skipped = &t.rerankSkipped
+		if t.SearchRequest.GetIsAdvanced() {
+			// the distances of different metrics are not comparable, RRF by default
+			fallbackScore, err := rerank.NewFunctionScoreWithlegacy(t.schema.CollectionSchema, nil)
+			if err != nil {
+				return nil, err
+			}
+			op.fallbackScore = fallbackScore
+		}
+	}
+	return op, nil
 }
 
 func (op *rerankOperator) run(ctx context.Context, span trace.Span, inputs ...any) ([]any, error) {
@@ -302,15 +339,120 @@ func (op *rerankOperator) run(ctx context.Context, span trace.Span, inputs ...an
 		rankInputs = append(rankInputs, ret)
 		rankMetrics = append(rankMetrics, metrics[idx])
 	}
+	var originalDistances [][]map[any]float32
+	if op.returnOriginalDistances {
+		// collect before rerank, in case the reranker changes the input scores
+		originalDistances = collectOriginalDistances(op.nq, rankInputs)
+	}
+	if op.fusionContributions != nil {
+		*op.fusionContributions = collectFusionContributions(op.nq, rankInputs)
+	}
 	params := rerank.NewSearchParams(op.nq, op.topK, op.offset, op.roundDecimal, op.groupByFieldId,
 		op.groupSize, op.strictGroupSize, op.groupScorerStr, rankMetrics)
 	ret, err := op.functionScore.Process(ctx, params, rankInputs)
 	if err != nil {
-		return nil, err
+		if !op.bestEffort {
+			return nil, err
+		}
+		log.Ctx(ctx).Warn("rerank failed, fall back to the scores before rerank",
+			zap.String("rerank", op.functionScore.RerankName()), zap.Error(err))
+		if ret, err = op.fallback(ctx, params, rankInputs); err != nil {
+			return nil, err
+		}
+		*op.skipped = true
+	}
+	if op.returnOriginalDistances {
+		fillOriginalDistances(ret.GetResults(), originalDistances)
 	}
 	return []any{ret}, nil
 }
 
+// fallback orders the hits by the scores before rerank. The search of a single field is already reduced by topk and offset,
+// while the results of hybrid search are fused by the fallback rerank.
+func (op *rerankOperator) fallback(ctx context.Context, params *rerank.SearchParams, inputs []*milvuspb.SearchResults) (*milvuspb.SearchResults, error) {
+	if op.fallbackScore == nil {
+		return inputs[0], nil
+	}
+	return op.fallbackScore.Process(ctx, params, inputs)
+}
+
+// collectOriginalDistances maps the ids of each query to their distances, for each of the search results.
+func collectOriginalDistances(nq int64, results []*milvuspb.SearchResults) [][]map[any]float32 {
+	distances := make([][]map[any]float32, len(results))
+	for i, result := range results {
+		distances[i] = make([]map[any]float32, nq)
+		data := result.GetResults()
+		var offset int64
+		for q := int64(0); q < nq; q++ {
+			distances[i][q] = make(map[any]float32)
+			if q >= int64(len(data.GetTopks())) {
+				continue
+			}
+			for j := offset; j < offset+data.GetTopks()[q]; j++ {
+				distances[i][q][typeutil.GetPK(data.GetIds(), j)] = data.GetScores()[j]
+			}
+			offset += data.GetTopks()[q]
+		}
+	}
+	return distances
+}
+
+// missingOriginalDistance is the original distance of a hit absent in the result of a sub search request, or whose
+// distance is NaN or Inf. NaN is not used as it breaks the serialization of clients, the validity of each original
+// distance is reported along with the result, see fillOriginalDistancesValidity.
+const missingOriginalDistance = float32(math.MaxFloat32)
+
+// fillOriginalDistances sets the distances before rerank as a parallel array of the scores.
+// Each row has one distance per search result, e.g. [row0_sub0, row0_sub1, row1_sub0, row1_sub1, ...],
+// missingOriginalDistance is used if the id is absent in the search result.
+func fillOriginalDistances(data *schemapb.SearchResultData, originalDistances [][]map[any]float32) {
+	if data == nil {
+		return
+	}
+	numSubs := len(originalDistances)
+	data.Distances = make([]float32, 0, len(data.GetScores())*numSubs)
+	var offset int64
+	for q, topk := range data.GetTopks() {
+		for j := offset; j < offset+topk; j++ {
+			pk := typeutil.GetPK(data.GetIds(), j)
+			for _, subDistances := range originalDistances {
+				distance, ok := subDistances[q][pk]
+				if !ok || isInvalidScore(distance) {
+					distance = missingOriginalDistance
+				}
+				data.Distances = append(data.Distances, distance)
+			}
+		}
+		offset += topk
+	}
+}
+
+// fusionContribution is the rank of a hit in the results of a sub search request, starting from 1.
+type fusionContribution struct {
+	SubRequest int `json:"sub_request"`
+	Rank       int `json:"rank"`
+}
+
+// collectFusionContributions maps the ids of each query to the ranks of them in each of the search results containing them.
+func collectFusionContributions(nq int64, results []*milvuspb.SearchResults) []map[any][]fusionContribution {
+	contributions := make([]map[any][]fusionContribution, nq)
+	for q := range contributions {
+		contributions[q] = make(map[any][]fusionContribution)
+	}
+	for i, result := range results {
+		data := result.GetResults()
+		var offset int64
+		for q := int64(0); q < nq && q < int64(len(data.GetTopks())); q++ {
+			for j := offset; j < offset+data.GetTopks()[q]; j++ {
+				pk := typeutil.GetPK(data.GetIds(), j)
+				contributions[q][pk] = append(contributions[q][pk], fusionContribution{SubRequest: i, Rank: int(j-offset) + 1})
+			}
+			offset += data.GetTopks()[q]
+		}
+	}
+	return contributions
+}
+
 type requeryOperator struct {
 	traceCtx         context.Context
 	outputFieldNames []string
@@ -325,6 +467,9 @@ type requeryOperator struct {
 	queryChannelsTs    map[string]Timestamp
 	consistencyLevel   commonpb.ConsistencyLevel
 	guaranteeTimestamp uint64
+	// mvcc timestamp of the search, used by the channels without the mvcc timestamp reported by the search results.
+	mvccTimestamp uint64
+	queryType     string
 
 	node types.ProxyComponent
 }
@@ -334,13 +479,17 @@ func newRequeryOperator(t *searchTask, _ map[string]any) (operator, error) {
 	if err != nil {
 		return nil, err
 	}
-	outputFieldNames := typeutil.NewSet[string](t.translatedOutputFields...)
+	outputFieldNames := t.translatedOutputFields
 	if t.SearchRequest.GetIsAdvanced() {
-		outputFieldNames.Insert(t.functionScore.GetAllInputFieldNames()...)
+		var numRerankOnly int
+		outputFieldNames, numRerankOnly = requeryOutputFields(t.translatedOutputFields, t.functionScore.GetAllInputFieldNames())
+		log.Ctx(t.TraceCtx()).Debug("requery fields of hybrid search",
+			zap.Int("numFields", len(outputFieldNames)),
+			zap.Int("numRerankOnlyFields", numRerankOnly))
 	}
 	return &requeryOperator{
 		traceCtx:           t.TraceCtx(),
-		outputFieldNames:   outputFieldNames.Collect(),
+		outputFieldNames:   outputFieldNames,
 		timestamp:          t.BeginTs(),
 		dbName:             t.request.GetDbName(),
 		collectionName:     t.request.GetCollectionName(),
@@ -348,23 +497,37 @@ func newRequeryOperator(t *searchTask, _ map[string]any) (operator, error) {
 		queryChannelsTs:    t.queryChannelsTs,
 		consistencyLevel:   t.SearchRequest.GetConsistencyLevel(),
 		guaranteeTimestamp: t.SearchRequest.GetGuaranteeTimestamp(),
+		mvccTimestamp:      t.SearchRequest.GetMvccTimestamp(),
 		notReturnAllMeta:   t.request.GetNotReturnAllMeta(),
 		partitionNames:     t.request.GetPartitionNames(),
 		partitionIDs:       t.SearchRequest.GetPartitionIDs(),
+		queryType:          t.queryTypeLabel(),
 		node:               t.node,
 	}, nil
 }
 
+// requeryOutputFields merges the output fields and the input fields of the rerank, so that each field is fetched once.
+// The number of the fields fetched only for the rerank is returned as well.
+func requeryOutputFields(outputFields []string, rerankInputFields []string) ([]string, int) {
+	fields := lo.Uniq(outputFields)
+	numOutputFields := len(fields)
+	fields = lo.Uniq(append(fields, rerankInputFields...))
+	return fields, len(fields) - numOutputFields
+}
+
 func (op *requeryOperator) run(ctx context.Context, span trace.Span, inputs ...any) ([]any, error) {
 	allIDs := inputs[0].(*schemapb.IDs)
 	if typeutil.GetSizeOfIDs(allIDs) == 0 {
 		return []any{[]*schemapb.FieldData{}}, nil
 	}
 
+	tr := timerecord.NewTimeRecorder("requery")
 	queryResult, err := op.requery(ctx, span, allIDs, op.outputFieldNames)
 	if err != nil {
 		return nil, err
 	}
+	metrics.ProxySearchRequeryLatency.WithLabelValues(strconv.FormatInt(paramtable.GetNodeID(), 10), op.queryType, op.collectionName).
+		Observe(float64(tr.ElapseSpan().Milliseconds()))
 	return []any{queryResult.GetFieldsData()}, nil
 }
 
@@ -400,6 +563,8 @@ func (op *requeryOperator) requery(ctx context.Context, span trace.Span, ids *sc
 			ReqID:            paramtable.GetNodeID(),
 			PartitionIDs:     op.partitionIDs, // use search partitionIDs
 			ConsistencyLevel: op.consistencyLevel,
+			// read the same snapshot as the search
+			MvccTimestamp: op.mvccTimestamp,
 		},
 		request:      queryReq,
 		plan:         plan,
@@ -500,13 +665,16 @@ func pickFieldData(ids *schemapb.IDs, pkOffset map[any]int, fields []*schemapb.F
 	// v3 v2 v5 v4 v1  (result vectors)
 	// ===========================================
 	fieldsData := make([]*schemapb.FieldData, len(fields))
+	memoryBudget := newSearchResultMemoryBudget()
 	for i := 0; i < typeutil.GetSizeOfIDs(ids); i++ {
 		id := typeutil.GetPK(ids, int64(i))
 		if _, ok := pkOffset[id]; !ok {
 			return nil, merr.WrapErrInconsistentRequery(fmt.Sprintf("incomplete query result, missing id %s, len(searchIDs) = %d, len(queryIDs) = %d, collection=%d",
 				id, typeutil.GetSizeOfIDs(ids), len(pkOffset), collectionID))
 		}
-		typeutil.AppendFieldData(fieldsData, fields, int64(pkOffset[id]))
+		if err := memoryBudget.consume(typeutil.AppendFieldData(fieldsData, fields, int64(pkOffset[id]))); err != nil {
+			return nil, err
+		}
 	}
 
 	return fieldsData, nil
@@ -752,3 +752,198 @@ func (s *SearchPipelineSuite) TestMergeIDsFunc() {
 		s.Equal(sortedIds, []string{"a", "b", "c", "d", "e"})
 	}
 }
+
+func (s *SearchPipelineSuite) TestFillOriginalDistances() {
+	genResult := func(ids []int64, scores []float32, topks []int64) *milvuspb.SearchResults {
+		return &milvuspb.SearchResults{
+			Results: &schemapb.SearchResultData{
+				Ids:    &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: ids}}},
+				Scores: scores,
+				Topks:  topks,
+			},
+		}
+	}
+	// nq = 2, the same id may appear in different queries with different distances
+	sub1 := genResult([]int64{1, 2, 1, 3}, []float32{0.9, 0.8, 0.7, 0.6}, []int64{2, 2})
+	sub2 := genResult([]int64{2, 4, 3}, []float32{0.5, 0.4, 0.3}, []int64{2, 1})
+	distances := collectOriginalDistances(2, []*milvuspb.SearchResults{sub1, sub2})
+
+	ranked := genResult([]int64{2, 1, 4, 3, 1}, []float32{1, 0.9, 0.8, 0.7, 0.6}, []int64{3, 2}).GetResults()
+	fillOriginalDistances(ranked, distances)
+	s.Len(ranked.GetDistances(), 10)
+	m := missingOriginalDistance
+	s.Equal([]float32{0.8, 0.5, 0.9, m, m, 0.4, 0.6, 0.3, 0.7, m}, ranked.GetDistances())
+
+	task := &searchTask{result: &milvuspb.SearchResults{Results: ranked}}
+	task.fillOriginalDistancesValidity()
+	s.Equal("[true,true,true,false,false,true,true,true,true,false]",
+		task.result.GetStatus().GetExtraInfo()[searchResultOriginalDistancesValidKey])
+}
+
+func (s *SearchPipelineSuite) TestFillFusionProvenance() {
+	genResult := func(ids []int64, topks []int64) *milvuspb.SearchResults {
+		return &milvuspb.SearchResults{
+			Results: &schemapb.SearchResultData{
+				Ids:    &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: ids}}},
+				Scores: make([]float32, len(ids)),
+				Topks:  topks,
+			},
+		}
+	}
+	sub1 := genResult([]int64{1, 2, 1, 3}, []int64{2, 2})
+	sub2 := genResult([]int64{2, 4, 3}, []int64{2, 1})
+	task := &searchTask{
+		fusionContributions: collectFusionContributions(2, []*milvuspb.SearchResults{sub1, sub2}),
+		// the hits are reordered and filtered after rerank
+		result: genResult([]int64{4, 2, 3}, []int64{2, 1}),
+	}
+	task.fillFusionProvenance()
+	s.JSONEq(`[
+		[{"sub_request": 1, "rank": 2}],
+		[{"sub_request": 0, "rank": 2}, {"sub_request": 1, "rank": 1}],
+		[{"sub_request": 0, "rank": 2}, {"sub_request": 1, "rank": 1}]
+	]`, task.result.GetStatus().GetExtraInfo()[searchResultFusionProvenanceKey])
+}
+
+func (s *SearchPipelineSuite) TestRerankOpWithOriginalDistances() {
+	schema := &schemapb.CollectionSchema{
+		Name: "test",
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "ts", DataType: schemapb.DataType_Int64},
+		},
+	}
+	funcScore, err := rerank.NewFunctionScore(schema, &schemapb.FunctionScore{
+		Functions: []*schemapb.FunctionSchema{
+			{
+				Name:            "test",
+				Type:            schemapb.FunctionType_Rerank,
+				InputFieldNames: []string{},
+				Params: []*commonpb.KeyValuePair{
+					{Key: "reranker", Value: "rrf"},
+				},
+			},
+		},
+	})
+	s.NoError(err)
+
+	nq := int64(2)
+	topk := int64(10)
+	reduceOp := searchReduceOperator{
+		context.Background(),
+		schema.Fields[0],
+		nq,
+		topk,
+		0,
+		1,
+		[]int64{1},
+		[]*planpb.QueryInfo{{}},
+	}
+	data := genTestSearchResultData(nq, topk, schemapb.DataType_Int64, "intField", 101, false)
+	reduced, err := reduceOp.run(context.Background(), s.span, []*internalpb.SearchResults{data})
+	s.NoError(err)
+	originalScores := append([]float32{}, reduced[0].([]*milvuspb.SearchResults)[0].GetResults().GetScores()...)
+
+	op := rerankOperator{
+		nq:                      nq,
+		topK:                    topk,
+		roundDecimal:            -1,
+		functionScore:           funcScore,
+		returnOriginalDistances: true,
+	}
+	ret, err := op.run(context.Background(), s.span, reduced[0], []string{"IP"})
+	s.NoError(err)
+	result := ret[0].(*milvuspb.SearchResults).GetResults()
+	s.Len(result.GetDistances(), len(result.GetScores()))
+	s.ElementsMatch(originalScores, result.GetDistances())
+}
+
+func (s *SearchPipelineSuite) TestRerankOpBestEffort() {
+	schema := &schemapb.CollectionSchema{
+		Name: "test",
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "ts", DataType: schemapb.DataType_Int64},
+		},
+	}
+	funcScore, err := rerank.NewFunctionScore(schema, &schemapb.FunctionScore{
+		Functions: []*schemapb.FunctionSchema{
+			{
+				Name:            "test",
+				Type:            schemapb.FunctionType_Rerank,
+				InputFieldNames: []string{"ts"},
+				Params: []*commonpb.KeyValuePair{
+					{Key: "reranker", Value: "decay"},
+					{Key: "origin", Value: "4"},
+					{Key: "scale", Value: "4"},
+					{Key: "offset", Value: "4"},
+					{Key: "decay", Value: "0.5"},
+					{Key: "function", Value: "gauss"},
+				},
+			},
+		},
+	})
+	s.NoError(err)
+
+	nq := int64(2)
+	topk := int64(10)
+	reduceOp := searchReduceOperator{
+		context.Background(),
+		schema.Fields[0],
+		nq,
+		topk,
+		0,
+		1,
+		[]int64{1},
+		[]*planpb.QueryInfo{{}},
+	}
+	// the input field of the rerank is missing in the results, so the rerank fails
+	data := genTestSearchResultData(nq, topk, schemapb.DataType_Int64, "intField", 102, false)
+	reduced, err := reduceOp.run(context.Background(), s.span, []*internalpb.SearchResults{data})
+	s.NoError(err)
+	reducedResult := reduced[0].([]*milvuspb.SearchResults)[0].GetResults()
+
+	op := rerankOperator{
+		nq:            nq,
+		topK:          topk,
+		roundDecimal:  -1,
+		functionScore: funcScore,
+	}
+	_, err = op.run(context.Background(), s.span, reduced[0], []string{"IP"})
+	s.Error(err)
+
+	skipped := false
+	op.bestEffort = true
+	op.skipped = &skipped
+	ret, err := op.run(context.Background(), s.span, reduced[0], []string{"IP"})
+	s.NoError(err)
+	s.True(skipped)
+	result := ret[0].(*milvuspb.SearchResults).GetResults()
+	s.Equal(reducedResult.GetIds().GetIntId().GetData(), result.GetIds().GetIntId().GetData())
+	s.Equal(reducedResult.GetScores(), result.GetScores())
+
+	// the results of hybrid search are fused by the fallback rerank
+	fallbackScore, err := rerank.NewFunctionScoreWithlegacy(schema, nil)
+	s.NoError(err)
+	skipped = false
+	op.fallbackScore = fallbackScore
+	inputs := []*milvuspb.SearchResults{reduced[0].([]*milvuspb.SearchResults)[0], reduced[0].([]*milvuspb.SearchResults)[0]}
+	ret, err = op.run(context.Background(), s.span, inputs, []string{"IP", "IP"})
+	s.NoError(err)
+	s.True(skipped)
+	s.NotEmpty(ret[0].(*milvuspb.SearchResults).GetResults().GetScores())
+}
+
+func (s *SearchPipelineSuite) TestRequeryOutputFields() {
+	fields, numRerankOnly := requeryOutputFields([]string{"title", "ts", "vector"}, []string{"ts", "rating", "rating"})
+	s.Equal([]string{"title", "ts", "vector", "rating"}, fields)
+	s.Equal(1, numRerankOnly)
+
+	fields, numRerankOnly = requeryOutputFields(nil, []string{"ts"})
+	s.Equal([]string{"ts"}, fields)
+	s.Equal(1, numRerankOnly)
+
+	fields, numRerankOnly = requeryOutputFields([]string{"title"}, nil)
+	s.Equal([]string{"title"}, fields)
+	s.Equal(0, numRerankOnly)
+}
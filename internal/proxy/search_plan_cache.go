@@ -0,0 +1,146 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/metrics"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+)
+
+const searchPlanCacheName = "SearchPlan"
+
+var (
+	searchPlanCacheOnce sync.Once
+	searchPlanCache     *planCache
+)
+
+// getSearchPlanCache returns the global search plan cache, nil is returned if the cache is disabled.
+func getSearchPlanCache() *planCache {
+	searchPlanCacheOnce.Do(func() {
+		size := Params.ProxyCfg.SearchPlanCacheSize.GetAsInt()
+		if size <= 0 {
+			return
+		}
+		searchPlanCache = newPlanCache(size, Params.ProxyCfg.SearchPlanCacheTTL.GetAsDuration(time.Second))
+	})
+	return searchPlanCache
+}
+
+// planCache caches the compiled plans to skip parsing the same expression repeatedly.
+// The cached plans are read-only, callers always get a clone of them.
+type planCache struct {
+	plans *expirable.LRU[string, *planpb.PlanNode]
+}
+
+func newPlanCache(size int, ttl time.Duration) *planCache {
+	return &planCache{
+		plans: expirable.NewLRU[string, *planpb.PlanNode](size, nil, ttl),
+	}
+}
+
+// getSearchPlan returns a clone of the cached search plan, the query info of the plan is replaced by the given one
+// so that the caller could keep modifying the query info after the plan is generated.
+func (c *planCache) getSearchPlan(key string, queryInfo *planpb.QueryInfo) (*planpb.PlanNode, bool) {
+	nodeID := fmt.Sprint(paramtable.GetNodeID())
+	plan, ok := c.plans.Get(key)
+	if !ok {
+		metrics.ProxyCacheStatsCounter.WithLabelValues(nodeID, searchPlanCacheName, metrics.CacheMissLabel).Inc()
+		return nil, false
+	}
+	metrics.ProxyCacheStatsCounter.WithLabelValues(nodeID, searchPlanCacheName, metrics.CacheHitLabel).Inc()
+	plan = proto.Clone(plan).(*planpb.PlanNode)
+	if vectorAnns := plan.GetVectorAnns(); vectorAnns != nil {
+		vectorAnns.QueryInfo = queryInfo
+	}
+	return plan, true
+}
+
+// addSearchPlan puts a clone of the plan into cache, the plan shall be added right after it is created.
+func (c *planCache) addSearchPlan(key string, plan *planpb.PlanNode) {
+	c.plans.Add(key, proto.Clone(plan).(*planpb.PlanNode))
+}
+
+// searchPlanCacheKey generates the key of a search plan. The schema update timestamp is part of the key,
+// so that the plans generated before schema change will never be hit and evicted eventually.
+func searchPlanCacheKey(collectionID int64, schemaUpdateTs uint64, dsl string, annsField string,
+	queryInfo *planpb.QueryInfo, exprTemplateValues map[string]*schemapb.TemplateValue,
+) (string, error) {
+	h := sha256.New()
+	writeUint64 := func(v uint64) {
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	writeUint64(uint64(collectionID))
+	writeUint64(schemaUpdateTs)
+	writeBytesWithLen(h, []byte(dsl))
+	writeBytesWithLen(h, []byte(annsField))
+
+	marshaler := proto.MarshalOptions{Deterministic: true}
+	bs, err := marshaler.Marshal(queryInfo)
+	if err != nil {
+		return "", err
+	}
+	writeBytesWithLen(h, bs)
+
+	names := make([]string, 0, len(exprTemplateValues))
+	for name := range exprTemplateValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeBytesWithLen(h, []byte(name))
+		bs, err := marshaler.Marshal(exprTemplateValues[name])
+		if err != nil {
+			return "", err
+		}
+		writeBytesWithLen(h, bs)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// searchPlanHash returns the stable hash of the compiled plan, the logically identical searches share the same hash.
+func searchPlanHash(plan *planpb.PlanNode) (string, error) {
+	bs, err := proto.MarshalOptions{Deterministic: true}.Marshal(plan)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(bs)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// writeBytesWithLen writes the length before bytes, to avoid collisions between adjacent variable length fields.
+func writeBytesWithLen(h hash.Hash, bs []byte) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(len(bs)))
+	h.Write(buf[:])
+	h.Write(bs)
+}
@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+)
+
+func TestSearchPlanCacheKey(t *testing.T) {
+	queryInfo := &planpb.QueryInfo{Topk: 10, MetricType: "L2", SearchParams: `{"nprobe": 10}`, QueryFieldId: 101}
+	templateValues := map[string]*schemapb.TemplateValue{
+		"a": {Val: &schemapb.TemplateValue_Int64Val{Int64Val: 1}},
+		"b": {Val: &schemapb.TemplateValue_StringVal{StringVal: "x"}},
+	}
+	key, err := searchPlanCacheKey(1, 100, "a > {a}", "vec", queryInfo, templateValues)
+	assert.NoError(t, err)
+
+	same, err := searchPlanCacheKey(1, 100, "a > {a}", "vec", queryInfo, templateValues)
+	assert.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	differents := []func() (string, error){
+		func() (string, error) {
+			return searchPlanCacheKey(2, 100, "a > {a}", "vec", queryInfo, templateValues)
+		},
+		func() (string, error) {
+			// schema changed
+			return searchPlanCacheKey(1, 101, "a > {a}", "vec", queryInfo, templateValues)
+		},
+		func() (string, error) {
+			return searchPlanCacheKey(1, 100, "a >= {a}", "vec", queryInfo, templateValues)
+		},
+		func() (string, error) {
+			return searchPlanCacheKey(1, 100, "a > {a}", "vec2", queryInfo, templateValues)
+		},
+		func() (string, error) {
+			return searchPlanCacheKey(1, 100, "a > {a}", "vec", &planpb.QueryInfo{Topk: 20, MetricType: "L2", SearchParams: `{"nprobe": 10}`, QueryFieldId: 101}, templateValues)
+		},
+		func() (string, error) {
+			return searchPlanCacheKey(1, 100, "a > {a}", "vec", queryInfo, map[string]*schemapb.TemplateValue{
+				"a": {Val: &schemapb.TemplateValue_Int64Val{Int64Val: 2}},
+				"b": {Val: &schemapb.TemplateValue_StringVal{StringVal: "x"}},
+			})
+		},
+		func() (string, error) {
+			// adjacent fields shall not collide
+			return searchPlanCacheKey(1, 100, "a > {a}v", "ec", queryInfo, templateValues)
+		},
+	}
+	for _, f := range differents {
+		other, err := f()
+		assert.NoError(t, err)
+		assert.NotEqual(t, key, other)
+	}
+}
+
+func TestPlanCache(t *testing.T) {
+	paramtable.Init()
+	cache := newPlanCache(2, time.Minute)
+
+	queryInfo := &planpb.QueryInfo{Topk: 10, MetricType: "L2"}
+	plan := &planpb.PlanNode{
+		Node: &planpb.PlanNode_VectorAnns{
+			VectorAnns: &planpb.VectorANNS{
+				FieldId:   101,
+				QueryInfo: queryInfo,
+			},
+		},
+	}
+	_, ok := cache.getSearchPlan("k1", queryInfo)
+	assert.False(t, ok)
+
+	cache.addSearchPlan("k1", plan)
+	// modifications after adding shall not affect the cached plan
+	plan.OutputFieldIds = []int64{100}
+
+	newQueryInfo := &planpb.QueryInfo{Topk: 10, MetricType: "L2"}
+	cached, ok := cache.getSearchPlan("k1", newQueryInfo)
+	assert.True(t, ok)
+	assert.Empty(t, cached.GetOutputFieldIds())
+	assert.Equal(t, int64(101), cached.GetVectorAnns().GetFieldId())
+	assert.Same(t, newQueryInfo, cached.GetVectorAnns().GetQueryInfo())
+
+	// modifications of the returned plan shall not affect the cached plan either
+	cached.OutputFieldIds = []int64{100}
+	cached, ok = cache.getSearchPlan("k1", newQueryInfo)
+	assert.True(t, ok)
+	assert.Empty(t, cached.GetOutputFieldIds())
+
+	cache.addSearchPlan("k2", plan)
+	cache.addSearchPlan("k3", plan)
+	_, ok = cache.getSearchPlan("k1", queryInfo)
+	assert.False(t, ok)
+	_, ok = cache.getSearchPlan("k3", queryInfo)
+	assert.True(t, ok)
+}
+
+func TestSearchPlanHash(t *testing.T) {
+	paramtable.Init()
+	schema := newSchemaInfo(constructCollectionSchema(testInt64Field, testFloatVecField, 8, "test_collection"))
+	newTask := func() *searchTask {
+		return &searchTask{
+			ctx:           context.Background(),
+			SearchRequest: &internalpb.SearchRequest{},
+			schema:        schema,
+			withPlanHash:  true,
+		}
+	}
+
+	task := newTask()
+	_, _, _, _, err := task.tryGeneratePlan(getValidSearchParams(), testInt64Field+" > 1", nil)
+	assert.NoError(t, err)
+	assert.Len(t, task.planHashes, 1)
+	assert.NotEmpty(t, task.planHashes[0])
+
+	// logically identical
+	same := newTask()
+	_, _, _, _, err = same.tryGeneratePlan(getValidSearchParams(), "("+testInt64Field+">1)", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, task.planHashes, same.planHashes)
+
+	other := newTask()
+	_, _, _, _, err = other.tryGeneratePlan(getValidSearchParams(), testInt64Field+" > 2", nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, task.planHashes, other.planHashes)
+
+	disabled := newTask()
+	disabled.withPlanHash = false
+	_, _, _, _, err = disabled.tryGeneratePlan(getValidSearchParams(), testInt64Field+" > 1", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, disabled.planHashes)
+}
@@ -0,0 +1,145 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+)
+
+// queryVectorsURIScheme is the only scheme of query_vectors_uri, the query vectors are fetched from the object storage
+// of the cluster only, never from arbitrary urls.
+const queryVectorsURIScheme = "s3"
+
+var (
+	queryVectorsChunkManagerMu sync.Mutex
+	queryVectorsChunkManager   storage.ChunkManager
+)
+
+// getQueryVectorsChunkManager returns the chunk manager of the object storage to fetch the query vectors from,
+// it is created on the first use, and created again by the next use if the creation fails.
+func getQueryVectorsChunkManager(ctx context.Context) (storage.ChunkManager, error) {
+	queryVectorsChunkManagerMu.Lock()
+	defer queryVectorsChunkManagerMu.Unlock()
+	if queryVectorsChunkManager != nil {
+		return queryVectorsChunkManager, nil
+	}
+	cm, err := storage.NewChunkManagerFactoryWithParam(paramtable.Get()).NewPersistentStorageChunkManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queryVectorsChunkManager = cm
+	return cm, nil
+}
+
+// parseQueryVectorsURI parses query_vectors_uri of the form s3://<bucket>/<path>, and returns the path of the object.
+// The bucket shall be the one of the cluster, and the path shall be under proxy.queryVectors.uriPrefix.
+func parseQueryVectorsURI(uri string) (string, error) {
+	prefix := strings.Trim(Params.ProxyCfg.QueryVectorsURIPrefix.GetValue(), "/")
+	if prefix == "" {
+		return "", merr.WrapErrParameterInvalidMsg("%s is disabled, please set proxy.queryVectors.uriPrefix first", QueryVectorsURIKey)
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != queryVectorsURIScheme {
+		return "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be of the form %s://<bucket>/<path>", QueryVectorsURIKey, uri, queryVectorsURIScheme)
+	}
+	if bucket := Params.MinioCfg.BucketName.GetValue(); u.Host != bucket {
+		return "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, only the bucket %s is accessible", QueryVectorsURIKey, uri, bucket)
+	}
+	// clean the path before checking the prefix, so that the path could not escape the prefix by ..
+	objectPath := path.Clean(strings.TrimPrefix(u.Path, "/"))
+	if !strings.HasPrefix(objectPath, prefix+"/") {
+		return "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, only the objects under %s are accessible", QueryVectorsURIKey, uri, prefix)
+	}
+	return objectPath, nil
+}
+
+// fetchQueryVectors reads the serialized placeholder group from the object, which is checked to be no larger than
+// proxy.queryVectors.maxObjectSize before being read.
+func fetchQueryVectors(ctx context.Context, cm storage.ChunkManager, objectPath string) ([]byte, error) {
+	maxSize := Params.ProxyCfg.MaxQueryVectorsObjectSize.GetAsSize()
+	size, err := cm.Size(ctx, objectPath)
+	if err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("failed to access the query vectors %s, err: %s", objectPath, err.Error())
+	}
+	if size > maxSize {
+		return nil, merr.WrapErrParameterTooLarge(QueryVectorsURIKey,
+			fmt.Sprintf("the query vectors object size %d exceeds the maximum %d", size, maxSize))
+	}
+	bs, err := cm.Read(ctx, objectPath)
+	if err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("failed to read the query vectors %s, err: %s", objectPath, err.Error())
+	}
+	// the object may be overwritten after the size is checked
+	if int64(len(bs)) > maxSize {
+		return nil, merr.WrapErrParameterTooLarge(QueryVectorsURIKey,
+			fmt.Sprintf("the query vectors object size %d exceeds the maximum %d", len(bs), maxSize))
+	}
+	placeholderGroup := &commonpb.PlaceholderGroup{}
+	if err := proto.Unmarshal(bs, placeholderGroup); err != nil || len(placeholderGroup.GetPlaceholders()) != 1 {
+		return nil, merr.WrapErrParameterInvalidMsg("the query vectors %s is not a serialized placeholder group with one placeholder", objectPath)
+	}
+	return bs, nil
+}
+
+// resolveQueryVectorsURI replaces the placeholder group of the request with the one fetched from query_vectors_uri,
+// so that the large batch searches do not have to send the query vectors in the request. The dimension of the query
+// vectors is checked against the anns field by initSearchRequest. It shall be called before checkNq, as nq is derived
+// from the fetched placeholder group.
+func (t *searchTask) resolveQueryVectorsURI(ctx context.Context) error {
+	uri, err := funcutil.GetAttrByKeyFromRepeatedKV(QueryVectorsURIKey, t.request.GetSearchParams())
+	if err != nil {
+		return nil
+	}
+	if t.SearchRequest.GetIsAdvanced() {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", QueryVectorsURIKey)
+	}
+	if len(t.request.GetPlaceholderGroup()) > 0 {
+		return merr.WrapErrParameterInvalidMsg("placeholder group shall be empty if %s is specified", QueryVectorsURIKey)
+	}
+	objectPath, err := parseQueryVectorsURI(uri)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Params.ProxyCfg.QueryVectorsFetchTimeout.GetAsDuration(time.Second))
+	defer cancel()
+	cm, err := getQueryVectorsChunkManager(ctx)
+	if err != nil {
+		return err
+	}
+	placeholderGroup, err := fetchQueryVectors(ctx, cm, objectPath)
+	if err != nil {
+		return err
+	}
+	t.request.PlaceholderGroup = placeholderGroup
+	t.request.Nq = 0
+	return nil
+}
@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus/internal/mocks"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+)
+
+func TestParseQueryVectorsURI(t *testing.T) {
+	bucket := paramtable.Get().MinioCfg.BucketName.GetValue()
+
+	_, err := parseQueryVectorsURI("s3://" + bucket + "/batch/vectors")
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	paramtable.Get().Save(paramtable.Get().ProxyCfg.QueryVectorsURIPrefix.Key, "batch/")
+	defer paramtable.Get().Reset(paramtable.Get().ProxyCfg.QueryVectorsURIPrefix.Key)
+
+	objectPath, err := parseQueryVectorsURI("s3://" + bucket + "/batch/job1/vectors")
+	assert.NoError(t, err)
+	assert.Equal(t, "batch/job1/vectors", objectPath)
+
+	for _, uri := range []string{
+		"http://" + bucket + "/batch/vectors",
+		"s3://another-bucket/batch/vectors",
+		"s3://" + bucket + "/insert_log/1/2/3",
+		"s3://" + bucket + "/batch/../insert_log/1/2/3",
+		"s3://" + bucket + "/batch",
+	} {
+		_, err := parseQueryVectorsURI(uri)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid, uri)
+	}
+}
+
+func TestFetchQueryVectors(t *testing.T) {
+	placeholderGroup, err := proto.Marshal(&commonpb.PlaceholderGroup{
+		Placeholders: []*commonpb.PlaceholderValue{{
+			Tag:    "$0",
+			Type:   commonpb.PlaceholderType_FloatVector,
+			Values: [][]byte{make([]byte, 16), make([]byte, 16)},
+		}},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	cm := mocks.NewChunkManager(t)
+	cm.EXPECT().Size(mock.Anything, "batch/vectors").Return(int64(len(placeholderGroup)), nil)
+	cm.EXPECT().Read(mock.Anything, "batch/vectors").Return(placeholderGroup, nil)
+	bs, err := fetchQueryVectors(ctx, cm, "batch/vectors")
+	assert.NoError(t, err)
+	assert.Equal(t, placeholderGroup, bs)
+
+	// not a placeholder group
+	cm = mocks.NewChunkManager(t)
+	cm.EXPECT().Size(mock.Anything, "batch/garbage").Return(int64(3), nil)
+	cm.EXPECT().Read(mock.Anything, "batch/garbage").Return([]byte{0xff, 0xff, 0xff}, nil)
+	_, err = fetchQueryVectors(ctx, cm, "batch/garbage")
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	// the oversized object is not read
+	paramtable.Get().Save(paramtable.Get().ProxyCfg.MaxQueryVectorsObjectSize.Key, "16")
+	defer paramtable.Get().Reset(paramtable.Get().ProxyCfg.MaxQueryVectorsObjectSize.Key)
+	cm = mocks.NewChunkManager(t)
+	cm.EXPECT().Size(mock.Anything, "batch/vectors").Return(int64(len(placeholderGroup)), nil)
+	_, err = fetchQueryVectors(ctx, cm, "batch/vectors")
+	assert.ErrorIs(t, err, merr.ErrParameterTooLarge)
+}
@@ -22,6 +22,28 @@ import (
 	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
 )
 
+// searchResultMemoryBudget tracks the estimated size of the field data assembled by a search,
+// to abort the oversized search rather than OOM the proxy.
+type searchResultMemoryBudget struct {
+	budget int64
+	used   int64
+}
+
+func newSearchResultMemoryBudget() *searchResultMemoryBudget {
+	return &searchResultMemoryBudget{
+		budget: paramtable.Get().ProxyCfg.SearchResultMemoryBudget.GetAsSize(),
+	}
+}
+
+// consume accounts the size appended, no limit if the budget is less or equal to 0.
+func (b *searchResultMemoryBudget) consume(size int64) error {
+	b.used += size
+	if b.budget > 0 && b.used > b.budget {
+		return merr.WrapErrSearchResultTooLarge(b.used, b.budget, "exceeds proxy.searchResultMemoryBudget")
+	}
+	return nil
+}
+
 func reduceSearchResult(ctx context.Context, subSearchResultData []*schemapb.SearchResultData, reduceInfo *reduce.ResultInfo) (*milvuspb.SearchResults, error) {
 	if reduceInfo.GetGroupByFieldId() > 0 {
 		if reduceInfo.GetIsAdvance() {
@@ -305,7 +327,7 @@ func reduceSearchResultDataWithGroupBy(ctx context.Context, subSearchResultData
 
 		// limit search result to avoid oom
 		if retSize > maxOutputSize {
-			return nil, fmt.Errorf("search results exceed the maxOutputSize Limit %d", maxOutputSize)
+			return nil, merr.WrapErrSearchResultTooLarge(retSize, maxOutputSize, "exceeds quotaAndLimits.limits.maxOutputSize")
 		}
 	}
 	ret.Results.TopK = realTopK // realTopK is the topK of the nq-th query
@@ -421,7 +443,7 @@ func reduceSearchResultDataNoGroupBy(ctx context.Context, subSearchResultData []
 
 			// limit search result to avoid oom
 			if retSize > maxOutputSize {
-				return nil, fmt.Errorf("search results exceed the maxOutputSize Limit %d", maxOutputSize)
+				return nil, merr.WrapErrSearchResultTooLarge(retSize, maxOutputSize, "exceeds quotaAndLimits.limits.maxOutputSize")
 			}
 		}
 		ret.Results.TopK = realTopK // realTopK is the topK of the nq-th query
@@ -7,6 +7,8 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
 )
 
 type SearchReduceUtilTestSuite struct {
@@ -84,6 +86,57 @@ func (struts *SearchReduceUtilTestSuite) TestReduceSearchResultWithEmtpyGroupDat
 	struts.Nil(results.Results.GetGroupByFieldValue())
 }
 
+func (struts *SearchReduceUtilTestSuite) TestReduceSearchResultExceedMaxOutputSize() {
+	genData := func(ids []int64, scores []float32) *schemapb.SearchResultData {
+		return &schemapb.SearchResultData{
+			NumQueries: 1,
+			TopK:       int64(len(ids)),
+			Topks:      []int64{int64(len(ids))},
+			Scores:     scores,
+			Ids:        &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: ids}}},
+			FieldsData: []*schemapb.FieldData{{
+				Type:      schemapb.DataType_Int64,
+				FieldName: "price",
+				FieldId:   101,
+				Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+					Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: ids}},
+				}},
+			}},
+		}
+	}
+	data := []*schemapb.SearchResultData{genData([]int64{1, 3}, []float32{0.9, 0.7}), genData([]int64{2, 4}, []float32{0.8, 0.6})}
+
+	_, err := reduceSearchResultDataNoGroupBy(context.Background(), data, 1, 4, "IP", schemapb.DataType_Int64, 0)
+	struts.NoError(err)
+
+	paramtable.Get().Save(paramtable.Get().QuotaConfig.MaxOutputSize.Key, "16")
+	defer paramtable.Get().Reset(paramtable.Get().QuotaConfig.MaxOutputSize.Key)
+	_, err = reduceSearchResultDataNoGroupBy(context.Background(), data, 1, 4, "IP", schemapb.DataType_Int64, 0)
+	struts.ErrorIs(err, merr.ErrSearchResultTooLarge)
+}
+
+func (struts *SearchReduceUtilTestSuite) TestPickFieldDataExceedMemoryBudget() {
+	ids := &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{3, 1, 2}}}}
+	pkOffset := map[any]int{int64(1): 0, int64(2): 1, int64(3): 2}
+	fields := []*schemapb.FieldData{{
+		Type:      schemapb.DataType_Int64,
+		FieldName: "price",
+		FieldId:   101,
+		Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+			Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: []int64{10, 20, 30}}},
+		}},
+	}}
+
+	fieldsData, err := pickFieldData(ids, pkOffset, fields, 1)
+	struts.NoError(err)
+	struts.Equal([]int64{30, 10, 20}, fieldsData[0].GetScalars().GetLongData().GetData())
+
+	paramtable.Get().Save(paramtable.Get().ProxyCfg.SearchResultMemoryBudget.Key, "16")
+	defer paramtable.Get().Reset(paramtable.Get().ProxyCfg.SearchResultMemoryBudget.Key)
+	_, err = pickFieldData(ids, pkOffset, fields, 1)
+	struts.ErrorIs(err, merr.ErrSearchResultTooLarge)
+}
+
 func TestSearchReduceUtilTestSuite(t *testing.T) {
 	suite.Run(t, new(SearchReduceUtilTestSuite))
 }
@@ -0,0 +1,196 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+const (
+	sortOrderAsc  = "asc"
+	sortOrderDesc = "desc"
+)
+
+// parseSortBy resolves the field the hits are sorted by, set by sort_by in the form of field[:asc|desc].
+// The field is fetched along with the search if it is not one of the output fields, and dropped after sorting.
+func (t *searchTask) parseSortBy() error {
+	sortBy, err := funcutil.GetAttrByKeyFromRepeatedKV(SortByKey, t.request.GetSearchParams())
+	if err != nil || sortBy == "" {
+		return nil
+	}
+	fieldName, order, _ := strings.Cut(sortBy, ":")
+	switch order {
+	case "", sortOrderAsc:
+	case sortOrderDesc:
+		t.sortByDesc = true
+	default:
+		return merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, the order should be %s or %s", SortByKey, sortBy, sortOrderAsc, sortOrderDesc)
+	}
+	isIteratorStr, _ := funcutil.GetAttrByKeyFromRepeatedKV(IteratorField, t.request.GetSearchParams())
+	isIterator, _ := strconv.ParseBool(isIteratorStr)
+	groupByField, _ := funcutil.GetAttrByKeyFromRepeatedKV(GroupByFieldKey, t.request.GetSearchParams())
+	switch {
+	case isIterator:
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator", SortByKey)
+	case t.withSearchCursor:
+		return merr.WrapErrParameterInvalidMsg("%s could not be used with %s", SortByKey, SearchCursorKey)
+	case groupByField != "":
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by grouping search", SortByKey)
+	}
+
+	field := typeutil.GetFieldByName(t.schema.CollectionSchema, fieldName)
+	if field == nil {
+		return merr.WrapErrFieldNotFound(fieldName, fmt.Sprintf("%s field not found in schema", SortByKey))
+	}
+	if !typeutil.IsArithmetic(field.GetDataType()) && !typeutil.IsStringType(field.GetDataType()) {
+		return merr.WrapErrParameterInvalidMsg("%s field %s should be numeric or string, but got %s",
+			SortByKey, fieldName, field.GetDataType().String())
+	}
+	t.sortByField = field
+	// the pk is returned as the ids, no need to fetch it
+	if !field.GetIsPrimaryKey() && !lo.Contains(t.translatedOutputFields, fieldName) {
+		t.translatedOutputFields = append(t.translatedOutputFields, fieldName)
+		t.SearchRequest.OutputFieldsId = append(t.SearchRequest.OutputFieldsId, field.GetFieldID())
+		t.sortByFieldFetched = true
+	}
+	return nil
+}
+
+// sortKeyGetter returns the function getting the sort key of the hit, false if the value is null.
+func sortKeyGetter(data *schemapb.SearchResultData, field *schemapb.FieldSchema) func(idx int64) (any, bool) {
+	if field.GetIsPrimaryKey() {
+		return func(idx int64) (any, bool) {
+			return typeutil.GetPK(data.GetIds(), idx), true
+		}
+	}
+	fieldData, ok := lo.Find(data.GetFieldsData(), func(fieldData *schemapb.FieldData) bool {
+		return fieldData.GetFieldName() == field.GetName()
+	})
+	if !ok {
+		return func(int64) (any, bool) { return nil, false }
+	}
+	validData := fieldData.GetValidData()
+	scalars := fieldData.GetScalars()
+	return func(idx int64) (any, bool) {
+		if int64(len(validData)) > idx && !validData[idx] {
+			return nil, false
+		}
+		switch fieldData.GetType() {
+		case schemapb.DataType_Int8, schemapb.DataType_Int16, schemapb.DataType_Int32:
+			if data := scalars.GetIntData().GetData(); idx < int64(len(data)) {
+				return int64(data[idx]), true
+			}
+		case schemapb.DataType_Int64:
+			if data := scalars.GetLongData().GetData(); idx < int64(len(data)) {
+				return data[idx], true
+			}
+		case schemapb.DataType_Float:
+			if data := scalars.GetFloatData().GetData(); idx < int64(len(data)) {
+				return float64(data[idx]), true
+			}
+		case schemapb.DataType_Double:
+			if data := scalars.GetDoubleData().GetData(); idx < int64(len(data)) {
+				return data[idx], true
+			}
+		case schemapb.DataType_String, schemapb.DataType_VarChar:
+			if data := scalars.GetStringData().GetData(); idx < int64(len(data)) {
+				return data[idx], true
+			}
+		}
+		return nil, false
+	}
+}
+
+func compareSortKeys(a, b any) int {
+	switch a := a.(type) {
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case string:
+		return cmp.Compare(a, b.(string))
+	}
+	return 0
+}
+
+// sortSearchResultDataByField re-orders the hits of each query by the field, the hits with null values are put last.
+// The sort is stable, so the hits with the same value are still ordered by score. Only the order is changed,
+// the scores are returned as they are.
+func sortSearchResultDataByField(data *schemapb.SearchResultData, field *schemapb.FieldSchema, desc bool) {
+	if data == nil || len(data.GetScores()) == 0 {
+		return
+	}
+	getKey := sortKeyGetter(data, field)
+	order := make([]int64, 0, len(data.GetScores()))
+	var offset int64
+	for _, topk := range data.GetTopks() {
+		hits := lo.RangeFrom(offset, int(topk))
+		slices.SortStableFunc(hits, func(i, j int64) int {
+			a, aValid := getKey(i)
+			b, bValid := getKey(j)
+			switch {
+			case !aValid || !bValid:
+				// nulls last
+				return cmp.Compare(lo.Ternary(aValid, 0, 1), lo.Ternary(bValid, 0, 1))
+			case desc:
+				return compareSortKeys(b, a)
+			default:
+				return compareSortKeys(a, b)
+			}
+		})
+		order = append(order, hits...)
+		offset += topk
+	}
+	reorderSearchResultData(data, order)
+}
+
+// reorderSearchResultData rearranges the hits in the order of the indexes, the number of hits of each query is unchanged.
+func reorderSearchResultData(data *schemapb.SearchResultData, order []int64) {
+	distancesPerHit := getDistancesPerHit(data)
+	ids := &schemapb.IDs{}
+	if data.GetIds().GetStrId() != nil {
+		ids.IdField = &schemapb.IDs_StrId{StrId: &schemapb.StringArray{}}
+	} else {
+		ids.IdField = &schemapb.IDs_IntId{IntId: &schemapb.LongArray{}}
+	}
+	scores := make([]float32, 0, len(order))
+	distances := make([]float32, 0, len(data.GetDistances()))
+	fieldsData := typeutil.PrepareResultFieldData(data.GetFieldsData(), int64(len(order)))
+	for _, j := range order {
+		typeutil.AppendPKs(ids, typeutil.GetPK(data.GetIds(), j))
+		scores = append(scores, data.GetScores()[j])
+		distances = appendHitDistances(distances, data, distancesPerHit, j)
+		typeutil.AppendFieldData(fieldsData, data.GetFieldsData(), j)
+	}
+	data.Ids = ids
+	data.Scores = scores
+	data.FieldsData = fieldsData
+	if distancesPerHit > 0 {
+		data.Distances = distances
+	}
+}
@@ -0,0 +1,96 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+func TestSearchTask_ParseSortBy(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "ts", DataType: schemapb.DataType_Int64},
+			{FieldID: 102, Name: "vec", DataType: schemapb.DataType_FloatVector},
+		},
+	})
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			schema:                 schema,
+			translatedOutputFields: []string{"pk"},
+			SearchRequest:          &internalpb.SearchRequest{OutputFieldsId: []int64{100}},
+			request:                &milvuspb.SearchRequest{SearchParams: params},
+		}
+	}
+
+	task := newTask()
+	assert.NoError(t, task.parseSortBy())
+	assert.Nil(t, task.sortByField)
+
+	// the sort field is fetched if it is not an output field
+	task = newTask(SortByKey, "ts:desc")
+	assert.NoError(t, task.parseSortBy())
+	assert.Equal(t, "ts", task.sortByField.GetName())
+	assert.True(t, task.sortByDesc)
+	assert.True(t, task.sortByFieldFetched)
+	assert.Equal(t, []string{"pk", "ts"}, task.translatedOutputFields)
+	assert.Equal(t, []int64{100, 101}, task.SearchRequest.GetOutputFieldsId())
+
+	task = newTask(SortByKey, "pk")
+	assert.NoError(t, task.parseSortBy())
+	assert.False(t, task.sortByDesc)
+	assert.False(t, task.sortByFieldFetched)
+
+	assert.ErrorIs(t, newTask(SortByKey, "ts:random").parseSortBy(), merr.ErrParameterInvalid)
+	assert.ErrorIs(t, newTask(SortByKey, "vec").parseSortBy(), merr.ErrParameterInvalid)
+	assert.ErrorIs(t, newTask(SortByKey, "unknown").parseSortBy(), merr.ErrFieldNotFound)
+	assert.ErrorIs(t, newTask(SortByKey, "ts", IteratorField, "true").parseSortBy(), merr.ErrParameterInvalid)
+	assert.ErrorIs(t, newTask(SortByKey, "ts", GroupByFieldKey, "ts").parseSortBy(), merr.ErrParameterInvalid)
+}
+
+func TestSortSearchResultDataByField(t *testing.T) {
+	newData := func() *schemapb.SearchResultData {
+		return &schemapb.SearchResultData{
+			NumQueries: 2,
+			TopK:       3,
+			Topks:      []int64{3, 2},
+			Scores:     []float32{0.9, 0.8, 0.7, 0.6, 0.5},
+			Ids: &schemapb.IDs{
+				IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{5, 4, 3, 2, 1}}},
+			},
+			FieldsData: []*schemapb.FieldData{{
+				Type:      schemapb.DataType_Int64,
+				FieldName: "ts",
+				ValidData: []bool{true, false, true, true, true},
+				Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+					Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: []int64{10, 0, 30, 20, 20}}},
+				}},
+			}},
+		}
+	}
+	tsField := &schemapb.FieldSchema{Name: "ts", DataType: schemapb.DataType_Int64}
+
+	data := newData()
+	sortSearchResultDataByField(data, tsField, true)
+	assert.Equal(t, []int64{3, 2}, data.GetTopks())
+	// nulls last, ties keep the score order
+	assert.Equal(t, []int64{3, 5, 4, 2, 1}, data.GetIds().GetIntId().GetData())
+	assert.Equal(t, []float32{0.7, 0.9, 0.8, 0.6, 0.5}, data.GetScores())
+	assert.Equal(t, []int64{30, 10, 0, 20, 20}, data.GetFieldsData()[0].GetScalars().GetLongData().GetData())
+	assert.Equal(t, []bool{true, true, false, true, true}, data.GetFieldsData()[0].GetValidData())
+
+	data = newData()
+	sortSearchResultDataByField(data, tsField, false)
+	assert.Equal(t, []int64{5, 3, 4, 2, 1}, data.GetIds().GetIntId().GetData())
+
+	data = newData()
+	sortSearchResultDataByField(data, &schemapb.FieldSchema{Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true}, false)
+	assert.Equal(t, []int64{3, 4, 5, 1, 2}, data.GetIds().GetIntId().GetData())
+	assert.Equal(t, []float32{0.7, 0.8, 0.9, 0.5, 0.6}, data.GetScores())
+}
@@ -0,0 +1,221 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/json"
+	"github.com/milvus-io/milvus/internal/util/exprutil"
+	"github.com/milvus-io/milvus/pkg/v2/log"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/commonpbutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+)
+
+const (
+	// maxTermStatsTerms bounds the terms counted by with_term_stats, each of which costs a count query.
+	maxTermStatsTerms = 64
+	// termStatsConcurrency bounds the count queries of with_term_stats running at the same time.
+	termStatsConcurrency = 8
+	// termStatsTemplateKey is the template variable of the term in the count queries.
+	termStatsTemplateKey = "term"
+)
+
+// searchTerm is a term of the term filters of the search.
+type searchTerm struct {
+	fieldID int64
+	value   *planpb.GenericValue
+}
+
+// parseTermStats parses with_term_stats, which reports the terms of the term filters matching no rows, so that
+// clients could prune the ineffective terms. The terms are counted by a count query each after the search.
+func (t *searchTask) parseTermStats(plan *planpb.PlanNode) ([]searchTerm, error) {
+	enabled, err := getBoolSearchParam(t.request.GetSearchParams(), WithTermStatsKey)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	expr, err := exprutil.ParseExprFromPlan(plan)
+	if err != nil {
+		return nil, err
+	}
+	terms := collectSearchTerms(expr)
+	if len(terms) == 0 {
+		return nil, merr.WrapErrParameterInvalidMsg("%s only works with the term filters on scalar fields", WithTermStatsKey)
+	}
+	if len(terms) > maxTermStatsTerms {
+		return nil, merr.WrapErrParameterInvalidMsg("%s supports at most %d terms, got %d", WithTermStatsKey, maxTermStatsTerms, len(terms))
+	}
+	return terms, nil
+}
+
+// collectSearchTerms collects the distinct terms of the term expressions, the ones on the json and array fields are skipped.
+func collectSearchTerms(expr *planpb.Expr) []searchTerm {
+	switch e := expr.GetExpr().(type) {
+	case *planpb.Expr_BinaryExpr:
+		terms := append(collectSearchTerms(e.BinaryExpr.GetLeft()), collectSearchTerms(e.BinaryExpr.GetRight())...)
+		return lo.UniqBy(terms, func(term searchTerm) string {
+			return fmt.Sprintf("%d:%s", term.fieldID, term.value.String())
+		})
+	case *planpb.Expr_UnaryExpr:
+		return collectSearchTerms(e.UnaryExpr.GetChild())
+	case *planpb.Expr_TermExpr:
+		column := e.TermExpr.GetColumnInfo()
+		if column.GetDataType() == schemapb.DataType_JSON || column.GetDataType() == schemapb.DataType_Array {
+			return nil
+		}
+		terms := lo.Map(e.TermExpr.GetValues(), func(value *planpb.GenericValue, _ int) searchTerm {
+			return searchTerm{fieldID: column.GetFieldId(), value: value}
+		})
+		return lo.UniqBy(terms, func(term searchTerm) string {
+			return term.value.String()
+		})
+	}
+	return nil
+}
+
+// genericValueToTemplateValue converts the term into the template value of the count query.
+func genericValueToTemplateValue(value *planpb.GenericValue) (*schemapb.TemplateValue, any, error) {
+	switch v := value.GetVal().(type) {
+	case *planpb.GenericValue_BoolVal:
+		return &schemapb.TemplateValue{Val: &schemapb.TemplateValue_BoolVal{BoolVal: v.BoolVal}}, v.BoolVal, nil
+	case *planpb.GenericValue_Int64Val:
+		return &schemapb.TemplateValue{Val: &schemapb.TemplateValue_Int64Val{Int64Val: v.Int64Val}}, v.Int64Val, nil
+	case *planpb.GenericValue_FloatVal:
+		return &schemapb.TemplateValue{Val: &schemapb.TemplateValue_FloatVal{FloatVal: v.FloatVal}}, v.FloatVal, nil
+	case *planpb.GenericValue_StringVal:
+		return &schemapb.TemplateValue{Val: &schemapb.TemplateValue_StringVal{StringVal: v.StringVal}}, v.StringVal, nil
+	default:
+		return nil, nil, merr.WrapErrParameterInvalidMsg("unsupported term %s for %s", value.String(), WithTermStatsKey)
+	}
+}
+
+// countTerm counts the rows matching the term, in the partitions searched and on the snapshot of the search.
+func (t *searchTask) countTerm(ctx context.Context, term searchTerm) (int64, error) {
+	field, err := t.schema.schemaHelper.GetFieldFromID(term.fieldID)
+	if err != nil {
+		return 0, err
+	}
+	templateValue, _, err := genericValueToTemplateValue(term.value)
+	if err != nil {
+		return 0, err
+	}
+	node := t.node.(*Proxy)
+	qt := &queryTask{
+		ctx:       ctx,
+		Condition: NewTaskCondition(ctx),
+		RetrieveRequest: &internalpb.RetrieveRequest{
+			Base: commonpbutil.NewMsgBase(
+				commonpbutil.WithMsgType(commonpb.MsgType_Retrieve),
+				commonpbutil.WithSourceID(paramtable.GetNodeID()),
+			),
+			ReqID:         paramtable.GetNodeID(),
+			MvccTimestamp: t.SearchRequest.GetMvccTimestamp(),
+		},
+		request: &milvuspb.QueryRequest{
+			Base: &commonpb.MsgBase{
+				MsgType:   commonpb.MsgType_Retrieve,
+				Timestamp: t.BeginTs(),
+			},
+			DbName:             t.request.GetDbName(),
+			CollectionName:     t.collectionName,
+			PartitionNames:     t.request.GetPartitionNames(),
+			Expr:               fmt.Sprintf("%s == {%s}", field.GetName(), termStatsTemplateKey),
+			ExprTemplateValues: map[string]*schemapb.TemplateValue{termStatsTemplateKey: templateValue},
+			OutputFields:       []string{"count(*)"},
+			ConsistencyLevel:   t.SearchRequest.GetConsistencyLevel(),
+			GuaranteeTimestamp: t.SearchRequest.GetGuaranteeTimestamp(),
+		},
+		mixCoord: node.mixCoord,
+		lb:       node.lbPolicy,
+	}
+	result, err := node.query(ctx, qt, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := merr.Error(result.GetStatus()); err != nil {
+		return 0, err
+	}
+	if len(result.GetFieldsData()) == 0 {
+		return 0, nil
+	}
+	counts := result.GetFieldsData()[0].GetScalars().GetLongData().GetData()
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	return counts[0], nil
+}
+
+// fillTermStats reports the terms matching no rows as a JSON object of the field names to the terms.
+// The terms are counted concurrently, the stats are skipped rather than failing the search if any count fails.
+func (t *searchTask) fillTermStats(ctx context.Context) {
+	counts := make([]int64, len(t.searchTerms))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(termStatsConcurrency)
+	for i, term := range t.searchTerms {
+		g.Go(func() error {
+			count, err := t.countTerm(gctx, term)
+			counts[i] = count
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		log.Ctx(ctx).Warn("failed to count the terms of the filter", zap.Error(err))
+		return
+	}
+	deadTerms, err := collectDeadTerms(t.schema, t.searchTerms, counts)
+	if err != nil {
+		log.Ctx(ctx).Warn("failed to collect the dead terms", zap.Error(err))
+		return
+	}
+	bs, err := json.Marshal(deadTerms)
+	if err != nil {
+		log.Ctx(ctx).Warn("failed to marshal the dead terms", zap.Error(err))
+		return
+	}
+	setSearchResultExtraInfo(t.result, searchResultDeadTermsKey, string(bs))
+}
+
+// collectDeadTerms groups the terms counted zero by their field names.
+func collectDeadTerms(schema *schemaInfo, terms []searchTerm, counts []int64) (map[string][]any, error) {
+	deadTerms := make(map[string][]any)
+	for i, term := range terms {
+		if counts[i] > 0 {
+			continue
+		}
+		field, err := schema.schemaHelper.GetFieldFromID(term.fieldID)
+		if err != nil {
+			return nil, err
+		}
+		_, value, err := genericValueToTemplateValue(term.value)
+		if err != nil {
+			return nil, err
+		}
+		deadTerms[field.GetName()] = append(deadTerms[field.GetName()], value)
+	}
+	return deadTerms, nil
+}
@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/parser/planparserv2"
+	"github.com/milvus-io/milvus/pkg/v2/common"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+func TestSearchTask_ParseTermStats(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "vec", DataType: schemapb.DataType_FloatVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "4"}}},
+			{FieldID: 102, Name: "color", DataType: schemapb.DataType_VarChar, TypeParams: []*commonpb.KeyValuePair{{Key: common.MaxLengthKey, Value: "16"}}},
+			{FieldID: 103, Name: "meta", DataType: schemapb.DataType_JSON},
+		},
+	})
+	newPlan := func(expr string) *planpb.PlanNode {
+		plan, err := planparserv2.CreateSearchPlan(schema.schemaHelper, expr, "vec", &planpb.QueryInfo{Topk: 10, MetricType: "L2"}, nil)
+		require.NoError(t, err)
+		return plan
+	}
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{schema: schema, request: &milvuspb.SearchRequest{SearchParams: params}}
+	}
+
+	terms, err := newTask().parseTermStats(newPlan(`pk in [1, 2]`))
+	assert.NoError(t, err)
+	assert.Nil(t, terms)
+
+	// the terms are deduplicated, and the ones on the json fields are skipped
+	terms, err = newTask(WithTermStatsKey, "true").parseTermStats(
+		newPlan(`pk in [1, 2, 2] and (color in ["red", "blue"] or meta["k"] in [1]) and not pk in [1]`))
+	require.NoError(t, err)
+	assert.Len(t, terms, 4)
+
+	_, err = newTask(WithTermStatsKey, "true").parseTermStats(newPlan(`pk > 1`))
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(WithTermStatsKey, "true").parseTermStats(newPlan(""))
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	pks := make([]string, 0, maxTermStatsTerms+1)
+	for i := 0; i <= maxTermStatsTerms; i++ {
+		pks = append(pks, strconv.Itoa(i))
+	}
+	_, err = newTask(WithTermStatsKey, "true").parseTermStats(newPlan(fmt.Sprintf("pk in [%s]", strings.Join(pks, ","))))
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	deadTerms, err := collectDeadTerms(schema, terms, []int64{3, 0, 0, 5})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]any{"pk": {int64(2)}, "color": {"red"}}, deadTerms)
+}
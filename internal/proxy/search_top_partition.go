@@ -0,0 +1,174 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/types"
+	"github.com/milvus-io/milvus/pkg/v2/log"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/querypb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+// parseTopPartitionOnly parses top_partition_only, which searches only the partition most likely to contain
+// the best hits among the partitions matched by the partition keys. The partition is picked by a pre-scan
+// for the top 1 hit of each query, so the hits in the other partitions are missed, which trades recall for latency.
+func (t *searchTask) parseTopPartitionOnly(isIterator bool, queryInfo *planpb.QueryInfo) (bool, error) {
+	enabled, err := getBoolSearchParam(t.request.GetSearchParams(), TopPartitionOnlyKey)
+	if err != nil || !enabled {
+		return false, err
+	}
+	params := t.request.GetSearchParams()
+	switch {
+	case !t.partitionKeyMode:
+		return false, merr.WrapErrParameterInvalidMsg("%s only works for collections with partition key", TopPartitionOnlyKey)
+	case t.scanAllPartitions:
+		return false, merr.WrapErrParameterInvalidMsg("%s could not be used with %s", TopPartitionOnlyKey, ScanAllPartitionsKey)
+	case isIterator:
+		return false, merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator", TopPartitionOnlyKey)
+	case hasGroupBy(queryInfo.GetGroupByFieldId()):
+		return false, merr.WrapErrParameterInvalidMsg("%s is not supported by grouping search", TopPartitionOnlyKey)
+	}
+	for _, key := range []string{PartitionKeyHintsKey, GroupResultsByPartitionKey} {
+		if _, err := funcutil.GetAttrByKeyFromRepeatedKV(key, params); err == nil {
+			return false, merr.WrapErrParameterInvalidMsg("%s could not be used with %s", TopPartitionOnlyKey, key)
+		}
+	}
+	return true, nil
+}
+
+// newTopPartitionScanPlan returns the plan of the pre-scan, which fetches the top 1 hit of each query without any output field.
+func newTopPartitionScanPlan(plan *planpb.PlanNode) ([]byte, error) {
+	scanPlan := proto.Clone(plan).(*planpb.PlanNode)
+	scanPlan.OutputFieldIds = nil
+	scanPlan.DynamicFields = nil
+	if queryInfo := scanPlan.GetVectorAnns().GetQueryInfo(); queryInfo != nil {
+		queryInfo.Topk = 1
+	}
+	return proto.Marshal(scanPlan)
+}
+
+// pruneToTopPartition narrows the partitions searched down to the one picked by the pre-scan,
+// if the partition keys match more than one partition.
+func (t *searchTask) pruneToTopPartition(ctx context.Context) error {
+	partitionIDs := t.SearchRequest.GetPartitionIDs()
+	if len(partitionIDs) <= 1 {
+		return nil
+	}
+
+	var mu sync.Mutex
+	results := make(map[int64][]*internalpb.SearchResults, len(partitionIDs))
+	wg, ctx := errgroup.WithContext(ctx)
+	for _, partitionID := range partitionIDs {
+		searchReq := typeutil.Clone(t.SearchRequest)
+		searchReq.PartitionIDs = []int64{partitionID}
+		searchReq.SerializedExprPlan = t.topPartitionScanPlan
+		searchReq.Topk = 1
+		searchReq.Offset = 0
+		searchReq.OutputFieldsId = nil
+		wg.Go(func() error {
+			return t.lb.Execute(ctx, CollectionWorkLoad{
+				db:             t.request.GetDbName(),
+				collectionID:   t.SearchRequest.CollectionID,
+				collectionName: t.collectionName,
+				nq:             t.GetNq(),
+				exec: func(ctx context.Context, nodeID int64, qn types.QueryNodeClient, channel string) error {
+					req := typeutil.Clone(searchReq)
+					req.GetBase().TargetID = nodeID
+					result, err := t.searchWithRetry(ctx, qn, &querypb.SearchRequest{
+						Req:             req,
+						DmlChannels:     []string{channel},
+						Scope:           querypb.DataScope_All,
+						TotalChannelNum: int32(1),
+					})
+					if err := merr.CheckRPCCall(result, err); err != nil {
+						return err
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					results[partitionID] = append(results[partitionID], result)
+					return nil
+				},
+				pinnedNodes:  t.pinnedNodes,
+				allowedNodes: t.resourceGroupNodes,
+			})
+		})
+	}
+	if err := wg.Wait(); err != nil {
+		return err
+	}
+
+	resultData := make(map[int64][]*schemapb.SearchResultData, len(results))
+	for partitionID, partitionResults := range results {
+		data, err := decodeSearchResults(ctx, partitionResults)
+		if err != nil {
+			return err
+		}
+		resultData[partitionID] = data
+	}
+	topPartition := selectTopPartition(partitionIDs, resultData, t.GetNq())
+	log.Ctx(ctx).Debug("search only the top partition matched by the partition keys",
+		zap.Int64s("partitionIDs", partitionIDs), zap.Int64("topPartition", topPartition))
+	t.SearchRequest.PartitionIDs = []int64{topPartition}
+	return nil
+}
+
+// selectTopPartition returns the partition having the best top 1 hits for the most queries, the earlier partition wins the ties.
+// The scores of the query nodes are always the larger the better.
+func selectTopPartition(partitionIDs []int64, results map[int64][]*schemapb.SearchResultData, nq int64) int64 {
+	bestScores := make(map[int64][]float32, len(partitionIDs))
+	for _, partitionID := range partitionIDs {
+		scores := lo.RepeatBy(int(nq), func(int) float32 { return minFloat32 })
+		for _, data := range results[partitionID] {
+			var offset int64
+			for q, topk := range data.GetTopks() {
+				if topk > 0 && q < len(scores) {
+					scores[q] = max(scores[q], data.GetScores()[offset])
+				}
+				offset += topk
+			}
+		}
+		bestScores[partitionID] = scores
+	}
+
+	wins := make(map[int64]int, len(partitionIDs))
+	for q := 0; q < int(nq); q++ {
+		winner := partitionIDs[0]
+		for _, partitionID := range partitionIDs[1:] {
+			if bestScores[partitionID][q] > bestScores[winner][q] {
+				winner = partitionID
+			}
+		}
+		// no partition has any hit for the query
+		if bestScores[winner][q] > minFloat32 {
+			wins[winner]++
+		}
+	}
+	return lo.MaxBy(partitionIDs, func(a, b int64) bool { return wins[a] > wins[b] })
+}
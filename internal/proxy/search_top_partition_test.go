@@ -0,0 +1,82 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+)
+
+func TestSearchTask_ParseTopPartitionOnly(t *testing.T) {
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			partitionKeyMode: true,
+			request:          &milvuspb.SearchRequest{SearchParams: params},
+		}
+	}
+	queryInfo := &planpb.QueryInfo{Topk: 10, GroupByFieldId: -1}
+
+	enabled, err := newTask().parseTopPartitionOnly(false, queryInfo)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	enabled, err = newTask(TopPartitionOnlyKey, "true").parseTopPartitionOnly(false, queryInfo)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	_, err = newTask(TopPartitionOnlyKey, "true").parseTopPartitionOnly(true, queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(TopPartitionOnlyKey, "true").parseTopPartitionOnly(false, &planpb.QueryInfo{Topk: 10, GroupByFieldId: 101})
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = newTask(TopPartitionOnlyKey, "true", PartitionKeyHintsKey, "[1]").parseTopPartitionOnly(false, queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	task := newTask(TopPartitionOnlyKey, "true")
+	task.partitionKeyMode = false
+	_, err = task.parseTopPartitionOnly(false, queryInfo)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestNewTopPartitionScanPlan(t *testing.T) {
+	plan := &planpb.PlanNode{
+		Node: &planpb.PlanNode_VectorAnns{VectorAnns: &planpb.VectorANNS{
+			QueryInfo: &planpb.QueryInfo{Topk: 100, MetricType: "L2"},
+		}},
+		OutputFieldIds: []int64{100, 101},
+	}
+	bs, err := newTopPartitionScanPlan(plan)
+	require.NoError(t, err)
+	scanPlan := &planpb.PlanNode{}
+	require.NoError(t, proto.Unmarshal(bs, scanPlan))
+	assert.Equal(t, int64(1), scanPlan.GetVectorAnns().GetQueryInfo().GetTopk())
+	assert.Equal(t, "L2", scanPlan.GetVectorAnns().GetQueryInfo().GetMetricType())
+	assert.Empty(t, scanPlan.GetOutputFieldIds())
+	// the plan of the search is untouched
+	assert.Equal(t, int64(100), plan.GetVectorAnns().GetQueryInfo().GetTopk())
+	assert.Equal(t, []int64{100, 101}, plan.GetOutputFieldIds())
+}
+
+func TestSelectTopPartition(t *testing.T) {
+	newData := func(scores []float32, topks ...int64) *schemapb.SearchResultData {
+		return &schemapb.SearchResultData{NumQueries: int64(len(topks)), Topks: topks, Scores: scores}
+	}
+	results := map[int64][]*schemapb.SearchResultData{
+		// two shards of the partition
+		1: {newData([]float32{0.9, 0.2}, 1, 1, 0), newData([]float32{0.5}, 0, 1, 0)},
+		2: {newData([]float32{0.8, 0.7, 0.1}, 1, 1, 1)},
+		3: {newData([]float32{0.95, 0.6, 0.3}, 1, 1, 1)},
+	}
+	// partition 3 has the best hits of query 0 and 2, partition 2 of query 1
+	assert.Equal(t, int64(3), selectTopPartition([]int64{1, 2, 3}, results, 3))
+	// the earlier partition wins the ties
+	assert.Equal(t, int64(1), selectTopPartition([]int64{1, 2}, results, 2))
+	// no partition has any hit
+	assert.Equal(t, int64(4), selectTopPartition([]int64{4, 5}, results, 1))
+}
@@ -0,0 +1,324 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
+)
+
+// parseUnionCollections returns the collections searched by the union search, the collection of the request first,
+// set by union_collections. The hits of all the collections are merged by score, so the searches paging or grouping
+// the hits of each collection are not supported.
+func parseUnionCollections(request *milvuspb.SearchRequest) ([]string, error) {
+	value, err := funcutil.GetAttrByKeyFromRepeatedKV(UnionCollectionsKey, request.GetSearchParams())
+	if err != nil || value == "" {
+		return nil, nil
+	}
+	names := []string{request.GetCollectionName()}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if lo.Contains(names, name) {
+			return nil, merr.WrapErrParameterInvalidMsg("collection %s is duplicated in %s", name, UnionCollectionsKey)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 1 {
+		return nil, nil
+	}
+
+	params := request.GetSearchParams()
+	if isIterator, _ := getBoolSearchParam(params, IteratorField); isIterator {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator", UnionCollectionsKey)
+	}
+	if groupByField, err := funcutil.GetAttrByKeyFromRepeatedKV(GroupByFieldKey, params); err == nil && groupByField != "" {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is not supported by grouping search", UnionCollectionsKey)
+	}
+	if offsetStr, err := funcutil.GetAttrByKeyFromRepeatedKV(OffsetKey, params); err == nil {
+		if offset, err := strconv.ParseInt(offsetStr, 0, 64); err != nil || offset > 0 {
+			return nil, merr.WrapErrParameterInvalidMsg("%s is not supported with offset", UnionCollectionsKey)
+		}
+	}
+	return names, nil
+}
+
+// getUnionAnnsField returns the vector field searched, which is the only vector field if anns_field is not specified.
+func getUnionAnnsField(schema *schemapb.CollectionSchema, annsField string) (*schemapb.FieldSchema, error) {
+	if annsField == "" {
+		fields := typeutil.GetVectorFieldSchemas(schema)
+		if len(fields) != 1 {
+			return nil, merr.WrapErrParameterInvalidMsg("%s is required by %s if collection %s has multiple vector fields",
+				AnnsFieldKey, UnionCollectionsKey, schema.GetName())
+		}
+		return fields[0], nil
+	}
+	field := typeutil.GetFieldByName(schema, annsField)
+	if field == nil || !typeutil.IsVectorType(field.GetDataType()) {
+		return nil, merr.WrapErrFieldNotFound(annsField, fmt.Sprintf("vector field not found in collection %s", schema.GetName()))
+	}
+	return field, nil
+}
+
+// checkUnionCollectionSchemas checks the vector fields searched of the collections are of the same type and dim.
+func checkUnionCollectionSchemas(ctx context.Context, dbName string, collectionNames []string, annsField string) error {
+	var first *schemapb.FieldSchema
+	var firstDim int64
+	for i, name := range collectionNames {
+		schema, err := globalMetaCache.GetCollectionSchema(ctx, dbName, name)
+		if err != nil {
+			return err
+		}
+		field, err := getUnionAnnsField(schema.CollectionSchema, annsField)
+		if err != nil {
+			return err
+		}
+		var dim int64
+		if !typeutil.IsSparseFloatVectorType(field.GetDataType()) {
+			if dim, err = typeutil.GetDim(field); err != nil {
+				return err
+			}
+		}
+		if i == 0 {
+			first, firstDim = field, dim
+			continue
+		}
+		if field.GetDataType() != first.GetDataType() || dim != firstDim {
+			return merr.WrapErrParameterInvalidMsg("the vector field %s of collection %s is %s with dim %d, mismatch with %s with dim %d of collection %s",
+				field.GetName(), name, field.GetDataType().String(), dim,
+				first.GetDataType().String(), firstDim, collectionNames[0])
+		}
+	}
+	return nil
+}
+
+// unionSearch runs the same search against each of the collections, with the schema and plan of each collection,
+// and merges the hits of each query globally by score.
+func (node *Proxy) unionSearch(ctx context.Context, request *milvuspb.SearchRequest, collectionNames []string) (*milvuspb.SearchResults, error) {
+	params := lo.Filter(request.GetSearchParams(), func(kv *commonpb.KeyValuePair, _ int) bool {
+		return kv.GetKey() != UnionCollectionsKey
+	})
+	subRequests := lo.Map(collectionNames, func(name string, _ int) *milvuspb.SearchRequest {
+		subRequest := proto.Clone(request).(*milvuspb.SearchRequest)
+		subRequest.CollectionName = name
+		subRequest.SearchParams = params
+		return subRequest
+	})
+	// checked before the schemas, so that nothing of the collections not granted is exposed
+	if err := node.checkUnionSearchAccess(ctx, subRequests[1:]); err != nil {
+		return &milvuspb.SearchResults{Status: merr.Status(err)}, nil
+	}
+	annsField, _ := funcutil.GetAttrByKeyFromRepeatedKV(AnnsFieldKey, request.GetSearchParams())
+	if err := checkUnionCollectionSchemas(ctx, request.GetDbName(), collectionNames, annsField); err != nil {
+		return &milvuspb.SearchResults{Status: merr.Status(err)}, nil
+	}
+
+	results := make([]*milvuspb.SearchResults, len(collectionNames))
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i, name := range collectionNames {
+		subRequest := subRequests[i]
+		group.Go(func() error {
+			rsp, err := node.Search(groupCtx, subRequest)
+			if err = merr.CheckRPCCall(rsp, err); err != nil {
+				return errors.Wrapf(err, "failed to search collection %s", name)
+			}
+			results[i] = rsp
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return &milvuspb.SearchResults{Status: merr.Status(err)}, nil
+	}
+
+	result, err := mergeUnionSearchResults(results, collectionNames, request.GetFunctionScore() != nil)
+	if err != nil {
+		return &milvuspb.SearchResults{Status: merr.Status(err)}, nil
+	}
+	result.CollectionName = request.GetCollectionName()
+	return result, nil
+}
+
+// checkUnionSearchAccess checks the privilege and the rate limit of the searches of the collections listed by
+// union_collections. The sub searches are issued in process, bypassing the interceptors of the grpc server, which
+// have checked the collection of the request only.
+func (node *Proxy) checkUnionSearchAccess(ctx context.Context, subRequests []*milvuspb.SearchRequest) error {
+	for _, subRequest := range subRequests {
+		if _, err := PrivilegeInterceptor(ctx, subRequest); err != nil {
+			if status.Code(err) == codes.PermissionDenied {
+				return merr.WrapErrPrivilegeNotPermitted("failed to search collection %s, %s", subRequest.GetCollectionName(), status.Convert(err).Message())
+			}
+			return errors.Wrapf(err, "failed to check the privilege of collection %s", subRequest.GetCollectionName())
+		}
+	}
+	if node.simpleLimiter == nil {
+		return nil
+	}
+	for _, subRequest := range subRequests {
+		dbID, collectionIDToPartIDs, rt, n, err := GetRequestInfo(ctx, subRequest)
+		if err != nil {
+			return err
+		}
+		if err := node.simpleLimiter.Check(dbID, collectionIDToPartIDs, rt, n); err != nil {
+			return errors.Wrapf(err, "failed to search collection %s", subRequest.GetCollectionName())
+		}
+	}
+	return nil
+}
+
+// unionSearchIDs appends the primary keys of the collections, which are converted to strings
+// if the primary keys of the collections are of different types.
+type unionSearchIDs struct {
+	ids   *schemapb.IDs
+	toStr bool
+}
+
+func (u *unionSearchIDs) append(pk any) {
+	if u.toStr {
+		if intPK, ok := pk.(int64); ok {
+			pk = strconv.FormatInt(intPK, 10)
+		}
+	}
+	typeutil.AppendPKs(u.ids, pk)
+}
+
+// mergeUnionSearchResults merges the hits of each query of the collections by score and keeps the topk of them,
+// the output fields are aligned by name as the order of them may differ among the collections.
+func mergeUnionSearchResults(results []*milvuspb.SearchResults, collectionNames []string, rerank bool) (*milvuspb.SearchResults, error) {
+	metricType := results[0].GetStatus().GetExtraInfo()[searchResultMetricTypeKey]
+	var nq, topk int64
+	var hasIntPK, hasStrPK bool
+	var sample *schemapb.SearchResultData
+	for i, result := range results {
+		if mt := result.GetStatus().GetExtraInfo()[searchResultMetricTypeKey]; mt != metricType {
+			return nil, merr.WrapErrParameterInvalidMsg("the scores are not comparable, metric type of collection %s is %s, but %s of collection %s",
+				collectionNames[i], mt, metricType, collectionNames[0])
+		}
+		data := result.GetResults()
+		nq = max(nq, data.GetNumQueries())
+		topk = max(topk, data.GetTopK())
+		hasIntPK = hasIntPK || data.GetIds().GetIntId() != nil
+		hasStrPK = hasStrPK || data.GetIds().GetStrId() != nil
+		if sample == nil && len(data.GetFieldsData()) > 0 {
+			sample = data
+		}
+	}
+	// rerank scores are always the larger the better
+	positivelyRelated := rerank || metric.PositivelyRelated(metricType)
+
+	// align the output fields of each collection by the order of the sample
+	fieldsData := make([][]*schemapb.FieldData, len(results))
+	for i, result := range results {
+		data := result.GetResults()
+		if typeutil.GetSizeOfIDs(data.GetIds()) == 0 {
+			continue
+		}
+		fieldsData[i] = make([]*schemapb.FieldData, 0, len(sample.GetFieldsData()))
+		for _, sampleField := range sample.GetFieldsData() {
+			field, ok := lo.Find(data.GetFieldsData(), func(field *schemapb.FieldData) bool {
+				return field.GetFieldName() == sampleField.GetFieldName()
+			})
+			if !ok || field.GetType() != sampleField.GetType() {
+				return nil, merr.WrapErrParameterInvalidMsg("output field %s of collection %s mismatches the one of collection %s",
+					sampleField.GetFieldName(), collectionNames[i], collectionNames[0])
+			}
+			fieldsData[i] = append(fieldsData[i], field)
+		}
+	}
+
+	merged := &schemapb.SearchResultData{
+		NumQueries:       nq,
+		TopK:             topk,
+		Topks:            make([]int64, nq),
+		Ids:              &schemapb.IDs{},
+		FieldsData:       typeutil.PrepareResultFieldData(sample.GetFieldsData(), topk*nq),
+		OutputFields:     results[0].GetResults().GetOutputFields(),
+		PrimaryFieldName: results[0].GetResults().GetPrimaryFieldName(),
+	}
+	ids := &unionSearchIDs{ids: merged.Ids, toStr: hasIntPK && hasStrPK}
+	hitCollections := make([]string, 0)
+
+	type hit struct {
+		result int
+		idx    int64
+	}
+	offsets := make([]int64, len(results))
+	for q := int64(0); q < nq; q++ {
+		hits := make([]hit, 0)
+		for i, result := range results {
+			data := result.GetResults()
+			if q >= int64(len(data.GetTopks())) {
+				continue
+			}
+			for j := offsets[i]; j < offsets[i]+data.GetTopks()[q]; j++ {
+				hits = append(hits, hit{result: i, idx: j})
+			}
+			offsets[i] += data.GetTopks()[q]
+		}
+		slices.SortStableFunc(hits, func(a, b hit) int {
+			scoreA, scoreB := results[a.result].GetResults().GetScores()[a.idx], results[b.result].GetResults().GetScores()[b.idx]
+			if positivelyRelated {
+				return cmp.Compare(scoreB, scoreA)
+			}
+			return cmp.Compare(scoreA, scoreB)
+		})
+		hits = hits[:min(int64(len(hits)), topk)]
+		for _, h := range hits {
+			data := results[h.result].GetResults()
+			ids.append(typeutil.GetPK(data.GetIds(), h.idx))
+			merged.Scores = append(merged.Scores, data.GetScores()[h.idx])
+			if len(merged.FieldsData) > 0 {
+				typeutil.AppendFieldData(merged.FieldsData, fieldsData[h.result], h.idx)
+			}
+			hitCollections = append(hitCollections, collectionNames[h.result])
+		}
+		merged.Topks[q] = int64(len(hits))
+	}
+
+	ret := &milvuspb.SearchResults{
+		Status:  merr.Success(),
+		Results: merged,
+	}
+	bs, err := json.Marshal(hitCollections)
+	if err != nil {
+		return nil, err
+	}
+	setSearchResultExtraInfo(ret, searchResultMetricTypeKey, metricType)
+	setSearchResultExtraInfo(ret, searchResultHitCollectionsKey, string(bs))
+	return ret, nil
+}
@@ -0,0 +1,166 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+)
+
+func TestParseUnionCollections(t *testing.T) {
+	newRequest := func(kvs ...string) *milvuspb.SearchRequest {
+		params := newSearchParams(kvs...)
+		return &milvuspb.SearchRequest{CollectionName: "c1", SearchParams: params}
+	}
+
+	names, err := parseUnionCollections(newRequest())
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	names, err = parseUnionCollections(newRequest(UnionCollectionsKey, " c2, c3,"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c1", "c2", "c3"}, names)
+
+	for _, kvs := range [][]string{
+		{UnionCollectionsKey, "c2,c1"},
+		{UnionCollectionsKey, "c2", IteratorField, "true"},
+		{UnionCollectionsKey, "c2", GroupByFieldKey, "category"},
+		{UnionCollectionsKey, "c2", OffsetKey, "10"},
+	} {
+		_, err = parseUnionCollections(newRequest(kvs...))
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid, kvs)
+	}
+}
+
+func TestGetUnionAnnsField(t *testing.T) {
+	schema := &schemapb.CollectionSchema{
+		Name: "c1",
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "dense", DataType: schemapb.DataType_FloatVector},
+			{FieldID: 102, Name: "sparse", DataType: schemapb.DataType_SparseFloatVector},
+		},
+	}
+	field, err := getUnionAnnsField(schema, "sparse")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(102), field.GetFieldID())
+
+	_, err = getUnionAnnsField(schema, "")
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = getUnionAnnsField(schema, "pk")
+	assert.ErrorIs(t, err, merr.ErrFieldNotFound)
+}
+
+func TestMergeUnionSearchResults(t *testing.T) {
+	newResult := func(metricType string, ids *schemapb.IDs, scores []float32, topks []int64, fields ...*schemapb.FieldData) *milvuspb.SearchResults {
+		result := &milvuspb.SearchResults{
+			Status: merr.Success(),
+			Results: &schemapb.SearchResultData{
+				NumQueries: int64(len(topks)),
+				TopK:       2,
+				Topks:      topks,
+				Scores:     scores,
+				Ids:        ids,
+				FieldsData: fields,
+			},
+		}
+		setSearchResultExtraInfo(result, searchResultMetricTypeKey, metricType)
+		return result
+	}
+	newInt64Field := func(name string, data ...int64) *schemapb.FieldData {
+		return &schemapb.FieldData{
+			Type:      schemapb.DataType_Int64,
+			FieldName: name,
+			Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+				Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: data}},
+			}},
+		}
+	}
+	newFloatField := func(name string, data ...float32) *schemapb.FieldData {
+		return &schemapb.FieldData{
+			Type:      schemapb.DataType_Float,
+			FieldName: name,
+			Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+				Data: &schemapb.ScalarField_FloatData{FloatData: &schemapb.FloatArray{Data: data}},
+			}},
+		}
+	}
+	intIDs := &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 4}}}}
+	strIDs := &schemapb.IDs{IdField: &schemapb.IDs_StrId{StrId: &schemapb.StringArray{Data: []string{"a", "b", "c"}}}}
+
+	results := []*milvuspb.SearchResults{
+		newResult(metric.IP, intIDs, []float32{0.9, 0.5, 0.8, 0.7}, []int64{2, 2},
+			newInt64Field("age", 10, 20, 30, 40), newFloatField("rating", 1, 2, 3, 4)),
+		// the output fields are in a different order
+		newResult(metric.IP, strIDs, []float32{0.6, 0.95, 0.85}, []int64{1, 2},
+			newFloatField("rating", 5, 6, 7), newInt64Field("age", 50, 60, 70)),
+	}
+	ret, err := mergeUnionSearchResults(results, []string{"c1", "c2"}, false)
+	require.NoError(t, err)
+	data := ret.GetResults()
+	assert.Equal(t, []int64{2, 2}, data.GetTopks())
+	// the primary keys of different types are converted to strings
+	assert.Equal(t, []string{"1", "a", "b", "c"}, data.GetIds().GetStrId().GetData())
+	assert.Equal(t, []float32{0.9, 0.6, 0.95, 0.85}, data.GetScores())
+	assert.Equal(t, "age", data.GetFieldsData()[0].GetFieldName())
+	assert.Equal(t, []int64{10, 50, 60, 70}, data.GetFieldsData()[0].GetScalars().GetLongData().GetData())
+	assert.Equal(t, []float32{1, 5, 6, 7}, data.GetFieldsData()[1].GetScalars().GetFloatData().GetData())
+	assert.Equal(t, `["c1","c2","c2","c2"]`, ret.GetStatus().GetExtraInfo()[searchResultHitCollectionsKey])
+	assert.Equal(t, metric.IP, ret.GetStatus().GetExtraInfo()[searchResultMetricTypeKey])
+
+	// the smaller the better for distances
+	results = []*milvuspb.SearchResults{
+		newResult(metric.L2, intIDs, []float32{0.1, 0.5, 0.2, 0.3}, []int64{2, 2}),
+		newResult(metric.L2, &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{5, 6, 7}}}},
+			[]float32{0.3, 0.1, 0.25}, []int64{1, 2}),
+	}
+	ret, err = mergeUnionSearchResults(results, []string{"c1", "c2"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 5, 6, 3}, ret.GetResults().GetIds().GetIntId().GetData())
+
+	results[1].GetStatus().GetExtraInfo()[searchResultMetricTypeKey] = metric.IP
+	_, err = mergeUnionSearchResults(results, []string{"c1", "c2"}, false)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestProxy_UnionSearchAccess(t *testing.T) {
+	paramtable.Init()
+	paramtable.Get().Save(Params.CommonCfg.AuthorizationEnabled.Key, "true")
+	defer paramtable.Get().Reset(Params.CommonCfg.AuthorizationEnabled.Key)
+
+	client := &MockMixCoordClientInterface{}
+	client.listPolicy = func(ctx context.Context, in *internalpb.ListPolicyRequest) (*internalpb.ListPolicyResponse, error) {
+		return &internalpb.ListPolicyResponse{
+			Status: merr.Success(),
+			PolicyInfos: []string{
+				funcutil.PolicyForPrivilege("union_role", commonpb.ObjectType_Collection.String(), "union_c1", commonpb.ObjectPrivilege_PrivilegeSearch.String(), "default"),
+			},
+			UserRoles: []string{funcutil.EncodeUserRoleCache("union_user", "union_role")},
+		}, nil
+	}
+	require.NoError(t, InitMetaCache(context.Background(), client, newShardClientMgr()))
+	defer func() { globalMetaCache = nil }()
+	defer CleanPrivilegeCache()
+
+	// the collection of the request is checked by the interceptor, the ones of union_collections are not granted
+	node := &Proxy{}
+	ctx := GetContext(context.Background(), "union_user:123456")
+	request := &milvuspb.SearchRequest{
+		CollectionName: "union_c1",
+		SearchParams:   []*commonpb.KeyValuePair{{Key: UnionCollectionsKey, Value: "union_c2"}},
+	}
+	rsp, err := node.unionSearch(ctx, request, []string{"union_c1", "union_c2"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, merr.Error(rsp.GetStatus()), merr.ErrPrivilegeNotPermitted)
+	assert.Contains(t, rsp.GetStatus().GetReason(), "union_c2")
+}
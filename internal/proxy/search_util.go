@@ -2,23 +2,32 @@ package proxy
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"math"
+	"math/rand"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/cockroachdb/errors"
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/json"
 	"github.com/milvus-io/milvus/pkg/v2/common"
 	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
 	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
 	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/metric"
 	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
 )
 
@@ -98,7 +107,7 @@ func parseSearchIteratorV2Info(searchParamsPair []*commonpb.KeyValuePair, groupB
 
 	// disable groupBy when doing iteratorV2
 	// same behavior with V1
-	if isIteratorV2 && groupByFieldId > 0 {
+	if isIteratorV2 && hasGroupBy(groupByFieldId) {
 		return nil, merr.WrapErrParameterInvalid("", "",
 			"GroupBy is not permitted when using a search iterator")
 	}
@@ -159,6 +168,121 @@ func parseSearchIteratorV2Info(searchParamsPair []*commonpb.KeyValuePair, groupB
 	return planIteratorV2Info, nil
 }
 
+// maxSearchCursorTiedPKs is the max number of the returned hits with the same score as the search cursor, they are
+// excluded by the pks from the next page, so the filter is bounded.
+const maxSearchCursorTiedPKs = 1024
+
+// searchCursor is the position of the last hit returned by a search, the next page starts right after it.
+// Hits are paged by score, the hits with the same score as the cursor are paged by excluding the pks returned already.
+// The cursor is not signed, it only narrows the search the client could issue by itself, it's validated in structure.
+type searchCursor struct {
+	CollectionID int64    `json:"collection_id"`
+	MetricType   string   `json:"metric_type"`
+	Score        float32  `json:"score"`
+	IntPKs       []int64  `json:"int_pks,omitempty"`
+	StrPKs       []string `json:"str_pks,omitempty"`
+}
+
+// encodeSearchCursor encodes the cursor into an opaque token returned to clients.
+func encodeSearchCursor(cursor *searchCursor) (string, error) {
+	bs, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bs), nil
+}
+
+// decodeSearchCursor decodes the cursor token and validates it is well-formed and generated by the searches on the collection.
+func decodeSearchCursor(token string, collectionID int64) (*searchCursor, error) {
+	bs, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is malformed", SearchCursorKey)
+	}
+	cursor := &searchCursor{}
+	if err := json.Unmarshal(bs, cursor); err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is malformed", SearchCursorKey)
+	}
+	if cursor.CollectionID != collectionID {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is not generated by the searches on collection %d", SearchCursorKey, collectionID)
+	}
+	numPKs := len(cursor.IntPKs) + len(cursor.StrPKs)
+	if cursor.MetricType == "" || numPKs == 0 || len(cursor.IntPKs) > 0 && len(cursor.StrPKs) > 0 {
+		return nil, merr.WrapErrParameterInvalidMsg("%s is malformed", SearchCursorKey)
+	}
+	if numPKs > maxSearchCursorTiedPKs {
+		return nil, merr.WrapErrParameterInvalidMsg("%s holds %d pks, more than the limit %d", SearchCursorKey, numPKs, maxSearchCursorTiedPKs)
+	}
+	return cursor, nil
+}
+
+// hasRangeSearchParams returns whether the search params of the index, in JSON, specify a range search.
+func hasRangeSearchParams(paramsStr string) (bool, error) {
+	if paramsStr == "" {
+		return false, nil
+	}
+	params := make(map[string]any)
+	if err := json.Unmarshal([]byte(paramsStr), &params); err != nil {
+		return false, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a JSON object", ParamsKey, paramsStr)
+	}
+	_, hasRadius := params[radiusKey]
+	_, hasRangeFilter := params[rangeFilterKey]
+	return hasRadius || hasRangeFilter, nil
+}
+
+// rangeSearchParams returns the range search params fetching the hits not better than the cursor,
+// the bound is inclusive so that the hits with the same score are fetched as well.
+func (c *searchCursor) rangeSearchParams() map[string]any {
+	if metric.PositivelyRelated(c.MetricType) {
+		// radius < score <= range_filter
+		return map[string]any{radiusKey: -math.MaxFloat32, rangeFilterKey: float64(c.Score)}
+	}
+	// range_filter <= distance < radius
+	return map[string]any{radiusKey: math.MaxFloat32, rangeFilterKey: float64(c.Score)}
+}
+
+// exclusionExpr returns the filter excluding the hits with the same score as the cursor returned already.
+func (c *searchCursor) exclusionExpr(pkFieldName string) string {
+	var values []string
+	if len(c.IntPKs) > 0 {
+		values = lo.Map(c.IntPKs, func(pk int64, _ int) string { return strconv.FormatInt(pk, 10) })
+	} else {
+		values = lo.Map(c.StrPKs, func(pk string, _ int) string { return strconv.Quote(pk) })
+	}
+	return fmt.Sprintf("%s not in [%s]", pkFieldName, strings.Join(values, ", "))
+}
+
+// nextSearchCursor returns the cursor after the last hit of the single query search, nil if there is no hit.
+// The pks of the previous cursor are kept if the hits with its score are not exhausted yet, the paging fails if
+// there are more than maxSearchCursorTiedPKs hits with the same score.
+func nextSearchCursor(data *schemapb.SearchResultData, prev *searchCursor, collectionID int64, metricType string) (*searchCursor, error) {
+	numHits := len(data.GetScores())
+	if numHits == 0 {
+		return nil, nil
+	}
+	cursor := &searchCursor{
+		CollectionID: collectionID,
+		MetricType:   metricType,
+		Score:        data.GetScores()[numHits-1],
+	}
+	if prev != nil && prev.Score == cursor.Score {
+		cursor.IntPKs = append(cursor.IntPKs, prev.IntPKs...)
+		cursor.StrPKs = append(cursor.StrPKs, prev.StrPKs...)
+	}
+	for i := numHits - 1; i >= 0 && data.GetScores()[i] == cursor.Score; i-- {
+		switch pk := typeutil.GetPK(data.GetIds(), int64(i)).(type) {
+		case int64:
+			cursor.IntPKs = append(cursor.IntPKs, pk)
+		case string:
+			cursor.StrPKs = append(cursor.StrPKs, pk)
+		}
+	}
+	if numPKs := len(cursor.IntPKs) + len(cursor.StrPKs); numPKs > maxSearchCursorTiedPKs {
+		return nil, merr.WrapErrParameterInvalidMsg("%d hits have the same score %v, more than %d could be paged by %s, "+
+			"please narrow the filter or use the search iterator", numPKs, cursor.Score, maxSearchCursorTiedPKs, SearchCursorKey)
+	}
+	return cursor, nil
+}
+
 // parseSearchInfo returns QueryInfo and offset
 func parseSearchInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb.CollectionSchema, rankParams *rankParams) (*SearchInfo, error) {
 	var topK int64
@@ -167,14 +291,14 @@ func parseSearchInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb
 	topKStr, err := funcutil.GetAttrByKeyFromRepeatedKV(TopKKey, searchParamsPair)
 	if err != nil {
 		if externalLimit <= 0 {
-			return nil, fmt.Errorf("%s is required", TopKKey)
+			return nil, merr.WithReasonCode(fmt.Errorf("%s is required", TopKKey), merr.ReasonTopKInvalid)
 		}
 		topK = externalLimit
 	} else {
 		topKInParam, err := strconv.ParseInt(topKStr, 0, 64)
 		if err != nil {
 			if externalLimit <= 0 {
-				return nil, fmt.Errorf("%s [%s] is invalid", TopKKey, topKStr)
+				return nil, merr.WithReasonCode(fmt.Errorf("%s [%s] is invalid", TopKKey, topKStr), merr.ReasonTopKInvalid)
 			}
 			topK = externalLimit
 		} else {
@@ -194,7 +318,7 @@ func parseSearchInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb
 			// 2. GetAsInt64 has cached inside, no need to worry about cpu cost for parsing here
 			topK = Params.QuotaConfig.TopKLimit.GetAsInt64()
 		} else {
-			return nil, fmt.Errorf("%s [%d] is invalid, %w", TopKKey, topK, err)
+			return nil, merr.WithReasonCode(fmt.Errorf("%s [%d] is invalid, %w", TopKKey, topK, err), merr.ReasonTopKInvalid)
 		}
 	}
 
@@ -205,12 +329,12 @@ func parseSearchInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb
 		if err == nil {
 			offset, err = strconv.ParseInt(offsetStr, 0, 64)
 			if err != nil {
-				return nil, fmt.Errorf("%s [%s] is invalid", OffsetKey, offsetStr)
+				return nil, merr.WithReasonCode(fmt.Errorf("%s [%s] is invalid", OffsetKey, offsetStr), merr.ReasonOffsetInvalid)
 			}
 
 			if offset != 0 {
 				if err := validateLimit(offset); err != nil {
-					return nil, fmt.Errorf("%s [%d] is invalid, %w", OffsetKey, offset, err)
+					return nil, merr.WithReasonCode(fmt.Errorf("%s [%d] is invalid, %w", OffsetKey, offset, err), merr.ReasonOffsetInvalid)
 				}
 			}
 		}
@@ -218,7 +342,7 @@ func parseSearchInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb
 
 	queryTopK := topK + offset
 	if err := validateLimit(queryTopK); err != nil {
-		return nil, fmt.Errorf("%s+%s [%d] is invalid, %w", OffsetKey, TopKKey, queryTopK, err)
+		return nil, merr.WithReasonCode(fmt.Errorf("%s+%s [%d] is invalid, %w", OffsetKey, TopKKey, queryTopK, err), merr.ReasonResultWindowExceeded)
 	}
 
 	// 2. parse metrics type
@@ -240,11 +364,13 @@ func parseSearchInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb
 
 	roundDecimal, err := strconv.ParseInt(roundDecimalStr, 0, 64)
 	if err != nil {
-		return nil, fmt.Errorf("%s [%s] is invalid, should be -1 or an integer in range [0, 6]", RoundDecimalKey, roundDecimalStr)
+		return nil, merr.WithReasonCode(fmt.Errorf("%s [%s] is invalid, should be -1 or an integer in range [0, 6]", RoundDecimalKey, roundDecimalStr),
+			merr.ReasonRoundDecimalInvalid)
 	}
 
 	if roundDecimal != -1 && (roundDecimal > 6 || roundDecimal < 0) {
-		return nil, fmt.Errorf("%s [%s] is invalid, should be -1 or an integer in range [0, 6]", RoundDecimalKey, roundDecimalStr)
+		return nil, merr.WithReasonCode(fmt.Errorf("%s [%s] is invalid, should be -1 or an integer in range [0, 6]", RoundDecimalKey, roundDecimalStr),
+			merr.ReasonRoundDecimalInvalid)
 	}
 
 	// 4. parse search param str
@@ -252,6 +378,12 @@ func parseSearchInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb
 	if err != nil {
 		searchParamStr = ""
 	}
+	if hints, searchParamStr, err = applySearchHints(hints, searchParamStr); err != nil {
+		return nil, err
+	}
+	if searchParamStr, err = applySearchSeed(searchParamsPair, searchParamStr); err != nil {
+		return nil, err
+	}
 
 	// 5. parse group by field and group by size
 	var groupByFieldId, groupSize int64
@@ -267,11 +399,11 @@ func parseSearchInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb
 	}
 
 	// 6. parse iterator tag, prevent trying to groupBy when doing iteration or doing range-search
-	if isIterator && groupByFieldId > 0 {
+	if isIterator && hasGroupBy(groupByFieldId) {
 		return nil, merr.WrapErrParameterInvalid("", "",
 			"Not allowed to do groupBy when doing iteration")
 	}
-	if strings.Contains(searchParamStr, radiusKey) && groupByFieldId > 0 {
+	if strings.Contains(searchParamStr, radiusKey) && hasGroupBy(groupByFieldId) {
 		return nil, merr.WrapErrParameterInvalid("", "",
 			"Not allowed to do range-search when doing search-group-by")
 	}
@@ -300,17 +432,198 @@ func parseSearchInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb
 }
 
 func getOutputFieldIDs(schema *schemaInfo, outputFields []string) (outputFieldIDs []UniqueID, err error) {
+	// the fields are fetched by requery and carried in the results, which bloats both of them for wide schemas.
+	if maxOutputFields := Params.ProxyCfg.MaxOutputFields.GetAsInt(); maxOutputFields > 0 && len(outputFields) > maxOutputFields {
+		return nil, merr.WrapErrParameterInvalidMsg("the number of output fields %d exceeds the maximum %d", len(outputFields), maxOutputFields)
+	}
 	outputFieldIDs = make([]UniqueID, 0, len(outputFields))
 	for _, name := range outputFields {
 		id, ok := schema.MapFieldID(name)
 		if !ok {
-			return nil, fmt.Errorf("Field %s not exist", name)
+			candidates := lo.Map(schema.GetFields(), func(field *schemapb.FieldSchema, _ int) string {
+				return field.GetName()
+			})
+			return nil, wrapErrOutputFieldNotExist(name, candidates)
 		}
 		outputFieldIDs = append(outputFieldIDs, id)
 	}
 	return outputFieldIDs, nil
 }
 
+// getBoolSearchParam parses the bool value of the given key in search params, false is returned if the key is absent.
+func getBoolSearchParam(params []*commonpb.KeyValuePair, key string) (bool, error) {
+	valueStr, err := funcutil.GetAttrByKeyFromRepeatedKV(key, params)
+	if err != nil {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return false, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be true or false", key, valueStr)
+	}
+	return value, nil
+}
+
+// sumInt64 returns the sum of the values, e.g. the total number of hits of the topks.
+func sumInt64(values []int64) int64 {
+	var sum int64
+	for _, value := range values {
+		sum += value
+	}
+	return sum
+}
+
+// parseMaxFieldBytes parses max_field_bytes from the search params, proxy.defaultMaxFieldBytes is used if it is not
+// specified, 0 is returned if neither is set.
+func parseMaxFieldBytes(params []*commonpb.KeyValuePair) (int, error) {
+	valueStr, err := funcutil.GetAttrByKeyFromRepeatedKV(MaxFieldBytesKey, params)
+	if err != nil {
+		return max(Params.ProxyCfg.DefaultMaxFieldBytes.GetAsInt(), 0), nil
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value <= 0 {
+		return 0, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a positive integer", MaxFieldBytesKey, valueStr)
+	}
+	return value, nil
+}
+
+// searchResultsHaveMore returns whether any query has more candidates than the topk, i.e. the candidates of some shard
+// are truncated at the topk, or the shards return more candidates than the topk in total. A shard returning exactly
+// topk candidates is regarded as truncated, so it may be a false positive, but never a false negative.
+func searchResultsHaveMore(ctx context.Context, toReduceResults []*internalpb.SearchResults, topk int64) (bool, error) {
+	resultData, err := decodeSearchResults(ctx, toReduceResults)
+	if err != nil {
+		return false, err
+	}
+	if len(resultData) == 0 {
+		return false, nil
+	}
+	for q := int64(0); q < resultData[0].GetNumQueries(); q++ {
+		var total int64
+		for _, data := range resultData {
+			if q >= int64(len(data.GetTopks())) {
+				continue
+			}
+			if data.GetTopks()[q] >= topk {
+				return true, nil
+			}
+			total += data.GetTopks()[q]
+		}
+		if total > topk {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseVectorPrecision parses vector_precision from the search params, which is fp16 or bf16.
+// The type of the half precision vectors is returned, DataType_None if it is not specified.
+func parseVectorPrecision(params []*commonpb.KeyValuePair) (schemapb.DataType, error) {
+	valueStr, err := funcutil.GetAttrByKeyFromRepeatedKV(VectorPrecisionKey, params)
+	if err != nil {
+		return schemapb.DataType_None, nil
+	}
+	switch strings.ToLower(valueStr) {
+	case "fp16":
+		return schemapb.DataType_Float16Vector, nil
+	case "bf16":
+		return schemapb.DataType_BFloat16Vector, nil
+	default:
+		return schemapb.DataType_None, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be fp16 or bf16", VectorPrecisionKey, valueStr)
+	}
+}
+
+// downcastFloatVectors converts the float vectors to the half precision vectors of dataType, which halves the size of them.
+// The precision is lost, fp16 keeps about 3 significant decimal digits within the range of ±65504,
+// while bf16 keeps the range of float32 with about 2 significant decimal digits.
+// The vectors of the other types are left as is.
+func downcastFloatVectors(fieldsData []*schemapb.FieldData, dataType schemapb.DataType) {
+	for _, fieldData := range fieldsData {
+		if fieldData.GetType() != schemapb.DataType_FloatVector || fieldData.GetVectors() == nil {
+			continue
+		}
+		vectors := fieldData.GetVectors()
+		data := vectors.GetFloatVector().GetData()
+		switch dataType {
+		case schemapb.DataType_Float16Vector:
+			vectors.Data = &schemapb.VectorField_Float16Vector{Float16Vector: typeutil.Float32ArrayToFloat16Bytes(data)}
+		case schemapb.DataType_BFloat16Vector:
+			vectors.Data = &schemapb.VectorField_Bfloat16Vector{Bfloat16Vector: typeutil.Float32ArrayToBFloat16Bytes(data)}
+		default:
+			continue
+		}
+		fieldData.Type = dataType
+	}
+}
+
+// checkPartitionKeyFanout rejects the search if the partition key expression resolves to too many partitions.
+func checkPartitionKeyFanout(numPartitions int) error {
+	maxFanout := Params.ProxyCfg.MaxPartitionKeyFanout.GetAsInt()
+	if maxFanout <= 0 || numPartitions <= maxFanout {
+		return nil
+	}
+	return merr.WrapErrParameterInvalidMsg("partition key expression hits %d partitions, exceeds the limit %d, please narrow down the partition keys in the filter",
+		numPartitions, maxFanout)
+}
+
+// genPlaceholderVectorFieldData generates vector field data without any vector.
+// Note that it only keeps the metadata of the field, clients shall not take it as a real empty vector.
+func genPlaceholderVectorFieldData(field *schemapb.FieldSchema) *schemapb.FieldData {
+	dim, _ := typeutil.GetDim(field) // sparse float vector has no dim
+	return &schemapb.FieldData{
+		Type:      field.GetDataType(),
+		FieldName: field.GetName(),
+		FieldId:   field.GetFieldID(),
+		IsDynamic: field.GetIsDynamic(),
+		Field: &schemapb.FieldData_Vectors{
+			Vectors: &schemapb.VectorField{
+				Dim: dim,
+			},
+		},
+	}
+}
+
+// checkPlaceholderGroupDim checks the dense vectors of the placeholder group are compatible with the vector field,
+// the other placeholders, e.g. sparse vectors or texts, are left to be checked by the query nodes.
+func checkPlaceholderGroupDim(field *schemapb.FieldSchema, placeholderGroupBytes []byte) error {
+	placeholderGroup := &commonpb.PlaceholderGroup{}
+	if err := proto.Unmarshal(placeholderGroupBytes, placeholderGroup); err != nil {
+		return merr.WrapErrParameterInvalidMsg("failed to unmarshal placeholder group: %s", err.Error())
+	}
+	for _, placeholder := range placeholderGroup.GetPlaceholders() {
+		var dataType schemapb.DataType
+		var bytesPerDim float64
+		switch placeholder.GetType() {
+		case commonpb.PlaceholderType_FloatVector:
+			dataType, bytesPerDim = schemapb.DataType_FloatVector, 4
+		case commonpb.PlaceholderType_Float16Vector:
+			dataType, bytesPerDim = schemapb.DataType_Float16Vector, 2
+		case commonpb.PlaceholderType_BFloat16Vector:
+			dataType, bytesPerDim = schemapb.DataType_BFloat16Vector, 2
+		case commonpb.PlaceholderType_BinaryVector:
+			dataType, bytesPerDim = schemapb.DataType_BinaryVector, 1.0/8
+		case commonpb.PlaceholderType_Int8Vector:
+			dataType, bytesPerDim = schemapb.DataType_Int8Vector, 1
+		default:
+			continue
+		}
+		if field.GetDataType() != dataType {
+			return merr.WrapErrParameterInvalidMsg("the query vectors of type %s could not be used to search field %s of type %s",
+				placeholder.GetType().String(), field.GetName(), field.GetDataType().String())
+		}
+		dim, err := typeutil.GetDim(field)
+		if err != nil {
+			return err
+		}
+		for _, value := range placeholder.GetValues() {
+			if float64(len(value)) != float64(dim)*bytesPerDim {
+				return merr.WrapErrParameterInvalidMsg("the dim of the query vectors %v does not match the dim %d of field %s",
+					float64(len(value))/bytesPerDim, dim, field.GetName())
+			}
+		}
+	}
+	return nil
+}
+
 func getNqFromSubSearch(req *milvuspb.SubSearchRequest) (int64, error) {
 	if req.GetNq() == 0 {
 		// keep compatible with older client version.
@@ -345,7 +658,34 @@ func getNq(req *milvuspb.SearchRequest) (int64, error) {
 	return req.GetNq(), nil
 }
 
+const (
+	partitionMatchExact  = "exact"
+	partitionMatchRegexp = "regex"
+)
+
+// parsePartitionNameRegexp returns whether the partition names shall be used as regexp,
+// the proxy config is respected if partition_match is not set in params.
+func parsePartitionNameRegexp(params []*commonpb.KeyValuePair) (bool, error) {
+	match, err := funcutil.GetAttrByKeyFromRepeatedKV(PartitionMatchKey, params)
+	if err != nil {
+		return Params.ProxyCfg.PartitionNameRegexp.GetAsBool(), nil
+	}
+	switch strings.ToLower(match) {
+	case partitionMatchExact:
+		return false, nil
+	case partitionMatchRegexp:
+		return true, nil
+	default:
+		return false, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be %s or %s",
+			PartitionMatchKey, match, partitionMatchExact, partitionMatchRegexp)
+	}
+}
+
 func getPartitionIDs(ctx context.Context, dbName string, collectionName string, partitionNames []string) (partitionIDs []UniqueID, err error) {
+	return getPartitionIDsWithMatch(ctx, dbName, collectionName, partitionNames, Params.ProxyCfg.PartitionNameRegexp.GetAsBool())
+}
+
+func getPartitionIDsWithMatch(ctx context.Context, dbName string, collectionName string, partitionNames []string, useRegexp bool) (partitionIDs []UniqueID, err error) {
 	for _, tag := range partitionNames {
 		if err := validatePartitionTag(tag, false); err != nil {
 			return nil, err
@@ -357,8 +697,6 @@ func getPartitionIDs(ctx context.Context, dbName string, collectionName string,
 		return nil, err
 	}
 
-	useRegexp := Params.ProxyCfg.PartitionNameRegexp.GetAsBool()
-
 	partitionsSet := typeutil.NewUniqueSet()
 	for _, partitionName := range partitionNames {
 		if useRegexp {
@@ -376,7 +714,8 @@ func getPartitionIDs(ctx context.Context, dbName string, collectionName string,
 				}
 			}
 			if !found {
-				return nil, fmt.Errorf("partition name %s not found", partitionName)
+				// the pattern matching nothing is most likely a typo, never search an empty set of partitions silently
+				return nil, fmt.Errorf("partition name %s matched no partitions", partitionName)
 			}
 		} else {
 			partitionID, found := partitionsMap[partitionName]
@@ -400,7 +739,7 @@ func (g *groupByInfo) GetGroupByFieldId() int64 {
 	if g != nil {
 		return g.groupByFieldId
 	}
-	return 0
+	return -1
 }
 
 func (g *groupByInfo) GetGroupSize() int64 {
@@ -417,6 +756,27 @@ func (g *groupByInfo) GetStrictGroupSize() bool {
 	return false
 }
 
+// groupByRerank is the rerank of a search checked against the grouping search.
+type groupByRerank interface {
+	IsSupportGroup() bool
+	RerankName() string
+}
+
+// hasGroupBy tells whether the search is grouped. The group by field id is -1 if the search is not grouped,
+// any non-negative id is a real field, same for both the search and hybrid search.
+func hasGroupBy(groupByFieldID int64) bool {
+	return groupByFieldID >= 0
+}
+
+// checkRerankGroupBySupport rejects the grouping search with the rerank not supporting it.
+func checkRerankGroupBySupport(rerank groupByRerank, groupByFieldID int64) error {
+	if !hasGroupBy(groupByFieldID) || rerank.IsSupportGroup() {
+		return nil
+	}
+	return merr.WithReasonCode(merr.WrapErrParameterInvalidMsg("Rerank %s does not support grouping search", rerank.RerankName()),
+		merr.ReasonRerankGroupUnsupported)
+}
+
 func parseGroupByInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemapb.CollectionSchema) (*groupByInfo, error) {
 	ret := &groupByInfo{}
 
@@ -431,12 +791,20 @@ func parseGroupByInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemap
 		for _, field := range fields {
 			if field.Name == groupByFieldName {
 				groupByFieldId = field.FieldID
+				// the vectors, dense or sparse, make no groups, every hit would be a group of its own.
+				if typeutil.IsVectorType(field.GetDataType()) {
+					return nil, merr.WrapErrParameterInvalidMsg("groupBy field %s is a vector field, grouping by vector fields is not supported", groupByFieldName)
+				}
 				break
 			}
 		}
 		if groupByFieldId == -1 {
 			return nil, merr.WrapErrFieldNotFound(groupByFieldName, "groupBy field not found in schema")
 		}
+		// searches are routed by the partition key, grouping by it only produces degenerate groups.
+		if partitionKeyField, err := typeutil.GetPartitionKeyFieldSchema(schema); err == nil && partitionKeyField.GetFieldID() == groupByFieldId {
+			return nil, merr.WrapErrParameterInvalidMsg("groupBy field %s is the partition key field, grouping by partition key is not supported", groupByFieldName)
+		}
 	}
 	ret.groupByFieldId = groupByFieldId
 
@@ -444,21 +812,22 @@ func parseGroupByInfo(searchParamsPair []*commonpb.KeyValuePair, schema *schemap
 	var groupSize int64
 	groupSizeStr, err := funcutil.GetAttrByKeyFromRepeatedKV(GroupSizeKey, searchParamsPair)
 	if err != nil {
+		// only the best hit of each group is returned by default, the topk counts the groups.
 		groupSize = 1
 	} else {
 		groupSize, err = strconv.ParseInt(groupSizeStr, 0, 64)
 		if err != nil {
-			return nil, merr.WrapErrParameterInvalidMsg(
-				fmt.Sprintf("failed to parse input group size:%s", groupSizeStr))
+			return nil, merr.WithReasonCode(merr.WrapErrParameterInvalidMsg(
+				fmt.Sprintf("failed to parse input group size:%s", groupSizeStr)), merr.ReasonGroupSizeInvalid)
 		}
 		if groupSize <= 0 {
-			return nil, merr.WrapErrParameterInvalidMsg(
-				fmt.Sprintf("input group size:%d is negative, failed to do search_groupby", groupSize))
+			return nil, merr.WithReasonCode(merr.WrapErrParameterInvalidMsg(
+				fmt.Sprintf("input group size:%d is negative, failed to do search_groupby", groupSize)), merr.ReasonGroupSizeInvalid)
 		}
 	}
 	if groupSize > Params.QuotaConfig.MaxGroupSize.GetAsInt64() {
-		return nil, merr.WrapErrParameterInvalidMsg(
-			fmt.Sprintf("input group size:%d exceeds configured max group size:%d", groupSize, Params.QuotaConfig.MaxGroupSize.GetAsInt64()))
+		return nil, merr.WithReasonCode(merr.WrapErrParameterInvalidMsg(
+			fmt.Sprintf("input group size:%d exceeds configured max group size:%d", groupSize, Params.QuotaConfig.MaxGroupSize.GetAsInt64())), merr.ReasonGroupSizeExceeded)
 	}
 	ret.groupSize = groupSize
 
@@ -488,24 +857,24 @@ func parseRankParams(rankParamsPair []*commonpb.KeyValuePair, schema *schemapb.C
 
 	limitStr, err := funcutil.GetAttrByKeyFromRepeatedKV(LimitKey, rankParamsPair)
 	if err != nil {
-		return nil, errors.New(LimitKey + " not found in rank_params")
+		return nil, merr.WithReasonCode(errors.New(LimitKey+" not found in rank_params"), merr.ReasonTopKInvalid)
 	}
 	limit, err = strconv.ParseInt(limitStr, 0, 64)
 	if err != nil {
-		return nil, fmt.Errorf("%s [%s] is invalid", LimitKey, limitStr)
+		return nil, merr.WithReasonCode(fmt.Errorf("%s [%s] is invalid", LimitKey, limitStr), merr.ReasonTopKInvalid)
 	}
 
 	offsetStr, err := funcutil.GetAttrByKeyFromRepeatedKV(OffsetKey, rankParamsPair)
 	if err == nil {
 		offset, err = strconv.ParseInt(offsetStr, 0, 64)
 		if err != nil {
-			return nil, fmt.Errorf("%s [%s] is invalid", OffsetKey, offsetStr)
+			return nil, merr.WithReasonCode(fmt.Errorf("%s [%s] is invalid", OffsetKey, offsetStr), merr.ReasonOffsetInvalid)
 		}
 	}
 
 	// validate max result window.
 	if err = validateMaxQueryResultWindow(offset, limit); err != nil {
-		return nil, fmt.Errorf("invalid max query result window, %w", err)
+		return nil, merr.WithReasonCode(fmt.Errorf("invalid max query result window, %w", err), merr.ReasonResultWindowExceeded)
 	}
 
 	roundDecimalStr, err := funcutil.GetAttrByKeyFromRepeatedKV(RoundDecimalKey, rankParamsPair)
@@ -515,11 +884,13 @@ func parseRankParams(rankParamsPair []*commonpb.KeyValuePair, schema *schemapb.C
 
 	roundDecimal, err = strconv.ParseInt(roundDecimalStr, 0, 64)
 	if err != nil {
-		return nil, fmt.Errorf("%s [%s] is invalid, should be -1 or an integer in range [0, 6]", RoundDecimalKey, roundDecimalStr)
+		return nil, merr.WithReasonCode(fmt.Errorf("%s [%s] is invalid, should be -1 or an integer in range [0, 6]", RoundDecimalKey, roundDecimalStr),
+			merr.ReasonRoundDecimalInvalid)
 	}
 
 	if roundDecimal != -1 && (roundDecimal > 6 || roundDecimal < 0) {
-		return nil, fmt.Errorf("%s [%s] is invalid, should be -1 or an integer in range [0, 6]", RoundDecimalKey, roundDecimalStr)
+		return nil, merr.WithReasonCode(fmt.Errorf("%s [%s] is invalid, should be -1 or an integer in range [0, 6]", RoundDecimalKey, roundDecimalStr),
+			merr.ReasonRoundDecimalInvalid)
 	}
 
 	// parse group_by parameters from main request body for hybrid search
@@ -586,3 +957,1008 @@ func getMetricType(toReduceResults []*internalpb.SearchResults) string {
 	}
 	return metricType
 }
+
+// getSubMetricTypes returns the effective metric type of each sub search request, indexed by request index.
+// The metric type reported by QueryNodes takes precedence since the one in the request may be empty.
+func getSubMetricTypes(toReduceResults []*internalpb.SearchResults, subReqs []*internalpb.SubSearchRequest) []string {
+	metricTypes := make([]string, len(subReqs))
+	for i, subReq := range subReqs {
+		metricTypes[i] = subReq.GetMetricType()
+	}
+	for _, result := range toReduceResults {
+		for _, subResult := range result.GetSubResults() {
+			reqIndex := int(subResult.GetReqIndex())
+			if reqIndex < 0 || reqIndex >= len(metricTypes) || subResult.GetMetricType() == "" {
+				continue
+			}
+			metricTypes[reqIndex] = subResult.GetMetricType()
+		}
+	}
+	return metricTypes
+}
+
+// setSearchResultExtraInfo attaches a piece of metadata to the status of search results.
+func setSearchResultExtraInfo(result *milvuspb.SearchResults, key string, value string) {
+	if result == nil {
+		return
+	}
+	if result.Status == nil {
+		result.Status = merr.Success()
+	}
+	if result.Status.ExtraInfo == nil {
+		result.Status.ExtraInfo = make(map[string]string)
+	}
+	result.Status.ExtraInfo[key] = value
+}
+
+// parsePartitionKeyHintValues parses the partition key hints of query rows, one hint per row.
+func parsePartitionKeyHintValues(hintsStr string, partitionKeyField *schemapb.FieldSchema, nq int64) (*schemapb.FieldData, error) {
+	scalars := &schemapb.ScalarField{}
+	numHints := 0
+	switch partitionKeyField.GetDataType() {
+	case schemapb.DataType_Int64:
+		var hints []int64
+		if err := json.Unmarshal([]byte(hintsStr), &hints); err != nil {
+			return nil, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a list of int64", PartitionKeyHintsKey, hintsStr)
+		}
+		scalars.Data = &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: hints}}
+		numHints = len(hints)
+	case schemapb.DataType_VarChar:
+		var hints []string
+		if err := json.Unmarshal([]byte(hintsStr), &hints); err != nil {
+			return nil, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a list of string", PartitionKeyHintsKey, hintsStr)
+		}
+		scalars.Data = &schemapb.ScalarField_StringData{StringData: &schemapb.StringArray{Data: hints}}
+		numHints = len(hints)
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported partition key type %s", partitionKeyField.GetDataType().String())
+	}
+	if int64(numHints) != nq {
+		return nil, merr.WrapErrParameterInvalidMsg("the number of %s (%d) should be equal to nq (%d)", PartitionKeyHintsKey, numHints, nq)
+	}
+	return &schemapb.FieldData{
+		Type:      partitionKeyField.GetDataType(),
+		FieldName: partitionKeyField.GetName(),
+		FieldId:   partitionKeyField.GetFieldID(),
+		Field:     &schemapb.FieldData_Scalars{Scalars: scalars},
+	}, nil
+}
+
+// groupRowsByPartition groups the query rows by their partitions, rows of each group are in ascending order.
+func groupRowsByPartition(rowPartitionIDs []int64) map[int64][]int {
+	groups := make(map[int64][]int)
+	for row, partitionID := range rowPartitionIDs {
+		groups[partitionID] = append(groups[partitionID], row)
+	}
+	return groups
+}
+
+// slicePlaceholderGroup picks the given query rows from the placeholder group.
+func slicePlaceholderGroup(placeholderGroup *commonpb.PlaceholderGroup, rows []int, nq int64) (*commonpb.PlaceholderGroup, error) {
+	sliced := &commonpb.PlaceholderGroup{}
+	for _, placeholder := range placeholderGroup.GetPlaceholders() {
+		if int64(len(placeholder.GetValues())) != nq {
+			return nil, merr.WrapErrParameterInvalidMsg("number of placeholder values (%d) mismatches nq (%d)", len(placeholder.GetValues()), nq)
+		}
+		values := make([][]byte, 0, len(rows))
+		for _, row := range rows {
+			values = append(values, placeholder.GetValues()[row])
+		}
+		sliced.Placeholders = append(sliced.Placeholders, &commonpb.PlaceholderValue{
+			Tag:    placeholder.GetTag(),
+			Type:   placeholder.GetType(),
+			Values: values,
+		})
+	}
+	return sliced, nil
+}
+
+// padSearchResultRows pads the search result of some query rows back to nq rows, the rows not searched get empty results.
+// rows must be in ascending order, so that the hits of the result keep the row order.
+func padSearchResultRows(result *internalpb.SearchResults, rows []int, nq int64) error {
+	result.NumQueries = nq
+	if result.GetSlicedBlob() == nil {
+		return nil
+	}
+	data := &schemapb.SearchResultData{}
+	if err := proto.Unmarshal(result.GetSlicedBlob(), data); err != nil {
+		return err
+	}
+	if len(data.GetTopks()) != len(rows) {
+		return fmt.Errorf("search result's nq(%d) mis-match with %d", len(data.GetTopks()), len(rows))
+	}
+	topks := make([]int64, nq)
+	for i, row := range rows {
+		topks[row] = data.GetTopks()[i]
+	}
+	data.Topks = topks
+	data.NumQueries = nq
+	blob, err := proto.Marshal(data)
+	if err != nil {
+		return err
+	}
+	result.SlicedBlob = blob
+	return nil
+}
+
+// filterSearchResultDataByMinScore drops the hits with scores less than minScore.
+func filterSearchResultDataByMinScore(data *schemapb.SearchResultData, minScore float32) {
+	filterSearchResultData(data, func(_ int, score float32) bool {
+		return score >= minScore
+	})
+}
+
+// elbowMinGapRatio is the min ratio of the largest score gap to the average one, for the gap to be regarded as the elbow.
+const elbowMinGapRatio = 2
+
+// elbowCut returns the number of the hits to keep by the elbow heuristic, the scores are sorted from the best to the worst.
+// The hits are cut at the largest gap between the adjacent scores within the topk, the hits after it are regarded
+// as much less relevant than the ones before. The gaps of all the candidates, including the ones beyond the topk,
+// make up the average gap. If the largest gap is less than elbowMinGapRatio times of the average, the scores
+// decrease smoothly and there is no elbow, the topk hits are kept as usual. The result is bounded in [1, topk].
+func elbowCut(scores []float32, topk int64) int64 {
+	n := int64(len(scores))
+	if n <= 1 || topk <= 1 {
+		return min(n, topk)
+	}
+	var total, largest float64
+	cut := int64(0)
+	for i := int64(0); i < n-1; i++ {
+		gap := math.Abs(float64(scores[i]) - float64(scores[i+1]))
+		total += gap
+		if i < topk && gap > largest {
+			largest = gap
+			cut = i + 1
+		}
+	}
+	if largest == 0 || largest < total/float64(n-1)*elbowMinGapRatio {
+		return min(n, topk)
+	}
+	return cut
+}
+
+// cutSearchResultDataAtElbow cuts the hits of each query at the elbow of the scores, see elbowCut for the heuristic.
+func cutSearchResultDataAtElbow(data *schemapb.SearchResultData, topk int64) {
+	if data == nil {
+		return
+	}
+	cuts := make([]int64, len(data.GetTopks()))
+	var offset int64
+	for row, rowTopk := range data.GetTopks() {
+		cuts[row] = elbowCut(data.GetScores()[offset:offset+rowTopk], topk)
+		offset += rowTopk
+	}
+	kept := make([]int64, len(data.GetTopks()))
+	filterSearchResultData(data, func(row int, _ float32) bool {
+		kept[row]++
+		return kept[row] <= cuts[row]
+	})
+	data.TopK = min(data.GetTopK(), topk)
+}
+
+// sampleSearchResultData keeps a random sample of ratio of the hits of each query, rounded to the nearest, and at least one
+// for the queries with hits. The hits sampled keep their order. The sample of each query is drawn from a random source seeded
+// by seed plus the query row, so the same seed samples the same hits of the same results.
+func sampleSearchResultData(data *schemapb.SearchResultData, ratio float64, seed int64) {
+	if data == nil {
+		return
+	}
+	kept := make([]bool, 0, len(data.GetScores()))
+	for row, topk := range data.GetTopks() {
+		rowKept := make([]bool, topk)
+		n := min(max(int(math.Round(float64(topk)*ratio)), 1), int(topk))
+		for _, i := range rand.New(rand.NewSource(seed + int64(row))).Perm(int(topk))[:n] {
+			rowKept[i] = true
+		}
+		kept = append(kept, rowKept...)
+	}
+	hit := 0
+	filterSearchResultData(data, func(_ int, _ float32) bool {
+		hit++
+		return kept[hit-1]
+	})
+}
+
+// getDistancesPerHit returns the number of the original distances of each hit, 0 if the distances are not returned.
+func getDistancesPerHit(data *schemapb.SearchResultData) int {
+	if len(data.GetScores()) == 0 {
+		return 0
+	}
+	return len(data.GetDistances()) / len(data.GetScores())
+}
+
+// appendHitDistances appends the original distances of the j-th hit to dst, the distances are strided by distancesPerHit.
+func appendHitDistances(dst []float32, data *schemapb.SearchResultData, distancesPerHit int, j int64) []float32 {
+	if distancesPerHit <= 0 {
+		return dst
+	}
+	return append(dst, data.GetDistances()[j*int64(distancesPerHit):(j+1)*int64(distancesPerHit)]...)
+}
+
+// filterSearchResultData keeps the hits whose scores satisfy the predicate, the result arrays are compacted in place.
+// The predicate is called with the query row of the hit and its score.
+func filterSearchResultData(data *schemapb.SearchResultData, keep func(row int, score float32) bool) {
+	if data == nil || len(data.GetScores()) == 0 {
+		return
+	}
+	distancesPerHit := getDistancesPerHit(data)
+	// keep the type of ids even if all hits are dropped
+	ids := &schemapb.IDs{}
+	if data.GetIds().GetStrId() != nil {
+		ids.IdField = &schemapb.IDs_StrId{StrId: &schemapb.StringArray{}}
+	} else {
+		ids.IdField = &schemapb.IDs_IntId{IntId: &schemapb.LongArray{}}
+	}
+	scores := make([]float32, 0, len(data.GetScores()))
+	distances := make([]float32, 0, len(data.GetDistances()))
+	topks := make([]int64, 0, len(data.GetTopks()))
+	fieldsData := typeutil.PrepareResultFieldData(data.GetFieldsData(), int64(len(data.GetScores())))
+	var offset int64
+	for row, topk := range data.GetTopks() {
+		var kept int64
+		for j := offset; j < offset+topk; j++ {
+			if !keep(row, data.GetScores()[j]) {
+				continue
+			}
+			typeutil.AppendPKs(ids, typeutil.GetPK(data.GetIds(), j))
+			scores = append(scores, data.GetScores()[j])
+			distances = appendHitDistances(distances, data, distancesPerHit, j)
+			typeutil.AppendFieldData(fieldsData, data.GetFieldsData(), j)
+			kept++
+		}
+		topks = append(topks, kept)
+		offset += topk
+	}
+	data.Ids = ids
+	data.Scores = scores
+	data.Topks = topks
+	data.FieldsData = fieldsData
+	if distancesPerHit > 0 {
+		data.Distances = distances
+	}
+}
+
+func isInvalidScore(score float32) bool {
+	return math.IsNaN(float64(score)) || math.IsInf(float64(score), 0)
+}
+
+// sanitizeInvalidScores replaces the NaN and Inf scores with the extreme finite values, or drops the hits if drop is true.
+// Inf keeps its sign, while NaN is regarded as the worst score. Returns the number of invalid scores.
+func sanitizeInvalidScores(data *schemapb.SearchResultData, positivelyRelated bool, drop bool) int {
+	numInvalid := lo.CountBy(data.GetScores(), isInvalidScore)
+	if numInvalid == 0 {
+		return 0
+	}
+	if drop {
+		filterSearchResultData(data, func(_ int, score float32) bool {
+			return !isInvalidScore(score)
+		})
+		return numInvalid
+	}
+	for i, score := range data.GetScores() {
+		switch {
+		case math.IsInf(float64(score), 1):
+			data.Scores[i] = math.MaxFloat32
+		case math.IsInf(float64(score), -1):
+			data.Scores[i] = -math.MaxFloat32
+		case math.IsNaN(float64(score)):
+			if positivelyRelated {
+				data.Scores[i] = -math.MaxFloat32
+			} else {
+				data.Scores[i] = math.MaxFloat32
+			}
+		}
+	}
+	return numInvalid
+}
+
+// validateSearchMetricType checks the requested metric type against the metric type of the index on the field,
+// empty metric type is always valid since the metric type of the index is used then.
+func validateSearchMetricType(schema *schemaInfo, fieldID int64, metricType string, indexMetricTypes map[int64]string) error {
+	indexMetricType, ok := indexMetricTypes[fieldID]
+	if !ok || metricType == "" || strings.EqualFold(metricType, indexMetricType) {
+		return nil
+	}
+	fieldName := strconv.FormatInt(fieldID, 10)
+	if field, err := schema.schemaHelper.GetFieldFromID(fieldID); err == nil {
+		fieldName = field.GetName()
+	}
+	return merr.WithReasonCode(merr.WrapErrParameterInvalidMsg("metric type not match for field %s: the index is built with metric type %s, but %s is requested",
+		fieldName, indexMetricType, metricType), merr.ReasonMetricTypeMismatch)
+}
+
+var percentileRangeFilterPattern = regexp.MustCompile(`^p(\d+(\.\d+)?)$`)
+
+// parsePercentileRangeFilter extracts the percentile range filter like "p90" from the search params string,
+// the returned params string has the range filter removed as it could only be resolved after the candidates are fetched.
+// ok is false if there is no percentile range filter.
+func parsePercentileRangeFilter(searchParamStr string) (percentile float64, newSearchParamStr string, ok bool, err error) {
+	if !strings.Contains(searchParamStr, rangeFilterKey) {
+		return 0, searchParamStr, false, nil
+	}
+	params := make(map[string]any)
+	if err := json.Unmarshal([]byte(searchParamStr), &params); err != nil {
+		// left to segcore to report the malformed params
+		return 0, searchParamStr, false, nil
+	}
+	rangeFilter, isString := params[rangeFilterKey].(string)
+	if !isString {
+		return 0, searchParamStr, false, nil
+	}
+	matches := percentileRangeFilterPattern.FindStringSubmatch(rangeFilter)
+	if matches == nil {
+		return 0, "", false, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a number or a percentile like p90", rangeFilterKey, rangeFilter)
+	}
+	percentile, _ = strconv.ParseFloat(matches[1], 64)
+	if percentile <= 0 || percentile > 100 {
+		return 0, "", false, merr.WrapErrParameterInvalidMsg("percentile of %s [%s] should be in range (0, 100]", rangeFilterKey, rangeFilter)
+	}
+	delete(params, rangeFilterKey)
+	bs, err := json.Marshal(params)
+	if err != nil {
+		return 0, "", false, err
+	}
+	return percentile, string(bs), true, nil
+}
+
+// filterSearchResultDataByPercentile resolves the percentile range filter of each query row from the scores of its hits,
+// the bound is the score that the given percent of the hits are not better than. Like an absolute range filter,
+// the hits better than the bound are dropped.
+func filterSearchResultDataByPercentile(data *schemapb.SearchResultData, percentile float64, positivelyRelated bool) {
+	if data == nil || len(data.GetScores()) == 0 {
+		return
+	}
+	// sorted from the worst to the best
+	isBetter := func(a, b float32) bool {
+		if positivelyRelated {
+			return a > b
+		}
+		return a < b
+	}
+	bounds := make([]float32, len(data.GetTopks()))
+	var offset int64
+	for row, topk := range data.GetTopks() {
+		if topk > 0 {
+			scores := slices.Clone(data.GetScores()[offset : offset+topk])
+			sort.Slice(scores, func(i, j int) bool {
+				return isBetter(scores[j], scores[i])
+			})
+			idx := int(math.Ceil(percentile/100*float64(topk))) - 1
+			bounds[row] = scores[max(idx, 0)]
+		}
+		offset += topk
+	}
+	filterSearchResultData(data, func(row int, score float32) bool {
+		return !isBetter(score, bounds[row])
+	})
+}
+
+// truncatedFieldMarker is appended to the field values truncated by max_field_bytes.
+const truncatedFieldMarker = "...[truncated]"
+
+// fillNullableValidData makes sure every nullable field carries the valid bitmap, so that clients could always tell
+// null from the zero value. The shards with no null value may return no bitmap, then all the rows are valid.
+func fillNullableValidData(fieldsData []*schemapb.FieldData, schema *schemapb.CollectionSchema, numRows int) {
+	for _, fieldData := range fieldsData {
+		if len(fieldData.GetValidData()) != 0 {
+			continue
+		}
+		field := typeutil.GetField(schema, fieldData.GetFieldId())
+		if field == nil || !field.GetNullable() {
+			continue
+		}
+		fieldData.ValidData = lo.RepeatBy(numRows, func(int) bool { return true })
+	}
+}
+
+// truncateFieldsData cuts the varchar and JSON values longer than maxBytes and appends the truncation marker,
+// returns the number of values truncated. The pk field is never truncated, neither is the dynamic field,
+// as clients expand it into the dynamic keys. The truncated JSON values are returned as JSON strings to keep them valid.
+func truncateFieldsData(fieldsData []*schemapb.FieldData, maxBytes int, pkFieldID int64) int {
+	truncated := 0
+	for _, fieldData := range fieldsData {
+		if fieldData.GetFieldId() == pkFieldID || fieldData.GetIsDynamic() {
+			continue
+		}
+		switch fieldData.GetType() {
+		case schemapb.DataType_VarChar, schemapb.DataType_String:
+			data := fieldData.GetScalars().GetStringData().GetData()
+			for i, value := range data {
+				if len(value) > maxBytes {
+					data[i] = truncateUTF8(value, maxBytes) + truncatedFieldMarker
+					truncated++
+				}
+			}
+		case schemapb.DataType_JSON:
+			data := fieldData.GetScalars().GetJsonData().GetData()
+			for i, value := range data {
+				if len(value) > maxBytes {
+					// marshaling a string never fails
+					data[i], _ = json.Marshal(truncateUTF8(string(value), maxBytes) + truncatedFieldMarker)
+					truncated++
+				}
+			}
+		}
+	}
+	return truncated
+}
+
+// truncateUTF8 cuts the string to at most maxBytes bytes without splitting a multi-byte character.
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
+
+// maxLoggedExprLength is the max length of the expressions and plans logged, which may be very large if large terms are passed.
+const maxLoggedExprLength = 1024
+
+// truncateExprForLog cuts the expression to maxLoggedExprLength for logging, with the length of the part cut.
+func truncateExprForLog(expr string) string {
+	if len(expr) <= maxLoggedExprLength {
+		return expr
+	}
+	truncated := truncateUTF8(expr, maxLoggedExprLength)
+	return fmt.Sprintf("%s...(%d bytes truncated)", truncated, len(expr)-len(truncated))
+}
+
+// truncatedStringer truncates the string of the value for logging, which is evaluated only if the log is emitted.
+type truncatedStringer struct {
+	fmt.Stringer
+}
+
+func (s truncatedStringer) String() string {
+	return truncateExprForLog(s.Stringer.String())
+}
+
+// checkSearchExprLength rejects the expressions longer than proxy.maxSearchExprLength, as parsing them is slow.
+func checkSearchExprLength(expr string) error {
+	maxLength := Params.ProxyCfg.MaxSearchExprLength.GetAsSize()
+	if maxLength > 0 && int64(len(expr)) > maxLength {
+		return merr.WrapErrParameterInvalidMsg("the length of the filter expression %d bytes exceeds the limit %d bytes", len(expr), maxLength)
+	}
+	return nil
+}
+
+// iterativeFilterHint makes segcore filter iteratively during the index search.
+const iterativeFilterHint = "iterative_filter"
+
+// bruteForceHint makes segcore scan the raw vectors instead of searching by the index, it is set by the ground truth
+// search of self_recall_check only, never accepted from the requests.
+const bruteForceHint = "brute_force"
+
+// searchHintParams are the index search params could be overridden by hints, the value is whether it is an integer.
+var searchHintParams = map[string]bool{
+	"ef":                true,
+	"nprobe":            true,
+	"search_list":       true,
+	"itopk_size":        true,
+	"search_width":      true,
+	"reorder_k":         true,
+	"refine_k":          false,
+	"drop_ratio_search": false,
+}
+
+// applySearchHints parses the hints, a comma separated list of the iterative_filter flag and index search param
+// overrides like "ef=64". The overrides are merged into the search params, the flag is returned as the query info hints.
+func applySearchHints(hints string, searchParamStr string) (string, string, error) {
+	if hints == "" || hints == iterativeFilterHint {
+		return hints, searchParamStr, nil
+	}
+
+	flag := ""
+	overrides := make(map[string]any)
+	for _, item := range strings.Split(hints, ",") {
+		item = strings.TrimSpace(item)
+		if item == iterativeFilterHint {
+			flag = item
+			continue
+		}
+		key, value, found := strings.Cut(item, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found {
+			return "", "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, %s is neither %s nor in the format of key=value",
+				common.HintsKey, hints, item, iterativeFilterHint)
+		}
+		isInteger, ok := searchHintParams[key]
+		if !ok {
+			supportedKeys := lo.Keys(searchHintParams)
+			sort.Strings(supportedKeys)
+			return "", "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, unknown key %s, supported keys are %v",
+				common.HintsKey, hints, key, supportedKeys)
+		}
+		if _, ok := overrides[key]; ok {
+			return "", "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, duplicated key %s", common.HintsKey, hints, key)
+		}
+		if isInteger {
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || v <= 0 {
+				return "", "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, %s should be a positive integer", common.HintsKey, hints, key)
+			}
+			overrides[key] = v
+		} else {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+				return "", "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, %s should be a non-negative number", common.HintsKey, hints, key)
+			}
+			overrides[key] = v
+		}
+	}
+	searchParamStr, err := mergeSearchParams(searchParamStr, overrides)
+	if err != nil {
+		return "", "", err
+	}
+	return flag, searchParamStr, nil
+}
+
+// mergeSearchParams sets the overrides into the search params string, it is returned as is if there is no override.
+func mergeSearchParams(searchParamStr string, overrides map[string]any) (string, error) {
+	if len(overrides) == 0 {
+		return searchParamStr, nil
+	}
+	params := make(map[string]any)
+	if searchParamStr != "" {
+		if err := json.Unmarshal([]byte(searchParamStr), &params); err != nil {
+			return "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, %s", ParamsKey, searchParamStr, err.Error())
+		}
+	}
+	for key, value := range overrides {
+		params[key] = value
+	}
+	bs, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+// applySearchSeed forwards the seed to the index through the search params, so that the indexes with randomized
+// tie-breaking or sampling return reproducible results. The indexes without randomization ignore it.
+//
+// None of the built-in indexes reads the seed at present: FLAT, the IVF family, HNSW, DISKANN, SCANN and the sparse
+// indexes serving BM25 search deterministically on the same data, and GPU_CAGRA randomizes its entry points by its
+// own search params. So the results differing run to run come from the data searched, e.g. the growing segments and
+// the ties among the shards, which the seed does not fix. The seed is forwarded for the indexes reading it in the future.
+func applySearchSeed(searchParamsPair []*commonpb.KeyValuePair, searchParamStr string) (string, error) {
+	seedStr, err := funcutil.GetAttrByKeyFromRepeatedKV(SeedKey, searchParamsPair)
+	if err != nil {
+		return searchParamStr, nil
+	}
+	seed, err := strconv.ParseInt(seedStr, 10, 64)
+	if err != nil || seed < 0 {
+		return "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a non-negative integer", SeedKey, seedStr)
+	}
+	return mergeSearchParams(searchParamStr, map[string]any{SeedKey: seed})
+}
+
+// dynamicFieldPrefixHead is the head of the output field patterns selecting dynamic fields by prefix, e.g. `$meta.user_*`.
+const dynamicFieldPrefixHead = common.MetaFieldName + "."
+
+// parseDynamicFieldPrefixes picks the dynamic field prefix patterns out of the output fields, the dynamic field is
+// requested in place of them, and the dynamic keys are filtered by the prefixes after the search.
+// No prefix is returned if all the dynamic fields are requested anyway.
+func parseDynamicFieldPrefixes(outputFields []string, schema *schemaInfo) ([]string, []string, error) {
+	fields := make([]string, 0, len(outputFields))
+	prefixes := make([]string, 0)
+	allDynamicFields := false
+	for _, outputField := range outputFields {
+		name := strings.TrimSpace(outputField)
+		if name == "*" || name == common.MetaFieldName {
+			allDynamicFields = true
+		}
+		if !strings.HasPrefix(name, dynamicFieldPrefixHead) || !strings.HasSuffix(name, "*") {
+			fields = append(fields, outputField)
+			continue
+		}
+		if !schema.GetEnableDynamicField() {
+			return nil, nil, merr.WrapErrParameterInvalidMsg("output field %s selects dynamic fields, but dynamic field is not enabled", name)
+		}
+		prefix := strings.TrimSuffix(strings.TrimPrefix(name, dynamicFieldPrefixHead), "*")
+		if strings.Contains(prefix, "*") {
+			return nil, nil, merr.WrapErrParameterInvalidMsg("output field %s is invalid, only prefix pattern is supported", name)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	if len(prefixes) == 0 {
+		return outputFields, nil, nil
+	}
+	if allDynamicFields {
+		return fields, nil, nil
+	}
+	return append(fields, common.MetaFieldName), prefixes, nil
+}
+
+// filterDynamicFieldsByPrefix keeps the dynamic keys requested explicitly or matching any of the prefixes in the
+// dynamic field data, returns the prefixes matching none of the keys.
+func filterDynamicFieldsByPrefix(fieldsData []*schemapb.FieldData, keys []string, prefixes []string) ([]string, error) {
+	keySet := typeutil.NewSet(keys...)
+	matched := typeutil.NewSet[string]()
+	for _, fieldData := range fieldsData {
+		if !fieldData.GetIsDynamic() {
+			continue
+		}
+		data := fieldData.GetScalars().GetJsonData().GetData()
+		for i, value := range data {
+			if len(value) == 0 {
+				continue
+			}
+			values := make(map[string]json.RawMessage)
+			if err := json.Unmarshal(value, &values); err != nil {
+				return nil, merr.WrapErrServiceInternal(fmt.Sprintf("failed to unmarshal dynamic field: %s", err.Error()))
+			}
+			for key := range values {
+				if keySet.Contain(key) {
+					continue
+				}
+				prefix, ok := lo.Find(prefixes, func(prefix string) bool {
+					return strings.HasPrefix(key, prefix)
+				})
+				if !ok {
+					delete(values, key)
+					continue
+				}
+				matched.Insert(prefix)
+			}
+			bs, err := json.Marshal(values)
+			if err != nil {
+				return nil, err
+			}
+			data[i] = bs
+		}
+	}
+	return lo.Filter(prefixes, func(prefix string, _ int) bool {
+		return !matched.Contain(prefix)
+	}), nil
+}
+
+// dropDynamicOutputFields keeps only the static fields in the translated output fields if no_dynamic_fields is enabled,
+// the dynamic field expanded from `*` is dropped, and the dynamic keys or patterns requested explicitly are rejected.
+func dropDynamicOutputFields(requested []string, translated []string, userOutput []string, userDynamic []string) ([]string, []string, error) {
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		if name == common.MetaFieldName || strings.HasPrefix(name, dynamicFieldPrefixHead) {
+			return nil, nil, merr.WrapErrParameterInvalidMsg("output field %s selects dynamic fields, but %s is enabled", name, NoDynamicFieldsKey)
+		}
+	}
+	if len(userDynamic) > 0 {
+		return nil, nil, merr.WrapErrParameterInvalidMsg("output fields %v are dynamic fields, but %s is enabled", userDynamic, NoDynamicFieldsKey)
+	}
+	isStatic := func(name string, _ int) bool {
+		return name != common.MetaFieldName
+	}
+	return lo.Filter(translated, isStatic), lo.Filter(userOutput, isStatic), nil
+}
+
+// applyDefaultSearchParams merges the default search params of the collection under the search params of the request,
+// the request ones take precedence. The index params under the params key are merged key by key.
+func applyDefaultSearchParams(searchParamsPair []*commonpb.KeyValuePair, defaultParamsStr string) ([]*commonpb.KeyValuePair, error) {
+	defaultParams := make(map[string]any)
+	if err := json.Unmarshal([]byte(defaultParamsStr), &defaultParams); err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("collection property %s [%s] is invalid, %s",
+			common.CollectionDefaultSearchParamsKey, defaultParamsStr, err.Error())
+	}
+
+	result := lo.Filter(searchParamsPair, func(pair *commonpb.KeyValuePair, _ int) bool {
+		return pair.GetKey() != ParamsKey
+	})
+	paramsStr, err := funcutil.GetAttrByKeyFromRepeatedKV(ParamsKey, searchParamsPair)
+	if defaultIndexParams, ok := defaultParams[ParamsKey]; ok {
+		indexParams, ok := defaultIndexParams.(map[string]any)
+		if !ok {
+			return nil, merr.WrapErrParameterInvalidMsg("%s in collection property %s should be a JSON object",
+				ParamsKey, common.CollectionDefaultSearchParamsKey)
+		}
+		requestIndexParams := make(map[string]any)
+		if paramsStr != "" {
+			if err := json.Unmarshal([]byte(paramsStr), &requestIndexParams); err != nil {
+				return nil, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, %s", ParamsKey, paramsStr, err.Error())
+			}
+		}
+		bs, err := json.Marshal(indexParams)
+		if err != nil {
+			return nil, err
+		}
+		if paramsStr, err = mergeSearchParams(string(bs), requestIndexParams); err != nil {
+			return nil, err
+		}
+		result = append(result, &commonpb.KeyValuePair{Key: ParamsKey, Value: paramsStr})
+	} else if err == nil {
+		result = append(result, &commonpb.KeyValuePair{Key: ParamsKey, Value: paramsStr})
+	}
+
+	keys := lo.Keys(defaultParams)
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == ParamsKey {
+			continue
+		}
+		if _, err := funcutil.GetAttrByKeyFromRepeatedKV(key, searchParamsPair); err == nil {
+			continue
+		}
+		value, ok := defaultParams[key].(string)
+		if !ok {
+			bs, err := json.Marshal(defaultParams[key])
+			if err != nil {
+				return nil, err
+			}
+			value = string(bs)
+		}
+		result = append(result, &commonpb.KeyValuePair{Key: key, Value: value})
+	}
+	return result, nil
+}
+
+// splitConjuncts flattens the logical and expressions into their conjuncts.
+func splitConjuncts(expr *planpb.Expr) []*planpb.Expr {
+	binaryExpr := expr.GetBinaryExpr()
+	if binaryExpr == nil || binaryExpr.GetOp() != planpb.BinaryExpr_LogicalAnd {
+		return []*planpb.Expr{expr}
+	}
+	return append(splitConjuncts(binaryExpr.GetLeft()), splitConjuncts(binaryExpr.GetRight())...)
+}
+
+// andCommonFilter combines the common filter of a hybrid search with the filter of a sub search request by AND.
+// The sub filter refines the common one, the common filter is not repeated if the sub filter already contains it.
+func andCommonFilter(commonExpr *planpb.Expr, subExpr *planpb.Expr) *planpb.Expr {
+	if commonExpr == nil {
+		return subExpr
+	}
+	if subExpr == nil {
+		return proto.Clone(commonExpr).(*planpb.Expr)
+	}
+	for _, conjunct := range splitConjuncts(subExpr) {
+		if proto.Equal(conjunct, commonExpr) {
+			return subExpr
+		}
+	}
+	return &planpb.Expr{
+		Expr: &planpb.Expr_BinaryExpr{
+			BinaryExpr: &planpb.BinaryExpr{
+				Left:  proto.Clone(commonExpr).(*planpb.Expr),
+				Right: subExpr,
+				Op:    planpb.BinaryExpr_LogicalAnd,
+			},
+		},
+		IsTemplate: commonExpr.GetIsTemplate() || subExpr.GetIsTemplate(),
+	}
+}
+
+// mergeSearchResultsByPartition concatenates the results reduced in each partition, the hits of each query are
+// returned in blocks of partitions in the order of partitionIDs, and tagged by the partition id output field.
+func mergeSearchResultsByPartition(results []*milvuspb.SearchResults, partitionIDs []int64, nq int64) *milvuspb.SearchResults {
+	merged := &schemapb.SearchResultData{
+		NumQueries: nq,
+		Ids:        &schemapb.IDs{},
+		Topks:      make([]int64, nq),
+	}
+	var sample []*schemapb.FieldData
+	distancesPerHit := 0
+	for _, result := range results {
+		data := result.GetResults()
+		if len(data.GetScores()) > 0 {
+			sample = data.GetFieldsData()
+			distancesPerHit = getDistancesPerHit(data)
+			if data.GetIds().GetStrId() != nil {
+				merged.Ids.IdField = &schemapb.IDs_StrId{StrId: &schemapb.StringArray{}}
+			} else {
+				merged.Ids.IdField = &schemapb.IDs_IntId{IntId: &schemapb.LongArray{}}
+			}
+			break
+		}
+	}
+	merged.FieldsData = typeutil.PrepareResultFieldData(sample, 0)
+
+	partitionIDData := make([]int64, 0)
+	offsets := make([]int64, len(results))
+	for row := int64(0); row < nq; row++ {
+		for i, result := range results {
+			data := result.GetResults()
+			if row >= int64(len(data.GetTopks())) {
+				continue
+			}
+			topk := data.GetTopks()[row]
+			for j := offsets[i]; j < offsets[i]+topk; j++ {
+				typeutil.AppendPKs(merged.Ids, typeutil.GetPK(data.GetIds(), j))
+				merged.Scores = append(merged.Scores, data.GetScores()[j])
+				merged.Distances = appendHitDistances(merged.Distances, data, distancesPerHit, j)
+				typeutil.AppendFieldData(merged.FieldsData, data.GetFieldsData(), j)
+				partitionIDData = append(partitionIDData, partitionIDs[i])
+			}
+			offsets[i] += topk
+			merged.Topks[row] += topk
+		}
+		merged.TopK = max(merged.TopK, merged.Topks[row])
+	}
+	merged.FieldsData = append(merged.FieldsData, &schemapb.FieldData{
+		Type:      schemapb.DataType_Int64,
+		FieldName: partitionIDOutputField,
+		Field: &schemapb.FieldData_Scalars{
+			Scalars: &schemapb.ScalarField{
+				Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: partitionIDData}},
+			},
+		},
+	})
+	return &milvuspb.SearchResults{
+		Status:  merr.Success(),
+		Results: merged,
+	}
+}
+
+// countTemplateArrayValue returns the number of the elements in the template array value, nested arrays are counted recursively.
+func countTemplateArrayValue(value *schemapb.TemplateArrayValue) int {
+	switch {
+	case value.GetBoolData() != nil:
+		return len(value.GetBoolData().GetData())
+	case value.GetLongData() != nil:
+		return len(value.GetLongData().GetData())
+	case value.GetDoubleData() != nil:
+		return len(value.GetDoubleData().GetData())
+	case value.GetStringData() != nil:
+		return len(value.GetStringData().GetData())
+	case value.GetJsonData() != nil:
+		return len(value.GetJsonData().GetData())
+	case value.GetArrayData() != nil:
+		count := 0
+		for _, element := range value.GetArrayData().GetData() {
+			count += countTemplateArrayValue(element)
+		}
+		return count
+	}
+	return 0
+}
+
+// validateExprTemplateValues checks the number and the serialized size of the expression template values against the limits,
+// so that huge term lists are rejected before the plan is generated. The limit is disabled if it is less or equal to 0.
+func validateExprTemplateValues(values map[string]*schemapb.TemplateValue, maxCount int, maxSize int64) error {
+	if len(values) == 0 {
+		return nil
+	}
+	count := 0
+	size := int64(0)
+	for _, value := range values {
+		if value.GetArrayVal() != nil {
+			count += countTemplateArrayValue(value.GetArrayVal())
+		} else {
+			count++
+		}
+		size += int64(proto.Size(value))
+	}
+	if maxCount > 0 && count > maxCount {
+		return merr.WrapErrParameterInvalidMsg("the number of expression template values %d exceeds the limit %d", count, maxCount)
+	}
+	if maxSize > 0 && size > maxSize {
+		return merr.WrapErrParameterInvalidMsg("the size of expression template values %d bytes exceeds the limit %d bytes", size, maxSize)
+	}
+	return nil
+}
+
+// parseTimeRangeFilter compiles time_range in the form of `field:start:end` into the equivalent filter expression.
+// Either bound could be omitted, e.g. `field:start:` matches the values no earlier than start, both bounds are inclusive.
+// The field shall be a numeric field of the collection, where the timestamps are stored.
+func parseTimeRangeFilter(timeRange string, schema *schemapb.CollectionSchema) (string, error) {
+	parts := strings.Split(timeRange, ":")
+	if len(parts) != 3 || parts[0] == "" || (parts[1] == "" && parts[2] == "") {
+		return "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be in the form of field:start:end", TimeRangeKey, timeRange)
+	}
+	fieldName := parts[0]
+	field := typeutil.GetFieldByName(schema, fieldName)
+	if field == nil {
+		return "", merr.WrapErrFieldNotFound(fieldName, fmt.Sprintf("%s field not found in schema", TimeRangeKey))
+	}
+	if !typeutil.IsArithmetic(field.GetDataType()) {
+		return "", merr.WrapErrParameterInvalidMsg("%s field %s should be numeric, but got %s", TimeRangeKey, fieldName, field.GetDataType().String())
+	}
+
+	parseBound := func(bound string) (float64, error) {
+		var value float64
+		var err error
+		if typeutil.IsIntegerType(field.GetDataType()) {
+			var intValue int64
+			intValue, err = strconv.ParseInt(bound, 10, 64)
+			value = float64(intValue)
+		} else {
+			value, err = strconv.ParseFloat(bound, 64)
+		}
+		if err != nil {
+			return 0, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, bound %s is not a valid %s value",
+				TimeRangeKey, timeRange, bound, field.GetDataType().String())
+		}
+		return value, nil
+	}
+	conds := make([]string, 0, 2)
+	var start, end float64
+	var err error
+	if parts[1] != "" {
+		if start, err = parseBound(parts[1]); err != nil {
+			return "", err
+		}
+		conds = append(conds, fmt.Sprintf("%s >= %s", fieldName, parts[1]))
+	}
+	if parts[2] != "" {
+		if end, err = parseBound(parts[2]); err != nil {
+			return "", err
+		}
+		conds = append(conds, fmt.Sprintf("%s <= %s", fieldName, parts[2]))
+	}
+	if len(conds) == 2 && start > end {
+		return "", merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, start should not be greater than end", TimeRangeKey, timeRange)
+	}
+	return strings.Join(conds, " && "), nil
+}
+
+// mergeTimeRangeFilter merges the time range filter with the filter of the request, both shall be satisfied.
+func mergeTimeRangeFilter(dsl string, timeRangeExpr string) string {
+	if strings.TrimSpace(dsl) == "" {
+		return timeRangeExpr
+	}
+	return fmt.Sprintf("(%s) && (%s)", dsl, timeRangeExpr)
+}
+
+// outputFieldsRank ranks the field names by their positions in the output fields requested, the fields expanded
+// from the wildcard or a struct array field follow the schema order, and the dynamic keys rank the dynamic field.
+func outputFieldsRank(outputFields []string, schema *schemaInfo) map[string]int {
+	rank := make(map[string]int)
+	add := func(name string) {
+		if _, ok := rank[name]; !ok {
+			rank[name] = len(rank)
+		}
+	}
+	structArrayFields := make(map[string]*schemapb.StructArrayFieldSchema)
+	for _, structArrayField := range schema.GetStructArrayFields() {
+		structArrayFields[structArrayField.GetName()] = structArrayField
+	}
+	for _, name := range outputFields {
+		name = strings.TrimSpace(name)
+		if name == "*" {
+			for _, field := range schema.GetFields() {
+				add(field.GetName())
+			}
+			for _, structArrayField := range schema.GetStructArrayFields() {
+				add(structArrayField.GetName())
+				for _, field := range structArrayField.GetFields() {
+					add(field.GetName())
+				}
+			}
+			continue
+		}
+		add(name)
+		if structArrayField, ok := structArrayFields[name]; ok {
+			for _, field := range structArrayField.GetFields() {
+				add(field.GetName())
+			}
+			continue
+		}
+		if _, ok := schema.fieldMap.Get(name); !ok {
+			add(common.MetaFieldName)
+		}
+	}
+	return rank
+}
+
+// sortByOutputFieldsRank stably sorts the items by the rank of their names, the ones not ranked are put at the end.
+func sortByOutputFieldsRank[T any](items []T, rank map[string]int, name func(T) string) {
+	rankOf := func(item T) int {
+		if r, ok := rank[name(item)]; ok {
+			return r
+		}
+		return len(rank)
+	}
+	slices.SortStableFunc(items, func(a, b T) int {
+		return rankOf(a) - rankOf(b)
+	})
+}
+
+// mergeChannelsMvcc merges the mvcc timestamps of the channels into dst, keeping the largest one of each channel,
+// as a channel may be searched more than once, e.g. once per partition or per group of query rows.
+func mergeChannelsMvcc(dst, src map[string]Timestamp) {
+	for ch, ts := range src {
+		if ts > dst[ch] {
+			dst[ch] = ts
+		}
+	}
+}
@@ -69,6 +69,70 @@ const (
 	OffsetKey            = "offset"
 	LimitKey             = "limit"
 
+	SkipVectorRequeryKey       = "skip_vector_requery"
+	PartitionMatchKey          = "partition_match"
+	ReturnOriginalDistancesKey = "return_original_distances"
+	PartitionKeyHintsKey       = "partition_key_hints"
+	MinScoreKey                = "min_score"
+	AlwaysIncludePkKey         = "always_include_pk"
+	CachePlaceholderGroupKey   = "cache_placeholder_group"
+	PlaceholderGroupTokenKey   = "placeholder_group_token"
+	WithSearchStatsKey         = "with_search_stats"
+	SearchRequestIDKey         = "request_id"
+	MaxFieldBytesKey           = "max_field_bytes"
+	CountOnlyKey               = "count_only"
+	SeedKey                    = "seed"
+	ErrorOnEmptyKey            = "error_on_empty"
+	ScanAllPartitionsKey       = "scan_all_partitions"
+	StrictDynamicFieldPrefix   = "strict_dynamic_field_prefix"
+	IDsScoresOnlyKey           = "ids_scores_only"
+	StrictAnnsFieldsKey        = "strict_anns_fields"
+	CommonFilterKey            = "common_filter"
+	GroupResultsByPartitionKey = "group_results_by_partition"
+	PartialResultsOnTimeoutKey = "partial_results_on_timeout"
+	SearchCursorKey            = "search_cursor"
+	SearchResultFormatKey      = "format"
+	NodeIDsKey                 = "node_ids"
+	AdaptiveTopKKey            = "adaptive_topk"
+	WithPlanHashKey            = "with_plan_hash"
+	MaxSearchRequestsKey       = "max_search_requests"
+	TimeRangeKey               = "time_range"
+	ApproxDistinctFieldKey     = "approx_distinct_field"
+	ImpersonateUserKey         = "impersonate_user"
+	SortByKey                  = "sort_by"
+	HighlightKey               = "highlight"
+	HighlightPreTagKey         = "highlight_pre_tag"
+	HighlightPostTagKey        = "highlight_post_tag"
+	ResourceGroupKey           = "resource_group"
+	ExplainBM25Key             = "explain_bm25"
+	NoDynamicFieldsKey         = "no_dynamic_fields"
+	PlaceholderGroupRefKey     = "placeholder_group_ref"
+	SelfRecallCheckKey         = "self_recall_check"
+	BoostKey                   = "boost"
+	BoostModeKey               = "boost_mode"
+	WithIndexInfoKey           = "with_index_info"
+	RerankBestEffortKey        = "rerank_best_effort"
+	UnionCollectionsKey        = "union_collections"
+	VectorPrecisionKey         = "vector_precision"
+	MaxResultAgeKey            = "max_result_age"
+	MaxResultAgeFieldKey       = "max_result_age_field"
+	WithChannelMvccKey         = "with_channel_mvcc"
+	WithHasMoreKey             = "with_has_more"
+	PreviewTokensKey           = "preview_tokens"
+	SealedOnlyKey              = "sealed_only"
+	PreviewPartitionsKey       = "preview_partitions"
+	WithFusionProvenanceKey    = "with_fusion_provenance"
+	WithQueryOffsetsKey        = "with_query_offsets"
+	TopPartitionOnlyKey        = "top_partition_only"
+	WithTermStatsKey           = "with_term_stats"
+	WithExactnessKey           = "with_exactness"
+	SearchModeKey              = "mode"
+	QueryVectorsURIKey         = "query_vectors_uri"
+	ComputeDiversityKey        = "compute_diversity"
+	SampleKey                  = "sample"
+	SampleSeedKey              = "sample_seed"
+	DefaultVectorFieldKey      = "default_vector_field"
+
 	SearchIterV2Key        = "search_iter_v2"
 	SearchIterBatchSizeKey = "search_iter_batch_size"
 	SearchIterLastBoundKey = "search_iter_last_bound"
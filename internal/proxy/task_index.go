@@ -577,6 +577,7 @@ func (cit *createIndexTask) Execute(ctx context.Context) error {
 	if err = merr.CheckRPCCall(cit.result, err); err != nil {
 		return err
 	}
+	globalMetaCache.RemoveIndexMetricTypes(cit.collectionID)
 	return nil
 }
 
@@ -1064,6 +1065,7 @@ func (dit *dropIndexTask) Execute(ctx context.Context) error {
 		ctxLog.Warn("drop index failed", zap.Error(err))
 		return err
 	}
+	globalMetaCache.RemoveIndexMetricTypes(dit.collectionID)
 	return nil
 }
 
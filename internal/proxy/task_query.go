@@ -477,7 +477,11 @@ func (t *queryTask) PreExecute(ctx context.Context) error {
 	var consistencyLevel commonpb.ConsistencyLevel
 	useDefaultConsistency := t.request.GetUseDefaultConsistency()
 	t.RetrieveRequest.ConsistencyLevel = t.request.GetConsistencyLevel()
-	if useDefaultConsistency {
+	if t.reQuery {
+		// requery reads the snapshot of the search, the guarantee timestamp is resolved by the search already,
+		// resolving it again from the consistency level may produce a different one.
+		consistencyLevel = t.request.GetConsistencyLevel()
+	} else if useDefaultConsistency {
 		consistencyLevel = collectionInfo.consistencyLevel
 		guaranteeTs = parseGuaranteeTsFromConsistency(guaranteeTs, t.BeginTs(), consistencyLevel)
 	} else {
@@ -81,6 +81,12 @@ func (queue *baseTaskQueue) utEmpty() bool {
 	return queue.unissuedTasks.Len() == 0
 }
 
+func (queue *baseTaskQueue) utLen() int {
+	queue.utLock.RLock()
+	defer queue.utLock.RUnlock()
+	return queue.unissuedTasks.Len()
+}
+
 func (queue *baseTaskQueue) utFull() bool {
 	return int64(queue.unissuedTasks.Len()) >= queue.getMaxTaskNum()
 }
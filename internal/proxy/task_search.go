@@ -4,33 +4,42 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/samber/lo"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/json"
 	"github.com/milvus-io/milvus/internal/parser/planparserv2"
 	"github.com/milvus-io/milvus/internal/types"
 	"github.com/milvus-io/milvus/internal/util/exprutil"
 	"github.com/milvus-io/milvus/internal/util/function"
 	"github.com/milvus-io/milvus/internal/util/function/rerank"
+	"github.com/milvus-io/milvus/pkg/v2/common"
 	"github.com/milvus-io/milvus/pkg/v2/log"
 	"github.com/milvus-io/milvus/pkg/v2/metrics"
+	"github.com/milvus-io/milvus/pkg/v2/proto/indexpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/querypb"
 	"github.com/milvus-io/milvus/pkg/v2/util/commonpbutil"
+	"github.com/milvus-io/milvus/pkg/v2/util/contextutil"
 	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
 	"github.com/milvus-io/milvus/pkg/v2/util/merr"
 	"github.com/milvus-io/milvus/pkg/v2/util/metric"
 	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/v2/util/retry"
 	"github.com/milvus-io/milvus/pkg/v2/util/timerecord"
 	"github.com/milvus-io/milvus/pkg/v2/util/tsoutil"
 	"github.com/milvus-io/milvus/pkg/v2/util/typeutil"
@@ -47,6 +56,56 @@ const (
 	requeryThreshold = 0.5 * 1024 * 1024
 	radiusKey        = "radius"
 	rangeFilterKey   = "range_filter"
+	// partitionIDOutputField tags each hit with the partition it comes from, returned if group_results_by_partition is enabled.
+	partitionIDOutputField = "$partition_id"
+
+	// keys of the search metadata returned in the extra info of the result status
+	searchResultMetricTypeKey             = "metric_type"
+	searchResultSubMetricTypesKey         = "sub_metric_types"
+	searchResultTraceIDKey                = "trace_id"
+	searchResultQueryIDKey                = "query_id"
+	searchResultPlaceholderGroupTokenKey  = "placeholder_group_token"
+	searchResultQueriedChannelsKey        = "queried_channels"
+	searchResultNonEmptyChannelsKey       = "non_empty_channels"
+	searchResultTruncatedValuesKey        = "truncated_field_values"
+	searchResultMatchCountsKey            = "match_counts"
+	searchResultCostKey                   = "cost"
+	searchResultConsistencyDowngradedKey  = "consistency_downgraded"
+	searchResultEffectiveOffsetKey        = "effective_offset"
+	searchResultEffectiveLimitKey         = "effective_limit"
+	searchResultPartialResultsKey         = "partial_results"
+	searchResultCursorKey                 = "search_cursor"
+	searchResultFormatKey                 = "format"
+	searchResultPlanHashKey               = "plan_hash"
+	searchResultApproxDistinctCountKey    = "approx_distinct_count"
+	searchResultApproxDistinctRowsKey     = "approx_distinct_rows"
+	searchResultBM25ExplainKey            = "bm25_explain"
+	searchResultIndexInfoKey              = "index_info"
+	searchResultRerankSkippedKey          = "rerank_skipped"
+	searchResultHitCollectionsKey         = "hit_collections"
+	searchResultProcessedNqKey            = "processed_nq"
+	searchResultSchemaVersionKey          = "schema_version"
+	searchResultChannelMvccKey            = "channel_mvcc"
+	searchResultHasMoreKey                = "has_more"
+	searchResultPreviewTokensKey          = "preview_tokens"
+	searchResultPreviewPartitionsKey      = "preview_partitions"
+	searchResultFusionProvenanceKey       = "fusion_provenance"
+	searchResultQueryOffsetsKey           = "query_offsets"
+	searchResultDeadTermsKey              = "dead_terms"
+	searchResultExactnessKey              = "exactness"
+	searchResultDiversityKey              = "diversity"
+	searchResultRerankKey                 = "rerank"
+	searchResultOriginalDistancesValidKey = "original_distances_valid"
+
+	// partialResultsReduceRatio is the ratio of the remaining time reserved for reducing the partial results,
+	// the shards not responding before it are given up if partial_results_on_timeout is enabled.
+	partialResultsReduceRatio = 0.1
+	// adaptiveTopKCandidateRatio is the ratio of the candidates fetched to the topk if adaptive_topk is enabled,
+	// so that the elbow right after the topk could be seen as well.
+	adaptiveTopKCandidateRatio = 2
+	// maxSelfRecallCheckNq and maxSelfRecallCheckTopK bound the cost of the ground truth search of self_recall_check.
+	maxSelfRecallCheckNq   = 10
+	maxSelfRecallCheckTopK = 100
 )
 
 // type requery func(span trace.Span, ids *schemapb.IDs, outputFields []string) (*milvuspb.QueryResults, error)
@@ -89,11 +148,134 @@ type searchTask struct {
 	// New reranker functions
 	functionScore *rerank.FunctionScore
 	rankParams    *rankParams
+	// return the distances before rerank in the result, aligned with result rows
+	returnOriginalDistances bool
+	// results with lower rerank scores are dropped, nil if min_score is not specified
+	minScore *float32
+	// percentile of range_filter like "p90", resolved after the candidates are fetched, 0 if not specified
+	rangeFilterPercentile float64
 
 	isIterator bool
 	// we always remove pk field from output fields, as search result already contains pk field.
 	// if the user explicitly set pk field in output fields, we add it back to the result.
 	userRequestedPkFieldExplicitly bool
+	// vector output fields returned as placeholders without data, set if skip_vector_requery is enabled.
+	skippedVectorOutputFields []*schemapb.FieldSchema
+	// partition to search of each query row, set if partition_key_hints is specified.
+	rowPartitionIDs []int64
+	// token of the cached placeholder group, returned to clients to reference the placeholder group in the following searches.
+	placeholderGroupToken string
+	// report the fan-out stats in the result if with_search_stats is enabled.
+	withSearchStats bool
+	queriedChannels *typeutil.ConcurrentSet[string]
+	// client supplied request id to cancel the in-flight search, empty if not specified.
+	searchRequestID string
+	// the user issuing the search, only the user could cancel it by the request id.
+	searchRequestOwner string
+	inFlightCtx        context.Context
+	// only the ids and scores are returned, output fields are ignored, set by ids_scores_only.
+	idsScoresOnly bool
+	// the dynamic fields matching any of the prefixes are returned, set by the output fields like `$meta.user_*`.
+	dynamicFieldPrefixes []string
+	// fail the search if any of the dynamic field prefixes matches nothing, set by strict_dynamic_field_prefix.
+	strictDynamicFieldPrefix bool
+	// search all partitions ignoring the partition key routing, set by scan_all_partitions.
+	scanAllPartitions bool
+	// the scalar field values longer than it are truncated, 0 if max_field_bytes is not specified.
+	maxFieldBytes int
+	// only the number of hits of each query is returned if count_only is enabled. Topk is ignored,
+	// the hits are fetched up to the topk limit, so the counts are capped by it as well.
+	countOnly bool
+	// return ErrNoResults if none of the queries hits anything, set by error_on_empty.
+	errorOnEmpty bool
+	// returns the number of queued dql tasks, strong consistency searches are downgraded if it is too large.
+	dqQueueLen            func() int
+	consistencyDowngraded bool
+	// update timestamp of the collection schema the search is planned with, QueryNodes holding an older schema reject it.
+	schemaVersion uint64
+	// reduce the results arrived before the deadline instead of failing the search, set by partial_results_on_timeout.
+	partialResultsOnTimeout bool
+	// some of the shards did not respond in time, only the results arrived are reduced.
+	partialResults bool
+	// return the cursor of the next page in the result, set if search_cursor is specified.
+	withSearchCursor bool
+	// the position the page starts after, nil for the first page.
+	searchCursor *searchCursor
+	// partitions searched and reduced one by one, set if group_results_by_partition is enabled.
+	resultPartitionIDs []int64
+	// the partition each shard result comes from, only tracked if the results are grouped by partition.
+	resultPartitions *typeutil.ConcurrentMap[*internalpb.SearchResults, int64]
+	// encoding of the result field data, protobuf by default, set by format.
+	resultFormat string
+	// the query nodes the search is pinned to instead of the ones selected by the balancer, set by node_ids.
+	pinnedNodes []int64
+	// the query nodes of the resource group the search is directed to, set by resource_group.
+	resourceGroupNodes []int64
+	// the max number of hits of each query if adaptive_topk is enabled, the hits are cut at the elbow of the scores.
+	// 0 if adaptive_topk is disabled.
+	adaptiveTopK int64
+	// return the hashes of the compiled plans, one for each sub search of hybrid search, set by with_plan_hash.
+	withPlanHash bool
+	planHashes   []string
+	// the field to count the distinct values of over the hits, set by approx_distinct_field.
+	approxDistinctField *schemapb.FieldSchema
+	// the field the hits are sorted by instead of the score, set by sort_by.
+	sortByField *schemapb.FieldSchema
+	sortByDesc  bool
+	// the sort field is not one of the output fields, it is fetched only for sorting.
+	sortByFieldFetched bool
+	// mark the query terms in the text field of the hits, set by highlight.
+	highlight *searchHighlight
+	// the term frequencies of the query terms in the hits, set by explain_bm25.
+	explainBM25 *searchBM25Explain
+	// adjust the scores of the hits by a formula over their scalar fields, set by boost.
+	boost *searchBoost
+	// return the indexes of the anns fields searched, set by with_index_info.
+	withIndexInfo bool
+	// fall back to the scores before rerank if the rerank fails, set by rerank_best_effort.
+	rerankBestEffort bool
+	// the rerank failed and the hits are ordered by the scores before rerank.
+	rerankSkipped bool
+	// the float vectors returned are downcast to the half precision type, set by vector_precision.
+	vectorPrecision schemapb.DataType
+	// drop the hits older than the max age, set by max_result_age.
+	maxResultAge *searchMaxResultAge
+	// the time spent by PreExecute and Execute, logged along with PostExecute if the search is slow.
+	preExecuteSpan time.Duration
+	executeSpan    time.Duration
+	// return the mvcc timestamp of each channel searched, set by with_channel_mvcc.
+	withChannelMvcc bool
+	// report whether there are more hits beyond the topk, set by with_has_more. Unlike resultSizeInsufficient,
+	// which means fewer hits than the topk are found, it means the candidates are truncated at the topk.
+	withHasMore bool
+	// the analyzed tokens of each query text, set by preview_tokens. The search is not executed if it is set.
+	previewTokens [][]string
+	// search the sealed segments only, and fail if any growing segment is left out, set by sealed_only.
+	sealedOnly bool
+	// the partitions the partition key values in the filter are hashed to, set by preview_partitions.
+	// The search is not executed if it is set.
+	previewPartitions []string
+	// report the sub search requests each hit of hybrid search comes from, set by with_fusion_provenance.
+	withFusionProvenance bool
+	// the ranks of the hits of each query in the results of the sub search requests, collected by the rerank.
+	fusionContributions []map[any][]fusionContribution
+	// report the start index of the hits of each query in the result, set by with_query_offsets.
+	withQueryOffsets bool
+	// search only the partition picked by a pre-scan among the ones matched by the partition keys, set by top_partition_only.
+	topPartitionOnly bool
+	// the serialized plan of the pre-scan of top_partition_only.
+	topPartitionScanPlan []byte
+	// search the farthest vectors with the query vectors negated, set by mode=dissimilar.
+	dissimilar bool
+	// the terms of the term filters counted after the search, set by with_term_stats.
+	searchTerms []searchTerm
+	// return whether the hits of each query are exact or approximate, set by with_exactness.
+	withExactness bool
+	// return the average pairwise distance among the hits of each query, set by compute_diversity.
+	computeDiversity bool
+	// return a random sample of the hits of each query, set by sample and sample_seed, 0 if not sampled.
+	sampleRatio float64
+	sampleSeed  int64
 }
 
 func (t *searchTask) CanSkipAllocTimestamp() bool {
@@ -127,6 +309,10 @@ func (t *searchTask) CanSkipAllocTimestamp() bool {
 func (t *searchTask) PreExecute(ctx context.Context) error {
 	ctx, sp := otel.Tracer(typeutil.ProxyRole).Start(ctx, "Proxy-Search-PreExecute")
 	defer sp.End()
+	tr := timerecord.NewTimeRecorder("searchTask PreExecute")
+	defer func() {
+		t.preExecuteSpan = tr.ElapseSpan()
+	}()
 
 	t.SearchRequest.IsAdvanced = len(t.request.GetSubReqs()) > 0
 	t.Base.MsgType = commonpb.MsgType_Search
@@ -156,31 +342,92 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 	if t.partitionKeyMode && len(t.request.GetPartitionNames()) != 0 {
 		return errors.New("not support manually specifying the partition names if partition key mode is used")
 	}
+	if t.scanAllPartitions, err = t.parseScanAllPartitions(ctx); err != nil {
+		return err
+	}
 	if t.mustUsePartitionKey && !t.partitionKeyMode {
 		return merr.WrapErrAsInputError(merr.WrapErrParameterInvalidMsg("must use partition key in the search request " +
 			"because the mustUsePartitionKey config is true"))
 	}
 
 	if !t.partitionKeyMode && len(t.request.GetPartitionNames()) > 0 {
-		// translate partition name to partition ids. Use regex-pattern to match partition name.
-		t.SearchRequest.PartitionIDs, err = getPartitionIDs(ctx, t.request.GetDbName(), collectionName, t.request.GetPartitionNames())
+		// translate partition name to partition ids. Use regex-pattern to match partition name unless exact match is required.
+		useRegexp, err := parsePartitionNameRegexp(t.request.GetSearchParams())
+		if err != nil {
+			return err
+		}
+		t.SearchRequest.PartitionIDs, err = getPartitionIDsWithMatch(ctx, t.request.GetDbName(), collectionName, t.request.GetPartitionNames(), useRegexp)
 		if err != nil {
 			log.Warn("failed to get partition ids", zap.Error(err))
 			return err
 		}
 	}
 
-	t.translatedOutputFields, t.userOutputFields, t.userDynamicFields, t.userRequestedPkFieldExplicitly, err = translateOutputFields(t.request.OutputFields, t.schema, true)
+	if t.idsScoresOnly, err = getBoolSearchParam(t.request.GetSearchParams(), IDsScoresOnlyKey); err != nil {
+		return err
+	}
+	outputFields := t.request.GetOutputFields()
+	if t.idsScoresOnly {
+		// the output fields are ignored, so that neither field data nor requery is involved.
+		outputFields = nil
+	}
+	outputFields, dynamicFieldPrefixes, err := parseDynamicFieldPrefixes(outputFields, t.schema)
+	if err != nil {
+		return err
+	}
+	t.dynamicFieldPrefixes = dynamicFieldPrefixes
+	if t.strictDynamicFieldPrefix, err = getBoolSearchParam(t.request.GetSearchParams(), StrictDynamicFieldPrefix); err != nil {
+		return err
+	}
+	t.translatedOutputFields, t.userOutputFields, t.userDynamicFields, t.userRequestedPkFieldExplicitly, err = translateOutputFields(outputFields, t.schema, true)
 	if err != nil {
 		log.Warn("translate output fields failed", zap.Error(err), zap.Any("schema", t.schema))
 		return err
 	}
+	noDynamicFields, err := getBoolSearchParam(t.request.GetSearchParams(), NoDynamicFieldsKey)
+	if err != nil {
+		return err
+	}
+	if noDynamicFields {
+		// a predictable result schema, the dynamic field is never expanded.
+		t.translatedOutputFields, t.userOutputFields, err = dropDynamicOutputFields(t.request.GetOutputFields(),
+			t.translatedOutputFields, t.userOutputFields, t.userDynamicFields)
+		if err != nil {
+			return err
+		}
+	}
+	alwaysIncludePk, err := getBoolSearchParam(t.request.GetSearchParams(), AlwaysIncludePkKey)
+	if err != nil {
+		return err
+	}
+	// pk is added back to the output fields in PostExecute, only once even if it is requested explicitly as well.
+	t.userRequestedPkFieldExplicitly = (t.userRequestedPkFieldExplicitly || alwaysIncludePk) && !t.idsScoresOnly
 	log.Debug("translate output fields",
 		zap.Strings("output fields", t.translatedOutputFields))
 
 	if t.SearchRequest.GetIsAdvanced() {
-		if len(t.request.GetSubReqs()) > defaultMaxSearchRequest {
-			return errors.New(fmt.Sprintf("maximum of ann search requests is %d", defaultMaxSearchRequest))
+		maxSearchRequests, err := t.getMaxSearchRequests(ctx)
+		if err != nil {
+			return err
+		}
+		if len(t.request.GetSubReqs()) > maxSearchRequests {
+			return merr.WithReasonCode(merr.WrapErrParameterInvalidMsg("the number of ann search requests %d exceeds the maximum %d", len(t.request.GetSubReqs()), maxSearchRequests),
+				merr.ReasonTooManySearchRequests)
+		}
+	}
+
+	if err := t.resolveQueryVectorsURI(ctx); err != nil {
+		log.Warn("failed to resolve query vectors uri", zap.Error(err))
+		return err
+	}
+	if err := t.resolvePlaceholderGroupToken(ctx); err != nil {
+		log.Warn("failed to resolve placeholder group token", zap.Error(err))
+		return err
+	}
+	if t.SearchRequest.GetIsAdvanced() {
+		if err := t.resolvePlaceholderGroupRefs(); err != nil {
+			log.Warn("failed to resolve placeholder group references", zap.Error(err))
+			return err
 		}
 	}
 
@@ -194,6 +441,22 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 	if t.SearchRequest.IgnoreGrowing, err = isIgnoreGrowing(t.request.SearchParams); err != nil {
 		return err
 	}
+	if t.sealedOnly, err = getBoolSearchParam(t.request.GetSearchParams(), SealedOnlyKey); err != nil {
+		return err
+	}
+	if t.sealedOnly {
+		t.SearchRequest.IgnoreGrowing = true
+	}
+
+	if t.returnOriginalDistances, err = getBoolSearchParam(t.request.GetSearchParams(), ReturnOriginalDistancesKey); err != nil {
+		return err
+	}
+	if t.withFusionProvenance, err = getBoolSearchParam(t.request.GetSearchParams(), WithFusionProvenanceKey); err != nil {
+		return err
+	}
+	if t.withFusionProvenance && !t.SearchRequest.GetIsAdvanced() {
+		return merr.WrapErrParameterInvalidMsg("%s is only supported by hybrid search", WithFusionProvenanceKey)
+	}
 
 	outputFieldIDs, err := getOutputFieldIDs(t.schema, t.translatedOutputFields)
 	if err != nil {
@@ -202,6 +465,22 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 	}
 	t.SearchRequest.OutputFieldsId = outputFieldIDs
 
+	if err := t.applyCollectionDefaultSearchParams(ctx); err != nil {
+		return err
+	}
+	if err := t.applySearchCursor(); err != nil {
+		return err
+	}
+	if t.withPlanHash, err = getBoolSearchParam(t.request.GetSearchParams(), WithPlanHashKey); err != nil {
+		return err
+	}
+	if err := t.parseSortBy(); err != nil {
+		return err
+	}
+	if t.maxResultAge, err = t.parseMaxResultAge(); err != nil {
+		return err
+	}
+
 	// Currently, we get vectors by requery. Once we support getting vectors from search,
 	// searches with small result size could no longer need requery.
 	if t.SearchRequest.GetIsAdvanced() {
@@ -213,6 +492,71 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 		log.Debug("init search request failed", zap.Error(err))
 		return err
 	}
+	if t.minScore, err = t.parseMinScore(); err != nil {
+		return err
+	}
+	if t.sampleRatio, t.sampleSeed, err = t.parseSample(); err != nil {
+		return err
+	}
+	if t.rerankBestEffort, err = getBoolSearchParam(t.request.GetSearchParams(), RerankBestEffortKey); err != nil {
+		return err
+	}
+	if t.rerankBestEffort && t.functionScore == nil {
+		return merr.WrapErrParameterInvalidMsg("%s only works with rerank", RerankBestEffortKey)
+	}
+
+	if t.withSearchStats, err = getBoolSearchParam(t.request.GetSearchParams(), WithSearchStatsKey); err != nil {
+		return err
+	}
+	if t.withIndexInfo, err = getBoolSearchParam(t.request.GetSearchParams(), WithIndexInfoKey); err != nil {
+		return err
+	}
+	if t.withChannelMvcc, err = getBoolSearchParam(t.request.GetSearchParams(), WithChannelMvccKey); err != nil {
+		return err
+	}
+	if t.withHasMore, err = getBoolSearchParam(t.request.GetSearchParams(), WithHasMoreKey); err != nil {
+		return err
+	}
+	if t.withHasMore && hasGroupBy(t.SearchRequest.GetGroupByFieldId()) {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by grouping search", WithHasMoreKey)
+	}
+	if t.withQueryOffsets, err = getBoolSearchParam(t.request.GetSearchParams(), WithQueryOffsetsKey); err != nil {
+		return err
+	}
+	if t.withExactness, err = getBoolSearchParam(t.request.GetSearchParams(), WithExactnessKey); err != nil {
+		return err
+	}
+	if t.computeDiversity, err = t.parseComputeDiversity(); err != nil {
+		return err
+	}
+	t.searchRequestID, _ = funcutil.GetAttrByKeyFromRepeatedKV(SearchRequestIDKey, t.request.GetSearchParams())
+	if t.maxFieldBytes, err = parseMaxFieldBytes(t.request.GetSearchParams()); err != nil {
+		return err
+	}
+	if t.vectorPrecision, err = parseVectorPrecision(t.request.GetSearchParams()); err != nil {
+		return err
+	}
+	if t.errorOnEmpty, err = getBoolSearchParam(t.request.GetSearchParams(), ErrorOnEmptyKey); err != nil {
+		return err
+	}
+	if err := t.parseGroupResultsByPartition(ctx); err != nil {
+		return err
+	}
+	if t.partialResultsOnTimeout, err = getBoolSearchParam(t.request.GetSearchParams(), PartialResultsOnTimeoutKey); err != nil {
+		return err
+	}
+	if t.resultFormat, err = parseSearchResultFormat(t.request.GetSearchParams()); err != nil {
+		return err
+	}
+	if t.pinnedNodes, err = t.parsePinnedNodes(ctx); err != nil {
+		return err
+	}
+	if t.resourceGroupNodes, err = t.parseResourceGroup(ctx); err != nil {
+		return err
+	}
+	if t.approxDistinctField, err = t.parseApproxDistinctField(); err != nil {
+		return err
+	}
 
 	collectionInfo, err2 := globalMetaCache.GetCollectionInfo(ctx, t.request.GetDbName(), collectionName, t.CollectionID)
 	if err2 != nil {
@@ -224,7 +568,7 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 	var consistencyLevel commonpb.ConsistencyLevel
 	useDefaultConsistency := t.request.GetUseDefaultConsistency()
 	if useDefaultConsistency {
-		consistencyLevel = collectionInfo.consistencyLevel
+		consistencyLevel = t.downgradeConsistencyUnderLoad(ctx, collectionInfo.consistencyLevel)
 		guaranteeTs = parseGuaranteeTsFromConsistency(guaranteeTs, t.BeginTs(), consistencyLevel)
 	} else {
 		consistencyLevel = t.request.GetConsistencyLevel()
@@ -232,6 +576,7 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 		if consistencyLevel == 0 && guaranteeTs > 0 {
 			guaranteeTs = parseGuaranteeTs(guaranteeTs, t.BeginTs())
 		} else {
+			consistencyLevel = t.downgradeConsistencyUnderLoad(ctx, consistencyLevel)
 			// parse from guarantee timestamp and user input consistency level
 			guaranteeTs = parseGuaranteeTsFromConsistency(guaranteeTs, t.BeginTs(), consistencyLevel)
 		}
@@ -243,6 +588,8 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 	if collectionInfo.updateTimestamp > guaranteeTs {
 		guaranteeTs = collectionInfo.updateTimestamp
 	}
+	t.schemaVersion = collectionInfo.updateTimestamp
+	t.setRequestProperty(common.SearchSchemaVersionKey, strconv.FormatUint(t.schemaVersion, 10))
 
 	t.SearchRequest.GuaranteeTimestamp = guaranteeTs
 	t.SearchRequest.ConsistencyLevel = consistencyLevel
@@ -257,9 +604,23 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 	}
 
 	// Set username of this search request for feature like task scheduling.
-	if username, _ := GetCurUserFromContext(ctx); username != "" {
+	username, _ := GetCurUserFromContext(ctx)
+	impersonatedUser, err := t.parseImpersonateUser(ctx)
+	if err != nil {
+		return err
+	}
+	if impersonatedUser != "" {
+		username = impersonatedUser
+	}
+	if username != "" {
 		t.SearchRequest.Username = username
 	}
+	// Set priority of this search request for QueryNode schedulers to prioritize the interactive searches.
+	if priority, ok := contextutil.SearchPriority(ctx); ok {
+		if err := t.setSearchPriority(priority); err != nil {
+			return err
+		}
+	}
 
 	if collectionInfo.collectionTTL != 0 {
 		physicalTime := tsoutil.PhysicalTime(t.GetBase().GetTimestamp())
@@ -272,6 +633,7 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 	}
 
 	t.resultBuf = typeutil.NewConcurrentSet[*internalpb.SearchResults]()
+	t.queriedChannels = typeutil.NewConcurrentSet[string]()
 
 	if err = ValidateTask(t); err != nil {
 		return err
@@ -286,6 +648,182 @@ func (t *searchTask) PreExecute(ctx context.Context) error {
 	return nil
 }
 
+// isScorePositivelyRelated returns whether the larger scores of the results are the better.
+func (t *searchTask) isScorePositivelyRelated(toReduceResults []*internalpb.SearchResults) bool {
+	// rerank scores are always the larger the better, so are the scores fused by the fallback of hybrid search
+	if t.functionScore != nil && (!t.rerankSkipped || t.SearchRequest.GetIsAdvanced()) {
+		return true
+	}
+	return metric.PositivelyRelated(getMetricType(toReduceResults))
+}
+
+// sanitizeInvalidScores handles the NaN and Inf scores which break the serialization of clients.
+func (t *searchTask) sanitizeInvalidScores(toReduceResults []*internalpb.SearchResults) {
+	drop := Params.ProxyCfg.DropInvalidSearchScores.GetAsBool()
+	if numInvalid := sanitizeInvalidScores(t.result.GetResults(), t.isScorePositivelyRelated(toReduceResults), drop); numInvalid > 0 {
+		log.Ctx(t.ctx).Warn("search results contain NaN or Inf scores",
+			zap.Int64("collectionID", t.GetCollectionID()),
+			zap.String("metricType", getMetricType(toReduceResults)),
+			zap.Int("count", numInvalid),
+			zap.Bool("dropped", drop))
+	}
+}
+
+// parseMinScore parses the threshold of the rerank scores, which is applied after the results are fused.
+func (t *searchTask) parseMinScore() (*float32, error) {
+	minScoreStr, err := funcutil.GetAttrByKeyFromRepeatedKV(MinScoreKey, t.request.GetSearchParams())
+	if err != nil {
+		return nil, nil
+	}
+	minScore, err := strconv.ParseFloat(minScoreStr, 32)
+	if err != nil || math.IsNaN(minScore) {
+		return nil, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a float number", MinScoreKey, minScoreStr)
+	}
+	if t.functionScore == nil {
+		return nil, merr.WrapErrParameterInvalidMsg("%s only works with rerank, use %s to filter the distances of a search without rerank",
+			MinScoreKey, rangeFilterKey)
+	}
+	if hasGroupBy(t.SearchRequest.GetGroupByFieldId()) {
+		// a group may be partially dropped, it is ambiguous whether the group size shall be kept or not
+		return nil, merr.WrapErrParameterInvalidMsg("%s is not supported with grouping search", MinScoreKey)
+	}
+	ret := float32(minScore)
+	return &ret, nil
+}
+
+// parseSample parses the ratio of the hits of each query to sample and the seed of the sampling, the seed is 0 if not
+// specified. The same seed samples the same hits of the same results, so that the previews could be reproduced.
+func (t *searchTask) parseSample() (float64, int64, error) {
+	ratioStr, err := funcutil.GetAttrByKeyFromRepeatedKV(SampleKey, t.request.GetSearchParams())
+	if err != nil {
+		return 0, 0, nil
+	}
+	ratio, err := strconv.ParseFloat(ratioStr, 64)
+	if err != nil || !(ratio > 0 && ratio <= 1) {
+		return 0, 0, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a float number in (0, 1]", SampleKey, ratioStr)
+	}
+	if t.isIterator {
+		return 0, 0, merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator", SampleKey)
+	}
+	if hasGroupBy(t.SearchRequest.GetGroupByFieldId()) {
+		// a group may be partially dropped, it is ambiguous whether the group size shall be kept or not
+		return 0, 0, merr.WrapErrParameterInvalidMsg("%s is not supported with grouping search", SampleKey)
+	}
+	var seed int64
+	if seedStr, err := funcutil.GetAttrByKeyFromRepeatedKV(SampleSeedKey, t.request.GetSearchParams()); err == nil {
+		if seed, err = strconv.ParseInt(seedStr, 10, 64); err != nil {
+			return 0, 0, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be an integer", SampleSeedKey, seedStr)
+		}
+	}
+	return ratio, seed, nil
+}
+
+// downgradeConsistencyUnderLoad downgrades strong consistency to bounded consistency if proxy is overloaded,
+// the searches don't wait for the latest data to be consumed by query nodes then.
+func (t *searchTask) downgradeConsistencyUnderLoad(ctx context.Context, consistencyLevel commonpb.ConsistencyLevel) commonpb.ConsistencyLevel {
+	threshold := Params.ProxyCfg.ConsistencyDowngradeQueueLen.GetAsInt()
+	// iterators rely on the guarantee timestamp to keep a consistent view across pages
+	if threshold <= 0 || consistencyLevel != commonpb.ConsistencyLevel_Strong || t.isIterator || t.dqQueueLen == nil {
+		return consistencyLevel
+	}
+	queueLen := t.dqQueueLen()
+	if queueLen < threshold {
+		return consistencyLevel
+	}
+	log.Ctx(ctx).Info("downgrade strong consistency search to bounded as proxy is overloaded",
+		zap.Int("queueLen", queueLen), zap.Int("threshold", threshold))
+	t.consistencyDowngraded = true
+	return commonpb.ConsistencyLevel_Bounded
+}
+
+// resolvePlaceholderGroupToken replaces the placeholder group of the request with the cached one referenced by token,
+// or caches the placeholder group of the request if cache_placeholder_group is enabled.
+// It shall be called before checkNq, as nq may be derived from the placeholder group.
+func (t *searchTask) resolvePlaceholderGroupToken(ctx context.Context) error {
+	token, err := funcutil.GetAttrByKeyFromRepeatedKV(PlaceholderGroupTokenKey, t.request.GetSearchParams())
+	hasToken := err == nil
+	cachePlaceholderGroup, err := getBoolSearchParam(t.request.GetSearchParams(), CachePlaceholderGroupKey)
+	if err != nil {
+		return err
+	}
+	if !hasToken && !cachePlaceholderGroup {
+		return nil
+	}
+	if t.SearchRequest.GetIsAdvanced() {
+		return merr.WrapErrParameterInvalidMsg("%s and %s are not supported by hybrid search", PlaceholderGroupTokenKey, CachePlaceholderGroupKey)
+	}
+	cache := getPlaceholderGroupCache()
+	if cache == nil {
+		return merr.WrapErrParameterInvalidMsg("placeholder group cache is disabled, please enable proxy.placeholderGroupCache first")
+	}
+
+	username := GetCurUserFromContextOrDefault(ctx)
+	if hasToken {
+		if len(t.request.GetPlaceholderGroup()) > 0 {
+			return merr.WrapErrParameterInvalidMsg("placeholder group shall be empty if %s is specified", PlaceholderGroupTokenKey)
+		}
+		placeholderGroup, err := cache.get(username, t.GetCollectionID(), token)
+		if err != nil {
+			return err
+		}
+		t.request.PlaceholderGroup = placeholderGroup
+		t.placeholderGroupToken = token
+		return nil
+	}
+	t.placeholderGroupToken, err = cache.add(username, t.GetCollectionID(), t.request.GetPlaceholderGroup())
+	return err
+}
+
+// resolvePlaceholderGroupRefs shares the placeholder group of a sub search request with the ones referencing it
+// by placeholder_group_ref, so that the query vectors fed to multiple fields are uploaded only once.
+// The sub search request referencing a placeholder group shall specify its anns_field, which is checked to be
+// compatible with the query vectors. It shall be called before checkNq, as nq may be derived from the placeholder group.
+func (t *searchTask) resolvePlaceholderGroupRefs() error {
+	subReqs := t.request.GetSubReqs()
+	for index, subReq := range subReqs {
+		refStr, err := funcutil.GetAttrByKeyFromRepeatedKV(PlaceholderGroupRefKey, subReq.GetSearchParams())
+		if err != nil {
+			continue
+		}
+		ref, err := strconv.Atoi(refStr)
+		if err != nil || ref < 0 || ref >= len(subReqs) || ref == index {
+			return merr.WrapErrParameterInvalidMsg("%s [%s] of sub search request %d is invalid, should be the index of another sub search request",
+				PlaceholderGroupRefKey, refStr, index)
+		}
+		if len(subReq.GetPlaceholderGroup()) > 0 {
+			return merr.WrapErrParameterInvalidMsg("placeholder group of sub search request %d shall be empty if %s is specified", index, PlaceholderGroupRefKey)
+		}
+		// only the uploaded placeholder groups could be referenced, no chained references
+		if _, err := funcutil.GetAttrByKeyFromRepeatedKV(PlaceholderGroupRefKey, subReqs[ref].GetSearchParams()); err == nil || len(subReqs[ref].GetPlaceholderGroup()) == 0 {
+			return merr.WrapErrParameterInvalidMsg("sub search request %d referenced by sub search request %d has no placeholder group", ref, index)
+		}
+		annsField, err := funcutil.GetAttrByKeyFromRepeatedKV(AnnsFieldKey, subReq.GetSearchParams())
+		if err != nil {
+			return merr.WrapErrParameterInvalidMsg("%s of sub search request %d shall be specified if %s is specified", AnnsFieldKey, index, PlaceholderGroupRefKey)
+		}
+		field := typeutil.GetFieldByName(t.schema.CollectionSchema, annsField)
+		if field == nil {
+			return merr.WrapErrFieldNotFound(annsField, fmt.Sprintf("%s of sub search request %d not found in schema", AnnsFieldKey, index))
+		}
+		if err := checkPlaceholderGroupDim(field, subReqs[ref].GetPlaceholderGroup()); err != nil {
+			return err
+		}
+		subReq.PlaceholderGroup = subReqs[ref].GetPlaceholderGroup()
+		subReq.Nq = subReqs[ref].GetNq()
+	}
+	return nil
+}
+
+// checkQueryVectorsDim fails the search early with a clear error if the query vectors do not match the anns field,
+// instead of leaving it to the query nodes.
+func (t *searchTask) checkQueryVectorsDim(fieldID int64, placeholderGroup []byte) error {
+	field := typeutil.GetField(t.schema.CollectionSchema, fieldID)
+	if field == nil {
+		return nil
+	}
+	return checkPlaceholderGroupDim(field, placeholderGroup)
+}
+
 func (t *searchTask) checkNq(ctx context.Context) (int64, error) {
 	var nq int64
 	if t.SearchRequest.GetIsAdvanced() {
@@ -303,7 +841,7 @@ func (t *searchTask) checkNq(ctx context.Context) (int64, error) {
 				continue
 			}
 			if subNq != nq {
-				err = merr.WrapErrParameterInvalid(nq, subNq, "sub search request nq should be the same")
+				err = merr.WithReasonCode(merr.WrapErrParameterInvalid(nq, subNq, "sub search request nq should be the same"), merr.ReasonNqMismatch)
 				return 0, err
 			}
 		}
@@ -316,11 +854,19 @@ func (t *searchTask) checkNq(ctx context.Context) (int64, error) {
 		}
 		t.request.Nq = nq
 	}
+	if nq == 0 {
+		// an empty placeholder group, nothing to search.
+		return 0, merr.WithReasonCode(merr.WrapErrParameterInvalidMsg("no query vectors provided"), merr.ReasonNqInvalid)
+	}
 
 	// Check if nq is valid:
 	// https://milvus.io/docs/limitations.md
 	if err := validateNQLimit(nq); err != nil {
-		return 0, fmt.Errorf("%s [%d] is invalid, %w", NQKey, nq, err)
+		reason := merr.ReasonNqTooLarge
+		if nq <= 0 {
+			reason = merr.ReasonNqInvalid
+		}
+		return 0, merr.WithReasonCode(fmt.Errorf("%s [%d] is invalid, %w", NQKey, nq, err), reason)
 	}
 	return nq, nil
 }
@@ -366,6 +912,52 @@ func (t *searchTask) initAdvancedSearchRequest(ctx context.Context) error {
 	t.partitionIDsSet = typeutil.NewConcurrentSet[UniqueID]()
 	log := log.Ctx(ctx).With(zap.Int64("collID", t.GetCollectionID()), zap.String("collName", t.collectionName))
 	var err error
+	if countOnly, err := getBoolSearchParam(t.request.GetSearchParams(), CountOnlyKey); err != nil {
+		return err
+	} else if countOnly {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", CountOnlyKey)
+	}
+	if adaptiveTopK, err := getBoolSearchParam(t.request.GetSearchParams(), AdaptiveTopKKey); err != nil {
+		return err
+	} else if adaptiveTopK {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", AdaptiveTopKKey)
+	}
+	// the iterator is only wired for single searches, the results of the sub searches could not be iterated.
+	if isIterator, err := getBoolSearchParam(t.request.GetSearchParams(), IteratorField); err != nil {
+		return err
+	} else if isIterator {
+		return merr.WrapErrParameterInvalidMsg("search iterator is not supported by hybrid search")
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(HighlightKey, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", HighlightKey)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(ExplainBM25Key, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", ExplainBM25Key)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(SelfRecallCheckKey, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", SelfRecallCheckKey)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(BoostKey, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", BoostKey)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(WithHasMoreKey, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", WithHasMoreKey)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(TopPartitionOnlyKey, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", TopPartitionOnlyKey)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(WithTermStatsKey, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", WithTermStatsKey)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(SearchModeKey, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", SearchModeKey)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(PreviewTokensKey, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", PreviewTokensKey)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(PreviewPartitionsKey, t.request.GetSearchParams()); err == nil {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", PreviewPartitionsKey)
+	}
 	// TODO: Use function score uniformly to implement related logic
 	if t.request.FunctionScore != nil {
 		if t.functionScore, err = rerank.NewFunctionScore(t.schema.CollectionSchema, t.request.FunctionScore); err != nil {
@@ -379,25 +971,49 @@ func (t *searchTask) initAdvancedSearchRequest(ctx context.Context) error {
 		}
 	}
 
-	t.needRequery = len(t.request.OutputFields) > 0 || len(t.functionScore.GetAllInputFieldNames()) > 0
+	if t.idsScoresOnly && len(t.functionScore.GetAllInputFieldNames()) > 0 {
+		return merr.WrapErrParameterInvalidMsg("%s is not supported with the rerank depending on field data", IDsScoresOnlyKey)
+	}
+	t.needRequery = !t.idsScoresOnly && (len(t.request.OutputFields) > 0 || len(t.functionScore.GetAllInputFieldNames()) > 0) ||
+		t.sortByFieldFetched
 
 	if t.rankParams, err = parseRankParams(t.request.GetSearchParams(), t.schema.CollectionSchema); err != nil {
 		log.Error("parseRankParams failed", zap.Error(err))
 		return err
 	}
 
-	if !t.functionScore.IsSupportGroup() && t.rankParams.GetGroupByFieldId() >= 0 {
-		return merr.WrapErrParameterInvalidMsg("Current rerank does not support grouping search")
+	if err := checkRerankGroupBySupport(t.functionScore, t.rankParams.GetGroupByFieldId()); err != nil {
+		return err
+	}
+
+	fieldMetricTypes, err := globalMetaCache.GetIndexMetricTypes(ctx, t.GetCollectionID())
+	if err != nil {
+		// QueryNodes check the metric types as well, so the search is not failed here.
+		log.Warn("failed to get metric types of indexes, skip validating the metric types of sub search requests", zap.Error(err))
 	}
 
 	t.SearchRequest.SubReqs = make([]*internalpb.SubSearchRequest, len(t.request.GetSubReqs()))
 	t.queryInfos = make([]*planpb.QueryInfo, len(t.request.GetSubReqs()))
 	queryFieldIDs := []int64{}
+	commonExpr, err := t.parseCommonFilter()
+	if err != nil {
+		return err
+	}
 	for index, subReq := range t.request.GetSubReqs() {
-		plan, queryInfo, offset, _, err := t.tryGeneratePlan(subReq.GetSearchParams(), subReq.GetDsl(), subReq.GetExprTemplateValues())
+		plan, queryInfo, offset, isIterator, err := t.tryGeneratePlan(subReq.GetSearchParams(), subReq.GetDsl(), subReq.GetExprTemplateValues())
 		if err != nil {
 			return err
 		}
+		if isIterator {
+			return merr.WrapErrParameterInvalidMsg("search iterator is not supported by the sub search requests of hybrid search")
+		}
+		if commonExpr != nil {
+			vectorAnns := plan.GetVectorAnns()
+			vectorAnns.Predicates = andCommonFilter(commonExpr, vectorAnns.GetPredicates())
+		}
+		if err := validateSearchMetricType(t.schema, queryInfo.GetQueryFieldId(), queryInfo.GetMetricType(), fieldMetricTypes); err != nil {
+			return err
+		}
 
 		ignoreGrowing := t.SearchRequest.IgnoreGrowing
 		if !ignoreGrowing {
@@ -429,9 +1045,12 @@ func (t *searchTask) initAdvancedSearchRequest(ctx context.Context) error {
 		}
 
 		internalSubReq.FieldId = queryInfo.GetQueryFieldId()
+		if err := t.checkQueryVectorsDim(internalSubReq.FieldId, internalSubReq.GetPlaceholderGroup()); err != nil {
+			return err
+		}
 		queryFieldIDs = append(queryFieldIDs, internalSubReq.FieldId)
 		// set PartitionIDs for sub search
-		if t.partitionKeyMode {
+		if t.partitionKeyMode && !t.scanAllPartitions {
 			// isolation has tighter constraint, check first
 			mvErr := setQueryInfoIfMvEnable(queryInfo, t, plan)
 			if mvErr != nil {
@@ -463,7 +1082,11 @@ func (t *searchTask) initAdvancedSearchRequest(ctx context.Context) error {
 		t.queryInfos[index] = queryInfo
 		log.Debug("proxy init search request",
 			zap.Int64s("plan.OutputFieldIds", plan.GetOutputFieldIds()),
-			zap.Stringer("plan", plan)) // may be very large if large term passed.
+			zap.Stringer("plan", truncatedStringer{plan})) // may be very large if large term passed.
+	}
+
+	if err := t.checkDuplicateAnnsFields(ctx, queryFieldIDs); err != nil {
+		return err
 	}
 
 	if function.HasNonBM25Functions(t.schema.CollectionSchema.Functions, queryFieldIDs) {
@@ -483,15 +1106,49 @@ func (t *searchTask) initAdvancedSearchRequest(ctx context.Context) error {
 	t.SearchRequest.GroupByFieldId = t.rankParams.GetGroupByFieldId()
 	t.SearchRequest.GroupSize = t.rankParams.GetGroupSize()
 
-	if t.partitionKeyMode {
+	if t.partitionKeyMode && !t.scanAllPartitions {
 		t.SearchRequest.PartitionIDs = t.partitionIDsSet.Collect()
+		// sub searches may hit different partitions, check the union of them as well.
+		if err := checkPartitionKeyFanout(len(t.SearchRequest.PartitionIDs)); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// checkDuplicateAnnsFields checks whether multiple sub searches target the same anns field, it's usually a mistake
+// which counts the scores of the field more than once in the fusion. The sub searches may legitimately differ in filters,
+// so it's only rejected if strict_anns_fields is enabled, otherwise a warning is logged.
+func (t *searchTask) checkDuplicateAnnsFields(ctx context.Context, queryFieldIDs []int64) error {
+	strict, err := getBoolSearchParam(t.request.GetSearchParams(), StrictAnnsFieldsKey)
+	if err != nil {
+		return err
+	}
+	duplicated := lo.FindDuplicates(queryFieldIDs)
+	if len(duplicated) == 0 {
+		return nil
+	}
+	fieldNames := lo.Map(duplicated, func(fieldID int64, _ int) string {
+		if field, err := t.schema.schemaHelper.GetFieldFromID(fieldID); err == nil {
+			return field.GetName()
+		}
+		return strconv.FormatInt(fieldID, 10)
+	})
+	if strict {
+		return merr.WrapErrParameterInvalidMsg("multiple sub searches target the same anns fields %v", fieldNames)
+	}
+	log.Ctx(ctx).Warn("multiple sub searches target the same anns fields, the scores may be counted more than once",
+		zap.Strings("fields", fieldNames))
+	return nil
+}
+
 func (t *searchTask) fillResult() {
 	limit := t.SearchRequest.GetTopk() - t.SearchRequest.GetOffset()
+	if t.adaptiveTopK > 0 {
+		// the topk is inflated to fetch the candidates beyond the elbow, the user's one is the limit.
+		limit = t.adaptiveTopK
+	}
 	resultSizeInsufficient := false
 	for _, topk := range t.result.Results.Topks {
 		if topk < limit {
@@ -499,77 +1156,464 @@ func (t *searchTask) fillResult() {
 			break
 		}
 	}
-	t.resultSizeInsufficient = resultSizeInsufficient
+	// the hits are cut at the elbow on purpose if adaptive_topk is enabled, retrying the search fetches no more of them.
+	t.resultSizeInsufficient = resultSizeInsufficient && t.adaptiveTopK == 0
 	t.result.CollectionName = t.collectionName
-}
 
-func (t *searchTask) initSearchRequest(ctx context.Context) error {
-	ctx, sp := otel.Tracer(typeutil.ProxyRole).Start(ctx, "init search request")
-	defer sp.End()
+	// the window applied may differ from the requested one, e.g. the topk of count_only searches, report the actual one.
+	offset := t.SearchRequest.GetOffset()
+	if t.SearchRequest.GetIsAdvanced() {
+		offset, limit = t.rankParams.GetOffset(), t.rankParams.GetLimit()
+	}
+	setSearchResultExtraInfo(t.result, searchResultEffectiveOffsetKey, strconv.FormatInt(offset, 10))
+	setSearchResultExtraInfo(t.result, searchResultEffectiveLimitKey, strconv.FormatInt(limit, 10))
+}
 
-	log := log.Ctx(ctx).With(zap.Int64("collID", t.GetCollectionID()), zap.String("collName", t.collectionName))
+// willRetryForInsufficientResult returns whether the search will be retried without topk reduce,
+// as the result size is insufficient.
+func (t *searchTask) willRetryForInsufficientResult() bool {
+	return t.resultSizeInsufficient && t.isTopkReduce && t.SearchRequest.GetIsTopkReduce() &&
+		paramtable.Get().AutoIndexConfig.EnableResultLimitCheck.GetAsBool()
+}
 
-	plan, queryInfo, offset, isIterator, err := t.tryGeneratePlan(t.request.GetSearchParams(), t.request.GetDsl(), t.request.GetExprTemplateValues())
+// fillCountOnlyResult replaces the hits with the number of hits of each query.
+func (t *searchTask) fillCountOnlyResult() error {
+	results := t.result.GetResults()
+	counts, err := json.Marshal(results.GetTopks())
 	if err != nil {
 		return err
 	}
+	setSearchResultExtraInfo(t.result, searchResultMatchCountsKey, string(counts))
 
-	if t.request.FunctionScore != nil {
-		if t.functionScore, err = rerank.NewFunctionScore(t.schema.CollectionSchema, t.request.FunctionScore); err != nil {
-			log.Warn("Failed to create function score", zap.Error(err))
-			return err
-		}
+	ids := &schemapb.IDs{}
+	if results.GetIds().GetStrId() != nil {
+		ids.IdField = &schemapb.IDs_StrId{StrId: &schemapb.StringArray{}}
+	} else {
+		ids.IdField = &schemapb.IDs_IntId{IntId: &schemapb.LongArray{}}
+	}
+	t.result.Results = &schemapb.SearchResultData{
+		NumQueries:       results.GetNumQueries(),
+		Topks:            make([]int64, len(results.GetTopks())),
+		Ids:              ids,
+		PrimaryFieldName: results.GetPrimaryFieldName(),
+	}
+	// no hit is returned, it is meaningless to retry for more hits.
+	t.resultSizeInsufficient = false
+	return nil
+}
 
-		if !t.functionScore.IsSupportGroup() && queryInfo.GetGroupByFieldId() > 0 {
-			return merr.WrapErrParameterInvalidMsg("Rerank %s does not support grouping search", t.functionScore.RerankName())
-		}
+// fillSearchStats reports how many channels are queried and how many of them return results,
+// which helps to correlate the latency with the fan-out width.
+func (t *searchTask) fillSearchStats(toReduceResults []*internalpb.SearchResults) {
+	nonEmptyChannels := lo.CountBy(toReduceResults, func(result *internalpb.SearchResults) bool {
+		return result.GetSlicedBlob() != nil
+	})
+	queriedChannels := 0
+	if t.queriedChannels != nil {
+		queriedChannels = len(t.queriedChannels.Collect())
+	}
+	setSearchResultExtraInfo(t.result, searchResultQueriedChannelsKey, strconv.Itoa(queriedChannels))
+	setSearchResultExtraInfo(t.result, searchResultNonEmptyChannelsKey, strconv.Itoa(nonEmptyChannels))
+	cost, err := json.Marshal(aggregateSearchCost(toReduceResults))
+	if err != nil {
+		log.Warn("failed to marshal search cost", zap.Error(err))
+		return
 	}
+	setSearchResultExtraInfo(t.result, searchResultCostKey, string(cost))
+}
 
-	t.isIterator = isIterator
-	t.SearchRequest.Offset = offset
-	t.SearchRequest.FieldId = queryInfo.GetQueryFieldId()
+// fillChannelMvcc reports the mvcc timestamp of each channel searched, i.e. the snapshot of each shard
+// the results reflect, as a JSON object of the channel names to the timestamps.
+func (t *searchTask) fillChannelMvcc() {
+	bs, err := json.Marshal(t.queryChannelsTs)
+	if err != nil {
+		log.Warn("failed to marshal channel mvcc timestamps", zap.Error(err))
+		return
+	}
+	setSearchResultExtraInfo(t.result, searchResultChannelMvccKey, string(bs))
+}
 
-	if t.partitionKeyMode {
-		// isolation has tighter constraint, check first
-		mvErr := setQueryInfoIfMvEnable(queryInfo, t, plan)
-		if mvErr != nil {
-			return mvErr
-		}
-		partitionIDs, err2 := t.tryParsePartitionIDsFromPlan(plan)
-		if err2 != nil {
-			return err2
-		}
-		if len(partitionIDs) > 0 {
-			t.SearchRequest.PartitionIDs = partitionIDs
-		}
+// fillQueryOffsets reports the start index of the hits of each query in the result arrays as a JSON array,
+// i.e. the prefix sums of the topks, so that clients need not compute the boundaries of the queries themselves.
+func (t *searchTask) fillQueryOffsets() {
+	topks := t.result.GetResults().GetTopks()
+	offsets := make([]int64, len(topks))
+	var offset int64
+	for i, topk := range topks {
+		offsets[i] = offset
+		offset += topk
+	}
+	bs, err := json.Marshal(offsets)
+	if err != nil {
+		log.Warn("failed to marshal query offsets", zap.Error(err))
+		return
+	}
+	setSearchResultExtraInfo(t.result, searchResultQueryOffsetsKey, string(bs))
+}
+
+// fillOriginalDistancesValidity reports whether each original distance is valid as a JSON array aligned with the distances,
+// the invalid ones are missingOriginalDistance. It shall be called after the hits are filtered and reordered.
+func (t *searchTask) fillOriginalDistancesValidity() {
+	valid := lo.Map(t.result.GetResults().GetDistances(), func(distance float32, _ int) bool {
+		return distance != missingOriginalDistance
+	})
+	bs, err := json.Marshal(valid)
+	if err != nil {
+		log.Warn("failed to marshal the validity of original distances", zap.Error(err))
+		return
+	}
+	setSearchResultExtraInfo(t.result, searchResultOriginalDistancesValidKey, string(bs))
+}
+
+// fillFusionProvenance reports the sub search requests each hit of hybrid search comes from and its ranks there,
+// as a JSON array aligned with the hits. It is looked up by the ids, as the hits may be filtered or reordered after rerank.
+func (t *searchTask) fillFusionProvenance() {
+	results := t.result.GetResults()
+	provenance := make([][]fusionContribution, 0, len(results.GetScores()))
+	var offset int64
+	for q, topk := range results.GetTopks() {
+		for j := offset; j < offset+topk; j++ {
+			contributions := []fusionContribution{}
+			if q < len(t.fusionContributions) {
+				contributions = append(contributions, t.fusionContributions[q][typeutil.GetPK(results.GetIds(), j)]...)
+			}
+			provenance = append(provenance, contributions)
+		}
+		offset += topk
+	}
+	bs, err := json.Marshal(provenance)
+	if err != nil {
+		log.Warn("failed to marshal fusion provenance", zap.Error(err))
+		return
+	}
+	setSearchResultExtraInfo(t.result, searchResultFusionProvenanceKey, string(bs))
+}
+
+// rerankInfo is the reranker applied to the search, the weights are the ones of the sub search requests if weighted.
+type rerankInfo struct {
+	Name    string            `json:"name"`
+	Legacy  bool              `json:"legacy"`
+	Params  map[string]string `json:"params"`
+	Weights []float32         `json:"weights,omitempty"`
+}
+
+// fillRerankInfo reports the reranker and its params as a JSON object, so that clients could tell which fusion is applied,
+// e.g. the rrf by default or the one converted from the legacy rank params of hybrid search.
+func (t *searchTask) fillRerankInfo() {
+	bs, err := json.Marshal(rerankInfo{
+		Name:    t.functionScore.RerankName(),
+		Legacy:  t.functionScore.IsLegacy(),
+		Params:  t.functionScore.RerankParams(),
+		Weights: t.functionScore.RerankWeights(),
+	})
+	if err != nil {
+		log.Warn("failed to marshal rerank info", zap.Error(err))
+		return
+	}
+	setSearchResultExtraInfo(t.result, searchResultRerankKey, string(bs))
+}
+
+// checkNoGrowingSkipped fails the sealed only search if any QueryNode reports the growing segments of its channel
+// are left out, whose rows are missing from the results silently otherwise.
+func checkNoGrowingSkipped(toReduceResults []*internalpb.SearchResults) error {
+	channels := lo.FilterMap(toReduceResults, func(result *internalpb.SearchResults, _ int) (string, bool) {
+		channel := result.GetBase().GetProperties()[common.SearchGrowingSkippedKey]
+		return channel, channel != ""
+	})
+	if len(channels) > 0 {
+		return merr.WrapErrSearchGrowingSkipped(channels, fmt.Sprintf("%s is enabled, but the growing segments are not searched, flush the collection first",
+			SealedOnlyKey))
+	}
+	return nil
+}
+
+// logSlowSearch emits a single warning with the shape and the phase timings of the search,
+// if the phases take more than proxy.slowSearchLogThreshold in total.
+func (t *searchTask) logSlowSearch(ctx context.Context, postExecuteSpan time.Duration) {
+	threshold := Params.ProxyCfg.SlowSearchLogThreshold.GetAsDuration(time.Millisecond)
+	total := t.preExecuteSpan + t.executeSpan + postExecuteSpan
+	if threshold <= 0 || total < threshold {
+		return
+	}
+	topk := t.SearchRequest.GetTopk()
+	if t.SearchRequest.GetIsAdvanced() {
+		topk = t.rankParams.GetLimit()
+	}
+	fanOut := 0
+	if t.queriedChannels != nil {
+		fanOut = len(t.queriedChannels.Collect())
+	}
+	log.Ctx(ctx).Warn("slow search",
+		zap.String("collection", t.collectionName),
+		zap.Int64("collectionID", t.GetCollectionID()),
+		zap.Int64("nq", t.SearchRequest.GetNq()),
+		zap.Int64("topk", topk),
+		zap.Int("numOutputFields", len(t.translatedOutputFields)),
+		zap.Int("numPartitions", len(t.SearchRequest.GetPartitionIDs())),
+		zap.Int("fanOut", fanOut),
+		zap.Duration("preExecute", t.preExecuteSpan),
+		zap.Duration("execute", t.executeSpan),
+		zap.Duration("postExecute", postExecuteSpan),
+		zap.Duration("total", total))
+}
+
+// searchIndexInfo is the index of an anns field searched, returned as a JSON list in the extra info of the result.
+// The index name and type are empty if the field is not indexed.
+type searchIndexInfo struct {
+	FieldName  string `json:"field_name"`
+	IndexName  string `json:"index_name"`
+	IndexType  string `json:"index_type"`
+	MetricType string `json:"metric_type"`
+}
+
+// fillIndexInfo returns the indexes of the anns fields searched, one for each sub search of hybrid search.
+// Only the sealed segments with the index built are searched by it, the growing segments and the ones
+// not indexed yet are searched by brute force or the interim index. It is a debug info, so the search is not failed
+// if the indexes could not be described.
+func (t *searchTask) fillIndexInfo(ctx context.Context) {
+	resp, err := t.mixCoord.DescribeIndex(ctx, &indexpb.DescribeIndexRequest{CollectionID: t.GetCollectionID()})
+	if err := merr.CheckRPCCall(resp, err); err != nil && !errors.Is(err, merr.ErrIndexNotFound) {
+		log.Ctx(ctx).Warn("failed to describe indexes of the anns fields", zap.Error(err))
+		return
+	}
+	fieldIDs := []int64{t.SearchRequest.GetFieldId()}
+	if t.SearchRequest.GetIsAdvanced() {
+		fieldIDs = lo.Map(t.SearchRequest.GetSubReqs(), func(subReq *internalpb.SubSearchRequest, _ int) int64 { return subReq.GetFieldId() })
+	}
+	infos := lo.Map(fieldIDs, func(fieldID int64, i int) *searchIndexInfo {
+		info := &searchIndexInfo{FieldName: typeutil.GetField(t.schema.CollectionSchema, fieldID).GetName()}
+		if i < len(t.queryInfos) {
+			info.MetricType = t.queryInfos[i].GetMetricType()
+		}
+		if index, ok := lo.Find(resp.GetIndexInfos(), func(index *indexpb.IndexInfo) bool {
+			return index.GetFieldID() == fieldID
+		}); ok {
+			info.IndexName = index.GetIndexName()
+			info.IndexType, _ = funcutil.GetAttrByKeyFromRepeatedKV(common.IndexTypeKey, index.GetIndexParams())
+			if info.MetricType == "" {
+				info.MetricType, _ = funcutil.GetAttrByKeyFromRepeatedKV(common.MetricTypeKey, index.GetIndexParams())
+			}
+		}
+		return info
+	})
+	bs, err := json.Marshal(infos)
+	if err != nil {
+		log.Ctx(ctx).Warn("failed to marshal index info", zap.Error(err))
+		return
+	}
+	setSearchResultExtraInfo(t.result, searchResultIndexInfoKey, string(bs))
+}
+
+// searchCost is the cost of the whole search reported by query nodes, it is returned as a JSON object
+// so that the per query costs could be added as another field once query nodes report them.
+type searchCost struct {
+	// the shards are searched in parallel, the time is the slowest one.
+	ResponseTimeMs  int64 `json:"response_time_ms"`
+	ServiceTimeMs   int64 `json:"service_time_ms"`
+	TotalNQ         int64 `json:"total_nq"`
+	RelatedDataSize int64 `json:"related_data_size"`
+}
+
+func aggregateSearchCost(toReduceResults []*internalpb.SearchResults) *searchCost {
+	cost := &searchCost{}
+	for _, result := range toReduceResults {
+		costAggregation := result.GetCostAggregation()
+		cost.ResponseTimeMs = max(cost.ResponseTimeMs, costAggregation.GetResponseTime())
+		cost.ServiceTimeMs = max(cost.ServiceTimeMs, costAggregation.GetServiceTime())
+		cost.TotalNQ = max(cost.TotalNQ, costAggregation.GetTotalNQ())
+		cost.RelatedDataSize += costAggregation.GetTotalRelatedDataSize()
+	}
+	return cost
+}
+
+// fillQueryID reports the trace id and the msg id of the search, so that clients could
+// correlate the search with the server side traces and logs.
+func (t *searchTask) fillQueryID(sp trace.Span) {
+	if traceID := sp.SpanContext().TraceID(); traceID.IsValid() {
+		setSearchResultExtraInfo(t.result, searchResultTraceIDKey, traceID.String())
+	}
+	setSearchResultExtraInfo(t.result, searchResultQueryIDKey, strconv.FormatInt(t.ID(), 10))
+}
+
+// fillMetricTypes reports the effective metric types in the search result, so that clients
+// could tell how the scores are computed. Advanced search reports the metric type of each sub request.
+func (t *searchTask) fillMetricTypes(toReduceResults []*internalpb.SearchResults) {
+	if t.SearchRequest.GetIsAdvanced() {
+		subMetricTypes := getSubMetricTypes(toReduceResults, t.SearchRequest.GetSubReqs())
+		setSearchResultExtraInfo(t.result, searchResultSubMetricTypesKey, strings.Join(subMetricTypes, ","))
+		return
+	}
+	metricType := getMetricType(toReduceResults)
+	if metricType == "" {
+		metricType = t.SearchRequest.GetMetricType()
+	}
+	setSearchResultExtraInfo(t.result, searchResultMetricTypeKey, metricType)
+}
+
+func (t *searchTask) initSearchRequest(ctx context.Context) error {
+	ctx, sp := otel.Tracer(typeutil.ProxyRole).Start(ctx, "init search request")
+	defer sp.End()
+
+	log := log.Ctx(ctx).With(zap.Int64("collID", t.GetCollectionID()), zap.String("collName", t.collectionName))
+
+	plan, queryInfo, offset, isIterator, err := t.tryGeneratePlan(t.request.GetSearchParams(), t.request.GetDsl(), t.request.GetExprTemplateValues())
+	if err != nil {
+		return err
+	}
+
+	percentile, searchParamStr, isPercentile, err := parsePercentileRangeFilter(queryInfo.GetSearchParams())
+	if err != nil {
+		return err
+	}
+	if isPercentile {
+		if isIterator {
+			return merr.WrapErrParameterInvalidMsg("percentile %s is not supported by search iterator", rangeFilterKey)
+		}
+		if hasGroupBy(queryInfo.GetGroupByFieldId()) {
+			return merr.WrapErrParameterInvalidMsg("percentile %s is not supported with grouping search", rangeFilterKey)
+		}
+		// the plan refers to the query info, so the range filter is removed from the plan as well.
+		queryInfo.SearchParams = searchParamStr
+		t.rangeFilterPercentile = percentile
+	}
+
+	if t.countOnly, err = getBoolSearchParam(t.request.GetSearchParams(), CountOnlyKey); err != nil {
+		return err
+	}
+	if t.countOnly {
+		if isIterator || hasGroupBy(queryInfo.GetGroupByFieldId()) || t.request.FunctionScore != nil {
+			return merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator, grouping search or rerank", CountOnlyKey)
+		}
+		queryInfo.Topk = Params.QuotaConfig.TopKLimit.GetAsInt64()
+		offset = 0
+	}
+
+	adaptiveTopK, err := getBoolSearchParam(t.request.GetSearchParams(), AdaptiveTopKKey)
+	if err != nil {
+		return err
+	}
+	if adaptiveTopK {
+		if isIterator || hasGroupBy(queryInfo.GetGroupByFieldId()) || t.countOnly || t.rangeFilterPercentile > 0 {
+			return merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator, grouping search, %s or percentile %s",
+				AdaptiveTopKKey, CountOnlyKey, rangeFilterKey)
+		}
+		t.adaptiveTopK = queryInfo.GetTopk() - offset
+		// the plan refers to the query info, so the candidates are fetched from query nodes as well.
+		queryInfo.Topk = max(queryInfo.GetTopk(), min(offset+t.adaptiveTopK*adaptiveTopKCandidateRatio, Params.QuotaConfig.TopKLimit.GetAsInt64()))
+	}
+
+	if t.request.FunctionScore != nil {
+		if t.functionScore, err = rerank.NewFunctionScore(t.schema.CollectionSchema, t.request.FunctionScore); err != nil {
+			log.Warn("Failed to create function score", zap.Error(err))
+			return err
+		}
+
+		if err := checkRerankGroupBySupport(t.functionScore, queryInfo.GetGroupByFieldId()); err != nil {
+			return err
+		}
+	}
+
+	if t.boost, err = t.parseBoost(offset, isIterator, queryInfo.GetGroupByFieldId()); err != nil {
+		return err
+	}
+
+	t.isIterator = isIterator
+	t.SearchRequest.Offset = offset
+	t.SearchRequest.FieldId = queryInfo.GetQueryFieldId()
+	if err := t.checkQueryVectorsDim(t.SearchRequest.FieldId, t.request.GetPlaceholderGroup()); err != nil {
+		return err
+	}
+
+	if t.previewPartitions, err = t.parsePreviewPartitions(ctx, plan); err != nil {
+		return err
+	}
+	if t.partitionKeyMode && !t.scanAllPartitions {
+		// isolation has tighter constraint, check first
+		mvErr := setQueryInfoIfMvEnable(queryInfo, t, plan)
+		if mvErr != nil {
+			return mvErr
+		}
+		partitionIDs, err2 := t.tryParsePartitionIDsFromPlan(plan)
+		if err2 != nil {
+			return err2
+		}
+		if len(partitionIDs) > 0 {
+			t.SearchRequest.PartitionIDs = partitionIDs
+		}
+	}
+	if t.topPartitionOnly, err = t.parseTopPartitionOnly(isIterator, queryInfo); err != nil {
+		return err
+	}
+	if t.dissimilar, err = t.parseDissimilarMode(queryInfo, isIterator); err != nil {
+		return err
+	}
+	if t.searchTerms, err = t.parseTermStats(plan); err != nil {
+		return err
 	}
 
 	vectorOutputFields := lo.Filter(t.schema.GetFields(), func(field *schemapb.FieldSchema, _ int) bool {
 		return lo.Contains(t.translatedOutputFields, field.GetName()) && typeutil.IsVectorType(field.GetDataType())
 	})
-	t.needRequery = len(vectorOutputFields) > 0
+	t.needRequery = len(vectorOutputFields) > 0 && !t.countOnly
+	// skip_vector_requery returns vector output fields as placeholders without data.
+	skipVectorRequery, err := getBoolSearchParam(t.request.GetSearchParams(), SkipVectorRequeryKey)
+	if err != nil {
+		return err
+	}
+	if t.needRequery && skipVectorRequery {
+		// requery is only issued to fetch vectors, skip it and return the vector fields without data.
+		t.skippedVectorOutputFields = vectorOutputFields
+		t.needRequery = false
+	}
+	if t.needRequery {
+		if err := t.checkRequeryAllowed(ctx); err != nil {
+			return err
+		}
+	}
 	if t.needRequery {
 		plan.OutputFieldIds = t.functionScore.GetAllInputFieldIDs()
+	} else if t.countOnly {
+		// no field is returned, only the pk is required to reduce the results.
+		primaryFieldSchema, err := t.schema.GetPkField()
+		if err != nil {
+			return err
+		}
+		plan.OutputFieldIds = []int64{primaryFieldSchema.GetFieldID()}
 	} else {
 		primaryFieldSchema, err := t.schema.GetPkField()
 		if err != nil {
 			return err
 		}
 		allFieldIDs := typeutil.NewSet[int64](t.SearchRequest.OutputFieldsId...)
+		for _, field := range t.skippedVectorOutputFields {
+			allFieldIDs.Remove(field.GetFieldID())
+		}
 		allFieldIDs.Insert(t.functionScore.GetAllInputFieldIDs()...)
 		allFieldIDs.Insert(primaryFieldSchema.FieldID)
 		plan.OutputFieldIds = allFieldIDs.Collect()
-		plan.DynamicFields = t.userDynamicFields
+		if len(t.dynamicFieldPrefixes) == 0 {
+			plan.DynamicFields = t.userDynamicFields
+		}
 	}
 
 	t.SearchRequest.SerializedExprPlan, err = proto.Marshal(plan)
 	if err != nil {
 		return err
 	}
+	if t.topPartitionOnly {
+		if t.topPartitionScanPlan, err = newTopPartitionScanPlan(plan); err != nil {
+			return err
+		}
+	}
 	if typeutil.IsFieldSparseFloatVector(t.schema.CollectionSchema, t.SearchRequest.FieldId) {
 		metrics.ProxySearchSparseNumNonZeros.WithLabelValues(strconv.FormatInt(paramtable.GetNodeID(), 10), t.collectionName, metrics.SearchLabel, strconv.FormatInt(t.SearchRequest.FieldId, 10)).Observe(float64(typeutil.EstimateSparseVectorNNZFromPlaceholderGroup(t.request.PlaceholderGroup, int(t.request.GetNq()))))
 	}
 	t.SearchRequest.PlaceholderGroup = t.request.PlaceholderGroup
+	if t.dissimilar {
+		if t.SearchRequest.PlaceholderGroup, err = negateQueryVectors(t.request.GetPlaceholderGroup()); err != nil {
+			return err
+		}
+	}
 	t.SearchRequest.Topk = queryInfo.GetTopk()
 	t.SearchRequest.MetricType = queryInfo.GetMetricType()
 	t.queryInfos = append(t.queryInfos, queryInfo)
@@ -583,6 +1627,18 @@ func (t *searchTask) initSearchRequest(ctx context.Context) error {
 			t.SearchRequest.AnalyzerName = analyzer
 		}
 	}
+	if t.highlight, err = t.parseHighlight(queryInfo); err != nil {
+		return err
+	}
+	if t.explainBM25, err = t.parseExplainBM25(queryInfo); err != nil {
+		return err
+	}
+	if err := t.checkSelfRecallCheck(queryInfo); err != nil {
+		return err
+	}
+	if t.previewTokens, err = t.parsePreviewTokens(queryInfo); err != nil {
+		return err
+	}
 
 	if function.HasNonBM25Functions(t.schema.CollectionSchema.Functions, []int64{queryInfo.GetQueryFieldId()}) {
 		ctx, sp := otel.Tracer(typeutil.ProxyRole).Start(ctx, "Proxy-Search-call-function-udf")
@@ -595,16 +1651,390 @@ func (t *searchTask) initSearchRequest(ctx context.Context) error {
 		if err := exec.ProcessSearch(ctx, t.SearchRequest); err != nil {
 			return err
 		}
-		sp.AddEvent("Call-function-udf")
+		sp.AddEvent("Call-function-udf")
+	}
+
+	t.rowPartitionIDs, err = t.parsePartitionKeyHints(ctx)
+	if err != nil {
+		return err
+	}
+	if len(t.rowPartitionIDs) > 0 {
+		t.SearchRequest.PartitionIDs = lo.Uniq(t.rowPartitionIDs)
+	}
+
+	log.Debug("proxy init search request",
+		zap.Int64s("plan.OutputFieldIds", plan.GetOutputFieldIds()),
+		zap.Stringer("plan", truncatedStringer{plan})) // may be very large if large term passed.
+
+	return nil
+}
+
+// checkRequeryAllowed rejects the search requiring a requery to fetch the vector output fields if it's disabled by
+// the collection property, or by the cluster config if the property is not set, so that the hidden cost is surfaced
+// to the client. The requery of hybrid search is required to fetch any output field, so it's not restricted.
+func (t *searchTask) checkRequeryAllowed(ctx context.Context) error {
+	collectionInfo, err := globalMetaCache.GetCollectionInfo(ctx, t.request.GetDbName(), t.collectionName, t.CollectionID)
+	if err != nil {
+		return err
+	}
+	disabled := Params.ProxyCfg.DisableSearchRequery.GetAsBool()
+	if disabledStr, err := funcutil.GetAttrByKeyFromRepeatedKV(common.CollectionSearchRequeryDisabledKey, collectionInfo.properties); err == nil {
+		if disabled, err = strconv.ParseBool(disabledStr); err != nil {
+			return merr.WrapErrParameterInvalidMsg("collection property %s [%s] is invalid, should be a boolean",
+				common.CollectionSearchRequeryDisabledKey, disabledStr)
+		}
+	}
+	if disabled {
+		return merr.WrapErrParameterInvalidMsg("search on collection %s requires a requery to fetch the vector output fields, which is disabled, "+
+			"please not request the vector output fields, or set %s to return them without data", t.collectionName, SkipVectorRequeryKey)
+	}
+	return nil
+}
+
+// applyCollectionDefaultSearchParams merges the default search params of the collection into the request, they are
+// meant for the params used in planning the search, like the metric type and the index params.
+func (t *searchTask) applyCollectionDefaultSearchParams(ctx context.Context) error {
+	collectionInfo, err := globalMetaCache.GetCollectionInfo(ctx, t.request.GetDbName(), t.collectionName, t.CollectionID)
+	if err != nil {
+		return err
+	}
+	defaultParamsStr, err := funcutil.GetAttrByKeyFromRepeatedKV(common.CollectionDefaultSearchParamsKey, collectionInfo.properties)
+	if err != nil {
+		return nil
+	}
+	if t.SearchRequest.GetIsAdvanced() {
+		for _, subReq := range t.request.GetSubReqs() {
+			if subReq.SearchParams, err = applyDefaultSearchParams(subReq.GetSearchParams(), defaultParamsStr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	t.request.SearchParams, err = applyDefaultSearchParams(t.request.GetSearchParams(), defaultParamsStr)
+	return err
+}
+
+// applySearchCursor translates the search cursor into a range filter from the score of the cursor,
+// plus a filter excluding the hits with the same score returned already, to fetch the page after the cursor.
+func (t *searchTask) applySearchCursor() error {
+	token, err := funcutil.GetAttrByKeyFromRepeatedKV(SearchCursorKey, t.request.GetSearchParams())
+	if err != nil {
+		return nil
+	}
+	params := t.request.GetSearchParams()
+	paramsStr, _ := funcutil.GetAttrByKeyFromRepeatedKV(ParamsKey, params)
+	offset, _ := funcutil.GetAttrByKeyFromRepeatedKV(OffsetKey, params)
+	roundDecimal, _ := funcutil.GetAttrByKeyFromRepeatedKV(RoundDecimalKey, params)
+	isIteratorStr, _ := funcutil.GetAttrByKeyFromRepeatedKV(IteratorField, params)
+	isIterator, _ := strconv.ParseBool(isIteratorStr)
+	switch {
+	case t.SearchRequest.GetIsAdvanced() || t.request.GetFunctionScore() != nil:
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search or rerank", SearchCursorKey)
+	case isIterator:
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator", SearchCursorKey)
+	case t.GetNq() != 1:
+		return merr.WrapErrParameterInvalidMsg("%s only supports searching with a single query, got nq %d", SearchCursorKey, t.GetNq())
+	case offset != "" && offset != "0":
+		return merr.WrapErrParameterInvalidMsg("%s could not be used with offset", SearchCursorKey)
+	case roundDecimal != "" && roundDecimal != "-1":
+		// rounded scores break the paging by score
+		return merr.WrapErrParameterInvalidMsg("%s could not be used with %s", SearchCursorKey, RoundDecimalKey)
+	}
+	if isRangeSearch, err := hasRangeSearchParams(paramsStr); err != nil {
+		return err
+	} else if isRangeSearch {
+		return merr.WrapErrParameterInvalidMsg("%s could not be used with range search", SearchCursorKey)
+	}
+	t.withSearchCursor = true
+	// the first page
+	if token == "" {
+		return nil
+	}
+
+	cursor, err := decodeSearchCursor(token, t.GetCollectionID())
+	if err != nil {
+		return err
+	}
+	metricType, err := funcutil.GetAttrByKeyFromRepeatedKV(common.MetricTypeKey, params)
+	if err == nil && metricType != "" && !strings.EqualFold(metricType, cursor.MetricType) {
+		return merr.WrapErrParameterInvalidMsg("%s is generated by the search with metric type %s, but %s is requested",
+			SearchCursorKey, cursor.MetricType, metricType)
+	}
+	if paramsStr, err = mergeSearchParams(paramsStr, cursor.rangeSearchParams()); err != nil {
+		return err
+	}
+	t.request.SearchParams = append(lo.Filter(params, func(pair *commonpb.KeyValuePair, _ int) bool {
+		return pair.GetKey() != ParamsKey && pair.GetKey() != common.MetricTypeKey
+	}), &commonpb.KeyValuePair{Key: ParamsKey, Value: paramsStr}, &commonpb.KeyValuePair{Key: common.MetricTypeKey, Value: cursor.MetricType})
+
+	pkField, err := t.schema.GetPkField()
+	if err != nil {
+		return err
+	}
+	if t.request.GetDsl() == "" {
+		t.request.Dsl = cursor.exclusionExpr(pkField.GetName())
+	} else {
+		t.request.Dsl = fmt.Sprintf("(%s) and (%s)", t.request.GetDsl(), cursor.exclusionExpr(pkField.GetName()))
+	}
+	t.searchCursor = cursor
+	return nil
+}
+
+// fillSearchCursor returns the cursor of the next page in the result, no cursor is returned if there is no more hit.
+func (t *searchTask) fillSearchCursor() error {
+	cursor, err := nextSearchCursor(t.result.GetResults(), t.searchCursor, t.GetCollectionID(), t.SearchRequest.GetMetricType())
+	if err != nil || cursor == nil {
+		return err
+	}
+	token, err := encodeSearchCursor(cursor)
+	if err != nil {
+		return err
+	}
+	setSearchResultExtraInfo(t.result, searchResultCursorKey, token)
+	return nil
+}
+
+// requirePrivilegedParam checks the search param key is set by a privileged user, and records the use in the audit log.
+func (t *searchTask) requirePrivilegedParam(ctx context.Context, key string, fields ...zap.Field) error {
+	username := GetCurUserFromContextOrDefault(ctx)
+	if !isPrivilegedUser(ctx) {
+		return merr.WrapErrPrivilegeNotPermitted("%s is only allowed for privileged users, user: %s", key, username)
+	}
+	log.Ctx(ctx).Info("[audit] search uses the privileged param", append([]zap.Field{
+		zap.String("param", key),
+		zap.String("username", username),
+		zap.String("db", t.request.GetDbName()),
+		zap.String("collection", t.collectionName),
+	}, fields...)...)
+	return nil
+}
+
+// parseScanAllPartitions parses scan_all_partitions, which searches all the partitions of a partition key collection
+// regardless of the partition key in the filter. It defeats the partition key isolation, so only privileged users are allowed.
+func (t *searchTask) parseScanAllPartitions(ctx context.Context) (bool, error) {
+	scanAllPartitions, err := getBoolSearchParam(t.request.GetSearchParams(), ScanAllPartitionsKey)
+	if err != nil || !scanAllPartitions {
+		return false, err
+	}
+	if !t.partitionKeyMode {
+		return false, merr.WrapErrParameterInvalidMsg("%s only works for collections with partition key", ScanAllPartitionsKey)
+	}
+	if _, err := funcutil.GetAttrByKeyFromRepeatedKV(PartitionKeyHintsKey, t.request.GetSearchParams()); err == nil {
+		return false, merr.WrapErrParameterInvalidMsg("%s could not be used with %s", ScanAllPartitionsKey, PartitionKeyHintsKey)
+	}
+	if err := t.requirePrivilegedParam(ctx, ScanAllPartitionsKey, zap.String("expr", t.request.GetDsl())); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// getMaxSearchRequests returns the max number of the ann search requests in a hybrid search.
+// Privileged users could override the configured limit for the request by max_search_requests.
+func (t *searchTask) getMaxSearchRequests(ctx context.Context) (int, error) {
+	maxSearchRequests := Params.ProxyCfg.MaxHybridSearchRequests.GetAsInt()
+	valueStr, err := funcutil.GetAttrByKeyFromRepeatedKV(MaxSearchRequestsKey, t.request.GetSearchParams())
+	if err != nil {
+		return maxSearchRequests, nil
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value <= 0 {
+		return 0, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a positive integer", MaxSearchRequestsKey, valueStr)
+	}
+	if err := t.requirePrivilegedParam(ctx, MaxSearchRequestsKey,
+		zap.Int("configured", maxSearchRequests), zap.Int("override", value)); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// recordPlanHash records the hash of the compiled plan if with_plan_hash is enabled,
+// the plan is hashed as it is compiled, before the output fields are filled.
+func (t *searchTask) recordPlanHash(plan *planpb.PlanNode) {
+	if !t.withPlanHash {
+		return
+	}
+	hash, err := searchPlanHash(plan)
+	if err != nil {
+		// for diagnostics only, never fail the search
+		log.Ctx(t.ctx).Warn("failed to hash the search plan", zap.Error(err))
+	}
+	t.planHashes = append(t.planHashes, hash)
+}
+
+// parsePinnedNodes parses node_ids, which pins the search to the query nodes to debug the node specific issues.
+// It bypasses the load balancing, so only privileged users are allowed.
+func (t *searchTask) parsePinnedNodes(ctx context.Context) ([]int64, error) {
+	nodeIDsStr, err := funcutil.GetAttrByKeyFromRepeatedKV(NodeIDsKey, t.request.GetSearchParams())
+	if err != nil {
+		return nil, nil
+	}
+	var nodeIDs []int64
+	if err := json.Unmarshal([]byte(nodeIDsStr), &nodeIDs); err != nil || len(nodeIDs) == 0 {
+		return nil, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, should be a non-empty list of int64", NodeIDsKey, nodeIDsStr)
+	}
+	nodeIDs = lo.Uniq(nodeIDs)
+	if err := t.requirePrivilegedParam(ctx, NodeIDsKey, zap.Int64s("nodeIDs", nodeIDs)); err != nil {
+		return nil, err
+	}
+	return nodeIDs, nil
+}
+
+// checkSelfRecallCheck validates the search could be evaluated by self_recall_check, where a ground truth search
+// is issued after the search to compute its recall. The ground truth search scans all the vectors by brute force,
+// so only the searches with small nq and topk are allowed.
+func (t *searchTask) checkSelfRecallCheck(queryInfo *planpb.QueryInfo) error {
+	enabled, err := getBoolSearchParam(t.request.GetSearchParams(), SelfRecallCheckKey)
+	if err != nil || !enabled {
+		return err
+	}
+	// segcore ignores the hints of range searches, the ground truth would be searched by the index then.
+	isRangeSearch, err := hasRangeSearchParams(queryInfo.GetSearchParams())
+	if err != nil {
+		return err
+	}
+	switch {
+	case t.SearchRequest.GetNq() > maxSelfRecallCheckNq:
+		return merr.WrapErrParameterInvalidMsg("%s only supports nq no more than %d, got %d", SelfRecallCheckKey, maxSelfRecallCheckNq, t.SearchRequest.GetNq())
+	case queryInfo.GetTopk() > maxSelfRecallCheckTopK:
+		return merr.WrapErrParameterInvalidMsg("%s only supports topk (including offset) no more than %d, got %d",
+			SelfRecallCheckKey, maxSelfRecallCheckTopK, queryInfo.GetTopk())
+	case hasGroupBy(queryInfo.GetGroupByFieldId()):
+		// the hits of grouping search are not comparable to the ground truth
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by grouping search", SelfRecallCheckKey)
+	case isRangeSearch:
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by range search", SelfRecallCheckKey)
+	}
+	if t.SearchRequest.GetIsRecallEvaluation() {
+		// the ground truth search, which shall not rely on the query hook of the query nodes to be exact.
+		queryInfo.Hints = bruteForceHint
+	}
+	return nil
+}
+
+// parseResourceGroup returns the query nodes of the resource group the search is directed to, set by resource_group.
+// The collection shall be loaded in the resource group, only the replicas there serve the search then.
+func (t *searchTask) parseResourceGroup(ctx context.Context) ([]int64, error) {
+	resourceGroup, err := funcutil.GetAttrByKeyFromRepeatedKV(ResourceGroupKey, t.request.GetSearchParams())
+	if err != nil {
+		return nil, nil
+	}
+	if err := t.requirePrivilegedParam(ctx, ResourceGroupKey, zap.String("resourceGroup", resourceGroup)); err != nil {
+		return nil, err
+	}
+	rgInfo, err := globalMetaCache.GetResourceGroupInfo(ctx, resourceGroup)
+	if err != nil {
+		return nil, err
+	}
+	if rgInfo.GetNumLoadedReplica()[t.GetCollectionID()] == 0 {
+		return nil, merr.WrapErrParameterInvalidMsg("collection %s is not loaded in resource group %s", t.collectionName, resourceGroup)
+	}
+	nodeIDs := lo.Map(rgInfo.GetNodes(), func(node *commonpb.NodeInfo, _ int) int64 { return node.GetNodeId() })
+	if len(nodeIDs) == 0 {
+		return nil, merr.WrapErrParameterInvalidMsg("no query node is available in resource group %s", resourceGroup)
+	}
+	return nodeIDs, nil
+}
+
+// parseImpersonateUser returns the end user the search is issued on behalf of, set by impersonate_user.
+// Gateways serving many end users with a single service account use it to have the end users scheduled fairly,
+// so only the privileged users are allowed to impersonate others.
+func (t *searchTask) parseImpersonateUser(ctx context.Context) (string, error) {
+	impersonatedUser, err := funcutil.GetAttrByKeyFromRepeatedKV(ImpersonateUserKey, t.request.GetSearchParams())
+	if err != nil {
+		return "", nil
+	}
+	if err := ValidateUsername(impersonatedUser); err != nil {
+		return "", err
+	}
+	if err := t.requirePrivilegedParam(ctx, ImpersonateUserKey, zap.String("impersonatedUser", impersonatedUser)); err != nil {
+		return "", err
+	}
+	return impersonatedUser, nil
+}
+
+// parseGroupResultsByPartition resolves the partitions to search if group_results_by_partition is enabled,
+// the results of each partition are reduced separately instead of merged globally.
+func (t *searchTask) parseGroupResultsByPartition(ctx context.Context) error {
+	enabled, err := getBoolSearchParam(t.request.GetSearchParams(), GroupResultsByPartitionKey)
+	if err != nil || !enabled {
+		return err
+	}
+	switch {
+	case t.SearchRequest.GetIsAdvanced():
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by hybrid search", GroupResultsByPartitionKey)
+	case t.isIterator:
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by search iterator", GroupResultsByPartitionKey)
+	case len(t.rowPartitionIDs) > 0:
+		return merr.WrapErrParameterInvalidMsg("%s could not be used with %s", GroupResultsByPartitionKey, PartitionKeyHintsKey)
+	case len(t.queryInfos) == 1 && hasGroupBy(t.queryInfos[0].GetGroupByFieldId()):
+		return merr.WrapErrParameterInvalidMsg("%s is not supported by grouping search", GroupResultsByPartitionKey)
+	}
+
+	partitionIDs := t.SearchRequest.GetPartitionIDs()
+	if len(partitionIDs) == 0 {
+		partitions, err := globalMetaCache.GetPartitions(ctx, t.request.GetDbName(), t.collectionName)
+		if err != nil {
+			return err
+		}
+		partitionIDs = lo.Values(partitions)
+	}
+	t.resultPartitionIDs = lo.Uniq(partitionIDs)
+	// each partition is searched by its own fan-out to all the shards, bounded as the partition key fan-out.
+	if maxFanout := Params.ProxyCfg.MaxPartitionKeyFanout.GetAsInt(); maxFanout > 0 && len(t.resultPartitionIDs) > maxFanout {
+		return merr.WrapErrParameterInvalidMsg("%s searches %d partitions, exceeds the limit %d, please specify fewer partitions",
+			GroupResultsByPartitionKey, len(t.resultPartitionIDs), maxFanout)
+	}
+	slices.Sort(t.resultPartitionIDs)
+	t.resultPartitions = typeutil.NewConcurrentMap[*internalpb.SearchResults, int64]()
+	return nil
+}
+
+// parsePartitionKeyHints returns the partition of each query row according to the partition key hints,
+// so that each row only searches its own partition instead of the union of partitions of all rows.
+func (t *searchTask) parsePartitionKeyHints(ctx context.Context) ([]int64, error) {
+	hintsStr, err := funcutil.GetAttrByKeyFromRepeatedKV(PartitionKeyHintsKey, t.request.GetSearchParams())
+	if err != nil {
+		return nil, nil
+	}
+	if !t.partitionKeyMode {
+		return nil, merr.WrapErrParameterInvalidMsg("%s only works for collections with partition key", PartitionKeyHintsKey)
+	}
+	partitionKeyField, err := typeutil.GetPartitionKeyFieldSchema(t.schema.CollectionSchema)
+	if err != nil {
+		return nil, err
+	}
+	hints, err := parsePartitionKeyHintValues(hintsStr, partitionKeyField, t.GetNq())
+	if err != nil {
+		return nil, err
+	}
+
+	partitionNames, err := getDefaultPartitionsInPartitionKeyMode(ctx, t.request.GetDbName(), t.collectionName)
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := globalMetaCache.GetPartitions(ctx, t.request.GetDbName(), t.collectionName)
+	if err != nil {
+		return nil, err
+	}
+	hashValues, err := typeutil.HashKey2Partitions(hints, partitionNames)
+	if err != nil {
+		return nil, err
+	}
+	rowPartitionIDs := make([]int64, 0, len(hashValues))
+	for _, hashValue := range hashValues {
+		partitionID, ok := partitions[partitionNames[hashValue]]
+		if !ok {
+			return nil, merr.WrapErrPartitionNotFound(partitionNames[hashValue])
+		}
+		rowPartitionIDs = append(rowPartitionIDs, partitionID)
 	}
-
-	log.Debug("proxy init search request",
-		zap.Int64s("plan.OutputFieldIds", plan.GetOutputFieldIds()),
-		zap.Stringer("plan", plan)) // may be very large if large term passed.
-
-	return nil
+	return rowPartitionIDs, nil
 }
 
+// defaultVectorFieldFirst searches the first vector field of the schema if anns_field is not specified and the collection
+// has multiple vector fields, set by default_vector_field.
+const defaultVectorFieldFirst = "first"
+
 func (t *searchTask) tryGeneratePlan(params []*commonpb.KeyValuePair, dsl string, exprTemplateValues map[string]*schemapb.TemplateValue) (*planpb.PlanNode, *planpb.QueryInfo, int64, bool, error) {
 	annsFieldName, err := funcutil.GetAttrByKeyFromRepeatedKV(AnnsFieldKey, params)
 	if err != nil || len(annsFieldName) == 0 {
@@ -614,7 +2044,14 @@ func (t *searchTask) tryGeneratePlan(params []*commonpb.KeyValuePair, dsl string
 		}
 
 		if enableMultipleVectorFields && len(vecFields) > 1 {
-			return nil, nil, 0, false, errors.New("multiple anns_fields exist, please specify a anns_field in search_params")
+			defaultVectorField, err := funcutil.GetAttrByKeyFromRepeatedKV(DefaultVectorFieldKey, params)
+			if err != nil {
+				return nil, nil, 0, false, errors.New("multiple anns_fields exist, please specify a anns_field in search_params")
+			}
+			if defaultVectorField != defaultVectorFieldFirst {
+				return nil, nil, 0, false, merr.WrapErrParameterInvalidMsg("%s [%s] is invalid, only %s is supported",
+					DefaultVectorFieldKey, defaultVectorField, defaultVectorFieldFirst)
+			}
 		}
 		annsFieldName = vecFields[0].Name
 	}
@@ -628,27 +2065,120 @@ func (t *searchTask) tryGeneratePlan(params []*commonpb.KeyValuePair, dsl string
 	}
 
 	annField := typeutil.GetFieldByName(t.schema.CollectionSchema, annsFieldName)
+	// grouping by the scalar fields is supported by the searches on the sparse float vectors, only the vector fields
+	// are rejected as the group by field, see parseGroupByInfo.
 	if searchInfo.planInfo.GetGroupByFieldId() != -1 && annField.GetDataType() == schemapb.DataType_BinaryVector {
 		return nil, nil, 0, false, errors.New("not support search_group_by operation based on binary vector column")
 	}
 
 	searchInfo.planInfo.QueryFieldId = annField.GetFieldID()
+	if err := t.resolveMetricType(searchInfo.planInfo, annsFieldName); err != nil {
+		return nil, nil, 0, false, err
+	}
+
+	if timeRange, err := funcutil.GetAttrByKeyFromRepeatedKV(TimeRangeKey, params); err == nil {
+		timeRangeExpr, err := parseTimeRangeFilter(timeRange, t.schema.CollectionSchema)
+		if err != nil {
+			return nil, nil, 0, false, err
+		}
+		dsl = mergeTimeRangeFilter(dsl, timeRangeExpr)
+	}
+
+	if err := checkSearchExprLength(dsl); err != nil {
+		return nil, nil, 0, false, err
+	}
+	if err := validateExprTemplateValues(exprTemplateValues,
+		Params.ProxyCfg.MaxExprTemplateValueCount.GetAsInt(), Params.ProxyCfg.MaxExprTemplateValueSize.GetAsSize()); err != nil {
+		return nil, nil, 0, false, err
+	}
+
+	planCacheKey := ""
+	planCache := getSearchPlanCache()
+	if planCache != nil {
+		planCacheKey, err = t.searchPlanCacheKey(dsl, annsFieldName, searchInfo.planInfo, exprTemplateValues)
+		if err != nil {
+			// cache is best effort, fallback to parse the plan
+			log.Ctx(t.ctx).Warn("failed to generate search plan cache key", zap.Error(err))
+			planCache = nil
+		} else if plan, ok := planCache.getSearchPlan(planCacheKey, searchInfo.planInfo); ok {
+			t.recordPlanHash(plan)
+			return plan, searchInfo.planInfo, searchInfo.offset, searchInfo.isIterator, nil
+		}
+	}
+
 	start := time.Now()
 	plan, planErr := planparserv2.CreateSearchPlan(t.schema.schemaHelper, dsl, annsFieldName, searchInfo.planInfo, exprTemplateValues)
 	if planErr != nil {
 		log.Ctx(t.ctx).Warn("failed to create query plan", zap.Error(planErr),
-			zap.String("dsl", dsl), // may be very large if large term passed.
+			zap.String("dsl", truncateExprForLog(dsl)), // may be very large if large term passed.
 			zap.String("anns field", annsFieldName), zap.Any("query info", searchInfo.planInfo))
 		metrics.ProxyParseExpressionLatency.WithLabelValues(strconv.FormatInt(paramtable.GetNodeID(), 10), "search", metrics.FailLabel).Observe(float64(time.Since(start).Milliseconds()))
 		return nil, nil, 0, false, merr.WrapErrParameterInvalidMsg("failed to create query plan: %v", planErr)
 	}
 	metrics.ProxyParseExpressionLatency.WithLabelValues(strconv.FormatInt(paramtable.GetNodeID(), 10), "search", metrics.SuccessLabel).Observe(float64(time.Since(start).Milliseconds()))
+	if planCache != nil {
+		planCache.addSearchPlan(planCacheKey, plan)
+	}
+	t.recordPlanHash(plan)
 	log.Ctx(t.ctx).Debug("create query plan",
-		zap.String("dsl", t.request.Dsl), // may be very large if large term passed.
+		zap.String("dsl", truncateExprForLog(t.request.Dsl)), // may be very large if large term passed.
 		zap.String("anns field", annsFieldName), zap.Any("query info", searchInfo.planInfo))
 	return plan, searchInfo.planInfo, searchInfo.offset, searchInfo.isIterator, nil
 }
 
+// resolveMetricType fills the metric type of the index on the field if the metric type is not specified,
+// so that the search never silently relies on a default metric type mismatching the index.
+func (t *searchTask) resolveMetricType(queryInfo *planpb.QueryInfo, annsFieldName string) error {
+	if queryInfo.GetMetricType() != "" {
+		return nil
+	}
+	indexMetricTypes, err := globalMetaCache.GetIndexMetricTypes(t.ctx, t.GetCollectionID())
+	if err != nil {
+		// QueryNodes fall back to the metric type of the index as well, so the search is not failed here.
+		log.Ctx(t.ctx).Warn("failed to get metric types of indexes, leave the metric type unspecified", zap.Error(err))
+		return nil
+	}
+	if len(indexMetricTypes) == 0 {
+		log.Ctx(t.ctx).Debug("no index metadata of the collection, leave the metric type unspecified", zap.String("collection", t.collectionName))
+		return nil
+	}
+	metricType, ok := indexMetricTypes[queryInfo.GetQueryFieldId()]
+	if !ok {
+		return merr.WrapErrParameterInvalidMsg("metric type is not specified and no index is found on field %s to determine it", annsFieldName)
+	}
+	queryInfo.MetricType = metricType
+	log.Ctx(t.ctx).Debug("metric type is not specified, use the metric type of the index",
+		zap.String("collection", t.collectionName),
+		zap.String("annsField", annsFieldName),
+		zap.String("metricType", metricType))
+	return nil
+}
+
+// parseCommonFilter compiles the filter shared by all the sub search requests of the hybrid search, returns nil if
+// there is no common filter. It's a convenience param saving the clients from repeating the filter in every sub
+// search request, the filter is ANDed into the filter of each sub search request and still evaluated by QueryNodes
+// once per sub search request.
+func (t *searchTask) parseCommonFilter() (*planpb.Expr, error) {
+	commonFilter, err := funcutil.GetAttrByKeyFromRepeatedKV(CommonFilterKey, t.request.GetSearchParams())
+	if err != nil || len(commonFilter) == 0 {
+		return nil, nil
+	}
+	expr, err := planparserv2.ParseExpr(t.schema.schemaHelper, commonFilter, t.request.GetExprTemplateValues())
+	if err != nil {
+		log.Ctx(t.ctx).Warn("failed to parse common filter", zap.Error(err), zap.String("filter", commonFilter))
+		return nil, merr.WrapErrParameterInvalidMsg("failed to parse %s: %v", CommonFilterKey, err)
+	}
+	return expr, nil
+}
+
+func (t *searchTask) searchPlanCacheKey(dsl string, annsField string, queryInfo *planpb.QueryInfo, exprTemplateValues map[string]*schemapb.TemplateValue) (string, error) {
+	collectionInfo, err := globalMetaCache.GetCollectionInfo(t.ctx, t.request.GetDbName(), t.collectionName, t.GetCollectionID())
+	if err != nil {
+		return "", err
+	}
+	return searchPlanCacheKey(t.GetCollectionID(), collectionInfo.updateTimestamp, dsl, annsField, queryInfo, exprTemplateValues)
+}
+
 func (t *searchTask) tryParsePartitionIDsFromPlan(plan *planpb.PlanNode) ([]int64, error) {
 	expr, err := exprutil.ParseExprFromPlan(plan)
 	if err != nil {
@@ -669,11 +2199,55 @@ func (t *searchTask) tryParsePartitionIDsFromPlan(plan *planpb.PlanNode) ([]int6
 			log.Ctx(t.ctx).Warn("failed to get partition ids", zap.Error(err2))
 			return nil, err2
 		}
+		if err2 := checkPartitionKeyFanout(len(PartitionIDs)); err2 != nil {
+			log.Ctx(t.ctx).Warn("partition key search fans out to too many partitions", zap.Error(err2))
+			return nil, err2
+		}
 		return PartitionIDs, nil
 	}
 	return nil, nil
 }
 
+// parsePreviewPartitions returns the partitions the partition key values in the filter are hashed to, set by
+// preview_partitions, so that the distribution of the partition keys could be verified without executing the search.
+// The partitions are listed regardless of the fan-out limit. It exposes the internal partitions, so only privileged
+// users are allowed.
+func (t *searchTask) parsePreviewPartitions(ctx context.Context, plan *planpb.PlanNode) ([]string, error) {
+	enabled, err := getBoolSearchParam(t.request.GetSearchParams(), PreviewPartitionsKey)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	if err := t.requirePrivilegedParam(ctx, PreviewPartitionsKey); err != nil {
+		return nil, err
+	}
+	if !t.partitionKeyMode {
+		return nil, merr.WrapErrParameterInvalidMsg("%s only works for collections with partition key", PreviewPartitionsKey)
+	}
+	expr, err := exprutil.ParseExprFromPlan(plan)
+	if err != nil {
+		return nil, err
+	}
+	partitionNames, err := assignPartitionKeys(ctx, t.request.GetDbName(), t.collectionName, exprutil.ParseKeys(expr, exprutil.PartitionKey))
+	if err != nil {
+		return nil, err
+	}
+	// empty if no partition key value is in the filter, all the partitions are searched then.
+	slices.Sort(partitionNames)
+	return partitionNames, nil
+}
+
+// fillPreviewPartitionsResult returns no hit but the partitions the partition key values are hashed to.
+func (t *searchTask) fillPreviewPartitionsResult() error {
+	bs, err := json.Marshal(t.previewPartitions)
+	if err != nil {
+		return err
+	}
+	t.result = fillInEmptyResult(t.SearchRequest.GetNq())
+	t.result.CollectionName = t.collectionName
+	setSearchResultExtraInfo(t.result, searchResultPreviewPartitionsKey, string(bs))
+	return nil
+}
+
 func (t *searchTask) Execute(ctx context.Context) error {
 	ctx, sp := otel.Tracer(typeutil.ProxyRole).Start(ctx, "Proxy-Search-Execute")
 	defer sp.End()
@@ -682,24 +2256,203 @@ func (t *searchTask) Execute(ctx context.Context) error {
 	tr := timerecord.NewTimeRecorder(fmt.Sprintf("proxy execute search %d", t.ID()))
 	defer tr.CtxElapse(ctx, "done")
 
-	err := t.lb.Execute(ctx, CollectionWorkLoad{
-		db:             t.request.GetDbName(),
-		collectionID:   t.SearchRequest.CollectionID,
-		collectionName: t.collectionName,
-		nq:             t.Nq,
-		exec:           t.searchShard,
-	})
+	if t.previewTokens != nil || t.previewPartitions != nil {
+		// the previews are resolved already, nothing to search.
+		return nil
+	}
+
+	if t.searchRequestID != "" {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		t.searchRequestOwner = GetCurUserFromContextOrDefault(ctx)
+		if err := registerInFlightSearch(t.searchRequestOwner, t.searchRequestID, cancel); err != nil {
+			cancel(nil)
+			return err
+		}
+		t.inFlightCtx = ctx
+	}
+
+	var err error
+	if t.topPartitionOnly {
+		err = t.pruneToTopPartition(ctx)
+	}
+	if err == nil {
+		execCtx := ctx
+		if t.partialResultsOnTimeout {
+			var cancel context.CancelFunc
+			execCtx, cancel = withPartialResultsDeadline(ctx)
+			defer cancel()
+		}
+		if rowGroups := groupRowsByPartition(t.rowPartitionIDs); len(rowGroups) > 1 {
+			err = t.executeByRowPartitions(execCtx, rowGroups)
+		} else if len(t.resultPartitionIDs) > 0 {
+			err = t.executeByPartitions(execCtx)
+		} else if Params.ProxyCfg.CoalesceIdenticalSearch.GetAsBool() && !t.partialResultsOnTimeout &&
+			len(t.pinnedNodes) == 0 && len(t.resourceGroupNodes) == 0 {
+			// the partial results shall never be shared with the searches not accepting them,
+			// neither shall the results from the pinned nodes or resource group
+			err = t.executeCoalesced(execCtx)
+		} else {
+			err = t.executeShards(execCtx)
+		}
+		if err != nil && t.partialResultsOnTimeout && ctx.Err() == nil &&
+			errors.Is(execCtx.Err(), context.DeadlineExceeded) && len(t.resultBuf.Collect()) > 0 {
+			log.Warn("search timeout, reduce the results collected before the deadline",
+				zap.Int("numResults", len(t.resultBuf.Collect())), zap.Error(err))
+			t.partialResults = true
+			err = nil
+		}
+	}
 	if err != nil {
+		if t.searchRequestID != "" {
+			if cause := context.Cause(ctx); cause != nil {
+				err = cause
+			}
+			unregisterInFlightSearch(t.searchRequestOwner, t.searchRequestID)
+		}
 		log.Warn("search execute failed", zap.Error(err))
 		return errors.Wrap(err, "failed to search")
 	}
 
+	t.executeSpan = tr.ElapseSpan()
+	metrics.ProxySearchExecuteLatency.WithLabelValues(strconv.FormatInt(paramtable.GetNodeID(), 10), t.queryTypeLabel(), t.collectionName).
+		Observe(float64(t.executeSpan.Milliseconds()))
 	log.Debug("Search Execute done.",
 		zap.Int64("collection", t.GetCollectionID()),
 		zap.Int64s("partitionIDs", t.GetPartitionIDs()))
 	return nil
 }
 
+// withPartialResultsDeadline returns the context to fan out the search with, the time reserved
+// for reducing the partial results is excluded from its deadline.
+func withPartialResultsDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	remaining := time.Until(deadline)
+	return context.WithTimeout(ctx, remaining-time.Duration(float64(remaining)*partialResultsReduceRatio))
+}
+
+// queryTypeLabel returns the query type label of the search metrics.
+func (t *searchTask) queryTypeLabel() string {
+	if t.SearchRequest.GetIsAdvanced() {
+		return metrics.HybridSearchLabel
+	}
+	return metrics.SearchLabel
+}
+
+// executeShards fans out the search request to the shard leaders of the collection.
+func (t *searchTask) executeShards(ctx context.Context) error {
+	return t.lb.Execute(ctx, CollectionWorkLoad{
+		db:             t.request.GetDbName(),
+		collectionID:   t.SearchRequest.CollectionID,
+		collectionName: t.collectionName,
+		nq:             t.Nq,
+		exec:           t.searchShard,
+		pinnedNodes:    t.pinnedNodes,
+		allowedNodes:   t.resourceGroupNodes,
+	})
+}
+
+// executeByRowPartitions searches each group of query rows in its own partition,
+// the results are padded back to nq rows so that they could be reduced as usual.
+func (t *searchTask) executeByRowPartitions(ctx context.Context, rowGroups map[int64][]int) error {
+	placeholderGroup := &commonpb.PlaceholderGroup{}
+	if err := proto.Unmarshal(t.SearchRequest.GetPlaceholderGroup(), placeholderGroup); err != nil {
+		return err
+	}
+
+	wg, ctx := errgroup.WithContext(ctx)
+	for partitionID, rows := range rowGroups {
+		slicedPlaceholderGroup, err := slicePlaceholderGroup(placeholderGroup, rows, t.GetNq())
+		if err != nil {
+			return err
+		}
+		searchReq := typeutil.Clone(t.SearchRequest)
+		searchReq.Nq = int64(len(rows))
+		searchReq.PartitionIDs = []int64{partitionID}
+		searchReq.PlaceholderGroup, err = proto.Marshal(slicedPlaceholderGroup)
+		if err != nil {
+			return err
+		}
+		wg.Go(func() error {
+			return t.lb.Execute(ctx, CollectionWorkLoad{
+				db:             t.request.GetDbName(),
+				collectionID:   t.SearchRequest.CollectionID,
+				collectionName: t.collectionName,
+				nq:             searchReq.GetNq(),
+				exec: func(ctx context.Context, nodeID int64, qn types.QueryNodeClient, channel string) error {
+					return t.searchShardWithRequest(ctx, nodeID, qn, channel, searchReq, rows)
+				},
+				pinnedNodes:  t.pinnedNodes,
+				allowedNodes: t.resourceGroupNodes,
+			})
+		})
+	}
+	return wg.Wait()
+}
+
+// partitionSearchConcurrency bounds the partitions searched at the same time by group_results_by_partition.
+const partitionSearchConcurrency = 8
+
+// executeByPartitions searches the partitions one by one, so that the results of each partition
+// could be reduced separately.
+func (t *searchTask) executeByPartitions(ctx context.Context) error {
+	wg, ctx := errgroup.WithContext(ctx)
+	wg.SetLimit(partitionSearchConcurrency)
+	for _, partitionID := range t.resultPartitionIDs {
+		searchReq := typeutil.Clone(t.SearchRequest)
+		searchReq.PartitionIDs = []int64{partitionID}
+		wg.Go(func() error {
+			return t.lb.Execute(ctx, CollectionWorkLoad{
+				db:             t.request.GetDbName(),
+				collectionID:   t.SearchRequest.CollectionID,
+				collectionName: t.collectionName,
+				nq:             t.GetNq(),
+				exec: func(ctx context.Context, nodeID int64, qn types.QueryNodeClient, channel string) error {
+					return t.searchShardWithRequest(ctx, nodeID, qn, channel, searchReq, nil)
+				},
+				pinnedNodes:  t.pinnedNodes,
+				allowedNodes: t.resourceGroupNodes,
+			})
+		})
+	}
+	return wg.Wait()
+}
+
+// reduceByPartitions reduces the results of each partition separately and concatenates them,
+// instead of merging the results of all partitions globally.
+func (t *searchTask) reduceByPartitions(ctx context.Context, span trace.Span, toReduceResults []*internalpb.SearchResults) (*milvuspb.SearchResults, error) {
+	partitionResults := make(map[int64][]*internalpb.SearchResults)
+	for _, result := range toReduceResults {
+		partitionID, ok := t.resultPartitions.Get(result)
+		if !ok {
+			return nil, merr.WrapErrServiceInternal("partition of the search result not found")
+		}
+		partitionResults[partitionID] = append(partitionResults[partitionID], result)
+	}
+
+	results := make([]*milvuspb.SearchResults, 0, len(partitionResults))
+	partitionIDs := make([]int64, 0, len(partitionResults))
+	for _, partitionID := range t.resultPartitionIDs {
+		if len(partitionResults[partitionID]) == 0 {
+			continue
+		}
+		pipeline, err := newBuiltInPipeline(t)
+		if err != nil {
+			return nil, err
+		}
+		result, err := pipeline.Run(ctx, span, partitionResults[partitionID])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+		partitionIDs = append(partitionIDs, partitionID)
+	}
+	return mergeSearchResultsByPartition(results, partitionIDs, t.GetNq()), nil
+}
+
 // find the last bound based on reduced results and metric type
 // only support nq == 1, for search iterator v2
 func getLastBound(result *milvuspb.SearchResults, incomingLastBound *float32, metricType string) float32 {
@@ -725,9 +2478,25 @@ func (t *searchTask) PostExecute(ctx context.Context) error {
 	tr := timerecord.NewTimeRecorder("searchTask PostExecute")
 	defer func() {
 		tr.CtxElapse(ctx, "done")
+		t.logSlowSearch(ctx, tr.ElapseSpan())
 	}()
 	log := log.Ctx(ctx).With(zap.Int64("nq", t.SearchRequest.GetNq()))
 
+	if t.previewTokens != nil {
+		return t.fillPreviewTokensResult()
+	}
+	if t.previewPartitions != nil {
+		return t.fillPreviewPartitionsResult()
+	}
+
+	if t.inFlightCtx != nil {
+		defer unregisterInFlightSearch(t.searchRequestOwner, t.searchRequestID)
+		if cause := context.Cause(t.inFlightCtx); cause != nil {
+			log.Info("search is canceled", zap.String("requestID", t.searchRequestID), zap.Error(cause))
+			return cause
+		}
+	}
+
 	toReduceResults, err := t.collectSearchResults(ctx)
 	if err != nil {
 		log.Warn("failed to collect search results", zap.Error(err))
@@ -746,28 +2515,153 @@ func (t *searchTask) PostExecute(ctx context.Context) error {
 			isRecallEvaluation = true
 		}
 		t.relatedDataSize += r.GetCostAggregation().GetTotalRelatedDataSize()
-		for ch, ts := range r.GetChannelsMvcc() {
-			t.queryChannelsTs[ch] = ts
-		}
+		mergeChannelsMvcc(t.queryChannelsTs, r.GetChannelsMvcc())
 	}
 
 	t.isTopkReduce = isTopkReduce
 	t.isRecallEvaluation = isRecallEvaluation
 
-	// call pipeline
-	pipeline, err := newBuiltInPipeline(t)
-	if err != nil {
-		log.Warn("Faild to create post process pipeline")
-		return err
+	if t.sealedOnly {
+		if err := checkNoGrowingSkipped(toReduceResults); err != nil {
+			return err
+		}
 	}
-	if t.result, err = pipeline.Run(ctx, sp, toReduceResults); err != nil {
-		return err
+
+	var hasMore bool
+	if t.withHasMore {
+		// counted on the candidates of the shards, before they are reduced
+		if hasMore, err = searchResultsHaveMore(ctx, toReduceResults, t.SearchRequest.GetTopk()); err != nil {
+			return err
+		}
+	}
+
+	if len(t.resultPartitionIDs) > 0 {
+		if t.result, err = t.reduceByPartitions(ctx, sp, toReduceResults); err != nil {
+			return err
+		}
+	} else {
+		// call pipeline
+		pipeline, err := newBuiltInPipeline(t)
+		if err != nil {
+			log.Warn("Faild to create post process pipeline")
+			return err
+		}
+		if t.result, err = pipeline.Run(ctx, sp, toReduceResults); err != nil {
+			return err
+		}
+	}
+	if t.boost != nil {
+		// boosted before the invalid scores are sanitized, as the formula may produce NaN or Inf as well.
+		if err := boostSearchResultData(t.result.GetResults(), t.boost, t.isScorePositivelyRelated(toReduceResults)); err != nil {
+			return err
+		}
+	}
+	t.sanitizeInvalidScores(toReduceResults)
+	if t.rangeFilterPercentile > 0 {
+		// two-phase range search: the bound is resolved from the candidates fetched, which costs an extra pass of the results.
+		filterSearchResultDataByPercentile(t.result.GetResults(), t.rangeFilterPercentile, t.isScorePositivelyRelated(toReduceResults))
+	}
+	if t.minScore != nil && !t.rerankSkipped {
+		// rerank scores are not known until fusion, so the threshold could only be applied here.
+		// the threshold of rerank scores makes no sense to the scores before rerank.
+		filterSearchResultDataByMinScore(t.result.GetResults(), *t.minScore)
+	}
+	if t.maxResultAge != nil {
+		// filtered before fillResult, so that the result size insufficiency counts the hits dropped.
+		filterSearchResultDataByAge(t.result.GetResults(), t.maxResultAge, time.Now())
+	}
+	if t.adaptiveTopK > 0 {
+		cutSearchResultDataAtElbow(t.result.GetResults(), t.adaptiveTopK)
+	}
+	if t.sortByField != nil {
+		// a presentation sort of the hits selected, the candidates are not affected.
+		sortSearchResultDataByField(t.result.GetResults(), t.sortByField, t.sortByDesc)
 	}
 	t.fillResult()
+	if t.sampleRatio > 0 && t.sampleRatio < 1 {
+		// sampled after fillResult, the hits dropped by sampling shall not make the result size insufficient and retry the search.
+		sampleSearchResultData(t.result.GetResults(), t.sampleRatio, t.sampleSeed)
+	}
+	if t.errorOnEmpty && sumInt64(t.result.GetResults().GetTopks()) == 0 && !t.willRetryForInsufficientResult() {
+		return merr.WrapErrNoResults(fmt.Sprintf("search on collection %s returns no results", t.collectionName))
+	}
+	if t.approxDistinctField != nil {
+		// counted before any field data is dropped or truncated
+		t.fillApproxDistinctCount()
+	}
+	if t.explainBM25 != nil {
+		// explained before the texts are highlighted
+		if err := t.fillBM25Explain(); err != nil {
+			return err
+		}
+	}
+	if t.sortByFieldFetched {
+		// the sort field is fetched only for sorting, never return it.
+		t.result.Results.FieldsData = lo.Filter(t.result.GetResults().GetFieldsData(), func(field *schemapb.FieldData, _ int) bool {
+			return field.GetFieldName() != t.sortByField.GetName()
+		})
+	}
+	if t.maxResultAge != nil && t.maxResultAge.fetched {
+		// the timestamp field is fetched only for filtering, never return it.
+		t.result.Results.FieldsData = lo.Filter(t.result.GetResults().GetFieldsData(), func(field *schemapb.FieldData, _ int) bool {
+			return field.GetFieldName() != t.maxResultAge.field.GetName()
+		})
+	}
+	if t.boost != nil && len(t.boost.fetchedFields) > 0 {
+		// the boost fields are fetched only for boosting, never return them.
+		t.result.Results.FieldsData = lo.Filter(t.result.GetResults().GetFieldsData(), func(field *schemapb.FieldData, _ int) bool {
+			return !lo.Contains(t.boost.fetchedFields, field.GetFieldName())
+		})
+	}
+	if t.idsScoresOnly {
+		// the input fields of the rerank are fetched along with the search, never return them.
+		t.result.Results.FieldsData = lo.Filter(t.result.GetResults().GetFieldsData(), func(field *schemapb.FieldData, _ int) bool {
+			return field.GetFieldName() == partitionIDOutputField
+		})
+	}
 	t.result.Results.OutputFields = t.userOutputFields
+	if len(t.resultPartitionIDs) > 0 {
+		t.result.Results.OutputFields = append(t.result.Results.OutputFields, partitionIDOutputField)
+	}
 	t.result.CollectionName = t.request.GetCollectionName()
+	for _, field := range t.skippedVectorOutputFields {
+		t.result.Results.FieldsData = append(t.result.Results.FieldsData, genPlaceholderVectorFieldData(field))
+	}
+	// the fields are assembled in no particular order, return them in the order of the output fields requested.
+	rank := outputFieldsRank(t.request.GetOutputFields(), t.schema)
+	sortByOutputFieldsRank(t.result.Results.FieldsData, rank, (*schemapb.FieldData).GetFieldName)
+	sortByOutputFieldsRank(t.result.Results.OutputFields, rank, func(name string) string { return name })
+
+	if len(t.dynamicFieldPrefixes) > 0 {
+		unmatched, err := filterDynamicFieldsByPrefix(t.result.GetResults().GetFieldsData(), t.userDynamicFields, t.dynamicFieldPrefixes)
+		if err != nil {
+			return err
+		}
+		// nothing to match if there is no hit
+		if t.strictDynamicFieldPrefix && len(unmatched) > 0 && sumInt64(t.result.GetResults().GetTopks()) > 0 {
+			return merr.WrapErrParameterInvalidMsg("dynamic field prefixes %v match none of the dynamic fields", unmatched)
+		}
+	}
+
+	fillNullableValidData(t.result.GetResults().GetFieldsData(), t.schema.CollectionSchema, typeutil.GetSizeOfIDs(t.result.GetResults().GetIds()))
+	if t.computeDiversity {
+		t.fillDiversity(ctx, getMetricType(toReduceResults))
+	}
+	if t.vectorPrecision != schemapb.DataType_None {
+		downcastFloatVectors(t.result.GetResults().GetFieldsData(), t.vectorPrecision)
+	}
 
 	primaryFieldSchema, _ := t.schema.GetPkField()
+	if t.maxFieldBytes > 0 {
+		truncated := truncateFieldsData(t.result.GetResults().GetFieldsData(), t.maxFieldBytes, primaryFieldSchema.GetFieldID())
+		setSearchResultExtraInfo(t.result, searchResultTruncatedValuesKey, strconv.Itoa(truncated))
+	}
+	if t.highlight != nil {
+		// highlighted after the texts are truncated, so that the highlight tags are never cut.
+		if err := t.fillHighlight(); err != nil {
+			return err
+		}
+	}
 	if t.userRequestedPkFieldExplicitly {
 		t.result.Results.OutputFields = append(t.result.Results.OutputFields, primaryFieldSchema.GetName())
 		var scalars *schemapb.ScalarField
@@ -796,6 +2690,73 @@ func (t *searchTask) PostExecute(ctx context.Context) error {
 		t.result.Results.FieldsData = append(t.result.Results.FieldsData, pkFieldData)
 	}
 	t.result.Results.PrimaryFieldName = primaryFieldSchema.GetName()
+	if t.countOnly {
+		if err := t.fillCountOnlyResult(); err != nil {
+			return err
+		}
+	}
+	if t.resultFormat == searchResultFormatArrow {
+		// encoded after all the field data is assembled, nothing shall touch the field data afterwards.
+		if err := t.fillArrowResult(); err != nil {
+			return err
+		}
+	}
+	t.fillMetricTypes(toReduceResults)
+	// echo the number of queries processed, so that clients could tell if all the queries of the batch are searched.
+	setSearchResultExtraInfo(t.result, searchResultProcessedNqKey, strconv.FormatInt(t.SearchRequest.GetNq(), 10))
+	// clients could tell the schema is changed between the searches by it, and invalidate their local caches.
+	setSearchResultExtraInfo(t.result, searchResultSchemaVersionKey, strconv.FormatUint(t.schemaVersion, 10))
+	t.fillQueryID(sp)
+	if t.placeholderGroupToken != "" {
+		setSearchResultExtraInfo(t.result, searchResultPlaceholderGroupTokenKey, t.placeholderGroupToken)
+	}
+	if t.withSearchStats {
+		t.fillSearchStats(toReduceResults)
+	}
+	if t.consistencyDowngraded {
+		setSearchResultExtraInfo(t.result, searchResultConsistencyDowngradedKey, "true")
+	}
+	if t.partialResults {
+		setSearchResultExtraInfo(t.result, searchResultPartialResultsKey, "true")
+	}
+	if t.withPlanHash {
+		setSearchResultExtraInfo(t.result, searchResultPlanHashKey, strings.Join(t.planHashes, ","))
+	}
+	if t.withIndexInfo {
+		t.fillIndexInfo(ctx)
+	}
+	if t.withChannelMvcc {
+		t.fillChannelMvcc()
+	}
+	if t.withHasMore {
+		setSearchResultExtraInfo(t.result, searchResultHasMoreKey, strconv.FormatBool(hasMore))
+	}
+	if t.withFusionProvenance {
+		t.fillFusionProvenance()
+	}
+	if t.withQueryOffsets {
+		t.fillQueryOffsets()
+	}
+	if t.returnOriginalDistances && t.functionScore != nil {
+		t.fillOriginalDistancesValidity()
+	}
+	if len(t.searchTerms) > 0 {
+		t.fillTermStats(ctx)
+	}
+	if t.withExactness {
+		t.fillExactness(ctx, toReduceResults)
+	}
+	if t.functionScore != nil {
+		t.fillRerankInfo()
+	}
+	if t.rerankSkipped {
+		setSearchResultExtraInfo(t.result, searchResultRerankSkippedKey, "rerank skipped due to error")
+	}
+	if t.withSearchCursor {
+		if err := t.fillSearchCursor(); err != nil {
+			return err
+		}
+	}
 	if t.isIterator && len(t.queryInfos) == 1 && t.queryInfos[0] != nil {
 		if iterInfo := t.queryInfos[0].GetSearchIteratorV2Info(); iterInfo != nil {
 			t.result.Results.SearchIteratorV2Results = &schemapb.SearchIteratorV2Results{
@@ -818,8 +2779,19 @@ func (t *searchTask) PostExecute(ctx context.Context) error {
 }
 
 func (t *searchTask) searchShard(ctx context.Context, nodeID int64, qn types.QueryNodeClient, channel string) error {
-	searchReq := typeutil.Clone(t.SearchRequest)
+	return t.searchShardWithRequest(ctx, nodeID, qn, channel, t.SearchRequest, nil)
+}
+
+// searchShardWithRequest searches the shard with the given request, rows are the query rows the request carries,
+// nil means all of the query rows.
+func (t *searchTask) searchShardWithRequest(ctx context.Context, nodeID int64, qn types.QueryNodeClient, channel string,
+	request *internalpb.SearchRequest, rows []int,
+) error {
+	searchReq := typeutil.Clone(request)
 	searchReq.GetBase().TargetID = nodeID
+	if t.queriedChannels != nil {
+		t.queriedChannels.Insert(channel)
+	}
 	req := &querypb.SearchRequest{
 		Req:             searchReq,
 		DmlChannels:     []string{channel},
@@ -828,14 +2800,11 @@ func (t *searchTask) searchShard(ctx context.Context, nodeID int64, qn types.Que
 	}
 
 	log := log.Ctx(ctx).With(zap.Int64("collection", t.GetCollectionID()),
-		zap.Int64s("partitionIDs", t.GetPartitionIDs()),
+		zap.Int64s("partitionIDs", searchReq.GetPartitionIDs()),
 		zap.Int64("nodeID", nodeID),
 		zap.String("channel", channel))
 
-	var result *internalpb.SearchResults
-	var err error
-
-	result, err = qn.Search(ctx, req)
+	result, err := t.searchWithRetry(ctx, qn, req)
 	if err != nil {
 		log.Warn("QueryNode search return error", zap.Error(err))
 		globalMetaCache.DeprecateShardCache(t.request.GetDbName(), t.collectionName)
@@ -849,7 +2818,21 @@ func (t *searchTask) searchShard(ctx context.Context, nodeID int64, qn types.Que
 	if result.GetStatus().GetErrorCode() != commonpb.ErrorCode_Success {
 		log.Warn("QueryNode search result error",
 			zap.String("reason", result.GetStatus().GetReason()))
-		return errors.Wrapf(merr.Error(result.GetStatus()), "fail to search on QueryNode %d", nodeID)
+		err := merr.Error(result.GetStatus())
+		if errors.Is(err, merr.ErrCollectionSchemaMismatch) {
+			// the schema is changed after the search is planned, the re-planned search shall use the latest schema.
+			globalMetaCache.RemoveCollection(ctx, t.request.GetDbName(), t.collectionName)
+		}
+		return errors.Wrapf(err, "fail to search on QueryNode %d", nodeID)
+	}
+	if rows != nil {
+		if err := padSearchResultRows(result, rows, t.GetNq()); err != nil {
+			log.Warn("failed to pad search result rows", zap.Error(err))
+			return err
+		}
+	}
+	if t.resultPartitions != nil && len(searchReq.GetPartitionIDs()) == 1 {
+		t.resultPartitions.Insert(result, searchReq.GetPartitionIDs()[0])
 	}
 	if t.resultBuf != nil {
 		t.resultBuf.Insert(result)
@@ -859,6 +2842,39 @@ func (t *searchTask) searchShard(ctx context.Context, nodeID int64, qn types.Que
 	return nil
 }
 
+// searchWithRetry searches on the query node, the search is retried on the same query node if it returns a retriable error,
+// up to proxy.searchShardRetryAttempts times. The retries are backed off exponentially and never exceed the request deadline.
+// The result of the last attempt is returned, the rpc errors are returned without retry.
+func (t *searchTask) searchWithRetry(ctx context.Context, qn types.QueryNodeClient, req *querypb.SearchRequest) (*internalpb.SearchResults, error) {
+	attempts := Params.ProxyCfg.SearchShardRetryAttempts.GetAsInt()
+	if attempts <= 1 {
+		return qn.Search(ctx, req)
+	}
+
+	interval := Params.ProxyCfg.SearchShardRetryInterval.GetAsDuration(time.Millisecond)
+	var result *internalpb.SearchResults
+	var rpcErr error
+	err := retry.Handle(ctx, func() (bool, error) {
+		result, rpcErr = qn.Search(ctx, req)
+		if rpcErr != nil {
+			return false, rpcErr
+		}
+		// not shard leader is handled by rerouting the search.
+		if result.GetStatus().GetErrorCode() == commonpb.ErrorCode_NotShardLeader {
+			return false, nil
+		}
+		if err := merr.Error(result.GetStatus()); err != nil {
+			return merr.IsRetryableErr(err), err
+		}
+		return false, nil
+	}, retry.Attempts(uint(attempts)), retry.Sleep(interval), retry.MaxSleepTime(10*interval))
+	if result == nil && rpcErr == nil {
+		// the context is done before the first attempt
+		return nil, err
+	}
+	return result, rpcErr
+}
+
 func (t *searchTask) estimateResultSize(nq int64, topK int64) (int64, error) {
 	vectorOutputFields := lo.Filter(t.schema.GetFields(), func(field *schemapb.FieldSchema, _ int) bool {
 		return lo.Contains(t.translatedOutputFields, field.GetName()) && typeutil.IsVectorType(field.GetDataType())
@@ -891,19 +2907,24 @@ func (t *searchTask) estimateResultSize(nq int64, topK int64) (int64, error) {
 func (t *searchTask) collectSearchResults(ctx context.Context) ([]*internalpb.SearchResults, error) {
 	select {
 	case <-t.TraceCtx().Done():
-		log.Ctx(ctx).Warn("search task wait to finish timeout!")
-		return nil, fmt.Errorf("search task wait to finish timeout, msgID=%d", t.ID())
+		if !t.partialResultsOnTimeout {
+			log.Ctx(ctx).Warn("search task wait to finish timeout!")
+			return nil, fmt.Errorf("search task wait to finish timeout, msgID=%d", t.ID())
+		}
+		// reduce the results collected before the deadline
+		log.Ctx(ctx).Warn("search task wait to finish timeout, reduce the partial results")
+		t.partialResults = true
 	default:
-		toReduceResults := make([]*internalpb.SearchResults, 0)
 		log.Ctx(ctx).Debug("all searches are finished or canceled")
-		t.resultBuf.Range(func(res *internalpb.SearchResults) bool {
-			toReduceResults = append(toReduceResults, res)
-			log.Ctx(ctx).Debug("proxy receives one search result",
-				zap.Int64("sourceID", res.GetBase().GetSourceID()))
-			return true
-		})
-		return toReduceResults, nil
 	}
+	toReduceResults := make([]*internalpb.SearchResults, 0)
+	t.resultBuf.Range(func(res *internalpb.SearchResults) bool {
+		toReduceResults = append(toReduceResults, res)
+		log.Ctx(ctx).Debug("proxy receives one search result",
+			zap.Int64("sourceID", res.GetBase().GetSourceID()))
+		return true
+	})
+	return toReduceResults, nil
 }
 
 func (t *searchTask) TraceCtx() context.Context {
@@ -938,6 +2959,26 @@ func (t *searchTask) SetTs(ts Timestamp) {
 	t.Base.Timestamp = ts
 }
 
+// setSearchPriority carries the search priority in the properties of the request MsgBase.
+func (t *searchTask) setSearchPriority(priority int64) error {
+	if priority < common.MinSearchPriority || priority > common.MaxSearchPriority {
+		return merr.WrapErrParameterInvalidRange(int64(common.MinSearchPriority), int64(common.MaxSearchPriority), priority, "invalid search priority")
+	}
+	t.setRequestProperty(common.SearchPriorityKey, strconv.FormatInt(priority, 10))
+	return nil
+}
+
+// setRequestProperty sets the property of the request MsgBase, which is carried to QueryNodes.
+func (t *searchTask) setRequestProperty(key, value string) {
+	if t.SearchRequest.GetBase() == nil {
+		t.SearchRequest.Base = commonpbutil.NewMsgBase()
+	}
+	if t.SearchRequest.Base.Properties == nil {
+		t.SearchRequest.Base.Properties = make(map[string]string)
+	}
+	t.SearchRequest.Base.Properties[key] = value
+}
+
 func (t *searchTask) OnEnqueue() error {
 	t.Base = commonpbutil.NewMsgBase()
 	t.Base.MsgType = commonpb.MsgType_Search
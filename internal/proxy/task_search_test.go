@@ -17,8 +17,10 @@ package proxy
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -32,21 +34,28 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/json"
 	"github.com/milvus-io/milvus/internal/mocks"
+	"github.com/milvus-io/milvus/internal/parser/planparserv2"
 	"github.com/milvus-io/milvus/internal/types"
 	"github.com/milvus-io/milvus/internal/util/dependency"
 	"github.com/milvus-io/milvus/internal/util/function"
+	"github.com/milvus-io/milvus/internal/util/function/rerank"
 	"github.com/milvus-io/milvus/internal/util/reduce"
 	"github.com/milvus-io/milvus/pkg/v2/common"
+	"github.com/milvus-io/milvus/pkg/v2/proto/indexpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/planpb"
 	"github.com/milvus-io/milvus/pkg/v2/proto/querypb"
+	"github.com/milvus-io/milvus/pkg/v2/util"
 	"github.com/milvus-io/milvus/pkg/v2/util/funcutil"
 	"github.com/milvus-io/milvus/pkg/v2/util/merr"
 	"github.com/milvus-io/milvus/pkg/v2/util/metric"
@@ -106,6 +115,41 @@ func TestSearchTask_PostExecute(t *testing.T) {
 		assert.Equal(t, qt.result.GetStatus().GetErrorCode(), commonpb.ErrorCode_Success)
 		assert.Equal(t, qt.resultSizeInsufficient, true)
 		assert.Equal(t, qt.isTopkReduce, false)
+		// the nq processed is echoed even if there is no hit
+		assert.Equal(t, "1", qt.result.GetStatus().GetExtraInfo()[searchResultProcessedNqKey])
+		assert.Equal(t, strconv.FormatUint(qt.schemaVersion, 10), qt.result.GetStatus().GetExtraInfo()[searchResultSchemaVersionKey])
+	})
+
+	t.Run("Test empty result with error on empty", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		collName := "test_collection_error_on_empty" + funcutil.GenRandomStr()
+		createColl(t, collName, qc)
+		qt := getSearchTask(t, collName)
+		qt.request.SearchParams = append(qt.request.SearchParams, &commonpb.KeyValuePair{
+			Key:   ErrorOnEmptyKey,
+			Value: "true",
+		})
+		err = qt.PreExecute(ctx)
+		assert.NoError(t, err)
+		assert.True(t, qt.errorOnEmpty)
+
+		qt.resultBuf.Insert(&internalpb.SearchResults{})
+		err := qt.PostExecute(context.TODO())
+		assert.ErrorIs(t, err, merr.ErrNoResults)
+
+		// the search will be retried without topk reduce, leave it to the retried search
+		qt = getSearchTask(t, collName)
+		qt.request.SearchParams = append(qt.request.SearchParams, &commonpb.KeyValuePair{
+			Key:   ErrorOnEmptyKey,
+			Value: "true",
+		})
+		err = qt.PreExecute(ctx)
+		assert.NoError(t, err)
+		qt.resultBuf.Insert(&internalpb.SearchResults{IsTopkReduce: true})
+		err = qt.PostExecute(context.TODO())
+		assert.NoError(t, err)
+		assert.True(t, qt.resultSizeInsufficient)
 	})
 
 	t.Run("test search iterator v2", func(t *testing.T) {
@@ -582,6 +626,15 @@ func getBaseSearchParams() []*commonpb.KeyValuePair {
 	}
 }
 
+// newSearchParams builds the search params from the keys and values in turn.
+func newSearchParams(kvs ...string) []*commonpb.KeyValuePair {
+	params := make([]*commonpb.KeyValuePair, 0, len(kvs)/2)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		params = append(params, &commonpb.KeyValuePair{Key: kvs[i], Value: kvs[i+1]})
+	}
+	return params
+}
+
 func getValidSearchParams() []*commonpb.KeyValuePair {
 	return []*commonpb.KeyValuePair{
 		{
@@ -807,6 +860,142 @@ func TestSearchTask_PreExecute(t *testing.T) {
 		assert.NoError(t, task.PreExecute(ctx))
 	})
 
+	t.Run("search with skip vector requery", func(t *testing.T) {
+		collName := "search_skip_vector_requery" + funcutil.GenRandomStr()
+		createColl(t, collName, qc)
+
+		task := getSearchTask(t, collName)
+		task.request.SearchParams = getValidSearchParams()
+		task.request.DslType = commonpb.DslType_BoolExprV1
+		task.request.OutputFields = []string{testFloatVecField, testInt64Field}
+		assert.NoError(t, task.PreExecute(ctx))
+		assert.True(t, task.needRequery)
+		assert.Empty(t, task.skippedVectorOutputFields)
+
+		task = getSearchTask(t, collName)
+		task.request.SearchParams = append(getValidSearchParams(), &commonpb.KeyValuePair{
+			Key:   SkipVectorRequeryKey,
+			Value: "true",
+		})
+		task.request.DslType = commonpb.DslType_BoolExprV1
+		task.request.OutputFields = []string{testFloatVecField, testInt64Field}
+		assert.NoError(t, task.PreExecute(ctx))
+		assert.False(t, task.needRequery)
+		require.Len(t, task.skippedVectorOutputFields, 1)
+		assert.Equal(t, testFloatVecField, task.skippedVectorOutputFields[0].GetName())
+
+		plan := &planpb.PlanNode{}
+		require.NoError(t, proto.Unmarshal(task.SearchRequest.GetSerializedExprPlan(), plan))
+		assert.NotContains(t, plan.GetOutputFieldIds(), task.skippedVectorOutputFields[0].GetFieldID())
+
+		fieldData := genPlaceholderVectorFieldData(task.skippedVectorOutputFields[0])
+		assert.Equal(t, testFloatVecField, fieldData.GetFieldName())
+		assert.Equal(t, int64(testVecDim), fieldData.GetVectors().GetDim())
+		assert.Empty(t, fieldData.GetVectors().GetFloatVector().GetData())
+
+		task = getSearchTask(t, collName)
+		task.request.SearchParams = append(getValidSearchParams(), &commonpb.KeyValuePair{
+			Key:   SkipVectorRequeryKey,
+			Value: "invalid",
+		})
+		task.request.DslType = commonpb.DslType_BoolExprV1
+		task.request.OutputFields = []string{testFloatVecField}
+		assert.ErrorIs(t, task.PreExecute(ctx), merr.ErrParameterInvalid)
+	})
+
+	t.Run("search with always include pk", func(t *testing.T) {
+		collName := "search_always_include_pk" + funcutil.GenRandomStr()
+		createColl(t, collName, qc)
+
+		task := getSearchTask(t, collName)
+		task.request.SearchParams = getValidSearchParams()
+		task.request.DslType = commonpb.DslType_BoolExprV1
+		assert.NoError(t, task.PreExecute(ctx))
+		assert.False(t, task.userRequestedPkFieldExplicitly)
+
+		// pk field is requested explicitly as well
+		for _, outputFields := range [][]string{nil, {testInt64Field}} {
+			task = getSearchTask(t, collName)
+			task.request.SearchParams = append(getValidSearchParams(), &commonpb.KeyValuePair{
+				Key:   AlwaysIncludePkKey,
+				Value: "true",
+			})
+			task.request.DslType = commonpb.DslType_BoolExprV1
+			task.request.OutputFields = outputFields
+			assert.NoError(t, task.PreExecute(ctx))
+			assert.True(t, task.userRequestedPkFieldExplicitly)
+			assert.NotContains(t, task.userOutputFields, testInt64Field)
+		}
+
+		task = getSearchTask(t, collName)
+		task.request.SearchParams = append(getValidSearchParams(), &commonpb.KeyValuePair{
+			Key:   AlwaysIncludePkKey,
+			Value: "invalid",
+		})
+		task.request.DslType = commonpb.DslType_BoolExprV1
+		assert.ErrorIs(t, task.PreExecute(ctx), merr.ErrParameterInvalid)
+	})
+
+	t.Run("search with count only", func(t *testing.T) {
+		collName := "search_count_only" + funcutil.GenRandomStr()
+		createColl(t, collName, qc)
+
+		task := getSearchTask(t, collName)
+		task.request.SearchParams = append(getValidSearchParams(), &commonpb.KeyValuePair{
+			Key:   CountOnlyKey,
+			Value: "true",
+		})
+		task.request.DslType = commonpb.DslType_BoolExprV1
+		task.request.OutputFields = []string{testFloatVecField}
+		assert.NoError(t, task.PreExecute(ctx))
+		assert.True(t, task.countOnly)
+		assert.False(t, task.needRequery)
+		// topk is ignored
+		assert.Equal(t, Params.QuotaConfig.TopKLimit.GetAsInt64(), task.SearchRequest.GetTopk())
+
+		task = getSearchTask(t, collName)
+		task.request.SearchParams = append(getValidSearchParams(), &commonpb.KeyValuePair{
+			Key:   CountOnlyKey,
+			Value: "true",
+		}, &commonpb.KeyValuePair{
+			Key:   IteratorField,
+			Value: "True",
+		})
+		task.request.DslType = commonpb.DslType_BoolExprV1
+		assert.ErrorIs(t, task.PreExecute(ctx), merr.ErrParameterInvalid)
+	})
+
+	t.Run("search with ids scores only", func(t *testing.T) {
+		collName := "search_ids_scores_only" + funcutil.GenRandomStr()
+		createColl(t, collName, qc)
+
+		task := getSearchTask(t, collName)
+		task.request.SearchParams = append(getValidSearchParams(), &commonpb.KeyValuePair{
+			Key:   IDsScoresOnlyKey,
+			Value: "true",
+		}, &commonpb.KeyValuePair{
+			Key:   AlwaysIncludePkKey,
+			Value: "true",
+		})
+		task.request.DslType = commonpb.DslType_BoolExprV1
+		task.request.OutputFields = []string{testFloatVecField, testInt64Field, "*"}
+		assert.NoError(t, task.PreExecute(ctx))
+		assert.True(t, task.idsScoresOnly)
+		assert.False(t, task.needRequery)
+		assert.False(t, task.userRequestedPkFieldExplicitly)
+		assert.Empty(t, task.translatedOutputFields)
+		assert.Empty(t, task.userOutputFields)
+		assert.Empty(t, task.SearchRequest.GetOutputFieldsId())
+
+		task = getSearchTask(t, collName)
+		task.request.SearchParams = append(getValidSearchParams(), &commonpb.KeyValuePair{
+			Key:   IDsScoresOnlyKey,
+			Value: "invalid",
+		})
+		task.request.DslType = commonpb.DslType_BoolExprV1
+		assert.ErrorIs(t, task.PreExecute(ctx), merr.ErrParameterInvalid)
+	})
+
 	t.Run("search consistent iterator pre_ts", func(t *testing.T) {
 		collName := "search_with_timeout" + funcutil.GenRandomStr()
 		createColl(t, collName, qc)
@@ -935,6 +1124,26 @@ func TestSearchTask_PreExecute(t *testing.T) {
 		assert.Equal(t, true, st.SearchRequest.GetIsAdvanced())
 	})
 
+	t.Run("advance search with iterator", func(t *testing.T) {
+		collName := "search_with_rerank" + funcutil.GenRandomStr()
+		createCollWithFields(t, collName, qc)
+		st := getSearchTaskWithRerank(t, collName, testFloatField)
+		st.request.SearchParams = append(getValidSearchParams(), &commonpb.KeyValuePair{
+			Key:   LimitKey,
+			Value: "10",
+		}, &commonpb.KeyValuePair{
+			Key:   IteratorField,
+			Value: "True",
+		})
+		st.request.DslType = commonpb.DslType_BoolExprV1
+		st.request.SubReqs = append(st.request.SubReqs, &milvuspb.SubSearchRequest{Nq: 1})
+		st.request.SubReqs = append(st.request.SubReqs, &milvuspb.SubSearchRequest{Nq: 1})
+		st.SetTs(tsoutil.ComposeTSByTime(time.Now(), 0))
+		err := st.PreExecute(ctx)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+		assert.ErrorContains(t, err, "search iterator is not supported by hybrid search")
+	})
+
 	t.Run("search with rerank grouping", func(t *testing.T) {
 		collName := "search_with_rerank" + funcutil.GenRandomStr()
 		createCollWithFields(t, collName, qc)
@@ -1106,6 +1315,7 @@ func TestSearchTask_WithFunctions(t *testing.T) {
 	cache.EXPECT().GetCollectionInfo(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&collectionInfo{}, nil).Maybe()
 	cache.EXPECT().GetShard(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]nodeInfo{}, nil).Maybe()
 	cache.EXPECT().DeprecateShardCache(mock.Anything, mock.Anything).Return().Maybe()
+	cache.EXPECT().GetIndexMetricTypes(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 	globalMetaCache = cache
 
 	{
@@ -3702,6 +3912,83 @@ func TestSearchTask_Requery(t *testing.T) {
 		}
 	})
 
+	t.Run("Test requery reads the search snapshot", func(t *testing.T) {
+		const (
+			searchTs   = Timestamp(150)
+			guaranteTs = Timestamp(90)
+			snapshotTs = Timestamp(100)
+		)
+		qn := mocks.NewMockQueryNodeClient(t)
+		qn.EXPECT().Query(mock.Anything, mock.Anything).RunAndReturn(
+			func(ctx context.Context, request *querypb.QueryRequest, option ...grpc.CallOption) (*internalpb.RetrieveResults, error) {
+				// never wait for the data newer than the search snapshot
+				assert.LessOrEqual(t, request.GetReq().GetGuaranteeTimestamp(), snapshotTs)
+				pks := ids
+				// the first row is deleted after the search snapshot
+				if request.GetReq().GetMvccTimestamp() != snapshotTs {
+					pks = ids[1:]
+				}
+				return &internalpb.RetrieveResults{
+					Status: merr.Success(),
+					Ids:    &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: pks}}},
+					FieldsData: []*schemapb.FieldData{{
+						Type:      schemapb.DataType_Int64,
+						FieldName: pkField,
+						FieldId:   100,
+						Field: &schemapb.FieldData_Scalars{
+							Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: pks}}},
+						},
+					}},
+				}, nil
+			})
+
+		lb := NewMockLBPolicy(t)
+		lb.EXPECT().Execute(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, workload CollectionWorkLoad) error {
+			return workload.exec(ctx, 0, qn, "dml_0")
+		})
+		lb.EXPECT().UpdateCostMetrics(mock.Anything, mock.Anything).Return().Maybe()
+		node.lbPolicy = lb
+
+		newTask := func(channelsTs map[string]Timestamp, mvccTs Timestamp) *searchTask {
+			return &searchTask{
+				ctx: ctx,
+				SearchRequest: &internalpb.SearchRequest{
+					Base: &commonpb.MsgBase{
+						MsgType:   commonpb.MsgType_Search,
+						SourceID:  paramtable.GetNodeID(),
+						Timestamp: searchTs,
+					},
+					ConsistencyLevel:   commonpb.ConsistencyLevel_Bounded,
+					GuaranteeTimestamp: guaranteTs,
+					MvccTimestamp:      mvccTs,
+				},
+				request: &milvuspb.SearchRequest{
+					CollectionName: collectionName,
+				},
+				schema:                 schema,
+				tr:                     timerecord.NewTimeRecorder("search"),
+				node:                   node,
+				queryChannelsTs:        channelsTs,
+				translatedOutputFields: []string{pkField},
+			}
+		}
+		resultIDs := &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: ids}}}
+
+		// the mvcc timestamps of the channels reported by the search results
+		op, err := newRequeryOperator(newTask(map[string]Timestamp{"dml_0": snapshotTs}, 0), nil)
+		assert.NoError(t, err)
+		queryResult, err := op.(*requeryOperator).requery(ctx, nil, resultIDs, []string{pkField})
+		assert.NoError(t, err)
+		assert.Equal(t, ids, queryResult.GetFieldsData()[0].GetScalars().GetLongData().GetData())
+
+		// the mvcc timestamp of the search, like search iterators
+		op, err = newRequeryOperator(newTask(nil, snapshotTs), nil)
+		assert.NoError(t, err)
+		queryResult, err = op.(*requeryOperator).requery(ctx, nil, resultIDs, []string{pkField})
+		assert.NoError(t, err)
+		assert.Equal(t, ids, queryResult.GetFieldsData()[0].GetScalars().GetLongData().GetData())
+	})
+
 	t.Run("Test no primary key", func(t *testing.T) {
 		collSchema := &schemapb.CollectionSchema{}
 		schema := newSchemaInfo(collSchema)
@@ -3904,6 +4191,60 @@ func (s *GetPartitionIDsSuite) TestRegexpPartitionNames() {
 	s.Error(err)
 }
 
+func (s *GetPartitionIDsSuite) TestPartitionNamesWithMetaCharacters() {
+	Params.Save(Params.ProxyCfg.PartitionNameRegexp.Key, "true")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	partitions := map[string]int64{"p.2024": 100, "p_2024": 200, "p*": 300, "pp": 400}
+
+	s.mockMetaCache.EXPECT().GetPartitions(mock.Anything, mock.Anything, mock.Anything).
+		Return(partitions, nil).Once()
+	result, err := getPartitionIDsWithMatch(ctx, "default_db", "test_collection", []string{"p.2024", "p*"}, true)
+	s.NoError(err)
+	s.ElementsMatch([]int64{100, 200, 400}, result)
+
+	s.mockMetaCache.EXPECT().GetPartitions(mock.Anything, mock.Anything, mock.Anything).
+		Return(partitions, nil).Once()
+	result, err = getPartitionIDsWithMatch(ctx, "default_db", "test_collection", []string{"p.2024", "p*"}, false)
+	s.NoError(err)
+	s.ElementsMatch([]int64{100, 300}, result)
+
+	s.mockMetaCache.EXPECT().GetPartitions(mock.Anything, mock.Anything, mock.Anything).
+		Return(partitions, nil).Once()
+	_, err = getPartitionIDsWithMatch(ctx, "default_db", "test_collection", []string{"p.*"}, false)
+	s.Error(err)
+
+	s.mockMetaCache.EXPECT().GetPartitions(mock.Anything, mock.Anything, mock.Anything).
+		Return(partitions, nil).Once()
+	_, err = getPartitionIDsWithMatch(ctx, "default_db", "test_collection", []string{"q.*"}, true)
+	s.ErrorContains(err, "partition name q.* matched no partitions")
+}
+
+func (s *GetPartitionIDsSuite) TestParsePartitionNameRegexp() {
+	Params.Save(Params.ProxyCfg.PartitionNameRegexp.Key, "true")
+	useRegexp, err := parsePartitionNameRegexp(nil)
+	s.NoError(err)
+	s.True(useRegexp)
+
+	useRegexp, err = parsePartitionNameRegexp([]*commonpb.KeyValuePair{{Key: PartitionMatchKey, Value: "exact"}})
+	s.NoError(err)
+	s.False(useRegexp)
+
+	Params.Save(Params.ProxyCfg.PartitionNameRegexp.Key, "false")
+	useRegexp, err = parsePartitionNameRegexp(nil)
+	s.NoError(err)
+	s.False(useRegexp)
+
+	useRegexp, err = parsePartitionNameRegexp([]*commonpb.KeyValuePair{{Key: PartitionMatchKey, Value: "regex"}})
+	s.NoError(err)
+	s.True(useRegexp)
+
+	_, err = parsePartitionNameRegexp([]*commonpb.KeyValuePair{{Key: PartitionMatchKey, Value: "fuzzy"}})
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
 func TestGetPartitionIDs(t *testing.T) {
 	suite.Run(t, new(GetPartitionIDsSuite))
 }
@@ -4084,6 +4425,7 @@ func (s *MaterializedViewTestSuite) SetupTest() {
 			collID:                s.colID,
 			partitionKeyIsolation: true,
 		}, nil)
+	s.mockMetaCache.EXPECT().GetIndexMetricTypes(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 	globalMetaCache = s.mockMetaCache
 }
 
@@ -4301,3 +4643,2624 @@ func genTestSearchResultData(nq int64, topk int64, dType schemapb.DataType, fiel
 	}
 	return result
 }
+
+func TestSearchTask_fillMetricTypes(t *testing.T) {
+	t.Run("search", func(t *testing.T) {
+		task := &searchTask{
+			SearchRequest: &internalpb.SearchRequest{MetricType: metric.L2},
+			result:        &milvuspb.SearchResults{},
+		}
+		task.fillMetricTypes(nil)
+		assert.Equal(t, metric.L2, task.result.GetStatus().GetExtraInfo()[searchResultMetricTypeKey])
+
+		task.fillMetricTypes([]*internalpb.SearchResults{{MetricType: metric.COSINE}})
+		assert.Equal(t, metric.COSINE, task.result.GetStatus().GetExtraInfo()[searchResultMetricTypeKey])
+	})
+
+	t.Run("advanced search", func(t *testing.T) {
+		task := &searchTask{
+			SearchRequest: &internalpb.SearchRequest{
+				IsAdvanced: true,
+				SubReqs: []*internalpb.SubSearchRequest{
+					{MetricType: metric.IP},
+					{MetricType: ""},
+				},
+			},
+			result: &milvuspb.SearchResults{Status: merr.Success()},
+		}
+		task.fillMetricTypes([]*internalpb.SearchResults{
+			{
+				IsAdvanced: true,
+				SubResults: []*internalpb.SubSearchResults{
+					{MetricType: metric.BM25, ReqIndex: 1},
+					{MetricType: metric.L2, ReqIndex: 2},
+				},
+			},
+		})
+		extraInfo := task.result.GetStatus().GetExtraInfo()
+		assert.Equal(t, "IP,BM25", extraInfo[searchResultSubMetricTypesKey])
+		_, ok := extraInfo[searchResultMetricTypeKey]
+		assert.False(t, ok)
+	})
+}
+
+func TestCheckPartitionKeyFanout(t *testing.T) {
+	paramtable.Init()
+	assert.NoError(t, checkPartitionKeyFanout(1024))
+
+	paramtable.Get().Save(paramtable.Get().ProxyCfg.MaxPartitionKeyFanout.Key, "16")
+	defer paramtable.Get().Reset(paramtable.Get().ProxyCfg.MaxPartitionKeyFanout.Key)
+	assert.NoError(t, checkPartitionKeyFanout(0))
+	assert.NoError(t, checkPartitionKeyFanout(16))
+	err := checkPartitionKeyFanout(17)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	assert.Contains(t, err.Error(), "hits 17 partitions")
+}
+
+func TestParsePartitionKeyHintValues(t *testing.T) {
+	int64Field := &schemapb.FieldSchema{FieldID: 101, Name: "key", DataType: schemapb.DataType_Int64, IsPartitionKey: true}
+	varcharField := &schemapb.FieldSchema{FieldID: 102, Name: "key", DataType: schemapb.DataType_VarChar, IsPartitionKey: true}
+
+	hints, err := parsePartitionKeyHintValues("[1, 2, 3]", int64Field, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, hints.GetScalars().GetLongData().GetData())
+
+	hints, err = parsePartitionKeyHintValues(`["a", "b"]`, varcharField, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, hints.GetScalars().GetStringData().GetData())
+
+	_, err = parsePartitionKeyHintValues("[1, 2, 3]", int64Field, 2)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = parsePartitionKeyHintValues(`["a", "b"]`, int64Field, 2)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = parsePartitionKeyHintValues("1", varcharField, 1)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestSearchByRowPartitions(t *testing.T) {
+	groups := groupRowsByPartition([]int64{10, 20, 10, 30, 20})
+	assert.Equal(t, map[int64][]int{10: {0, 2}, 20: {1, 4}, 30: {3}}, groups)
+
+	placeholderGroup := &commonpb.PlaceholderGroup{
+		Placeholders: []*commonpb.PlaceholderValue{{
+			Tag:    "$0",
+			Type:   commonpb.PlaceholderType_FloatVector,
+			Values: [][]byte{{0}, {1}, {2}, {3}, {4}},
+		}},
+	}
+	sliced, err := slicePlaceholderGroup(placeholderGroup, groups[20], 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "$0", sliced.GetPlaceholders()[0].GetTag())
+	assert.Equal(t, commonpb.PlaceholderType_FloatVector, sliced.GetPlaceholders()[0].GetType())
+	assert.Equal(t, [][]byte{{1}, {4}}, sliced.GetPlaceholders()[0].GetValues())
+
+	_, err = slicePlaceholderGroup(placeholderGroup, groups[20], 4)
+	assert.Error(t, err)
+
+	data := &schemapb.SearchResultData{
+		NumQueries: 2,
+		TopK:       2,
+		Topks:      []int64{2, 1},
+		Scores:     []float32{0.1, 0.2, 0.3},
+		Ids:        &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3}}}},
+	}
+	blob, err := proto.Marshal(data)
+	assert.NoError(t, err)
+	result := &internalpb.SearchResults{NumQueries: 2, SlicedBlob: blob}
+	assert.NoError(t, padSearchResultRows(result, groups[20], 5))
+	assert.Equal(t, int64(5), result.GetNumQueries())
+	padded := &schemapb.SearchResultData{}
+	assert.NoError(t, proto.Unmarshal(result.GetSlicedBlob(), padded))
+	assert.Equal(t, int64(5), padded.GetNumQueries())
+	assert.Equal(t, []int64{0, 2, 0, 0, 1}, padded.GetTopks())
+	assert.Equal(t, []int64{1, 2, 3}, padded.GetIds().GetIntId().GetData())
+
+	assert.Error(t, padSearchResultRows(&internalpb.SearchResults{SlicedBlob: blob}, groups[30], 5))
+
+	empty := &internalpb.SearchResults{NumQueries: 1}
+	assert.NoError(t, padSearchResultRows(empty, groups[30], 5))
+	assert.Equal(t, int64(5), empty.GetNumQueries())
+}
+
+func TestSearchTask_parseMinScore(t *testing.T) {
+	schema := &schemapb.CollectionSchema{
+		Name: "test",
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+		},
+	}
+	functionScore, err := rerank.NewFunctionScore(schema, &schemapb.FunctionScore{
+		Functions: []*schemapb.FunctionSchema{
+			{
+				Name:            "test",
+				Type:            schemapb.FunctionType_Rerank,
+				InputFieldNames: []string{},
+				Params: []*commonpb.KeyValuePair{
+					{Key: "reranker", Value: "rrf"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	newTask := func(minScore string) *searchTask {
+		task := &searchTask{
+			SearchRequest: &internalpb.SearchRequest{GroupByFieldId: -1},
+			request:       &milvuspb.SearchRequest{},
+			functionScore: functionScore,
+		}
+		if minScore != "" {
+			task.request.SearchParams = []*commonpb.KeyValuePair{{Key: MinScoreKey, Value: minScore}}
+		}
+		return task
+	}
+
+	minScore, err := newTask("").parseMinScore()
+	assert.NoError(t, err)
+	assert.Nil(t, minScore)
+
+	minScore, err = newTask("0.5").parseMinScore()
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0.5), *minScore)
+
+	_, err = newTask("abc").parseMinScore()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = newTask("NaN").parseMinScore()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	task := newTask("0.5")
+	task.functionScore = nil
+	_, err = task.parseMinScore()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	task = newTask("0.5")
+	task.SearchRequest.GroupByFieldId = 101
+	_, err = task.parseMinScore()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestFilterSearchResultDataByMinScore(t *testing.T) {
+	data := &schemapb.SearchResultData{
+		NumQueries: 2,
+		TopK:       3,
+		Topks:      []int64{3, 2},
+		Scores:     []float32{0.9, 0.5, 0.1, 0.4, 0.2},
+		Ids:        &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 4, 5}}}},
+		Distances:  []float32{1, 1, 2, 2, 3, 3, 4, 4, 5, 5},
+		FieldsData: []*schemapb.FieldData{
+			{
+				Type:      schemapb.DataType_Int64,
+				FieldName: "ts",
+				FieldId:   101,
+				Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+					Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: []int64{10, 20, 30, 40, 50}}},
+				}},
+			},
+		},
+	}
+	filterSearchResultDataByMinScore(data, 0.5)
+	assert.Equal(t, []int64{2, 0}, data.GetTopks())
+	assert.Equal(t, []float32{0.9, 0.5}, data.GetScores())
+	assert.Equal(t, []int64{1, 2}, data.GetIds().GetIntId().GetData())
+	assert.Equal(t, []float32{1, 1, 2, 2}, data.GetDistances())
+	assert.Equal(t, []int64{10, 20}, data.GetFieldsData()[0].GetScalars().GetLongData().GetData())
+
+	filterSearchResultDataByMinScore(data, 1)
+	assert.Equal(t, []int64{0, 0}, data.GetTopks())
+	assert.Empty(t, data.GetScores())
+	assert.NotNil(t, data.GetIds().GetIntId())
+	assert.Empty(t, data.GetDistances())
+
+	strData := &schemapb.SearchResultData{
+		Topks:  []int64{2},
+		Scores: []float32{0.9, 0.1},
+		Ids:    &schemapb.IDs{IdField: &schemapb.IDs_StrId{StrId: &schemapb.StringArray{Data: []string{"a", "b"}}}},
+	}
+	filterSearchResultDataByMinScore(strData, 0.5)
+	assert.Equal(t, []string{"a"}, strData.GetIds().GetStrId().GetData())
+	assert.Empty(t, strData.GetDistances())
+}
+
+func TestSearchTask_fillQueryID(t *testing.T) {
+	task := &searchTask{
+		SearchRequest: &internalpb.SearchRequest{Base: &commonpb.MsgBase{MsgID: 1001}},
+		result:        &milvuspb.SearchResults{},
+	}
+	task.fillQueryID(trace.SpanFromContext(context.Background()))
+	extraInfo := task.result.GetStatus().GetExtraInfo()
+	assert.Equal(t, "1001", extraInfo[searchResultQueryIDKey])
+	_, ok := extraInfo[searchResultTraceIDKey]
+	assert.False(t, ok)
+
+	traceID := trace.TraceID{0x1, 0x2}
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{0x1}})
+	task.fillQueryID(trace.SpanFromContext(trace.ContextWithSpanContext(context.Background(), spanCtx)))
+	extraInfo = task.result.GetStatus().GetExtraInfo()
+	assert.Equal(t, traceID.String(), extraInfo[searchResultTraceIDKey])
+	assert.Equal(t, "1001", extraInfo[searchResultQueryIDKey])
+}
+
+func TestSanitizeInvalidScores(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(1))
+	genData := func() *schemapb.SearchResultData {
+		return &schemapb.SearchResultData{
+			NumQueries: 2,
+			TopK:       3,
+			Topks:      []int64{3, 2},
+			Scores:     []float32{inf, 0.5, nan, 0.4, -inf},
+			Ids:        &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 4, 5}}}},
+		}
+	}
+
+	data := genData()
+	assert.Equal(t, 3, sanitizeInvalidScores(data, true, false))
+	assert.Equal(t, []float32{math.MaxFloat32, 0.5, -math.MaxFloat32, 0.4, -math.MaxFloat32}, data.GetScores())
+	assert.Equal(t, []int64{3, 2}, data.GetTopks())
+
+	data = genData()
+	assert.Equal(t, 3, sanitizeInvalidScores(data, false, false))
+	assert.Equal(t, []float32{math.MaxFloat32, 0.5, math.MaxFloat32, 0.4, -math.MaxFloat32}, data.GetScores())
+
+	data = genData()
+	assert.Equal(t, 3, sanitizeInvalidScores(data, true, true))
+	assert.Equal(t, []float32{0.5, 0.4}, data.GetScores())
+	assert.Equal(t, []int64{1, 1}, data.GetTopks())
+	assert.Equal(t, []int64{2, 4}, data.GetIds().GetIntId().GetData())
+
+	data = &schemapb.SearchResultData{Topks: []int64{1}, Scores: []float32{0.1}}
+	assert.Equal(t, 0, sanitizeInvalidScores(data, true, true))
+	assert.Equal(t, []float32{0.1}, data.GetScores())
+}
+
+func TestValidateSearchMetricType(t *testing.T) {
+	indexMetricTypes := map[int64]string{101: metric.COSINE}
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "vec", DataType: schemapb.DataType_FloatVector},
+			{FieldID: 102, Name: "vec2", DataType: schemapb.DataType_FloatVector},
+		},
+	})
+	assert.NoError(t, validateSearchMetricType(schema, 101, "", indexMetricTypes))
+	assert.NoError(t, validateSearchMetricType(schema, 101, metric.COSINE, indexMetricTypes))
+	assert.NoError(t, validateSearchMetricType(schema, 101, "cosine", indexMetricTypes))
+	// no index or no metric type
+	assert.NoError(t, validateSearchMetricType(schema, 102, metric.L2, indexMetricTypes))
+	assert.NoError(t, validateSearchMetricType(schema, 103, metric.L2, indexMetricTypes))
+
+	err := validateSearchMetricType(schema, 101, metric.L2, indexMetricTypes)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	assert.Contains(t, err.Error(), "field vec")
+	assert.Contains(t, err.Error(), metric.COSINE)
+	assert.Contains(t, err.Error(), metric.L2)
+}
+
+func TestSearchTask_fillSearchStats(t *testing.T) {
+	task := &searchTask{
+		result:          &milvuspb.SearchResults{},
+		queriedChannels: typeutil.NewConcurrentSet[string](),
+	}
+	task.queriedChannels.Insert("ch1")
+	task.queriedChannels.Insert("ch2")
+	task.queriedChannels.Insert("ch1")
+	task.fillSearchStats([]*internalpb.SearchResults{
+		{SlicedBlob: []byte{1}, CostAggregation: &internalpb.CostAggregation{ResponseTime: 5, ServiceTime: 3, TotalNQ: 2, TotalRelatedDataSize: 100}},
+		{CostAggregation: &internalpb.CostAggregation{ResponseTime: 8, ServiceTime: 2, TotalNQ: 2, TotalRelatedDataSize: 50}},
+	})
+	extraInfo := task.result.GetStatus().GetExtraInfo()
+	assert.Equal(t, "2", extraInfo[searchResultQueriedChannelsKey])
+	assert.Equal(t, "1", extraInfo[searchResultNonEmptyChannelsKey])
+	assert.JSONEq(t, `{"response_time_ms": 8, "service_time_ms": 3, "total_nq": 2, "related_data_size": 150}`, extraInfo[searchResultCostKey])
+}
+
+func TestParsePercentileRangeFilter(t *testing.T) {
+	_, params, ok, err := parsePercentileRangeFilter(`{"nprobe": 10}`)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, `{"nprobe": 10}`, params)
+
+	_, params, ok, err = parsePercentileRangeFilter(`{"radius": 1.0, "range_filter": 0.5}`)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, `{"radius": 1.0, "range_filter": 0.5}`, params)
+
+	percentile, params, ok, err := parsePercentileRangeFilter(`{"nprobe": 10, "range_filter": "p90"}`)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 90.0, percentile)
+	assert.NotContains(t, params, rangeFilterKey)
+	assert.Contains(t, params, "nprobe")
+
+	percentile, _, ok, err = parsePercentileRangeFilter(`{"range_filter": "p99.5"}`)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 99.5, percentile)
+
+	for _, invalid := range []string{`{"range_filter": "90"}`, `{"range_filter": "p0"}`, `{"range_filter": "p101"}`, `{"range_filter": "q90"}`} {
+		_, _, _, err = parsePercentileRangeFilter(invalid)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid, invalid)
+	}
+}
+
+func TestFilterSearchResultDataByPercentile(t *testing.T) {
+	genData := func() *schemapb.SearchResultData {
+		return &schemapb.SearchResultData{
+			NumQueries: 2,
+			TopK:       4,
+			Topks:      []int64{4, 2},
+			Scores:     []float32{0.9, 0.8, 0.7, 0.6, 0.5, 0.4},
+			Ids:        &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 4, 5, 6}}}},
+		}
+	}
+
+	// larger is better, drop the best quarter
+	data := genData()
+	filterSearchResultDataByPercentile(data, 75, true)
+	assert.Equal(t, []int64{3, 2}, data.GetTopks())
+	assert.Equal(t, []int64{2, 3, 4, 5, 6}, data.GetIds().GetIntId().GetData())
+
+	// smaller is better
+	data = genData()
+	filterSearchResultDataByPercentile(data, 50, false)
+	assert.Equal(t, []int64{2, 1}, data.GetTopks())
+	assert.Equal(t, []float32{0.9, 0.8, 0.5}, data.GetScores())
+
+	data = genData()
+	filterSearchResultDataByPercentile(data, 100, true)
+	assert.Equal(t, []int64{4, 2}, data.GetTopks())
+}
+
+func TestElbowCut(t *testing.T) {
+	// a clear gap after the second hit
+	assert.Equal(t, int64(2), elbowCut([]float32{0.95, 0.93, 0.4, 0.38, 0.37, 0.36}, 3))
+	// smaller is better, the gap is measured in absolute value
+	assert.Equal(t, int64(1), elbowCut([]float32{0.1, 0.9, 0.92, 0.94}, 2))
+	// the scores decrease smoothly, no elbow
+	assert.Equal(t, int64(3), elbowCut([]float32{0.9, 0.8, 0.7, 0.6, 0.5, 0.4}, 3))
+	assert.Equal(t, int64(3), elbowCut([]float32{0.5, 0.5, 0.5, 0.5}, 3))
+	// the elbow is right after the topk, which is only seen with the candidates
+	assert.Equal(t, int64(3), elbowCut([]float32{0.9, 0.89, 0.88, 0.2, 0.19, 0.18}, 3))
+	// the elbow beyond the topk is ignored
+	assert.Equal(t, int64(2), elbowCut([]float32{0.9, 0.89, 0.88, 0.87, 0.1}, 2))
+	assert.Equal(t, int64(1), elbowCut([]float32{0.9}, 3))
+	assert.Equal(t, int64(0), elbowCut(nil, 3))
+}
+
+func TestCutSearchResultDataAtElbow(t *testing.T) {
+	data := &schemapb.SearchResultData{
+		NumQueries: 2,
+		TopK:       6,
+		Topks:      []int64{4, 2},
+		Scores:     []float32{0.9, 0.88, 0.1, 0.09, 0.5, 0.49},
+		Ids:        &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 4, 5, 6}}}},
+	}
+	cutSearchResultDataAtElbow(data, 3)
+	assert.Equal(t, []int64{2, 2}, data.GetTopks())
+	assert.Equal(t, []int64{1, 2, 5, 6}, data.GetIds().GetIntId().GetData())
+	assert.Equal(t, int64(3), data.GetTopK())
+}
+
+func TestSampleSearchResultData(t *testing.T) {
+	newData := func() *schemapb.SearchResultData {
+		return &schemapb.SearchResultData{
+			NumQueries: 3,
+			TopK:       10,
+			Topks:      []int64{10, 0, 3},
+			Scores:     []float32{10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 3, 2, 1},
+			Ids:        &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}}}},
+		}
+	}
+	data := newData()
+	sampleSearchResultData(data, 0.3, 7)
+	assert.Equal(t, []int64{3, 0, 1}, data.GetTopks())
+	assert.Len(t, data.GetIds().GetIntId().GetData(), 4)
+	// the hits sampled keep their order
+	assert.IsDecreasing(t, data.GetScores()[:3])
+
+	// the same seed samples the same hits
+	again := newData()
+	sampleSearchResultData(again, 0.3, 7)
+	assert.Equal(t, data.GetIds().GetIntId().GetData(), again.GetIds().GetIntId().GetData())
+}
+
+func TestSearchTask_ParseSample(t *testing.T) {
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{SearchRequest: &internalpb.SearchRequest{GroupByFieldId: -1}, request: &milvuspb.SearchRequest{SearchParams: params}}
+	}
+
+	ratio, seed, err := newTask().parseSample()
+	assert.NoError(t, err)
+	assert.Zero(t, ratio)
+	ratio, seed, err = newTask(SampleKey, "0.1", SampleSeedKey, "42").parseSample()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.1, ratio)
+	assert.Equal(t, int64(42), seed)
+
+	for _, kvs := range [][]string{
+		{SampleKey, "0"},
+		{SampleKey, "1.5"},
+		{SampleKey, "NaN"},
+		{SampleKey, "0.1", SampleSeedKey, "abc"},
+	} {
+		_, _, err := newTask(kvs...).parseSample()
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid, kvs)
+	}
+	task := newTask(SampleKey, "0.1")
+	task.SearchRequest.GroupByFieldId = 101
+	_, _, err = task.parseSample()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestParseMaxFieldBytes(t *testing.T) {
+	maxBytes, err := parseMaxFieldBytes(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, maxBytes)
+
+	maxBytes, err = parseMaxFieldBytes([]*commonpb.KeyValuePair{{Key: MaxFieldBytesKey, Value: "1024"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1024, maxBytes)
+
+	paramtable.Get().Save(paramtable.Get().ProxyCfg.DefaultMaxFieldBytes.Key, "512")
+	defer paramtable.Get().Reset(paramtable.Get().ProxyCfg.DefaultMaxFieldBytes.Key)
+	maxBytes, err = parseMaxFieldBytes(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 512, maxBytes)
+	maxBytes, err = parseMaxFieldBytes([]*commonpb.KeyValuePair{{Key: MaxFieldBytesKey, Value: "1024"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1024, maxBytes)
+
+	for _, value := range []string{"0", "-1", "1.5", "abc"} {
+		_, err = parseMaxFieldBytes([]*commonpb.KeyValuePair{{Key: MaxFieldBytesKey, Value: value}})
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	}
+}
+
+func TestTruncateFieldsData(t *testing.T) {
+	genFieldsData := func() []*schemapb.FieldData {
+		return []*schemapb.FieldData{
+			{
+				FieldId: 100,
+				Type:    schemapb.DataType_VarChar,
+				Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_StringData{
+					StringData: &schemapb.StringArray{Data: []string{"abcde", "abcdef"}},
+				}}},
+			},
+			{
+				FieldId: 101,
+				Type:    schemapb.DataType_VarChar,
+				Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_StringData{
+					StringData: &schemapb.StringArray{Data: []string{"abcd", "abcdefgh", "ab中文"}},
+				}}},
+			},
+			{
+				FieldId: 102,
+				Type:    schemapb.DataType_JSON,
+				Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_JsonData{
+					JsonData: &schemapb.JSONArray{Data: [][]byte{[]byte(`{"a":1}`), []byte(`{"a":"b"}`)}},
+				}}},
+			},
+			{
+				FieldId:   103,
+				Type:      schemapb.DataType_JSON,
+				IsDynamic: true,
+				Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_JsonData{
+					JsonData: &schemapb.JSONArray{Data: [][]byte{[]byte(`{"dynamic":"value"}`)}},
+				}}},
+			},
+		}
+	}
+
+	fieldsData := genFieldsData()
+	truncated := truncateFieldsData(fieldsData, 7, 100)
+	assert.Equal(t, 3, truncated)
+	// pk is never truncated
+	assert.Equal(t, []string{"abcde", "abcdef"}, fieldsData[0].GetScalars().GetStringData().GetData())
+	// values no longer than the limit are kept, multi-byte characters are not split
+	assert.Equal(t, []string{"abcd", "abcdefg" + truncatedFieldMarker, "ab中" + truncatedFieldMarker}, fieldsData[1].GetScalars().GetStringData().GetData())
+	jsonData := fieldsData[2].GetScalars().GetJsonData().GetData()
+	assert.Equal(t, `{"a":1}`, string(jsonData[0]))
+	var value string
+	assert.NoError(t, json.Unmarshal(jsonData[1], &value))
+	assert.Equal(t, `{"a":"b`+truncatedFieldMarker, value)
+	// dynamic field is expanded by clients, keep it valid
+	assert.Equal(t, `{"dynamic":"value"}`, string(fieldsData[3].GetScalars().GetJsonData().GetData()[0]))
+
+	fieldsData = genFieldsData()
+	truncated = truncateFieldsData(fieldsData, 6, 0)
+	assert.Equal(t, 4, truncated)
+	assert.Equal(t, []string{"abcde", "abcdef"}, fieldsData[0].GetScalars().GetStringData().GetData())
+	assert.Equal(t, []string{"abcd", "abcdef" + truncatedFieldMarker, "ab中" + truncatedFieldMarker}, fieldsData[1].GetScalars().GetStringData().GetData())
+}
+
+func TestSearchTask_FillCountOnlyResult(t *testing.T) {
+	task := &searchTask{
+		countOnly:              true,
+		resultSizeInsufficient: true,
+		result: &milvuspb.SearchResults{
+			Status: merr.Success(),
+			Results: &schemapb.SearchResultData{
+				NumQueries:       2,
+				TopK:             3,
+				Topks:            []int64{3, 1},
+				Scores:           []float32{0.9, 0.8, 0.7, 0.6},
+				Ids:              &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{1, 2, 3, 4}}}},
+				FieldsData:       []*schemapb.FieldData{{FieldName: "field"}},
+				PrimaryFieldName: "pk",
+			},
+		},
+	}
+	assert.NoError(t, task.fillCountOnlyResult())
+	assert.Equal(t, "[3,1]", task.result.GetStatus().GetExtraInfo()[searchResultMatchCountsKey])
+	results := task.result.GetResults()
+	assert.Equal(t, int64(2), results.GetNumQueries())
+	assert.Equal(t, []int64{0, 0}, results.GetTopks())
+	assert.Empty(t, results.GetScores())
+	assert.Empty(t, results.GetFieldsData())
+	assert.NotNil(t, results.GetIds().GetIntId())
+	assert.Empty(t, results.GetIds().GetIntId().GetData())
+	assert.Equal(t, "pk", results.GetPrimaryFieldName())
+	assert.False(t, task.resultSizeInsufficient)
+}
+
+func TestApplySearchHints(t *testing.T) {
+	hints, params, err := applySearchHints("", `{"ef": 10}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "", hints)
+	assert.Equal(t, `{"ef": 10}`, params)
+
+	hints, params, err = applySearchHints(iterativeFilterHint, `{"ef": 10}`)
+	assert.NoError(t, err)
+	assert.Equal(t, iterativeFilterHint, hints)
+	assert.Equal(t, `{"ef": 10}`, params)
+
+	hints, params, err = applySearchHints("ef=64, iterative_filter,drop_ratio_search=0.2", `{"ef": 10, "nprobe": 8}`)
+	assert.NoError(t, err)
+	assert.Equal(t, iterativeFilterHint, hints)
+	assert.JSONEq(t, `{"ef": 64, "nprobe": 8, "drop_ratio_search": 0.2}`, params)
+
+	hints, params, err = applySearchHints("nprobe=16", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "", hints)
+	assert.JSONEq(t, `{"nprobe": 16}`, params)
+
+	for _, invalid := range []string{"disable", "unknown=1", "ef", "ef=0", "ef=1.5", "ef=8,ef=16", "drop_ratio_search=-1", "drop_ratio_search=NaN"} {
+		_, _, err = applySearchHints(invalid, "")
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid, invalid)
+	}
+}
+
+func TestParseSearchInfoWithHints(t *testing.T) {
+	searchParams := []*commonpb.KeyValuePair{
+		{Key: TopKKey, Value: "10"},
+		{Key: common.MetricTypeKey, Value: metric.L2},
+		{Key: ParamsKey, Value: `{"ef": 10}`},
+		{Key: common.HintsKey, Value: "ef=32"},
+	}
+	info, err := parseSearchInfo(searchParams, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", info.planInfo.GetHints())
+	assert.JSONEq(t, `{"ef": 32}`, info.planInfo.GetSearchParams())
+
+	// hints of sub search requests
+	info, err = parseSearchInfo(searchParams, nil, &rankParams{limit: 10})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ef": 32}`, info.planInfo.GetSearchParams())
+
+	searchParams[3].Value = "unknown=1"
+	_, err = parseSearchInfo(searchParams, nil, nil)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestApplySearchSeed(t *testing.T) {
+	params, err := applySearchSeed(nil, `{"ef": 10}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ef": 10}`, params)
+
+	params, err = applySearchSeed([]*commonpb.KeyValuePair{{Key: SeedKey, Value: "42"}}, `{"ef": 10}`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ef": 10, "seed": 42}`, params)
+
+	params, err = applySearchSeed([]*commonpb.KeyValuePair{{Key: SeedKey, Value: "0"}}, "")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"seed": 0}`, params)
+
+	for _, invalid := range []string{"-1", "1.5", "abc"} {
+		_, err = applySearchSeed([]*commonpb.KeyValuePair{{Key: SeedKey, Value: invalid}}, "")
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid, invalid)
+	}
+}
+
+func TestParseGroupByInfoWithSparseVector(t *testing.T) {
+	paramtable.Init()
+	schema := &schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "sparse", DataType: schemapb.DataType_SparseFloatVector},
+			{FieldID: 102, Name: "c2", DataType: schemapb.DataType_VarChar},
+		},
+	}
+	groupBy := func(fieldName string) []*commonpb.KeyValuePair {
+		params := lo.Filter(getValidSearchParams(), func(kv *commonpb.KeyValuePair, _ int) bool {
+			return kv.GetKey() != AnnsFieldKey
+		})
+		return append(params,
+			&commonpb.KeyValuePair{Key: AnnsFieldKey, Value: "sparse"},
+			&commonpb.KeyValuePair{Key: GroupByFieldKey, Value: fieldName})
+	}
+
+	// searching the sparse vectors grouped by a scalar field is supported
+	info, err := parseGroupByInfo(groupBy("c2"), schema)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(102), info.GetGroupByFieldId())
+
+	// grouping by the sparse vector field itself is not
+	_, err = parseGroupByInfo(groupBy("sparse"), schema)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	assert.ErrorContains(t, err, "grouping by vector fields is not supported")
+	_, err = parseSearchInfo(groupBy("sparse"), schema, nil)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestParseGroupByInfoWithPartitionKey(t *testing.T) {
+	paramtable.Init()
+	schema := &schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "key", DataType: schemapb.DataType_Int64, IsPartitionKey: true},
+			{FieldID: 102, Name: "c2", DataType: schemapb.DataType_Int64},
+		},
+	}
+
+	groupBy := func(fieldName string) []*commonpb.KeyValuePair {
+		return append(getValidSearchParams(), &commonpb.KeyValuePair{
+			Key:   GroupByFieldKey,
+			Value: fieldName,
+		})
+	}
+
+	info, err := parseGroupByInfo(groupBy("c2"), schema)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(102), info.GetGroupByFieldId())
+
+	_, err = parseGroupByInfo(groupBy("key"), schema)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	// search and hybrid search are both rejected
+	_, err = parseSearchInfo(groupBy("key"), schema, nil)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = parseRankParams(append(groupBy("key"), &commonpb.KeyValuePair{Key: LimitKey, Value: "10"}), schema)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestSearchTask_DowngradeConsistencyUnderLoad(t *testing.T) {
+	paramtable.Init()
+	queueLen := 10
+	task := &searchTask{
+		dqQueueLen: func() int { return queueLen },
+	}
+	ctx := context.Background()
+
+	// disabled by default
+	assert.Equal(t, commonpb.ConsistencyLevel_Strong, task.downgradeConsistencyUnderLoad(ctx, commonpb.ConsistencyLevel_Strong))
+	assert.False(t, task.consistencyDowngraded)
+
+	Params.Save(Params.ProxyCfg.ConsistencyDowngradeQueueLen.Key, "10")
+	defer Params.Reset(Params.ProxyCfg.ConsistencyDowngradeQueueLen.Key)
+
+	// only strong consistency is downgraded
+	assert.Equal(t, commonpb.ConsistencyLevel_Eventually, task.downgradeConsistencyUnderLoad(ctx, commonpb.ConsistencyLevel_Eventually))
+	assert.False(t, task.consistencyDowngraded)
+
+	queueLen = 9
+	assert.Equal(t, commonpb.ConsistencyLevel_Strong, task.downgradeConsistencyUnderLoad(ctx, commonpb.ConsistencyLevel_Strong))
+	assert.False(t, task.consistencyDowngraded)
+
+	// iterators are never downgraded
+	queueLen = 10
+	task.isIterator = true
+	assert.Equal(t, commonpb.ConsistencyLevel_Strong, task.downgradeConsistencyUnderLoad(ctx, commonpb.ConsistencyLevel_Strong))
+	assert.False(t, task.consistencyDowngraded)
+
+	task.isIterator = false
+	assert.Equal(t, commonpb.ConsistencyLevel_Bounded, task.downgradeConsistencyUnderLoad(ctx, commonpb.ConsistencyLevel_Strong))
+	assert.True(t, task.consistencyDowngraded)
+}
+
+func TestSearchTask_SchemaVersionMismatch(t *testing.T) {
+	paramtable.Init()
+	ctx := context.Background()
+	cache := NewMockCache(t)
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	task := &searchTask{
+		ctx:            ctx,
+		SearchRequest:  &internalpb.SearchRequest{Base: &commonpb.MsgBase{}, CollectionID: 100},
+		request:        &milvuspb.SearchRequest{DbName: "default"},
+		collectionName: "test_collection",
+		schemaVersion:  200,
+		resultBuf:      typeutil.NewConcurrentSet[*internalpb.SearchResults](),
+	}
+	task.setRequestProperty(common.SearchSchemaVersionKey, strconv.FormatUint(task.schemaVersion, 10))
+
+	// the QueryNode holding an older schema rejects the search, the collection is removed from the cache to re-plan
+	qn := mocks.NewMockQueryNodeClient(t)
+	qn.EXPECT().Search(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, req *querypb.SearchRequest, opts ...grpc.CallOption) (*internalpb.SearchResults, error) {
+		assert.Equal(t, "200", req.GetReq().GetBase().GetProperties()[common.SearchSchemaVersionKey])
+		return &internalpb.SearchResults{Status: merr.Status(merr.WrapErrCollectionSchemaMisMatch(100))}, nil
+	})
+	cache.EXPECT().RemoveCollection(mock.Anything, "default", "test_collection").Return().Once()
+	assert.ErrorIs(t, task.searchShard(ctx, 1, qn, "test_channel"), merr.ErrCollectionSchemaMismatch)
+}
+
+func TestTaskSearch_reduceGroupBySearchWithFewerGroups(t *testing.T) {
+	var (
+		nq    int64 = 1
+		limit int64 = 5
+	)
+	// only 3 groups among the hits, the best hit of each group is returned
+	ids := [][]int64{
+		{1, 3, 5, 7, 9},
+		{2, 4, 6, 8, 10},
+	}
+	scores := [][]float32{
+		{10, 8, 6, 4, 2},
+		{9, 7, 5, 3, 1},
+	}
+	groupByValuesArr := [][]int64{
+		{1, 2, 1, 3, 2},
+		{1, 2, 3, 1, 3},
+	}
+	expectedIDs := []int64{1, 3, 6}
+	expectedScores := []float32{-10, -8, -5}
+	expectedGroupByValues := []int64{1, 2, 3}
+
+	var results []*schemapb.SearchResultData
+	for j := range ids {
+		result := getSearchResultData(nq, limit)
+		result.Ids.IdField = &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: ids[j]}}
+		result.Scores = scores[j]
+		result.Topks = []int64{limit}
+		result.GroupByFieldValue = &schemapb.FieldData{
+			Type: schemapb.DataType_Int64,
+			Field: &schemapb.FieldData_Scalars{
+				Scalars: &schemapb.ScalarField{
+					Data: &schemapb.ScalarField_LongData{
+						LongData: &schemapb.LongArray{
+							Data: groupByValuesArr[j],
+						},
+					},
+				},
+			},
+		}
+		results = append(results, result)
+	}
+
+	reduced, err := reduceSearchResult(context.TODO(), results,
+		reduce.NewReduceSearchResultInfo(nq, limit).WithMetricType(metric.L2).WithPkType(schemapb.DataType_Int64).WithGroupByField(1).WithGroupSize(1))
+	assert.NoError(t, err)
+	assert.EqualValues(t, expectedIDs, reduced.GetResults().GetIds().GetIntId().Data)
+	assert.EqualValues(t, expectedScores, reduced.GetResults().GetScores())
+	assert.EqualValues(t, expectedGroupByValues, reduced.GetResults().GetGroupByFieldValue().GetScalars().GetLongData().GetData())
+	assert.EqualValues(t, []int64{3}, reduced.GetResults().GetTopks())
+}
+
+func TestSearchTask_ParseScanAllPartitions(t *testing.T) {
+	paramtable.Init()
+	cache := NewMockCache(t)
+	cache.EXPECT().GetUserRole("alice").Return([]string{util.RoleAdmin}).Maybe()
+	cache.EXPECT().GetUserRole("bob").Return([]string{"reader"}).Maybe()
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	newTask := func(partitionKeyMode bool, kvs ...string) *searchTask {
+		params := append(getValidSearchParams(), newSearchParams(kvs...)...)
+		return &searchTask{
+			request:          &milvuspb.SearchRequest{SearchParams: params},
+			collectionName:   "test_collection",
+			partitionKeyMode: partitionKeyMode,
+		}
+	}
+	userCtx := func(username string) context.Context {
+		return NewContextWithMetadata(context.Background(), username, "")
+	}
+
+	scanAll, err := newTask(true).parseScanAllPartitions(userCtx(util.UserRoot))
+	assert.NoError(t, err)
+	assert.False(t, scanAll)
+
+	// no one is privileged without authorization
+	_, err = newTask(true, ScanAllPartitionsKey, "true").parseScanAllPartitions(userCtx(util.UserRoot))
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	paramtable.Get().Save(Params.CommonCfg.AuthorizationEnabled.Key, "true")
+	defer paramtable.Get().Reset(Params.CommonCfg.AuthorizationEnabled.Key)
+
+	for _, username := range []string{util.UserRoot, "alice"} {
+		scanAll, err = newTask(true, ScanAllPartitionsKey, "true").parseScanAllPartitions(userCtx(username))
+		assert.NoError(t, err)
+		assert.True(t, scanAll)
+	}
+
+	_, err = newTask(true, ScanAllPartitionsKey, "true").parseScanAllPartitions(userCtx("bob"))
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	_, err = newTask(true, ScanAllPartitionsKey, "true").parseScanAllPartitions(context.Background())
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	_, err = newTask(false, ScanAllPartitionsKey, "true").parseScanAllPartitions(userCtx(util.UserRoot))
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = newTask(true, ScanAllPartitionsKey, "true", PartitionKeyHintsKey, "1").parseScanAllPartitions(userCtx(util.UserRoot))
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = newTask(true, ScanAllPartitionsKey, "yes").parseScanAllPartitions(userCtx(util.UserRoot))
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestParseDynamicFieldPrefixes(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		EnableDynamicField: true,
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: common.MetaFieldName, DataType: schemapb.DataType_JSON, IsDynamic: true},
+		},
+	})
+
+	fields, prefixes, err := parseDynamicFieldPrefixes([]string{"pk", "a"}, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pk", "a"}, fields)
+	assert.Empty(t, prefixes)
+
+	fields, prefixes, err = parseDynamicFieldPrefixes([]string{"pk", "$meta.user_*", "a", "$meta.*"}, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pk", "a", common.MetaFieldName}, fields)
+	assert.Equal(t, []string{"user_", ""}, prefixes)
+
+	// all the dynamic fields are requested anyway
+	fields, prefixes, err = parseDynamicFieldPrefixes([]string{"*", "$meta.user_*"}, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"*"}, fields)
+	assert.Empty(t, prefixes)
+
+	_, _, err = parseDynamicFieldPrefixes([]string{"$meta.user_*_name*"}, schema)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	schema.EnableDynamicField = false
+	_, _, err = parseDynamicFieldPrefixes([]string{"$meta.user_*"}, schema)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestFilterDynamicFieldsByPrefix(t *testing.T) {
+	fieldsData := []*schemapb.FieldData{
+		{
+			FieldName: "c1",
+			Type:      schemapb.DataType_JSON,
+			Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_JsonData{
+				JsonData: &schemapb.JSONArray{Data: [][]byte{[]byte(`{"user_id":1}`)}},
+			}}},
+		},
+		{
+			FieldName: common.MetaFieldName,
+			Type:      schemapb.DataType_JSON,
+			IsDynamic: true,
+			Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_JsonData{
+				JsonData: &schemapb.JSONArray{Data: [][]byte{
+					[]byte(`{"user_id":1,"user_name":"a","age":10,"city":"x"}`),
+					[]byte(`{"user_id":2,"score":1.5}`),
+				}},
+			}}},
+		},
+	}
+
+	unmatched, err := filterDynamicFieldsByPrefix(fieldsData, []string{"age"}, []string{"user_", "item_"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"item_"}, unmatched)
+
+	// non dynamic fields are untouched
+	assert.JSONEq(t, `{"user_id":1}`, string(fieldsData[0].GetScalars().GetJsonData().GetData()[0]))
+	data := fieldsData[1].GetScalars().GetJsonData().GetData()
+	assert.JSONEq(t, `{"user_id":1,"user_name":"a","age":10}`, string(data[0]))
+	assert.JSONEq(t, `{"user_id":2}`, string(data[1]))
+
+	fieldsData[1].GetScalars().GetJsonData().Data = [][]byte{[]byte(`not a json`)}
+	_, err = filterDynamicFieldsByPrefix(fieldsData, nil, []string{"user_"})
+	assert.Error(t, err)
+}
+
+func TestDropDynamicOutputFields(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		EnableDynamicField: true,
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "title", DataType: schemapb.DataType_VarChar},
+			{FieldID: 102, Name: common.MetaFieldName, DataType: schemapb.DataType_JSON, IsDynamic: true},
+		},
+	})
+	drop := func(outputFields ...string) ([]string, []string, error) {
+		translated, userOutput, userDynamic, _, err := translateOutputFields(outputFields, schema, true)
+		require.NoError(t, err)
+		return dropDynamicOutputFields(outputFields, translated, userOutput, userDynamic)
+	}
+
+	// the dynamic field is never expanded from *
+	translated, userOutput, err := drop("*")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"title"}, translated)
+	assert.ElementsMatch(t, []string{"title"}, userOutput)
+
+	translated, userOutput, err = drop("title")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"title"}, translated)
+	assert.Equal(t, []string{"title"}, userOutput)
+
+	// the dynamic keys requested explicitly are rejected
+	_, _, err = drop("title", "a")
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, _, err = drop(common.MetaFieldName)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, _, err = dropDynamicOutputFields([]string{"$meta.user_*"}, nil, nil, nil)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestSearchTask_SearchWithRetry(t *testing.T) {
+	paramtable.Init()
+	ctx := context.Background()
+	task := &searchTask{}
+	req := &querypb.SearchRequest{}
+	retriable := &internalpb.SearchResults{Status: merr.Status(merr.WrapErrServiceUnavailable("overloaded"))}
+	success := &internalpb.SearchResults{Status: merr.Success()}
+
+	t.Run("disabled", func(t *testing.T) {
+		qn := mocks.NewMockQueryNodeClient(t)
+		qn.EXPECT().Search(mock.Anything, mock.Anything).Return(retriable, nil).Once()
+		result, err := task.searchWithRetry(ctx, qn, req)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, merr.Error(result.GetStatus()), merr.ErrServiceUnavailable)
+	})
+
+	Params.Save(Params.ProxyCfg.SearchShardRetryAttempts.Key, "3")
+	defer Params.Reset(Params.ProxyCfg.SearchShardRetryAttempts.Key)
+	Params.Save(Params.ProxyCfg.SearchShardRetryInterval.Key, "10")
+	defer Params.Reset(Params.ProxyCfg.SearchShardRetryInterval.Key)
+
+	t.Run("retry until success", func(t *testing.T) {
+		qn := mocks.NewMockQueryNodeClient(t)
+		qn.EXPECT().Search(mock.Anything, mock.Anything).Return(retriable, nil).Twice()
+		qn.EXPECT().Search(mock.Anything, mock.Anything).Return(success, nil).Once()
+		result, err := task.searchWithRetry(ctx, qn, req)
+		assert.NoError(t, err)
+		assert.True(t, merr.Ok(result.GetStatus()))
+	})
+
+	t.Run("retry exhausted", func(t *testing.T) {
+		qn := mocks.NewMockQueryNodeClient(t)
+		qn.EXPECT().Search(mock.Anything, mock.Anything).Return(retriable, nil).Times(3)
+		result, err := task.searchWithRetry(ctx, qn, req)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, merr.Error(result.GetStatus()), merr.ErrServiceUnavailable)
+	})
+
+	t.Run("not retriable", func(t *testing.T) {
+		qn := mocks.NewMockQueryNodeClient(t)
+		qn.EXPECT().Search(mock.Anything, mock.Anything).Return(&internalpb.SearchResults{
+			Status: merr.Status(merr.WrapErrParameterInvalidMsg("invalid")),
+		}, nil).Once()
+		qn.EXPECT().Search(mock.Anything, mock.Anything).Return(nil, merr.WrapErrServiceUnavailable("rpc")).Once()
+		result, err := task.searchWithRetry(ctx, qn, req)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, merr.Error(result.GetStatus()), merr.ErrParameterInvalid)
+
+		// rpc errors are returned directly
+		_, err = task.searchWithRetry(ctx, qn, req)
+		assert.ErrorIs(t, err, merr.ErrServiceUnavailable)
+	})
+
+	t.Run("respect deadline", func(t *testing.T) {
+		Params.Save(Params.ProxyCfg.SearchShardRetryInterval.Key, "1000")
+		defer Params.Save(Params.ProxyCfg.SearchShardRetryInterval.Key, "10")
+		ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+
+		qn := mocks.NewMockQueryNodeClient(t)
+		qn.EXPECT().Search(mock.Anything, mock.Anything).Return(retriable, nil).Once()
+		start := time.Now()
+		result, err := task.searchWithRetry(ctx, qn, req)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, merr.Error(result.GetStatus()), merr.ErrServiceUnavailable)
+		assert.Less(t, time.Since(start), 200*time.Millisecond)
+	})
+}
+
+func TestSearchTask_CheckDuplicateAnnsFields(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "dense", DataType: schemapb.DataType_FloatVector},
+			{FieldID: 102, Name: "sparse", DataType: schemapb.DataType_SparseFloatVector},
+		},
+	})
+	ctx := context.Background()
+	newTask := func(strict string) *searchTask {
+		params := []*commonpb.KeyValuePair{}
+		if strict != "" {
+			params = append(params, &commonpb.KeyValuePair{Key: StrictAnnsFieldsKey, Value: strict})
+		}
+		return &searchTask{
+			request: &milvuspb.SearchRequest{SearchParams: params},
+			schema:  schema,
+		}
+	}
+
+	// distinct anns fields
+	assert.NoError(t, newTask("").checkDuplicateAnnsFields(ctx, []int64{101, 102}))
+	assert.NoError(t, newTask("true").checkDuplicateAnnsFields(ctx, []int64{101, 102}))
+
+	// duplicate anns fields are only warned if not strict
+	assert.NoError(t, newTask("").checkDuplicateAnnsFields(ctx, []int64{101, 102, 101}))
+	assert.NoError(t, newTask("false").checkDuplicateAnnsFields(ctx, []int64{101, 102, 101}))
+	err := newTask("true").checkDuplicateAnnsFields(ctx, []int64{101, 102, 101})
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	assert.ErrorContains(t, err, "dense")
+
+	assert.ErrorIs(t, newTask("invalid").checkDuplicateAnnsFields(ctx, []int64{101, 102}), merr.ErrParameterInvalid)
+}
+
+func TestSearchTask_FillResultEffectiveWindow(t *testing.T) {
+	task := &searchTask{
+		SearchRequest: &internalpb.SearchRequest{Topk: 15, Offset: 5},
+		result: &milvuspb.SearchResults{
+			Results: &schemapb.SearchResultData{Topks: []int64{10, 4}},
+		},
+	}
+	task.fillResult()
+	extraInfo := task.result.GetStatus().GetExtraInfo()
+	assert.Equal(t, "5", extraInfo[searchResultEffectiveOffsetKey])
+	assert.Equal(t, "10", extraInfo[searchResultEffectiveLimitKey])
+	assert.True(t, task.resultSizeInsufficient)
+
+	// the window of advanced search is applied after the fusion
+	task = &searchTask{
+		SearchRequest: &internalpb.SearchRequest{IsAdvanced: true},
+		rankParams:    &rankParams{limit: 20, offset: 2},
+		result: &milvuspb.SearchResults{
+			Results: &schemapb.SearchResultData{Topks: []int64{20}},
+		},
+	}
+	task.fillResult()
+	extraInfo = task.result.GetStatus().GetExtraInfo()
+	assert.Equal(t, "2", extraInfo[searchResultEffectiveOffsetKey])
+	assert.Equal(t, "20", extraInfo[searchResultEffectiveLimitKey])
+
+	// the topk inflated by adaptive_topk is not the limit, and the hits cut at the elbow are not insufficient
+	task = &searchTask{
+		SearchRequest: &internalpb.SearchRequest{Topk: 25, Offset: 5},
+		adaptiveTopK:  10,
+		result: &milvuspb.SearchResults{
+			Results: &schemapb.SearchResultData{Topks: []int64{3, 10}},
+		},
+	}
+	task.fillResult()
+	extraInfo = task.result.GetStatus().GetExtraInfo()
+	assert.Equal(t, "10", extraInfo[searchResultEffectiveLimitKey])
+	assert.False(t, task.resultSizeInsufficient)
+}
+
+func TestApplyDefaultSearchParams(t *testing.T) {
+	toMap := func(pairs []*commonpb.KeyValuePair) map[string]string {
+		return funcutil.KeyValuePair2Map(pairs)
+	}
+
+	// the request params take precedence, the index params are merged key by key
+	pairs, err := applyDefaultSearchParams([]*commonpb.KeyValuePair{
+		{Key: MetricTypeKey, Value: metric.IP},
+		{Key: ParamsKey, Value: `{"nprobe": 32}`},
+		{Key: TopKKey, Value: "10"},
+	}, `{"metric_type": "L2", "params": {"nprobe": 16, "ef": 64}, "round_decimal": 2, "ignore_growing": true}`)
+	assert.NoError(t, err)
+	params := toMap(pairs)
+	assert.Equal(t, metric.IP, params[MetricTypeKey])
+	assert.JSONEq(t, `{"nprobe": 32, "ef": 64}`, params[ParamsKey])
+	assert.Equal(t, "10", params[TopKKey])
+	assert.Equal(t, "2", params[RoundDecimalKey])
+	assert.Equal(t, "true", params[IgnoreGrowingKey])
+
+	// the defaults are used if the request does not specify them
+	pairs, err = applyDefaultSearchParams([]*commonpb.KeyValuePair{
+		{Key: TopKKey, Value: "10"},
+	}, `{"metric_type": "L2", "params": {"nprobe": 16}}`)
+	assert.NoError(t, err)
+	params = toMap(pairs)
+	assert.Equal(t, metric.L2, params[MetricTypeKey])
+	assert.JSONEq(t, `{"nprobe": 16}`, params[ParamsKey])
+
+	// the request params are kept if no default index params
+	pairs, err = applyDefaultSearchParams([]*commonpb.KeyValuePair{
+		{Key: ParamsKey, Value: `{"nprobe": 32}`},
+	}, `{"metric_type": "L2"}`)
+	assert.NoError(t, err)
+	params = toMap(pairs)
+	assert.Equal(t, `{"nprobe": 32}`, params[ParamsKey])
+	assert.Equal(t, metric.L2, params[MetricTypeKey])
+
+	_, err = applyDefaultSearchParams(nil, `invalid`)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = applyDefaultSearchParams(nil, `{"params": "nprobe"}`)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, err = applyDefaultSearchParams([]*commonpb.KeyValuePair{{Key: ParamsKey, Value: "invalid"}}, `{"params": {"nprobe": 16}}`)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestSearchTask_ApplyCollectionDefaultSearchParams(t *testing.T) {
+	ctx := context.Background()
+	properties := []*commonpb.KeyValuePair{}
+	cache := NewMockCache(t)
+	cache.EXPECT().GetCollectionInfo(mock.Anything, mock.Anything, mock.Anything, mock.Anything).RunAndReturn(
+		func(ctx context.Context, database, collectionName string, collectionID int64) (*collectionInfo, error) {
+			return &collectionInfo{properties: properties}, nil
+		})
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	searchParams := []*commonpb.KeyValuePair{{Key: ParamsKey, Value: `{"nprobe": 32}`}}
+	task := &searchTask{
+		SearchRequest: &internalpb.SearchRequest{},
+		request:       &milvuspb.SearchRequest{SearchParams: searchParams},
+	}
+
+	// no defaults, the search params are untouched
+	assert.NoError(t, task.applyCollectionDefaultSearchParams(ctx))
+	assert.Equal(t, searchParams, task.request.GetSearchParams())
+
+	properties = []*commonpb.KeyValuePair{{Key: common.CollectionDefaultSearchParamsKey, Value: `{"metric_type": "L2", "params": {"nprobe": 16}}`}}
+	assert.NoError(t, task.applyCollectionDefaultSearchParams(ctx))
+	params := funcutil.KeyValuePair2Map(task.request.GetSearchParams())
+	assert.Equal(t, metric.L2, params[MetricTypeKey])
+	assert.JSONEq(t, `{"nprobe": 32}`, params[ParamsKey])
+
+	// sub requests of advanced search
+	task = &searchTask{
+		SearchRequest: &internalpb.SearchRequest{IsAdvanced: true},
+		request: &milvuspb.SearchRequest{SubReqs: []*milvuspb.SubSearchRequest{
+			{SearchParams: []*commonpb.KeyValuePair{{Key: MetricTypeKey, Value: metric.IP}}},
+			{},
+		}},
+	}
+	assert.NoError(t, task.applyCollectionDefaultSearchParams(ctx))
+	assert.Equal(t, metric.IP, funcutil.KeyValuePair2Map(task.request.GetSubReqs()[0].GetSearchParams())[MetricTypeKey])
+	assert.Equal(t, metric.L2, funcutil.KeyValuePair2Map(task.request.GetSubReqs()[1].GetSearchParams())[MetricTypeKey])
+}
+
+func newCommonFilterTestSchemaHelper(t testing.TB) *typeutil.SchemaHelper {
+	schema := &schemapb.CollectionSchema{
+		Name: "test_common_filter",
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "id", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "age", DataType: schemapb.DataType_Int64},
+			{FieldID: 102, Name: "tag", DataType: schemapb.DataType_VarChar, TypeParams: []*commonpb.KeyValuePair{{Key: common.MaxLengthKey, Value: "64"}}},
+			{FieldID: 103, Name: "vector", DataType: schemapb.DataType_FloatVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "8"}}},
+		},
+	}
+	helper, err := typeutil.CreateSchemaHelper(schema)
+	require.NoError(t, err)
+	return helper
+}
+
+func TestAndCommonFilter(t *testing.T) {
+	helper := newCommonFilterTestSchemaHelper(t)
+	parse := func(expr string) *planpb.Expr {
+		parsed, err := planparserv2.ParseExpr(helper, expr, nil)
+		require.NoError(t, err)
+		return parsed
+	}
+	commonExpr := parse("age > 10")
+
+	t.Run("no common filter", func(t *testing.T) {
+		subExpr := parse("tag == 'a'")
+		assert.Same(t, subExpr, andCommonFilter(nil, subExpr))
+		assert.Nil(t, andCommonFilter(nil, nil))
+	})
+
+	t.Run("inherit common filter", func(t *testing.T) {
+		merged := andCommonFilter(commonExpr, nil)
+		assert.True(t, proto.Equal(commonExpr, merged))
+		assert.NotSame(t, commonExpr, merged)
+	})
+
+	t.Run("additional sub filter", func(t *testing.T) {
+		subExpr := parse("tag == 'a'")
+		merged := andCommonFilter(commonExpr, subExpr)
+		assert.True(t, proto.Equal(parse("age > 10 and tag == 'a'"), merged))
+	})
+
+	t.Run("sub filter contains common filter", func(t *testing.T) {
+		subExpr := parse("tag == 'a' and (age > 10 and id < 100)")
+		assert.Same(t, subExpr, andCommonFilter(commonExpr, subExpr))
+
+		subExpr = parse("tag == 'a' or age > 10")
+		merged := andCommonFilter(commonExpr, subExpr)
+		assert.Equal(t, planpb.BinaryExpr_LogicalAnd, merged.GetBinaryExpr().GetOp())
+	})
+}
+
+func TestSearchTask_ParseCommonFilter(t *testing.T) {
+	helper := newCommonFilterTestSchemaHelper(t)
+	newTask := func(filter string) *searchTask {
+		task := &searchTask{
+			ctx:     context.Background(),
+			request: &milvuspb.SearchRequest{},
+			schema:  &schemaInfo{schemaHelper: helper},
+		}
+		if filter != "" {
+			task.request.SearchParams = []*commonpb.KeyValuePair{{Key: CommonFilterKey, Value: filter}}
+		}
+		return task
+	}
+
+	expr, err := newTask("").parseCommonFilter()
+	assert.NoError(t, err)
+	assert.Nil(t, expr)
+
+	expr, err = newTask("age > 10").parseCommonFilter()
+	assert.NoError(t, err)
+	assert.NotNil(t, expr.GetUnaryRangeExpr())
+
+	_, err = newTask("unknown_field > 10").parseCommonFilter()
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+// BenchmarkParseCommonFilter measures the proxy side parsing of the filters of a hybrid search, the plans sent to
+// QueryNodes are of the same size either way as the common filter is ANDed into every sub search request.
+func BenchmarkParseCommonFilter(b *testing.B) {
+	helper := newCommonFilterTestSchemaHelper(b)
+	commonFilter := "tag in ['a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'] and age > 10"
+	subFilters := []string{"", "", "id < 1000", commonFilter}
+
+	// every sub search request repeats the common filter in its own filter
+	b.Run("per_sub_request", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, subFilter := range subFilters {
+				filter := commonFilter
+				if subFilter != "" {
+					filter = fmt.Sprintf("(%s) and (%s)", commonFilter, subFilter)
+				}
+				_, err := planparserv2.ParseExpr(helper, filter, nil)
+				require.NoError(b, err)
+			}
+		}
+	})
+
+	// the common filter is parsed once and ANDed into the filters of the sub search requests
+	b.Run("common_filter", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			commonExpr, err := planparserv2.ParseExpr(helper, commonFilter, nil)
+			require.NoError(b, err)
+			for _, subFilter := range subFilters {
+				var subExpr *planpb.Expr
+				if subFilter != "" {
+					subExpr, err = planparserv2.ParseExpr(helper, subFilter, nil)
+					require.NoError(b, err)
+				}
+				andCommonFilter(commonExpr, subExpr)
+			}
+		}
+	})
+}
+
+func TestSearchTask_ParseGroupResultsByPartition(t *testing.T) {
+	cache := NewMockCache(t)
+	cache.EXPECT().GetPartitions(mock.Anything, mock.Anything, mock.Anything).Return(map[string]int64{"_default": 102, "p1": 101}, nil).Maybe()
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	newTask := func(enabled string, partitionIDs ...int64) *searchTask {
+		params := getValidSearchParams()
+		if enabled != "" {
+			params = append(params, &commonpb.KeyValuePair{Key: GroupResultsByPartitionKey, Value: enabled})
+		}
+		return &searchTask{
+			SearchRequest:  &internalpb.SearchRequest{PartitionIDs: partitionIDs},
+			request:        &milvuspb.SearchRequest{SearchParams: params},
+			collectionName: "test_collection",
+			queryInfos:     []*planpb.QueryInfo{{GroupByFieldId: -1}},
+		}
+	}
+
+	task := newTask("")
+	assert.NoError(t, task.parseGroupResultsByPartition(context.Background()))
+	assert.Empty(t, task.resultPartitionIDs)
+	assert.Nil(t, task.resultPartitions)
+
+	assert.Error(t, newTask("invalid").parseGroupResultsByPartition(context.Background()))
+
+	task = newTask("true")
+	assert.NoError(t, task.parseGroupResultsByPartition(context.Background()))
+	assert.Equal(t, []int64{101, 102}, task.resultPartitionIDs)
+	assert.NotNil(t, task.resultPartitions)
+
+	task = newTask("true", 3, 1, 3)
+	assert.NoError(t, task.parseGroupResultsByPartition(context.Background()))
+	assert.Equal(t, []int64{1, 3}, task.resultPartitionIDs)
+
+	task = newTask("true")
+	task.SearchRequest.IsAdvanced = true
+	assert.ErrorIs(t, task.parseGroupResultsByPartition(context.Background()), merr.ErrParameterInvalid)
+
+	task = newTask("true")
+	task.rowPartitionIDs = []int64{1, 2}
+	assert.ErrorIs(t, task.parseGroupResultsByPartition(context.Background()), merr.ErrParameterInvalid)
+
+	task = newTask("true")
+	task.queryInfos[0].GroupByFieldId = 100
+	assert.ErrorIs(t, task.parseGroupResultsByPartition(context.Background()), merr.ErrParameterInvalid)
+
+	// bounded by the partition key fan-out
+	paramtable.Get().Save(paramtable.Get().ProxyCfg.MaxPartitionKeyFanout.Key, "2")
+	defer paramtable.Get().Reset(paramtable.Get().ProxyCfg.MaxPartitionKeyFanout.Key)
+	assert.NoError(t, newTask("true", 1, 2).parseGroupResultsByPartition(context.Background()))
+	assert.ErrorIs(t, newTask("true", 1, 2, 3).parseGroupResultsByPartition(context.Background()), merr.ErrParameterInvalid)
+}
+
+func TestMergeSearchResultsByPartition(t *testing.T) {
+	newResult := func(ids []int64, scores []float32, topks []int64) *milvuspb.SearchResults {
+		return &milvuspb.SearchResults{
+			Results: &schemapb.SearchResultData{
+				NumQueries: int64(len(topks)),
+				Ids:        &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: ids}}},
+				Scores:     scores,
+				Topks:      topks,
+				FieldsData: []*schemapb.FieldData{testutils.GenerateScalarFieldData(schemapb.DataType_Int64, "age", len(ids))},
+			},
+		}
+	}
+	results := []*milvuspb.SearchResults{
+		newResult([]int64{1, 2, 3}, []float32{0.9, 0.8, 0.7}, []int64{2, 1}),
+		newResult([]int64{4, 5}, []float32{0.95, 0.6}, []int64{1, 1}),
+	}
+
+	merged := mergeSearchResultsByPartition(results, []int64{10, 20}, 2)
+	data := merged.GetResults()
+	assert.Equal(t, []int64{3, 2}, data.GetTopks())
+	assert.Equal(t, int64(3), data.GetTopK())
+	assert.Equal(t, []int64{1, 2, 4, 3, 5}, data.GetIds().GetIntId().GetData())
+	assert.Equal(t, []float32{0.9, 0.8, 0.95, 0.7, 0.6}, data.GetScores())
+	require.Len(t, data.GetFieldsData(), 2)
+	assert.Equal(t, "age", data.GetFieldsData()[0].GetFieldName())
+	assert.Len(t, data.GetFieldsData()[0].GetScalars().GetLongData().GetData(), 5)
+	assert.Equal(t, partitionIDOutputField, data.GetFieldsData()[1].GetFieldName())
+	assert.Equal(t, []int64{10, 10, 20, 10, 20}, data.GetFieldsData()[1].GetScalars().GetLongData().GetData())
+
+	merged = mergeSearchResultsByPartition(nil, nil, 2)
+	assert.Equal(t, []int64{0, 0}, merged.GetResults().GetTopks())
+	assert.Len(t, merged.GetResults().GetFieldsData(), 1)
+}
+
+func TestSearchTask_PartialResultsOnTimeout(t *testing.T) {
+	paramtable.Init()
+	cache := NewMockCache(t)
+	cache.EXPECT().DeprecateShardCache(mock.Anything, mock.Anything).Return().Maybe()
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	newTask := func(ctx context.Context, partialResultsOnTimeout bool) *searchTask {
+		fastQN := mocks.NewMockQueryNodeClient(t)
+		fastQN.EXPECT().Search(mock.Anything, mock.Anything).Return(&internalpb.SearchResults{
+			Status:     merr.Success(),
+			NumQueries: 1,
+			TopK:       10,
+		}, nil)
+		// the slow shard never responds before the deadline
+		slowQN := mocks.NewMockQueryNodeClient(t)
+		slowQN.EXPECT().Search(mock.Anything, mock.Anything).RunAndReturn(
+			func(ctx context.Context, request *querypb.SearchRequest, option ...grpc.CallOption) (*internalpb.SearchResults, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+
+		lb := NewMockLBPolicy(t)
+		lb.EXPECT().Execute(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, workload CollectionWorkLoad) error {
+			wg, ctx := errgroup.WithContext(ctx)
+			wg.Go(func() error { return workload.exec(ctx, 1, fastQN, "dml_0") })
+			wg.Go(func() error { return workload.exec(ctx, 2, slowQN, "dml_1") })
+			return wg.Wait()
+		})
+		lb.EXPECT().UpdateCostMetrics(mock.Anything, mock.Anything).Return().Maybe()
+
+		return &searchTask{
+			ctx: ctx,
+			SearchRequest: &internalpb.SearchRequest{
+				Base:         &commonpb.MsgBase{MsgID: 1},
+				CollectionID: 100,
+				Nq:           1,
+			},
+			request:                 &milvuspb.SearchRequest{},
+			collectionName:          "test_collection",
+			resultBuf:               typeutil.NewConcurrentSet[*internalpb.SearchResults](),
+			queriedChannels:         typeutil.NewConcurrentSet[string](),
+			lb:                      lb,
+			partialResultsOnTimeout: partialResultsOnTimeout,
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		task := newTask(ctx, false)
+		assert.Error(t, task.Execute(ctx))
+		assert.False(t, task.partialResults)
+	})
+
+	t.Run("reduce partial results", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		task := newTask(ctx, true)
+		assert.NoError(t, task.Execute(ctx))
+		assert.True(t, task.partialResults)
+		assert.NoError(t, ctx.Err())
+
+		results, err := task.collectSearchResults(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("collect after deadline", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		task := &searchTask{ctx: ctx, resultBuf: typeutil.NewConcurrentSet[*internalpb.SearchResults]()}
+		task.resultBuf.Insert(&internalpb.SearchResults{})
+		_, err := task.collectSearchResults(ctx)
+		assert.Error(t, err)
+
+		task.partialResultsOnTimeout = true
+		results, err := task.collectSearchResults(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.True(t, task.partialResults)
+	})
+}
+
+func TestSearchTask_ResolveMetricType(t *testing.T) {
+	mixCoord := NewMixCoordMock()
+	mixCoord.DescribeIndexFunc = func(ctx context.Context, request *indexpb.DescribeIndexRequest, opts ...grpc.CallOption) (*indexpb.DescribeIndexResponse, error) {
+		return &indexpb.DescribeIndexResponse{
+			Status: merr.Success(),
+			IndexInfos: []*indexpb.IndexInfo{
+				{FieldID: 101, IndexParams: []*commonpb.KeyValuePair{{Key: common.MetricTypeKey, Value: metric.COSINE}}},
+			},
+		}, nil
+	}
+	cache, err := NewMetaCache(mixCoord, nil)
+	require.NoError(t, err)
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+	newTask := func() *searchTask {
+		return &searchTask{
+			ctx:            context.Background(),
+			SearchRequest:  &internalpb.SearchRequest{CollectionID: time.Now().UnixNano()},
+			collectionName: "test_collection",
+		}
+	}
+
+	t.Run("auto fill", func(t *testing.T) {
+		queryInfo := &planpb.QueryInfo{QueryFieldId: 101}
+		assert.NoError(t, newTask().resolveMetricType(queryInfo, "vec"))
+		assert.Equal(t, metric.COSINE, queryInfo.GetMetricType())
+	})
+
+	t.Run("specified", func(t *testing.T) {
+		queryInfo := &planpb.QueryInfo{QueryFieldId: 101, MetricType: metric.IP}
+		assert.NoError(t, newTask().resolveMetricType(queryInfo, "vec"))
+		assert.Equal(t, metric.IP, queryInfo.GetMetricType())
+	})
+
+	t.Run("no index on field", func(t *testing.T) {
+		queryInfo := &planpb.QueryInfo{QueryFieldId: 102}
+		err := newTask().resolveMetricType(queryInfo, "vec2")
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+		assert.Contains(t, err.Error(), "vec2")
+	})
+
+	t.Run("no index metadata", func(t *testing.T) {
+		cache, err := NewMetaCache(NewMixCoordMock(), nil)
+		require.NoError(t, err)
+		globalMetaCache = cache
+		task := newTask()
+		queryInfo := &planpb.QueryInfo{QueryFieldId: 101}
+		assert.NoError(t, task.resolveMetricType(queryInfo, "vec"))
+		assert.Empty(t, queryInfo.GetMetricType())
+	})
+}
+
+func TestSearchCursor(t *testing.T) {
+	t.Run("encode and decode", func(t *testing.T) {
+		cursor := &searchCursor{CollectionID: 100, MetricType: metric.L2, Score: 0.1, IntPKs: []int64{1, 2}}
+		token, err := encodeSearchCursor(cursor)
+		require.NoError(t, err)
+
+		decoded, err := decodeSearchCursor(token, 100)
+		require.NoError(t, err)
+		assert.Equal(t, cursor, decoded)
+
+		_, err = decodeSearchCursor(token, 101)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+		_, err = decodeSearchCursor("not a token", 100)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+		// malformed in structure
+		for _, malformed := range []*searchCursor{
+			{CollectionID: 100, Score: 0.1, IntPKs: []int64{1}},
+			{CollectionID: 100, MetricType: metric.L2, Score: 0.1},
+			{CollectionID: 100, MetricType: metric.L2, Score: 0.1, IntPKs: []int64{1}, StrPKs: []string{"a"}},
+			{CollectionID: 100, MetricType: metric.L2, Score: 0.1, IntPKs: make([]int64, maxSearchCursorTiedPKs+1)},
+		} {
+			bs, err := json.Marshal(malformed)
+			require.NoError(t, err)
+			_, err = decodeSearchCursor(base64.RawURLEncoding.EncodeToString(bs), 100)
+			assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+		}
+	})
+
+	t.Run("has range search params", func(t *testing.T) {
+		for paramsStr, expected := range map[string]bool{
+			"":                                 false,
+			`{"nprobe": 10}`:                   false,
+			`{"nprobe": 10, "radius": 0.5}`:    true,
+			`{"range_filter": 0.5}`:            true,
+			`{"search_list": "radius_tuning"}`: false,
+		} {
+			isRangeSearch, err := hasRangeSearchParams(paramsStr)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, isRangeSearch, paramsStr)
+		}
+		_, err := hasRangeSearchParams("not json")
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	})
+
+	t.Run("range search params", func(t *testing.T) {
+		params := (&searchCursor{MetricType: metric.IP, Score: 0.5}).rangeSearchParams()
+		assert.Equal(t, float64(0.5), params[rangeFilterKey])
+		assert.Less(t, params[radiusKey], float64(0))
+
+		params = (&searchCursor{MetricType: metric.L2, Score: 0.5}).rangeSearchParams()
+		assert.Equal(t, float64(0.5), params[rangeFilterKey])
+		assert.Greater(t, params[radiusKey], float64(0.5))
+	})
+
+	t.Run("exclusion expr", func(t *testing.T) {
+		assert.Equal(t, "pk not in [1, 2]", (&searchCursor{IntPKs: []int64{1, 2}}).exclusionExpr("pk"))
+		assert.Equal(t, `pk not in ["a", "b\"c"]`, (&searchCursor{StrPKs: []string{"a", `b"c`}}).exclusionExpr("pk"))
+	})
+
+	t.Run("next cursor", func(t *testing.T) {
+		cursor, err := nextSearchCursor(&schemapb.SearchResultData{}, nil, 100, metric.L2)
+		assert.NoError(t, err)
+		assert.Nil(t, cursor)
+
+		data := &schemapb.SearchResultData{
+			Ids:    &schemapb.IDs{IdField: &schemapb.IDs_IntId{IntId: &schemapb.LongArray{Data: []int64{5, 3, 4}}}},
+			Scores: []float32{0.1, 0.2, 0.2},
+			Topks:  []int64{3},
+		}
+		cursor, err = nextSearchCursor(data, nil, 100, metric.L2)
+		require.NoError(t, err)
+		assert.Equal(t, float32(0.2), cursor.Score)
+		assert.ElementsMatch(t, []int64{3, 4}, cursor.IntPKs)
+
+		// the ties continue from the previous page
+		cursor, err = nextSearchCursor(data, &searchCursor{Score: 0.2, IntPKs: []int64{1}}, 100, metric.L2)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{1, 3, 4}, cursor.IntPKs)
+		cursor, err = nextSearchCursor(data, &searchCursor{Score: 0.05, IntPKs: []int64{1}}, 100, metric.L2)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{3, 4}, cursor.IntPKs)
+
+		// too many ties to page by the cursor
+		_, err = nextSearchCursor(data, &searchCursor{Score: 0.2, IntPKs: make([]int64, maxSearchCursorTiedPKs)}, 100, metric.L2)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	})
+}
+
+func TestSearchTask_ApplySearchCursor(t *testing.T) {
+	schema := newSchemaInfo(constructCollectionSchema(testInt64Field, testFloatVecField, 8, "test_collection"))
+	newTask := func(kvs ...string) *searchTask {
+		params := append(getValidSearchParams(), newSearchParams(kvs...)...)
+		return &searchTask{
+			SearchRequest: &internalpb.SearchRequest{CollectionID: 100, Nq: 1},
+			request:       &milvuspb.SearchRequest{SearchParams: params, Dsl: "age > 10"},
+			schema:        schema,
+		}
+	}
+
+	task := newTask()
+	assert.NoError(t, task.applySearchCursor())
+	assert.False(t, task.withSearchCursor)
+
+	// the first page
+	task = newTask(SearchCursorKey, "")
+	assert.NoError(t, task.applySearchCursor())
+	assert.True(t, task.withSearchCursor)
+	assert.Nil(t, task.searchCursor)
+	assert.Equal(t, "age > 10", task.request.GetDsl())
+
+	token, err := encodeSearchCursor(&searchCursor{CollectionID: 100, MetricType: metric.L2, Score: 0.5, IntPKs: []int64{7}})
+	require.NoError(t, err)
+	task = newTask(SearchCursorKey, token)
+	assert.NoError(t, task.applySearchCursor())
+	assert.NotNil(t, task.searchCursor)
+	assert.Equal(t, fmt.Sprintf("(age > 10) and (%s not in [7])", testInt64Field), task.request.GetDsl())
+	searchParams := funcutil.KeyValuePair2Map(task.request.GetSearchParams())
+	indexParams := make(map[string]any)
+	require.NoError(t, json.Unmarshal([]byte(searchParams[ParamsKey]), &indexParams))
+	assert.Equal(t, float64(10), indexParams["nprobe"])
+	assert.Equal(t, float64(0.5), indexParams[rangeFilterKey])
+	assert.Contains(t, indexParams, radiusKey)
+
+	// the metric type shall not be changed between pages
+	token, err = encodeSearchCursor(&searchCursor{CollectionID: 100, MetricType: metric.IP, Score: 0.5, IntPKs: []int64{7}})
+	require.NoError(t, err)
+	assert.ErrorIs(t, newTask(SearchCursorKey, token).applySearchCursor(), merr.ErrParameterInvalid)
+
+	for _, kvs := range [][]string{
+		{SearchCursorKey, "", OffsetKey, "10"},
+		{SearchCursorKey, "", IteratorField, "true"},
+		{SearchCursorKey, "invalid"},
+	} {
+		assert.ErrorIs(t, newTask(kvs...).applySearchCursor(), merr.ErrParameterInvalid)
+	}
+	task = newTask(SearchCursorKey, "")
+	task.SearchRequest.Nq = 2
+	assert.ErrorIs(t, task.applySearchCursor(), merr.ErrParameterInvalid)
+}
+
+func TestValidateExprTemplateValues(t *testing.T) {
+	values := map[string]*schemapb.TemplateValue{
+		"age": {Val: &schemapb.TemplateValue_Int64Val{Int64Val: 10}},
+		"ids": {Val: &schemapb.TemplateValue_ArrayVal{ArrayVal: &schemapb.TemplateArrayValue{
+			Data: &schemapb.TemplateArrayValue_LongData{LongData: &schemapb.LongArray{Data: []int64{1, 2, 3}}},
+		}}},
+		"nested": {Val: &schemapb.TemplateValue_ArrayVal{ArrayVal: &schemapb.TemplateArrayValue{
+			Data: &schemapb.TemplateArrayValue_ArrayData{ArrayData: &schemapb.TemplateArrayValueArray{
+				Data: []*schemapb.TemplateArrayValue{
+					{Data: &schemapb.TemplateArrayValue_StringData{StringData: &schemapb.StringArray{Data: []string{"a", "b"}}}},
+					{Data: &schemapb.TemplateArrayValue_BoolData{BoolData: &schemapb.BoolArray{Data: []bool{true}}}},
+				},
+			}},
+		}}},
+	}
+	size := int64(0)
+	for _, value := range values {
+		size += int64(proto.Size(value))
+	}
+
+	assert.NoError(t, validateExprTemplateValues(nil, 1, 1))
+	assert.NoError(t, validateExprTemplateValues(values, 7, size))
+	assert.NoError(t, validateExprTemplateValues(values, 0, 0))
+
+	err := validateExprTemplateValues(values, 6, 0)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	assert.ErrorContains(t, err, "7 exceeds the limit 6")
+
+	err = validateExprTemplateValues(values, 0, size-1)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	assert.ErrorContains(t, err, fmt.Sprintf("%d bytes exceeds the limit %d bytes", size, size-1))
+
+	t.Run("search", func(t *testing.T) {
+		paramtable.Init()
+		Params.Save(Params.ProxyCfg.MaxExprTemplateValueCount.Key, "2")
+		defer Params.Reset(Params.ProxyCfg.MaxExprTemplateValueCount.Key)
+
+		schema := constructCollectionSchema(testInt64Field, testFloatVecField, 8, "test_collection")
+		schemaInfo := newSchemaInfo(schema)
+		task := &searchTask{
+			ctx:           context.Background(),
+			SearchRequest: &internalpb.SearchRequest{},
+			schema:        schemaInfo,
+		}
+		_, _, _, _, err := task.tryGeneratePlan(getValidSearchParams(), testInt64Field+" in {ids}", values)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+		assert.ErrorContains(t, err, "exceeds the limit 2")
+	})
+}
+
+func TestSearchTask_DefaultVectorField(t *testing.T) {
+	paramtable.Init()
+	schema := constructCollectionSchema(testInt64Field, testFloatVecField, 8, "test_collection")
+	schema.Fields = append(schema.Fields, &schemapb.FieldSchema{
+		FieldID:    common.StartOfUserFieldID + 2,
+		Name:       "another_vec",
+		DataType:   schemapb.DataType_FloatVector,
+		TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "8"}},
+	})
+	task := &searchTask{
+		ctx:           context.Background(),
+		SearchRequest: &internalpb.SearchRequest{},
+		schema:        newSchemaInfo(schema),
+	}
+	params := lo.Filter(getValidSearchParams(), func(kv *commonpb.KeyValuePair, _ int) bool {
+		return kv.GetKey() != AnnsFieldKey
+	})
+
+	_, _, _, _, err := task.tryGeneratePlan(params, "", nil)
+	assert.ErrorContains(t, err, "multiple anns_fields exist")
+
+	_, _, _, _, err = task.tryGeneratePlan(append(params, &commonpb.KeyValuePair{Key: DefaultVectorFieldKey, Value: "last"}), "", nil)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	plan, _, _, _, err := task.tryGeneratePlan(append(params, &commonpb.KeyValuePair{Key: DefaultVectorFieldKey, Value: defaultVectorFieldFirst}), "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(common.StartOfUserFieldID+1), plan.GetVectorAnns().GetFieldId())
+}
+
+func TestSearchTask_ParsePinnedNodes(t *testing.T) {
+	paramtable.Init()
+	cache := NewMockCache(t)
+	cache.EXPECT().GetUserRole("bob").Return([]string{"reader"}).Maybe()
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	newTask := func(kvs ...string) *searchTask {
+		params := append(getValidSearchParams(), newSearchParams(kvs...)...)
+		return &searchTask{
+			request:        &milvuspb.SearchRequest{SearchParams: params},
+			collectionName: "test_collection",
+		}
+	}
+	rootCtx := NewContextWithMetadata(context.Background(), util.UserRoot, "")
+
+	nodeIDs, err := newTask().parsePinnedNodes(rootCtx)
+	assert.NoError(t, err)
+	assert.Empty(t, nodeIDs)
+
+	// no one is privileged without authorization
+	_, err = newTask(NodeIDsKey, "[1]").parsePinnedNodes(rootCtx)
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	paramtable.Get().Save(Params.CommonCfg.AuthorizationEnabled.Key, "true")
+	defer paramtable.Get().Reset(Params.CommonCfg.AuthorizationEnabled.Key)
+
+	nodeIDs, err = newTask(NodeIDsKey, "[3, 1, 3]").parsePinnedNodes(rootCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 1}, nodeIDs)
+
+	_, err = newTask(NodeIDsKey, "[1]").parsePinnedNodes(NewContextWithMetadata(context.Background(), "bob", ""))
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	for _, invalid := range []string{"[]", "1,2", `["a"]`} {
+		_, err = newTask(NodeIDsKey, invalid).parsePinnedNodes(rootCtx)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	}
+}
+
+func TestSearchTask_ParseImpersonateUser(t *testing.T) {
+	paramtable.Init()
+	cache := NewMockCache(t)
+	cache.EXPECT().GetUserRole("gateway").Return([]string{"reader"}).Maybe()
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			request:        &milvuspb.SearchRequest{SearchParams: params},
+			collectionName: "test_collection",
+		}
+	}
+	rootCtx := NewContextWithMetadata(context.Background(), util.UserRoot, "")
+
+	username, err := newTask().parseImpersonateUser(rootCtx)
+	assert.NoError(t, err)
+	assert.Empty(t, username)
+
+	// no one is privileged without authorization
+	_, err = newTask(ImpersonateUserKey, "alice").parseImpersonateUser(rootCtx)
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	paramtable.Get().Save(Params.CommonCfg.AuthorizationEnabled.Key, "true")
+	defer paramtable.Get().Reset(Params.CommonCfg.AuthorizationEnabled.Key)
+
+	username, err = newTask(ImpersonateUserKey, "alice").parseImpersonateUser(rootCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", username)
+
+	_, err = newTask(ImpersonateUserKey, "alice").parseImpersonateUser(NewContextWithMetadata(context.Background(), "gateway", ""))
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	_, err = newTask(ImpersonateUserKey, "1alice").parseImpersonateUser(rootCtx)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestSearchTask_ParseResourceGroup(t *testing.T) {
+	paramtable.Init()
+	cache := NewMockCache(t)
+	cache.EXPECT().GetUserRole("bob").Return([]string{"reader"}).Maybe()
+	cache.EXPECT().GetResourceGroupInfo(mock.Anything, mock.Anything).RunAndReturn(
+		func(ctx context.Context, resourceGroup string) (*querypb.ResourceGroupInfo, error) {
+			switch resourceGroup {
+			case "rg1":
+				return &querypb.ResourceGroupInfo{
+					Name:             "rg1",
+					NumLoadedReplica: map[int64]int32{1: 1},
+					Nodes:            []*commonpb.NodeInfo{{NodeId: 3}, {NodeId: 4}},
+				}, nil
+			case "rg2":
+				return &querypb.ResourceGroupInfo{Name: "rg2", Nodes: []*commonpb.NodeInfo{{NodeId: 5}}}, nil
+			default:
+				return nil, merr.WrapErrResourceGroupNotFound(resourceGroup)
+			}
+		}).Maybe()
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			SearchRequest:  &internalpb.SearchRequest{CollectionID: 1},
+			request:        &milvuspb.SearchRequest{SearchParams: params},
+			collectionName: "test_collection",
+		}
+	}
+	rootCtx := NewContextWithMetadata(context.Background(), util.UserRoot, "")
+
+	nodeIDs, err := newTask().parseResourceGroup(rootCtx)
+	assert.NoError(t, err)
+	assert.Empty(t, nodeIDs)
+
+	// no one is privileged without authorization
+	_, err = newTask(ResourceGroupKey, "rg1").parseResourceGroup(rootCtx)
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	paramtable.Get().Save(Params.CommonCfg.AuthorizationEnabled.Key, "true")
+	defer paramtable.Get().Reset(Params.CommonCfg.AuthorizationEnabled.Key)
+
+	nodeIDs, err = newTask(ResourceGroupKey, "rg1").parseResourceGroup(rootCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, nodeIDs)
+
+	_, err = newTask(ResourceGroupKey, "rg1").parseResourceGroup(NewContextWithMetadata(context.Background(), "bob", ""))
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	// the collection is not loaded in the resource group
+	_, err = newTask(ResourceGroupKey, "rg2").parseResourceGroup(rootCtx)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	_, err = newTask(ResourceGroupKey, "rg3").parseResourceGroup(rootCtx)
+	assert.ErrorIs(t, err, merr.ErrResourceGroupNotFound)
+}
+
+func TestSearchTask_GetMaxSearchRequests(t *testing.T) {
+	paramtable.Init()
+	cache := NewMockCache(t)
+	cache.EXPECT().GetUserRole("bob").Return([]string{"reader"}).Maybe()
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			request:        &milvuspb.SearchRequest{SearchParams: params},
+			collectionName: "test_collection",
+		}
+	}
+	rootCtx := NewContextWithMetadata(context.Background(), util.UserRoot, "")
+
+	maxSearchRequests, err := newTask().getMaxSearchRequests(rootCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1024, maxSearchRequests)
+
+	Params.Save(Params.ProxyCfg.MaxHybridSearchRequests.Key, "8")
+	defer Params.Reset(Params.ProxyCfg.MaxHybridSearchRequests.Key)
+	maxSearchRequests, err = newTask().getMaxSearchRequests(rootCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, maxSearchRequests)
+
+	// no one is privileged without authorization
+	_, err = newTask(MaxSearchRequestsKey, "2048").getMaxSearchRequests(rootCtx)
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	paramtable.Get().Save(Params.CommonCfg.AuthorizationEnabled.Key, "true")
+	defer paramtable.Get().Reset(Params.CommonCfg.AuthorizationEnabled.Key)
+
+	maxSearchRequests, err = newTask(MaxSearchRequestsKey, "2048").getMaxSearchRequests(rootCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2048, maxSearchRequests)
+
+	_, err = newTask(MaxSearchRequestsKey, "2048").getMaxSearchRequests(NewContextWithMetadata(context.Background(), "bob", ""))
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	for _, invalid := range []string{"0", "-1", "abc"} {
+		_, err = newTask(MaxSearchRequestsKey, invalid).getMaxSearchRequests(rootCtx)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	}
+}
+
+func TestSearchParamErrorReasonCodes(t *testing.T) {
+	paramtable.Init()
+	schema := constructCollectionSchema(testInt64Field, testFloatVecField, 8, "test_collection")
+	assertReason := func(err error, expected merr.ReasonCode) {
+		reason, ok := merr.GetReasonCode(err)
+		assert.True(t, ok)
+		assert.Equal(t, expected, reason)
+		assert.Equal(t, string(expected), merr.Status(err).GetExtraInfo()[merr.ReasonCodeKey])
+	}
+
+	_, err := parseRankParams(newSearchParams(LimitKey, "abc"), schema)
+	assertReason(err, merr.ReasonTopKInvalid)
+	_, err = parseRankParams(newSearchParams(LimitKey, "10", RoundDecimalKey, "7"), schema)
+	assertReason(err, merr.ReasonRoundDecimalInvalid)
+	_, err = parseRankParams(newSearchParams(LimitKey, "10", OffsetKey, strconv.FormatInt(Params.QuotaConfig.MaxQueryResultWindow.GetAsInt64(), 10)), schema)
+	assertReason(err, merr.ReasonResultWindowExceeded)
+
+	_, err = parseSearchInfo(newSearchParams(TopKKey, "0"), schema, nil)
+	assertReason(err, merr.ReasonTopKInvalid)
+	_, err = parseSearchInfo(newSearchParams(TopKKey, "10", GroupByFieldKey, testInt64Field, GroupSizeKey, "0"), schema, nil)
+	assertReason(err, merr.ReasonGroupSizeInvalid)
+	_, err = parseSearchInfo(newSearchParams(TopKKey, "10", GroupByFieldKey, testInt64Field, GroupSizeKey,
+		strconv.FormatInt(Params.QuotaConfig.MaxGroupSize.GetAsInt64()+1, 10)), schema, nil)
+	assertReason(err, merr.ReasonGroupSizeExceeded)
+}
+
+func TestParseTimeRangeFilter(t *testing.T) {
+	schema := &schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "id", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "created_at", DataType: schemapb.DataType_Int64},
+			{FieldID: 102, Name: "score", DataType: schemapb.DataType_Double},
+			{FieldID: 103, Name: "name", DataType: schemapb.DataType_VarChar},
+		},
+	}
+
+	expr, err := parseTimeRangeFilter("created_at:100:200", schema)
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at >= 100 && created_at <= 200", expr)
+
+	expr, err = parseTimeRangeFilter("created_at:100:", schema)
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at >= 100", expr)
+
+	expr, err = parseTimeRangeFilter("score::1.5", schema)
+	assert.NoError(t, err)
+	assert.Equal(t, "score <= 1.5", expr)
+
+	for _, invalid := range []string{"created_at", "created_at::", ":1:2", "created_at:1:2:3", "created_at:a:2", "created_at:1.5:2", "created_at:3:2", "name:1:2"} {
+		_, err = parseTimeRangeFilter(invalid, schema)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid, invalid)
+	}
+	_, err = parseTimeRangeFilter("updated_at:1:2", schema)
+	assert.ErrorIs(t, err, merr.ErrFieldNotFound)
+
+	assert.Equal(t, "created_at >= 1", mergeTimeRangeFilter(" ", "created_at >= 1"))
+	assert.Equal(t, "(id > 0) && (created_at >= 1)", mergeTimeRangeFilter("id > 0", "created_at >= 1"))
+
+	t.Run("search", func(t *testing.T) {
+		paramtable.Init()
+		task := &searchTask{
+			ctx:           context.Background(),
+			SearchRequest: &internalpb.SearchRequest{},
+			schema:        newSchemaInfo(constructCollectionSchema(testInt64Field, testFloatVecField, 8, "test_collection")),
+		}
+		params := append(getValidSearchParams(), &commonpb.KeyValuePair{Key: TimeRangeKey, Value: testInt64Field + ":10:20"})
+		plan, _, _, _, err := task.tryGeneratePlan(params, testInt64Field+" != 15", nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, plan.GetVectorAnns().GetPredicates().GetBinaryExpr())
+
+		params = append(getValidSearchParams(), &commonpb.KeyValuePair{Key: TimeRangeKey, Value: "unknown:10:20"})
+		_, _, _, _, err = task.tryGeneratePlan(params, "", nil)
+		assert.ErrorIs(t, err, merr.ErrFieldNotFound)
+	})
+}
+
+func TestSearchTask_ResolvePlaceholderGroupRefs(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "title_vec", DataType: schemapb.DataType_FloatVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "4"}}},
+			{FieldID: 102, Name: "body_vec", DataType: schemapb.DataType_FloatVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "4"}}},
+			{FieldID: 103, Name: "image_vec", DataType: schemapb.DataType_FloatVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "8"}}},
+			{FieldID: 104, Name: "binary_vec", DataType: schemapb.DataType_BinaryVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "32"}}},
+		},
+	})
+	placeholderGroup, err := proto.Marshal(funcutil.Float32VectorsToPlaceholderGroup([][]float32{{1, 2, 3, 4}, {5, 6, 7, 8}}))
+	require.NoError(t, err)
+	newSubReq := func(placeholderGroup []byte, kvs ...string) *milvuspb.SubSearchRequest {
+		params := newSearchParams(kvs...)
+		return &milvuspb.SubSearchRequest{PlaceholderGroup: placeholderGroup, SearchParams: params}
+	}
+	newTask := func(subReqs ...*milvuspb.SubSearchRequest) *searchTask {
+		return &searchTask{
+			schema:  schema,
+			request: &milvuspb.SearchRequest{SubReqs: subReqs},
+		}
+	}
+
+	task := newTask(
+		newSubReq(placeholderGroup, AnnsFieldKey, "title_vec"),
+		newSubReq(nil, AnnsFieldKey, "body_vec", PlaceholderGroupRefKey, "0"),
+	)
+	assert.NoError(t, task.resolvePlaceholderGroupRefs())
+	assert.Equal(t, placeholderGroup, task.request.GetSubReqs()[1].GetPlaceholderGroup())
+	nq, err := getNqFromSubSearch(task.request.GetSubReqs()[1])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), nq)
+
+	for _, subReq := range []*milvuspb.SubSearchRequest{
+		// out of range or self reference
+		newSubReq(nil, AnnsFieldKey, "body_vec", PlaceholderGroupRefKey, "2"),
+		newSubReq(nil, AnnsFieldKey, "body_vec", PlaceholderGroupRefKey, "1"),
+		newSubReq(nil, AnnsFieldKey, "body_vec", PlaceholderGroupRefKey, "a"),
+		// both uploaded and referenced
+		newSubReq(placeholderGroup, AnnsFieldKey, "body_vec", PlaceholderGroupRefKey, "0"),
+		// anns field absent
+		newSubReq(nil, PlaceholderGroupRefKey, "0"),
+		// dim or type mismatch
+		newSubReq(nil, AnnsFieldKey, "image_vec", PlaceholderGroupRefKey, "0"),
+		newSubReq(nil, AnnsFieldKey, "binary_vec", PlaceholderGroupRefKey, "0"),
+	} {
+		task = newTask(newSubReq(placeholderGroup, AnnsFieldKey, "title_vec"), subReq)
+		assert.ErrorIs(t, task.resolvePlaceholderGroupRefs(), merr.ErrParameterInvalid)
+	}
+
+	task = newTask(newSubReq(placeholderGroup), newSubReq(nil, AnnsFieldKey, "unknown", PlaceholderGroupRefKey, "0"))
+	assert.ErrorIs(t, task.resolvePlaceholderGroupRefs(), merr.ErrFieldNotFound)
+
+	// chained references are not allowed
+	task = newTask(
+		newSubReq(placeholderGroup),
+		newSubReq(nil, AnnsFieldKey, "body_vec", PlaceholderGroupRefKey, "0"),
+		newSubReq(nil, AnnsFieldKey, "body_vec", PlaceholderGroupRefKey, "1"),
+	)
+	assert.ErrorIs(t, task.resolvePlaceholderGroupRefs(), merr.ErrParameterInvalid)
+}
+
+func TestFillNullableValidData(t *testing.T) {
+	schema := &schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "title", DataType: schemapb.DataType_VarChar, Nullable: true},
+			{FieldID: 102, Name: "score", DataType: schemapb.DataType_Int64, Nullable: true},
+			{FieldID: 103, Name: "count", DataType: schemapb.DataType_Int64},
+		},
+	}
+	fieldsData := []*schemapb.FieldData{
+		{
+			FieldId:   101,
+			FieldName: "title",
+			Type:      schemapb.DataType_VarChar,
+			ValidData: []bool{true, false, true},
+			Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_StringData{
+				StringData: &schemapb.StringArray{Data: []string{"a", "", "c"}},
+			}}},
+		},
+		{
+			FieldId:   102,
+			FieldName: "score",
+			Type:      schemapb.DataType_Int64,
+			Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_LongData{
+				LongData: &schemapb.LongArray{Data: []int64{0, 1, 2}},
+			}}},
+		},
+		{
+			FieldId:   103,
+			FieldName: "count",
+			Type:      schemapb.DataType_Int64,
+			Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{Data: &schemapb.ScalarField_LongData{
+				LongData: &schemapb.LongArray{Data: []int64{0, 1, 2}},
+			}}},
+		},
+	}
+
+	fillNullableValidData(fieldsData, schema, 3)
+	// the null rows are kept as they are
+	assert.Equal(t, []bool{true, false, true}, fieldsData[0].GetValidData())
+	// the zero values of the nullable field are not null
+	assert.Equal(t, []bool{true, true, true}, fieldsData[1].GetValidData())
+	assert.Empty(t, fieldsData[2].GetValidData())
+}
+
+func TestSearchTask_CheckSelfRecallCheck(t *testing.T) {
+	newTask := func(nq int64, kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		// nq of the request is 0 if the query vectors are fetched by query_vectors_uri, the resolved one is checked.
+		return &searchTask{
+			SearchRequest: &internalpb.SearchRequest{Nq: nq},
+			request:       &milvuspb.SearchRequest{SearchParams: params},
+		}
+	}
+	queryInfo := &planpb.QueryInfo{Topk: 10, GroupByFieldId: -1}
+
+	assert.NoError(t, newTask(100).checkSelfRecallCheck(queryInfo))
+	assert.NoError(t, newTask(2, SelfRecallCheckKey, "true").checkSelfRecallCheck(queryInfo))
+	assert.NoError(t, newTask(100, SelfRecallCheckKey, "false").checkSelfRecallCheck(queryInfo))
+
+	assert.ErrorIs(t, newTask(2, SelfRecallCheckKey, "yes").checkSelfRecallCheck(queryInfo), merr.ErrParameterInvalid)
+	assert.ErrorIs(t, newTask(maxSelfRecallCheckNq+1, SelfRecallCheckKey, "true").checkSelfRecallCheck(queryInfo), merr.ErrParameterInvalid)
+	assert.ErrorIs(t, newTask(2, SelfRecallCheckKey, "true").checkSelfRecallCheck(
+		&planpb.QueryInfo{Topk: maxSelfRecallCheckTopK + 1, GroupByFieldId: -1}), merr.ErrParameterInvalid)
+	assert.ErrorIs(t, newTask(2, SelfRecallCheckKey, "true").checkSelfRecallCheck(
+		&planpb.QueryInfo{Topk: 10, GroupByFieldId: 101}), merr.ErrParameterInvalid)
+	assert.ErrorIs(t, newTask(2, SelfRecallCheckKey, "true").checkSelfRecallCheck(
+		&planpb.QueryInfo{Topk: 10, GroupByFieldId: -1, SearchParams: `{"radius": 0.5}`}), merr.ErrParameterInvalid)
+
+	// the ground truth search is a brute force search
+	task := newTask(2, SelfRecallCheckKey, "true")
+	task.SearchRequest.IsRecallEvaluation = true
+	queryInfo = &planpb.QueryInfo{Topk: 10, GroupByFieldId: -1, Hints: iterativeFilterHint}
+	assert.NoError(t, task.checkSelfRecallCheck(queryInfo))
+	assert.Equal(t, bruteForceHint, queryInfo.GetHints())
+}
+
+func TestSearchTask_MalformedQueryVectors(t *testing.T) {
+	paramtable.Init()
+	t.Run("empty placeholder group", func(t *testing.T) {
+		task := &searchTask{
+			SearchRequest: &internalpb.SearchRequest{},
+			request:       &milvuspb.SearchRequest{},
+		}
+		_, err := task.checkNq(context.Background())
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+		assert.ErrorContains(t, err, "no query vectors provided")
+
+		placeholderGroup, err := proto.Marshal(&commonpb.PlaceholderGroup{
+			Placeholders: []*commonpb.PlaceholderValue{{Tag: "$0", Type: commonpb.PlaceholderType_FloatVector}},
+		})
+		require.NoError(t, err)
+		task.request.PlaceholderGroup = placeholderGroup
+		_, err = task.checkNq(context.Background())
+		assert.ErrorContains(t, err, "no query vectors provided")
+
+		task = &searchTask{
+			SearchRequest: &internalpb.SearchRequest{IsAdvanced: true},
+			request: &milvuspb.SearchRequest{SubReqs: []*milvuspb.SubSearchRequest{
+				{PlaceholderGroup: placeholderGroup},
+			}},
+		}
+		_, err = task.checkNq(context.Background())
+		assert.ErrorContains(t, err, "no query vectors provided")
+	})
+
+	t.Run("dim mismatch", func(t *testing.T) {
+		task := &searchTask{
+			schema: newSchemaInfo(&schemapb.CollectionSchema{
+				Fields: []*schemapb.FieldSchema{
+					{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+					{FieldID: 101, Name: "vec", DataType: schemapb.DataType_FloatVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "4"}}},
+					{FieldID: 102, Name: "text", DataType: schemapb.DataType_VarChar},
+				},
+			}),
+		}
+		matched, err := proto.Marshal(funcutil.Float32VectorsToPlaceholderGroup([][]float32{{1, 2, 3, 4}}))
+		require.NoError(t, err)
+		assert.NoError(t, task.checkQueryVectorsDim(101, matched))
+
+		mismatched, err := proto.Marshal(funcutil.Float32VectorsToPlaceholderGroup([][]float32{{1, 2, 3}}))
+		require.NoError(t, err)
+		err = task.checkQueryVectorsDim(101, mismatched)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+		assert.ErrorContains(t, err, "does not match the dim 4 of field vec")
+
+		// texts are converted by functions, left to be checked afterwards
+		texts, err := proto.Marshal(&commonpb.PlaceholderGroup{Placeholders: []*commonpb.PlaceholderValue{
+			{Tag: "$0", Type: commonpb.PlaceholderType_VarChar, Values: [][]byte{[]byte("text")}},
+		}})
+		require.NoError(t, err)
+		assert.NoError(t, task.checkQueryVectorsDim(101, texts))
+	})
+}
+
+func TestSearchTask_FillIndexInfo(t *testing.T) {
+	mixCoord := NewMixCoordMock()
+	mixCoord.DescribeIndexFunc = func(ctx context.Context, request *indexpb.DescribeIndexRequest, opts ...grpc.CallOption) (*indexpb.DescribeIndexResponse, error) {
+		return &indexpb.DescribeIndexResponse{
+			Status: merr.Success(),
+			IndexInfos: []*indexpb.IndexInfo{
+				{FieldID: 101, IndexName: "vec_idx", IndexParams: []*commonpb.KeyValuePair{
+					{Key: common.IndexTypeKey, Value: "HNSW"},
+					{Key: common.MetricTypeKey, Value: metric.COSINE},
+				}},
+			},
+		}, nil
+	}
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "vec", DataType: schemapb.DataType_FloatVector},
+			{FieldID: 102, Name: "vec2", DataType: schemapb.DataType_FloatVector},
+		},
+	})
+	newTask := func(searchRequest *internalpb.SearchRequest, queryInfos ...*planpb.QueryInfo) *searchTask {
+		return &searchTask{
+			SearchRequest: searchRequest,
+			schema:        schema,
+			mixCoord:      mixCoord,
+			queryInfos:    queryInfos,
+			result:        &milvuspb.SearchResults{Status: merr.Success()},
+		}
+	}
+
+	task := newTask(&internalpb.SearchRequest{FieldId: 101}, &planpb.QueryInfo{})
+	task.fillIndexInfo(context.Background())
+	assert.JSONEq(t, `[{"field_name":"vec","index_name":"vec_idx","index_type":"HNSW","metric_type":"COSINE"}]`,
+		task.result.GetStatus().GetExtraInfo()[searchResultIndexInfoKey])
+
+	task = newTask(&internalpb.SearchRequest{
+		IsAdvanced: true,
+		SubReqs:    []*internalpb.SubSearchRequest{{FieldId: 101}, {FieldId: 102}},
+	}, &planpb.QueryInfo{MetricType: metric.IP}, &planpb.QueryInfo{MetricType: metric.L2})
+	task.fillIndexInfo(context.Background())
+	assert.JSONEq(t, `[{"field_name":"vec","index_name":"vec_idx","index_type":"HNSW","metric_type":"IP"},`+
+		`{"field_name":"vec2","index_name":"","index_type":"","metric_type":"L2"}]`,
+		task.result.GetStatus().GetExtraInfo()[searchResultIndexInfoKey])
+
+	// a debug info, the search is not failed
+	mixCoord.DescribeIndexFunc = func(ctx context.Context, request *indexpb.DescribeIndexRequest, opts ...grpc.CallOption) (*indexpb.DescribeIndexResponse, error) {
+		return nil, errors.New("mock")
+	}
+	task = newTask(&internalpb.SearchRequest{FieldId: 101}, &planpb.QueryInfo{})
+	task.fillIndexInfo(context.Background())
+	assert.NotContains(t, task.result.GetStatus().GetExtraInfo(), searchResultIndexInfoKey)
+}
+
+func TestDowncastFloatVectors(t *testing.T) {
+	newFieldsData := func() []*schemapb.FieldData {
+		return []*schemapb.FieldData{
+			{
+				FieldName: "dense",
+				Type:      schemapb.DataType_FloatVector,
+				Field: &schemapb.FieldData_Vectors{Vectors: &schemapb.VectorField{
+					Dim:  2,
+					Data: &schemapb.VectorField_FloatVector{FloatVector: &schemapb.FloatArray{Data: []float32{0.5, -1.25, 3, 0.1}}},
+				}},
+			},
+			{
+				FieldName: "binary",
+				Type:      schemapb.DataType_BinaryVector,
+				Field: &schemapb.FieldData_Vectors{Vectors: &schemapb.VectorField{
+					Dim:  8,
+					Data: &schemapb.VectorField_BinaryVector{BinaryVector: []byte{0xff, 0x0f}},
+				}},
+			},
+		}
+	}
+
+	precision, err := parseVectorPrecision(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, schemapb.DataType_None, precision)
+	_, err = parseVectorPrecision([]*commonpb.KeyValuePair{{Key: VectorPrecisionKey, Value: "fp8"}})
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+
+	precision, err = parseVectorPrecision([]*commonpb.KeyValuePair{{Key: VectorPrecisionKey, Value: "fp16"}})
+	require.NoError(t, err)
+	fieldsData := newFieldsData()
+	downcastFloatVectors(fieldsData, precision)
+	assert.Equal(t, schemapb.DataType_Float16Vector, fieldsData[0].GetType())
+	assert.Equal(t, int64(2), fieldsData[0].GetVectors().GetDim())
+	assert.Len(t, fieldsData[0].GetVectors().GetFloat16Vector(), 8)
+	assert.InDeltaSlice(t, []float32{0.5, -1.25, 3, 0.1}, typeutil.Float16BytesToFloat32Vector(fieldsData[0].GetVectors().GetFloat16Vector()), 1e-3)
+	// the other vectors are left as is
+	assert.Equal(t, schemapb.DataType_BinaryVector, fieldsData[1].GetType())
+	assert.Equal(t, []byte{0xff, 0x0f}, fieldsData[1].GetVectors().GetBinaryVector())
+
+	precision, err = parseVectorPrecision([]*commonpb.KeyValuePair{{Key: VectorPrecisionKey, Value: "bf16"}})
+	require.NoError(t, err)
+	fieldsData = newFieldsData()
+	downcastFloatVectors(fieldsData, precision)
+	assert.Equal(t, schemapb.DataType_BFloat16Vector, fieldsData[0].GetType())
+	assert.Len(t, fieldsData[0].GetVectors().GetBfloat16Vector(), 8)
+	assert.InDeltaSlice(t, []float32{0.5, -1.25, 3, 0.1}, typeutil.BFloat16BytesToFloat32Vector(fieldsData[0].GetVectors().GetBfloat16Vector()), 1e-2)
+}
+
+func TestSearchTask_FillChannelMvcc(t *testing.T) {
+	task := &searchTask{
+		queryChannelsTs: map[string]uint64{"dml_0": 100, "dml_1": 200},
+		result:          &milvuspb.SearchResults{Status: merr.Success()},
+	}
+	task.fillChannelMvcc()
+	channelMvcc := make(map[string]uint64)
+	require.NoError(t, json.Unmarshal([]byte(task.result.GetStatus().GetExtraInfo()[searchResultChannelMvccKey]), &channelMvcc))
+	assert.Equal(t, map[string]uint64{"dml_0": 100, "dml_1": 200}, channelMvcc)
+}
+
+func TestSearchResultsHaveMore(t *testing.T) {
+	newResult := func(topks ...int64) *internalpb.SearchResults {
+		blob, err := proto.Marshal(&schemapb.SearchResultData{NumQueries: int64(len(topks)), Topks: topks})
+		require.NoError(t, err)
+		return &internalpb.SearchResults{SlicedBlob: blob}
+	}
+
+	hasMore, err := searchResultsHaveMore(context.Background(), nil, 10)
+	assert.NoError(t, err)
+	assert.False(t, hasMore)
+
+	// fewer candidates than topk
+	hasMore, err = searchResultsHaveMore(context.Background(), []*internalpb.SearchResults{newResult(3, 5), newResult(4, 2)}, 10)
+	assert.NoError(t, err)
+	assert.False(t, hasMore)
+
+	// the candidates of a shard are truncated at topk
+	hasMore, err = searchResultsHaveMore(context.Background(), []*internalpb.SearchResults{newResult(3, 10), newResult(0, 0)}, 10)
+	assert.NoError(t, err)
+	assert.True(t, hasMore)
+
+	// more candidates than topk in total
+	hasMore, err = searchResultsHaveMore(context.Background(), []*internalpb.SearchResults{newResult(6, 1), newResult(5, 1), {}}, 10)
+	assert.NoError(t, err)
+	assert.True(t, hasMore)
+
+	_, err = searchResultsHaveMore(context.Background(), []*internalpb.SearchResults{{SlicedBlob: []byte{0xff}}}, 10)
+	assert.Error(t, err)
+}
+
+func TestCheckNoGrowingSkipped(t *testing.T) {
+	newResult := func(growingSkipped string) *internalpb.SearchResults {
+		return &internalpb.SearchResults{Base: &commonpb.MsgBase{
+			Properties: map[string]string{common.SearchGrowingSkippedKey: growingSkipped},
+		}}
+	}
+
+	assert.NoError(t, checkNoGrowingSkipped(nil))
+	assert.NoError(t, checkNoGrowingSkipped([]*internalpb.SearchResults{{}, newResult("")}))
+	err := checkNoGrowingSkipped([]*internalpb.SearchResults{{}, newResult("dml_1")})
+	assert.ErrorIs(t, err, merr.ErrSearchGrowingSkipped)
+	assert.ErrorContains(t, err, "dml_1")
+	assert.False(t, merr.IsRetryableErr(err))
+}
+
+func TestSearchTask_CheckRequeryAllowed(t *testing.T) {
+	paramtable.Init()
+	var properties []*commonpb.KeyValuePair
+	cache := NewMockCache(t)
+	cache.EXPECT().GetCollectionInfo(mock.Anything, mock.Anything, mock.Anything, mock.Anything).RunAndReturn(
+		func(ctx context.Context, dbName string, collectionName string, collectionID int64) (*collectionInfo, error) {
+			return &collectionInfo{properties: properties}, nil
+		})
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+	task := &searchTask{
+		SearchRequest:  &internalpb.SearchRequest{CollectionID: 1},
+		request:        &milvuspb.SearchRequest{},
+		collectionName: "test",
+	}
+	ctx := context.Background()
+
+	assert.NoError(t, task.checkRequeryAllowed(ctx))
+	Params.Save(Params.ProxyCfg.DisableSearchRequery.Key, "true")
+	defer Params.Reset(Params.ProxyCfg.DisableSearchRequery.Key)
+	assert.ErrorIs(t, task.checkRequeryAllowed(ctx), merr.ErrParameterInvalid)
+
+	// the collection property overrides the cluster config
+	properties = []*commonpb.KeyValuePair{{Key: common.CollectionSearchRequeryDisabledKey, Value: "false"}}
+	assert.NoError(t, task.checkRequeryAllowed(ctx))
+	Params.Reset(Params.ProxyCfg.DisableSearchRequery.Key)
+	properties = []*commonpb.KeyValuePair{{Key: common.CollectionSearchRequeryDisabledKey, Value: "true"}}
+	assert.ErrorIs(t, task.checkRequeryAllowed(ctx), merr.ErrParameterInvalid)
+	properties = []*commonpb.KeyValuePair{{Key: common.CollectionSearchRequeryDisabledKey, Value: "maybe"}}
+	assert.ErrorIs(t, task.checkRequeryAllowed(ctx), merr.ErrParameterInvalid)
+}
+
+func TestSearchTask_ParsePreviewPartitions(t *testing.T) {
+	paramtable.Init()
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "tenant", DataType: schemapb.DataType_Int64, IsPartitionKey: true},
+		},
+	})
+	partitionNames := []string{"_default_0", "_default_1", "_default_2", "_default_3"}
+	cache := NewMockCache(t)
+	cache.EXPECT().GetUserRole("bob").Return([]string{"reader"}).Maybe()
+	cache.EXPECT().GetPartitionsIndex(mock.Anything, mock.Anything, mock.Anything).Return(partitionNames, nil).Maybe()
+	cache.EXPECT().GetCollectionSchema(mock.Anything, mock.Anything, mock.Anything).Return(schema, nil).Maybe()
+	globalMetaCache = cache
+	defer func() { globalMetaCache = nil }()
+
+	newTask := func(kvs ...string) *searchTask {
+		params := newSearchParams(kvs...)
+		return &searchTask{
+			schema:           schema,
+			partitionKeyMode: true,
+			collectionName:   "test_collection",
+			SearchRequest:    &internalpb.SearchRequest{Nq: 1},
+			request:          &milvuspb.SearchRequest{SearchParams: params},
+		}
+	}
+	// tenant in [1, 2]
+	plan := &planpb.PlanNode{Node: &planpb.PlanNode_VectorAnns{VectorAnns: &planpb.VectorANNS{
+		Predicates: &planpb.Expr{Expr: &planpb.Expr_TermExpr{TermExpr: &planpb.TermExpr{
+			ColumnInfo: &planpb.ColumnInfo{FieldId: 101, DataType: schemapb.DataType_Int64, IsPartitionKey: true},
+			Values: []*planpb.GenericValue{
+				{Val: &planpb.GenericValue_Int64Val{Int64Val: 1}},
+				{Val: &planpb.GenericValue_Int64Val{Int64Val: 2}},
+			},
+		}}},
+	}}}
+	rootCtx := NewContextWithMetadata(context.Background(), util.UserRoot, "")
+
+	partitions, err := newTask().parsePreviewPartitions(rootCtx, plan)
+	assert.NoError(t, err)
+	assert.Nil(t, partitions)
+
+	// no one is privileged without authorization
+	_, err = newTask(PreviewPartitionsKey, "true").parsePreviewPartitions(rootCtx, plan)
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	paramtable.Get().Save(Params.CommonCfg.AuthorizationEnabled.Key, "true")
+	defer paramtable.Get().Reset(Params.CommonCfg.AuthorizationEnabled.Key)
+
+	task := newTask(PreviewPartitionsKey, "true")
+	partitions, err = task.parsePreviewPartitions(rootCtx, plan)
+	require.NoError(t, err)
+	expected := lo.Uniq(lo.Map([]int64{1, 2}, func(key int64, _ int) string {
+		hash, _ := typeutil.Hash32Int64(key)
+		return partitionNames[hash%uint32(len(partitionNames))]
+	}))
+	slices.Sort(expected)
+	assert.Equal(t, expected, partitions)
+
+	task.previewPartitions = partitions
+	require.NoError(t, task.fillPreviewPartitionsResult())
+	assert.Equal(t, []int64{0}, task.result.GetResults().GetTopks())
+	bs, err := json.Marshal(expected)
+	require.NoError(t, err)
+	assert.Equal(t, string(bs), task.result.GetStatus().GetExtraInfo()[searchResultPreviewPartitionsKey])
+
+	_, err = newTask(PreviewPartitionsKey, "true").parsePreviewPartitions(NewContextWithMetadata(context.Background(), "bob", ""), plan)
+	assert.ErrorIs(t, err, merr.ErrPrivilegeNotPermitted)
+
+	task = newTask(PreviewPartitionsKey, "true")
+	task.partitionKeyMode = false
+	_, err = task.parsePreviewPartitions(rootCtx, plan)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
+
+func TestCheckSearchExprLength(t *testing.T) {
+	paramtable.Get().Save(Params.ProxyCfg.MaxSearchExprLength.Key, "16")
+	defer paramtable.Get().Reset(Params.ProxyCfg.MaxSearchExprLength.Key)
+	assert.NoError(t, checkSearchExprLength(""))
+	assert.NoError(t, checkSearchExprLength(strings.Repeat("a", 16)))
+	err := checkSearchExprLength(strings.Repeat("a", 17))
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	assert.ErrorContains(t, err, "17 bytes exceeds the limit 16 bytes")
+
+	paramtable.Get().Save(Params.ProxyCfg.MaxSearchExprLength.Key, "0")
+	assert.NoError(t, checkSearchExprLength(strings.Repeat("a", 17)))
+}
+
+func TestTruncateExprForLog(t *testing.T) {
+	assert.Equal(t, "id in [1, 2]", truncateExprForLog("id in [1, 2]"))
+	expr := "id in [" + strings.Repeat("1, ", maxLoggedExprLength) + "1]"
+	truncated := truncateExprForLog(expr)
+	assert.True(t, strings.HasPrefix(truncated, expr[:maxLoggedExprLength]))
+	assert.True(t, strings.HasSuffix(truncated, fmt.Sprintf("...(%d bytes truncated)", len(expr)-maxLoggedExprLength)))
+
+	plan := &planpb.PlanNode{OutputFieldIds: []int64{100}}
+	assert.Equal(t, plan.String(), truncatedStringer{plan}.String())
+}
+
+type fakeGroupByRerank struct {
+	supportGroup bool
+}
+
+func (r fakeGroupByRerank) IsSupportGroup() bool { return r.supportGroup }
+
+func (r fakeGroupByRerank) RerankName() string { return "fake" }
+
+func TestCheckRerankGroupBySupport(t *testing.T) {
+	// the group by field id of the search comes from the query info, and that of the hybrid search from the rank params,
+	// both are -1 if the search is not grouped.
+	queryInfo, err := parseSearchInfo(getValidSearchParams(), nil, nil)
+	require.NoError(t, err)
+	rankParams, err := parseRankParams([]*commonpb.KeyValuePair{{Key: LimitKey, Value: "10"}}, nil)
+	require.NoError(t, err)
+
+	for _, groupByFieldID := range []int64{queryInfo.planInfo.GetGroupByFieldId(), rankParams.GetGroupByFieldId()} {
+		assert.EqualValues(t, -1, groupByFieldID)
+		assert.NoError(t, checkRerankGroupBySupport(fakeGroupByRerank{}, groupByFieldID))
+	}
+	// field id 0 is a real field
+	for _, groupByFieldID := range []int64{0, 101} {
+		err := checkRerankGroupBySupport(fakeGroupByRerank{}, groupByFieldID)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+		assert.ErrorContains(t, err, "Rerank fake does not support grouping search")
+		assert.NoError(t, checkRerankGroupBySupport(fakeGroupByRerank{supportGroup: true}, groupByFieldID))
+	}
+	assert.True(t, hasGroupBy(0))
+	assert.False(t, hasGroupBy(-1))
+	var groupBy *groupByInfo
+	assert.False(t, hasGroupBy(groupBy.GetGroupByFieldId()))
+	var functionScore *rerank.FunctionScore
+	assert.NoError(t, checkRerankGroupBySupport(functionScore, 101))
+}
+
+func TestSortByOutputFieldsRank(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		EnableDynamicField: true,
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, Name: "pk", DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, Name: "vec", DataType: schemapb.DataType_FloatVector},
+			{FieldID: 102, Name: "title", DataType: schemapb.DataType_VarChar},
+			{FieldID: 103, Name: "price", DataType: schemapb.DataType_Double},
+			{FieldID: 104, Name: common.MetaFieldName, DataType: schemapb.DataType_JSON, IsDynamic: true},
+		},
+	})
+	newFieldsData := func(names ...string) []*schemapb.FieldData {
+		return lo.Map(names, func(name string, _ int) *schemapb.FieldData {
+			return &schemapb.FieldData{FieldName: name}
+		})
+	}
+	sortedNames := func(outputFields []string, names ...string) []string {
+		fieldsData := newFieldsData(names...)
+		sortByOutputFieldsRank(fieldsData, outputFieldsRank(outputFields, schema), (*schemapb.FieldData).GetFieldName)
+		return lo.Map(fieldsData, func(field *schemapb.FieldData, _ int) string { return field.GetFieldName() })
+	}
+
+	// the pk is placed as requested, the dynamic keys rank the dynamic field, and the unrequested fields are put at the end
+	outputFields := []string{"price", "color", "pk", "title"}
+	assert.Equal(t, []string{"price", common.MetaFieldName, "pk", "title", partitionIDOutputField},
+		sortedNames(outputFields, "title", partitionIDOutputField, "pk", common.MetaFieldName, "price"))
+	// stable for the same request
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, []string{"price", common.MetaFieldName, "pk", "title"},
+			sortedNames(outputFields, "pk", "title", common.MetaFieldName, "price"))
+	}
+	// the wildcard follows the schema order
+	assert.Equal(t, []string{"price", "pk", "vec", "title", common.MetaFieldName},
+		sortedNames([]string{"price", "*"}, common.MetaFieldName, "title", "vec", "pk", "price"))
+
+	names := []string{"title", "color", "price"}
+	sortByOutputFieldsRank(names, outputFieldsRank(outputFields, schema), func(name string) string { return name })
+	assert.Equal(t, []string{"price", "color", "title"}, names)
+}
+
+func TestSearchTask_SetSearchPriority(t *testing.T) {
+	task := &searchTask{SearchRequest: &internalpb.SearchRequest{}}
+	assert.NoError(t, task.setSearchPriority(common.MaxSearchPriority))
+	assert.Equal(t, "9", task.SearchRequest.GetBase().GetProperties()[common.SearchPriorityKey])
+
+	for _, priority := range []int64{common.MinSearchPriority - 1, common.MaxSearchPriority + 1} {
+		err := task.setSearchPriority(priority)
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	}
+}
+
+func TestSearchTask_FillRerankInfo(t *testing.T) {
+	schema := constructCollectionSchema(testInt64Field, testFloatVecField, 8, "test_collection")
+	newTask := func(rankParams ...*commonpb.KeyValuePair) *searchTask {
+		functionScore, err := rerank.NewFunctionScoreWithlegacy(schema, rankParams)
+		require.NoError(t, err)
+		return &searchTask{functionScore: functionScore, result: &milvuspb.SearchResults{}}
+	}
+
+	task := newTask()
+	task.fillRerankInfo()
+	assert.JSONEq(t, `{"name":"rrf","legacy":true,"params":{}}`, task.result.GetStatus().GetExtraInfo()[searchResultRerankKey])
+
+	task = newTask(
+		&commonpb.KeyValuePair{Key: "strategy", Value: "weighted"},
+		&commonpb.KeyValuePair{Key: "params", Value: `{"weights": [0.3, 0.7], "norm_score": true}`},
+	)
+	task.fillRerankInfo()
+	assert.JSONEq(t, `{"name":"weighted","legacy":true,"params":{"weights":"[0.3,0.7]","norm_score":"true"},"weights":[0.3,0.7]}`,
+		task.result.GetStatus().GetExtraInfo()[searchResultRerankKey])
+}
+
+func TestSearchTask_FillQueryOffsets(t *testing.T) {
+	task := &searchTask{
+		result: &milvuspb.SearchResults{
+			Status:  merr.Success(),
+			Results: &schemapb.SearchResultData{NumQueries: 4, TopK: 3, Topks: []int64{3, 0, 2, 3}},
+		},
+	}
+	task.fillQueryOffsets()
+	assert.Equal(t, "[0,3,3,5]", task.result.GetStatus().GetExtraInfo()[searchResultQueryOffsetsKey])
+}
+
+func TestMergeChannelsMvcc(t *testing.T) {
+	channelsTs := make(map[string]Timestamp)
+	mergeChannelsMvcc(channelsTs, map[string]Timestamp{"dml_0": 200, "dml_1": 100})
+	mergeChannelsMvcc(channelsTs, map[string]Timestamp{"dml_0": 150, "dml_1": 300, "dml_2": 50})
+	assert.Equal(t, map[string]Timestamp{"dml_0": 200, "dml_1": 300, "dml_2": 50}, channelsTs)
+}
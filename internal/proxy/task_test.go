@@ -670,6 +670,15 @@ func TestTranslateOutputFields(t *testing.T) {
 	// Test non-existent field, dynamic field not enabled
 	_, _, _, _, err = translateOutputFields([]string{"A"}, schema, true)
 	assert.Error(t, err)
+	assert.ErrorIs(t, err, merr.ErrFieldNotFound)
+	assert.Equal(t, merr.InputError, merr.GetErrorType(err))
+	assert.NotContains(t, err.Error(), "did you mean")
+
+	// Test non-existent field with a similar field name
+	_, _, _, _, err = translateOutputFields([]string{"float_vectro"}, schema, true)
+	assert.ErrorIs(t, err, merr.ErrFieldNotFound)
+	assert.Equal(t, merr.InputError, merr.GetErrorType(err))
+	assert.Contains(t, err.Error(), "field float_vectro not exist, did you mean float_vector?")
 
 	t.Run("enable dynamic schema", func(t *testing.T) {
 		collSchema := &schemapb.CollectionSchema{
@@ -39,6 +39,10 @@ func (v *searchTaskValidator) validateSearch(search *searchTask) error {
 	if maxResultEntries <= 0 {
 		return nil
 	}
+	// no result entry is returned by count only search
+	if search.countOnly {
+		return nil
+	}
 	// check if number of result entries is too large
 	nEntries := search.GetNq() * search.GetTopk()
 	// if there is group size, multiply it
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -70,8 +71,6 @@ const (
 
 	defaultMaxArrayCapacity = 4096
 
-	defaultMaxSearchRequest = 1024
-
 	// DefaultArithmeticIndexType name of default index type for scalar field
 	DefaultArithmeticIndexType = indexparamcheck.IndexINVERTED
 
@@ -1357,6 +1356,26 @@ func GetCurUserFromContextOrDefault(ctx context.Context) string {
 	return username
 }
 
+// isPrivilegedUser returns whether the current user is root, a super user or granted the admin role.
+// No one is privileged if authorization is disabled, since the clients could not be told apart.
+func isPrivilegedUser(ctx context.Context) bool {
+	if !Params.CommonCfg.AuthorizationEnabled.GetAsBool() {
+		return false
+	}
+	username, err := GetCurUserFromContext(ctx)
+	if err != nil {
+		return false
+	}
+	if username == util.UserRoot || lo.Contains(Params.CommonCfg.SuperUsers.GetAsStrings(), username) {
+		return true
+	}
+	roleNames, err := GetRole(username)
+	if err != nil {
+		return false
+	}
+	return lo.Contains(roleNames, util.RoleAdmin)
+}
+
 func GetCurDBNameFromContextOrDefault(ctx context.Context) string {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -1632,7 +1651,8 @@ func translateOutputFields(outputFields []string, schema *schemaInfo, removePkFi
 					userOutputFieldsMap[outputFieldName] = true
 					userDynamicFieldsMap[dynamicNestedPath] = true
 				} else {
-					return nil, nil, nil, false, fmt.Errorf("field %s not exist", outputFieldName)
+					candidates := append(lo.Keys(allFieldNameMap), lo.Keys(structArrayNameToFields)...)
+					return nil, nil, nil, false, wrapErrOutputFieldNotExist(outputFieldName, candidates)
 				}
 			}
 		}
@@ -1658,6 +1678,56 @@ func translateOutputFields(outputFields []string, schema *schemaInfo, removePkFi
 	return resultFieldNames, userOutputFields, userDynamicFields, userRequestedPkFieldExplicitly, nil
 }
 
+// wrapErrOutputFieldNotExist returns an input error for the unknown output field,
+// the closest known field name is suggested if there is any.
+func wrapErrOutputFieldNotExist(fieldName string, candidates []string) error {
+	desc := fmt.Sprintf("field %s not exist", fieldName)
+	if suggestion := suggestFieldName(fieldName, candidates); suggestion != "" {
+		desc = fmt.Sprintf("%s, did you mean %s?", desc, suggestion)
+	}
+	return merr.WrapErrAsInputError(merr.WrapErrFieldNotFoundWithDesc(fieldName, desc))
+}
+
+// suggestFieldName returns the candidate with the minimal edit distance to the given name,
+// an empty string is returned if none of the candidates is close enough.
+func suggestFieldName(fieldName string, candidates []string) string {
+	maxDistance := max(len([]rune(fieldName))/3, 1)
+	suggestion := ""
+	minDistance := maxDistance + 1
+	// sort candidates to keep the suggestion stable among candidates with the same distance
+	sorted := lo.Uniq(candidates)
+	sort.Strings(sorted)
+	for _, candidate := range sorted {
+		distance := levenshteinDistance(strings.ToLower(fieldName), strings.ToLower(candidate))
+		if distance < minDistance {
+			suggestion = candidate
+			minDistance = distance
+		}
+	}
+	return suggestion
+}
+
+func levenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
 func validateIndexName(indexName string) error {
 	indexName = strings.TrimSpace(indexName)
 
@@ -3827,3 +3827,43 @@ func TestCheckAndFlattenStructFieldData(t *testing.T) {
 func TestValidateFieldsInStruct(t *testing.T) {
 	// todo(SpadeA): add test cases
 }
+
+func TestSuggestFieldName(t *testing.T) {
+	candidates := []string{"id", "embedding", "text", "text_embedding", "$meta"}
+	assert.Equal(t, "embedding", suggestFieldName("embeding", candidates))
+	assert.Equal(t, "embedding", suggestFieldName("Embedding", candidates))
+	assert.Equal(t, "text", suggestFieldName("txt", candidates))
+	assert.Equal(t, "", suggestFieldName("vector", candidates))
+	assert.Equal(t, "", suggestFieldName("a", candidates))
+	assert.Equal(t, "", suggestFieldName("embeding", nil))
+
+	assert.Equal(t, 0, levenshteinDistance("", ""))
+	assert.Equal(t, 3, levenshteinDistance("", "abc"))
+	assert.Equal(t, 3, levenshteinDistance("kitten", "sitting"))
+	assert.Equal(t, 1, levenshteinDistance("向量", "向"))
+}
+
+func TestGetOutputFieldIDs(t *testing.T) {
+	schema := newSchemaInfo(&schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{Name: "id", FieldID: 100, DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{Name: "embedding", FieldID: 101, DataType: schemapb.DataType_FloatVector},
+		},
+	})
+	ids, err := getOutputFieldIDs(schema, []string{"id", "embedding"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{100, 101}, ids)
+
+	_, err = getOutputFieldIDs(schema, []string{"embeding"})
+	assert.ErrorIs(t, err, merr.ErrFieldNotFound)
+	assert.Equal(t, merr.InputError, merr.GetErrorType(err))
+	assert.Contains(t, err.Error(), "did you mean embedding?")
+
+	paramtable.Get().Save(Params.ProxyCfg.MaxOutputFields.Key, "2")
+	defer paramtable.Get().Reset(Params.ProxyCfg.MaxOutputFields.Key)
+	_, err = getOutputFieldIDs(schema, []string{"id", "embedding"})
+	assert.NoError(t, err)
+	_, err = getOutputFieldIDs(schema, []string{"id", "embedding", "id"})
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	assert.Contains(t, err.Error(), "the number of output fields 3 exceeds the maximum 2")
+}
@@ -424,6 +424,14 @@ func (sd *shardDelegator) Search(ctx context.Context, req *querypb.SearchRequest
 	}
 	defer sd.distribution.Unpin(version)
 
+	// report the growing segments left out, so that the search requiring them fails rather than missing the rows silently
+	growingSkipped := ""
+	if len(growing) > 0 && (req.GetReq().GetIgnoreGrowing() || lo.ContainsBy(req.GetReq().GetSubReqs(), func(subReq *internalpb.SubSearchRequest) bool {
+		return subReq.GetIgnoreGrowing()
+	})) {
+		growingSkipped = sd.vchannelName
+	}
+
 	if req.GetReq().GetIsAdvanced() {
 		futures := make([]*conc.Future[*internalpb.SearchResults], len(req.GetReq().GetSubReqs()))
 		for index, subReq := range req.GetReq().GetSubReqs() {
@@ -496,9 +504,19 @@ func (sd *shardDelegator) Search(ctx context.Context, req *querypb.SearchRequest
 			}
 			results[i] = result
 		}
+		if growingSkipped != "" {
+			results = segments.MarkGrowingSkipped(results, growingSkipped)
+		}
 		return results, nil
 	}
-	return sd.search(ctx, req, sealed, growing, sealedRowCount)
+	results, err := sd.search(ctx, req, sealed, growing, sealedRowCount)
+	if err != nil {
+		return nil, err
+	}
+	if growingSkipped != "" {
+		results = segments.MarkGrowingSkipped(results, growingSkipped)
+	}
+	return results, nil
 }
 
 func (sd *shardDelegator) QueryStream(ctx context.Context, req *querypb.QueryRequest, srv streamrpc.QueryStreamServer) error {
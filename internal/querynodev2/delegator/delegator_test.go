@@ -498,6 +498,34 @@ func (s *DelegatorSuite) TestSearch() {
 
 		s.NoError(err)
 		s.Equal(3, len(results))
+		for _, result := range results {
+			s.Empty(segments.GetGrowingSkipped(result))
+		}
+	})
+
+	s.Run("ignore_growing", func() {
+		defer func() {
+			s.workerManager.ExpectedCalls = nil
+		}()
+		worker := &cluster.MockWorker{}
+		worker.EXPECT().SearchSegments(mock.Anything, mock.AnythingOfType("*querypb.SearchRequest")).
+			Run(func(_ context.Context, req *querypb.SearchRequest) {
+				s.Equal(querypb.DataScope_Historical, req.GetScope())
+			}).Return(&internalpb.SearchResults{}, nil)
+		s.workerManager.EXPECT().GetWorker(mock.Anything, mock.AnythingOfType("int64")).Return(worker, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		results, err := s.delegator.Search(ctx, &querypb.SearchRequest{
+			Req:         &internalpb.SearchRequest{Base: commonpbutil.NewMsgBase(), IgnoreGrowing: true},
+			DmlChannels: []string{s.vchannelName},
+		})
+
+		s.NoError(err)
+		s.Equal(2, len(results))
+		for _, result := range results {
+			s.Equal(s.vchannelName, segments.GetGrowingSkipped(result))
+		}
 	})
 
 	s.Run("partition_not_loaded", func() {
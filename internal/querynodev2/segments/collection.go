@@ -92,11 +92,11 @@ func (m *collectionManager) PutOrRef(collectionID int64, schema *schemapb.Collec
 	defer m.mut.Unlock()
 
 	if collection, ok := m.collections[collectionID]; ok {
-		if loadMeta.GetSchemaVersion() > collection.schemaVersion {
+		if loadMeta.GetSchemaVersion() > collection.schemaVersion.Load() {
 			// the schema may be changed even the collection is loaded
 			collection.schema.Store(schema)
 			collection.ccollection.UpdateSchema(schema, loadMeta.GetSchemaVersion())
-			collection.schemaVersion = loadMeta.GetSchemaVersion()
+			collection.schemaVersion.Store(loadMeta.GetSchemaVersion())
 			log.Info("update collection schema",
 				zap.Int64("collectionID", collectionID),
 				zap.Uint64("schemaVersion", loadMeta.GetSchemaVersion()),
@@ -131,6 +131,9 @@ func (m *collectionManager) UpdateSchema(collectionID int64, schema *schemapb.Co
 		return err
 	}
 	collection.schema.Store(schema)
+	if version > collection.schemaVersion.Load() {
+		collection.schemaVersion.Store(version)
+	}
 	return nil
 }
 
@@ -190,7 +193,7 @@ type Collection struct {
 	schema        atomic.Pointer[schemapb.CollectionSchema]
 	isGpuIndex    bool
 	loadFields    typeutil.Set[int64]
-	schemaVersion uint64
+	schemaVersion atomic.Uint64
 
 	refCount *atomic.Uint32
 }
@@ -224,6 +227,11 @@ func (c *Collection) Schema() *schemapb.CollectionSchema {
 	return c.schema.Load()
 }
 
+// SchemaVersion returns the version of the collection schema, which is the update timestamp of the collection.
+func (c *Collection) SchemaVersion() uint64 {
+	return c.schemaVersion.Load()
+}
+
 // IsGpuIndex returns a boolean value indicating whether the collection is using a GPU index.
 func (c *Collection) IsGpuIndex() bool {
 	return c.isGpuIndex
@@ -337,6 +345,7 @@ func NewCollection(collectionID int64, schema *schemapb.CollectionSchema, indexM
 		coll.partitions.Insert(partitionID)
 	}
 	coll.schema.Store(schema)
+	coll.schemaVersion.Store(loadMetaInfo.GetSchemaVersion())
 
 	return coll, nil
 }
@@ -26,6 +26,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
 	"github.com/milvus-io/milvus/internal/util/reduce"
 	"github.com/milvus-io/milvus/internal/util/segcore"
@@ -51,13 +52,51 @@ func ReduceSearchOnQueryNode(ctx context.Context, results []*internalpb.SearchRe
 	return ReduceSearchResults(ctx, results, info)
 }
 
+// MarkGrowingSkipped marks the search results of the channel that its growing segments are left out,
+// an empty result is added to carry the mark if there is no result.
+func MarkGrowingSkipped(results []*internalpb.SearchResults, channel string) []*internalpb.SearchResults {
+	if len(results) == 0 {
+		results = append(results, &internalpb.SearchResults{})
+	}
+	for _, result := range results {
+		setGrowingSkipped(result, channel)
+	}
+	return results
+}
+
+// GetGrowingSkipped returns the channel whose growing segments are left out by the search, empty if there is none.
+func GetGrowingSkipped(result *internalpb.SearchResults) string {
+	return result.GetBase().GetProperties()[common.SearchGrowingSkippedKey]
+}
+
+func setGrowingSkipped(result *internalpb.SearchResults, channel string) {
+	if channel == "" {
+		return
+	}
+	if result.Base == nil {
+		result.Base = &commonpb.MsgBase{}
+	}
+	if result.Base.Properties == nil {
+		result.Base.Properties = make(map[string]string)
+	}
+	result.Base.Properties[common.SearchGrowingSkippedKey] = channel
+}
+
 func ReduceSearchResults(ctx context.Context, results []*internalpb.SearchResults, info *reduce.ResultInfo) (*internalpb.SearchResults, error) {
+	// the mark may be carried by an empty result, take it before the empty results are filtered
+	var growingSkipped string
+	for _, result := range results {
+		if channel := GetGrowingSkipped(result); channel != "" {
+			growingSkipped = channel
+		}
+	}
 	results = lo.Filter(results, func(result *internalpb.SearchResults, _ int) bool {
 		return result != nil && result.GetSlicedBlob() != nil
 	})
 
 	if len(results) == 1 {
 		log.Debug("Shortcut return ReduceSearchResults", zap.Any("result info", info))
+		setGrowingSkipped(results[0], growingSkipped)
 		return results[0], nil
 	}
 
@@ -129,6 +168,7 @@ func ReduceSearchResults(ctx context.Context, results []*internalpb.SearchResult
 	searchResults.ChannelsMvcc = channelsMvcc
 	searchResults.IsTopkReduce = isTopkReduce
 	searchResults.IsRecallEvaluation = isRecallEvaluation
+	setGrowingSkipped(searchResults, growingSkipped)
 	return searchResults, nil
 }
 
@@ -143,10 +183,14 @@ func ReduceAdvancedSearchResults(ctx context.Context, results []*internalpb.Sear
 		IsAdvanced: true,
 	}
 
+	var growingSkipped string
 	for index, result := range results {
 		if result.GetIsTopkReduce() {
 			isTopkReduce = true
 		}
+		if channel := GetGrowingSkipped(result); channel != "" {
+			growingSkipped = channel
+		}
 		relatedDataSize += result.GetCostAggregation().GetTotalRelatedDataSize()
 		for ch, ts := range result.GetChannelsMvcc() {
 			channelsMvcc[ch] = ts
@@ -178,6 +222,7 @@ func ReduceAdvancedSearchResults(ctx context.Context, results []*internalpb.Sear
 	}
 	searchResults.CostAggregation.TotalRelatedDataSize = relatedDataSize
 	searchResults.IsTopkReduce = isTopkReduce
+	setGrowingSkipped(searchResults, growingSkipped)
 	return searchResults, nil
 }
 
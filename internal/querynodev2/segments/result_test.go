@@ -1012,6 +1012,24 @@ func TestResult_MergeRequestCost(t *testing.T) {
 	assert.Equal(t, int64(43), channelCost.TotalNQ)
 }
 
+func TestResult_GrowingSkipped(t *testing.T) {
+	// the mark is carried by an empty result if there is no result
+	results := MarkGrowingSkipped(nil, "dml_0")
+	assert.Len(t, results, 1)
+	assert.Equal(t, "dml_0", GetGrowingSkipped(results[0]))
+
+	// kept by the reduce, though the empty result carrying it is filtered
+	result := &internalpb.SearchResults{MetricType: metric.IP, NumQueries: 1, TopK: 1, SlicedBlob: []byte{1}}
+	assert.Empty(t, GetGrowingSkipped(result))
+	reduced, err := ReduceSearchResults(context.Background(), append(results, result), reduce.NewReduceSearchResultInfo(1, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, "dml_0", GetGrowingSkipped(reduced))
+
+	reduced, err = ReduceAdvancedSearchResults(context.Background(), MarkGrowingSkipped([]*internalpb.SearchResults{{SlicedBlob: []byte{1}}}, "dml_1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "dml_1", GetGrowingSkipped(reduced))
+}
+
 func TestResult(t *testing.T) {
 	paramtable.Init()
 	suite.Run(t, new(ResultSuite))
@@ -9,6 +9,7 @@ import (
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
 	"github.com/milvus-io/milvus/internal/mocks/util/mock_segcore"
 	"github.com/milvus-io/milvus/internal/util/reduce"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
 	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
 )
 
@@ -55,6 +56,18 @@ func (suite *SearchReduceSuite) TestResult_ReduceSearchResultData() {
 		suite.Nil(err)
 		suite.ElementsMatch([]int64{1, 5, 2, 3}, res.Ids.GetIntId().Data)
 	})
+	suite.Run("exceed maxOutputSize", func() {
+		paramtable.Get().Save(paramtable.Get().QuotaConfig.MaxOutputSize.Key, "-1")
+		defer paramtable.Get().Reset(paramtable.Get().QuotaConfig.MaxOutputSize.Key)
+		ids := []int64{1, 2, 3, 4}
+		scores := []float32{-1.0, -2.0, -3.0, -4.0}
+		topks := []int64{int64(len(ids))}
+		data := mock_segcore.GenSearchResultData(nq, topk, ids, scores, topks)
+		reduceInfo := reduce.NewReduceSearchResultInfo(nq, topk).WithGroupSize(1)
+		searchReduce := InitSearchReducer(reduceInfo)
+		_, err := searchReduce.ReduceSearchResultData(context.TODO(), []*schemapb.SearchResultData{data}, reduceInfo)
+		suite.ErrorIs(err, merr.ErrSearchResultTooLarge)
+	})
 }
 
 func (suite *SearchReduceSuite) TestResult_SearchGroupByResult() {
@@ -20,6 +20,7 @@ import (
 	"github.com/milvus-io/milvus/internal/querynodev2/segments"
 	"github.com/milvus-io/milvus/internal/util/searchutil/scheduler"
 	"github.com/milvus-io/milvus/internal/util/segcore"
+	"github.com/milvus-io/milvus/pkg/v2/common"
 	"github.com/milvus-io/milvus/pkg/v2/log"
 	"github.com/milvus-io/milvus/pkg/v2/metrics"
 	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
@@ -89,6 +90,31 @@ func (t *SearchTask) Username() string {
 	return t.req.Req.GetUsername()
 }
 
+// Priority returns the search priority set by proxy, the larger the earlier it's expected to be scheduled.
+// Return common.MinSearchPriority if the task do not contain any priority.
+func (t *SearchTask) Priority() int64 {
+	priority, err := strconv.ParseInt(t.req.GetReq().GetBase().GetProperties()[common.SearchPriorityKey], 10, 64)
+	if err != nil {
+		return common.MinSearchPriority
+	}
+	return priority
+}
+
+// checkSchemaVersion rejects the search planned with a newer schema than the collection schema on this node, the
+// fields the plan refers to may be missing here. The newer schema on this node is compatible as the fields are only
+// added. The proxy re-plans the search on the error.
+func (t *SearchTask) checkSchemaVersion() error {
+	version, err := strconv.ParseUint(t.req.GetReq().GetBase().GetProperties()[common.SearchSchemaVersionKey], 10, 64)
+	if err != nil {
+		return nil
+	}
+	if localVersion := t.collection.SchemaVersion(); version > localVersion {
+		return merr.WrapErrCollectionSchemaMisMatch(t.collection.ID(),
+			fmt.Sprintf("search planned with schema version %d, querynode holds schema version %d", version, localVersion))
+	}
+	return nil
+}
+
 func (t *SearchTask) GetNodeID() int64 {
 	return t.serverID
 }
@@ -120,6 +146,10 @@ func (t *SearchTask) PreExecute() error {
 		username).
 		Observe(inQueueDurationMS)
 
+	if err := t.checkSchemaVersion(); err != nil {
+		return err
+	}
+
 	// Execute merged task's PreExecute.
 	for _, subTask := range t.others {
 		err := subTask.PreExecute()
@@ -293,6 +323,8 @@ func (t *SearchTask) Merge(other *SearchTask) bool {
 		t.req.GetReq().GetMvccTimestamp() != other.req.GetReq().GetMvccTimestamp() ||
 		t.req.GetReq().GetDslType() != other.req.GetReq().GetDslType() ||
 		t.req.GetDmlChannels()[0] != other.req.GetDmlChannels()[0] ||
+		t.Priority() != other.Priority() ||
+		t.req.GetReq().GetBase().GetProperties()[common.SearchSchemaVersionKey] != other.req.GetReq().GetBase().GetProperties()[common.SearchSchemaVersionKey] ||
 		nq+otherNq > paramtable.Get().QueryNodeCfg.MaxGroupNQ.GetAsInt64() ||
 		diffTopk && ratio > paramtable.Get().QueryNodeCfg.TopKMergeRatio.GetAsFloat() ||
 		!funcutil.SliceSetEqual(t.req.GetReq().GetPartitionIDs(), other.req.GetReq().GetPartitionIDs()) ||
@@ -27,7 +27,14 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/mocks/util/mock_segcore"
+	"github.com/milvus-io/milvus/internal/querynodev2/segments"
 	"github.com/milvus-io/milvus/pkg/v2/common"
+	"github.com/milvus-io/milvus/pkg/v2/proto/internalpb"
+	"github.com/milvus-io/milvus/pkg/v2/proto/querypb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/milvus-io/milvus/pkg/v2/util/paramtable"
 )
 
 type SearchTaskSuite struct {
@@ -142,6 +149,39 @@ func (s *SearchTaskSuite) TestCombinePlaceHolderGroups() {
 	})
 }
 
+func (s *SearchTaskSuite) TestCheckSchemaVersion() {
+	paramtable.Init()
+	schema := mock_segcore.GenTestCollectionSchema("test_schema_version", schemapb.DataType_Int64, true)
+	collection, err := segments.NewCollection(1, schema, nil, &querypb.LoadMetaInfo{
+		LoadType:      querypb.LoadType_LoadCollection,
+		SchemaVersion: 100,
+	})
+	s.Require().NoError(err)
+	defer segments.DeleteCollection(collection)
+	s.EqualValues(100, collection.SchemaVersion())
+
+	newTask := func(properties map[string]string) *SearchTask {
+		return &SearchTask{
+			collection: collection,
+			req: &querypb.SearchRequest{
+				Req:         &internalpb.SearchRequest{Base: &commonpb.MsgBase{Properties: properties}},
+				DmlChannels: []string{"test_channel"},
+			},
+		}
+	}
+
+	// the search without schema version is not checked
+	s.NoError(newTask(nil).checkSchemaVersion())
+	s.NoError(newTask(map[string]string{common.SearchSchemaVersionKey: "100"}).checkSchemaVersion())
+	// the fields are only added, the search planned with an older schema is compatible
+	s.NoError(newTask(map[string]string{common.SearchSchemaVersionKey: "90"}).checkSchemaVersion())
+	// the search planned with a newer schema may refer to the fields missing on this node
+	s.ErrorIs(newTask(map[string]string{common.SearchSchemaVersionKey: "110"}).checkSchemaVersion(), merr.ErrCollectionSchemaMismatch)
+
+	// the searches planned with different schema versions are not merged
+	s.False(newTask(map[string]string{common.SearchSchemaVersionKey: "100"}).Merge(newTask(map[string]string{common.SearchSchemaVersionKey: "90"})))
+}
+
 func TestSearchTask(t *testing.T) {
 	suite.Run(t, new(SearchTaskSuite))
 }
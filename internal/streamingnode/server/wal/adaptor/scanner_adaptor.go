@@ -161,8 +161,8 @@ func (s *scannerAdaptorImpl) execute() {
 	}()
 
 	err := s.consumeEventLoop(msgChan)
-	if errors.Is(err, context.Canceled) {
-		s.logger.Info("the consuming event loop of scanner is closed")
+	if errors.Is(err, context.Canceled) || errors.Is(err, message.ErrUpstreamClosed) {
+		s.logger.Info("the consuming event loop of scanner is closed", zap.Error(err))
 		return
 	}
 	s.logger.Warn("the consuming event loop of scanner is closed with unexpected error", zap.Error(err))
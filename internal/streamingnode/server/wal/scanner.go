@@ -1,8 +1,6 @@
 package wal
 
 import (
-	"github.com/cockroachdb/errors"
-
 	"github.com/milvus-io/milvus/pkg/v2/streaming/util/message"
 	"github.com/milvus-io/milvus/pkg/v2/streaming/util/options"
 	"github.com/milvus-io/milvus/pkg/v2/streaming/util/types"
@@ -10,7 +8,8 @@ import (
 
 type MessageFilter = func(message.ImmutableMessage) bool
 
-var ErrUpstreamClosed = errors.New("upstream closed")
+// ErrUpstreamClosed is the same sentinel as message.ErrUpstreamClosed returned by the message handlers.
+var ErrUpstreamClosed = message.ErrUpstreamClosed
 
 // ReadOption is the option for reading records from the wal.
 type ReadOption struct {
@@ -118,6 +118,10 @@ func getRerankName(funcSchema *schemapb.FunctionSchema) string {
 // Currently only supports single rerank
 type FunctionScore struct {
 	reranker Reranker
+	// the params of the rerank function, echoed to the clients to tell the fusion applied.
+	params []*commonpb.KeyValuePair
+	// whether the rerank function is converted from the legacy rank params of hybrid search.
+	legacy bool
 }
 
 func createFunction(collSchema *schemapb.CollectionSchema, funcSchema *schemapb.FunctionSchema) (Reranker, error) {
@@ -154,7 +158,7 @@ func NewFunctionScore(collSchema *schemapb.CollectionSchema, funcScoreSchema *sc
 	if len(funcScoreSchema.Functions) > 1 || len(funcScoreSchema.Functions) == 0 {
 		return nil, fmt.Errorf("Currently only supports one rerank, but got %d", len(funcScoreSchema.Functions))
 	}
-	funcScore := &FunctionScore{}
+	funcScore := &FunctionScore{params: funcScoreSchema.Functions[0].GetParams()}
 	var err error
 	if funcScore.reranker, err = createFunction(collSchema, funcScoreSchema.Functions[0]); err != nil {
 		return nil, err
@@ -217,7 +221,7 @@ func NewFunctionScoreWithlegacy(collSchema *schemapb.CollectionSchema, rankParam
 	default:
 		return nil, fmt.Errorf("unsupported rank type %s", rankTypeStr)
 	}
-	funcScore := &FunctionScore{}
+	funcScore := &FunctionScore{params: fSchema.Params, legacy: true}
 	if funcScore.reranker, err = createFunction(collSchema, &fSchema); err != nil {
 		return nil, err
 	}
@@ -287,3 +291,34 @@ func (fScore *FunctionScore) RerankName() string {
 	}
 	return fScore.reranker.GetRankName()
 }
+
+// RerankParams returns the params of the rerank function except the name of the reranker, the defaults of the params
+// not specified are not included.
+func (fScore *FunctionScore) RerankParams() map[string]string {
+	if fScore == nil {
+		return nil
+	}
+	params := make(map[string]string, len(fScore.params))
+	for _, param := range fScore.params {
+		if strings.ToLower(param.GetKey()) != reranker {
+			params[param.GetKey()] = param.GetValue()
+		}
+	}
+	return params
+}
+
+// IsLegacy returns whether the rerank function is converted from the legacy rank params of hybrid search.
+func (fScore *FunctionScore) IsLegacy() bool {
+	return fScore != nil && fScore.legacy
+}
+
+// RerankWeights returns the weights of the sub search requests, nil if the reranker does not weigh them.
+func (fScore *FunctionScore) RerankWeights() []float32 {
+	if fScore == nil {
+		return nil
+	}
+	if weighted, ok := fScore.reranker.(interface{ getWeights() []float32 }); ok {
+		return weighted.getWeights()
+	}
+	return nil
+}
@@ -79,6 +79,9 @@ func (s *FunctionScoreSuite) TestNewFunctionScore() {
 	s.Equal([]int64{102}, f.GetAllInputFieldIDs())
 	s.Equal(true, f.IsSupportGroup())
 	s.Equal("decay", f.reranker.GetRankName())
+	s.False(f.IsLegacy())
+	s.Equal(map[string]string{originKey: "4", scaleKey: "4", offsetKey: "4", decayKey: "0.5", functionKey: "gauss"}, f.RerankParams())
+	s.Nil(f.RerankWeights())
 
 	{
 		schema.Fields[3].Nullable = true
@@ -364,6 +367,9 @@ func (s *FunctionScoreSuite) TestlegacyFunction() {
 		f, err := NewFunctionScoreWithlegacy(schema, rankParams)
 		s.NoError(err)
 		s.Equal(f.reranker.GetRankName(), weightedName)
+		s.True(f.IsLegacy())
+		s.Equal(map[string]string{WeightsParamsKey: "[1]"}, f.RerankParams())
+		s.Equal([]float32{1.0}, f.RerankWeights())
 	}
 	{
 		rankParams := []*commonpb.KeyValuePair{
@@ -125,6 +125,10 @@ func (weighted *WeightedFunction[T]) Process(ctx context.Context, searchParams *
 	return outputs, nil
 }
 
+func (weighted *WeightedFunction[T]) getWeights() []float32 {
+	return weighted.weight
+}
+
 type normalizeFunc func(float32) float32
 
 func getNormalizeFunc(normScore bool, metrics string) normalizeFunc {
@@ -154,6 +154,20 @@ const (
 	JSONCastTypeKey     = "json_cast_type"
 	JSONPathKey         = "json_path"
 	JSONCastFunctionKey = "json_cast_function"
+
+	// SearchPriorityKey is the property of the search request MsgBase holding the search priority,
+	// the larger the priority is, the earlier the search is expected to be scheduled.
+	SearchPriorityKey = "search_priority"
+	MinSearchPriority = 0
+	MaxSearchPriority = 9
+
+	// SearchSchemaVersionKey is the property of the search request MsgBase holding the version of the collection
+	// schema the search is planned with, QueryNodes holding an older schema reject the search.
+	SearchSchemaVersionKey = "search_schema_version"
+
+	// SearchGrowingSkippedKey is the property of the search results MsgBase holding the channel, whose growing
+	// segments are left out as the search ignores growing.
+	SearchGrowingSkippedKey = "search_growing_skipped"
 )
 
 // Doc-in-doc-out
@@ -168,6 +182,12 @@ const (
 	CollectionTTLConfigKey      = "collection.ttl.seconds"
 	CollectionAutoCompactionKey = "collection.autocompaction.enabled"
 	CollectionDescription       = "collection.description"
+	// CollectionDefaultSearchParamsKey is the JSON object of the default search params of the collection,
+	// e.g. {"metric_type": "L2", "params": {"nprobe": 16}}, the search params of the requests take precedence.
+	CollectionDefaultSearchParamsKey = "collection.search.defaultParams"
+	// CollectionSearchRequeryDisabledKey rejects the searches requiring a requery on the collection if true,
+	// it overrides the cluster config proxy.disableSearchRequery.
+	CollectionSearchRequeryDisabledKey = "collection.search.requery.disabled"
 
 	// rate limit
 	CollectionInsertRateMaxKey   = "collection.insertRate.max.mb"
@@ -129,6 +129,26 @@ var (
 			Buckets:   buckets, // unit: ms
 		}, []string{nodeIDLabelName, queryTypeLabelName})
 
+	// ProxySearchExecuteLatency record the time that the proxy fans out the search to query nodes and waits for the results.
+	ProxySearchExecuteLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: milvusNamespace,
+			Subsystem: typeutil.ProxyRole,
+			Name:      "search_execute_latency",
+			Help:      "latency that proxy fans out search to query nodes",
+			Buckets:   buckets, // unit: ms
+		}, []string{nodeIDLabelName, queryTypeLabelName, collectionName})
+
+	// ProxySearchRequeryLatency record the time that the proxy requeries the output fields of the search result.
+	ProxySearchRequeryLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: milvusNamespace,
+			Subsystem: typeutil.ProxyRole,
+			Name:      "search_requery_latency",
+			Help:      "latency that proxy requeries the output fields of search result",
+			Buckets:   buckets, // unit: ms
+		}, []string{nodeIDLabelName, queryTypeLabelName, collectionName})
+
 	// ProxyDecodeResultLatency record the time that the proxy decodes the search result.
 	ProxyDecodeResultLatency = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -418,6 +438,15 @@ var (
 			Help:      "counter of recall search",
 		}, []string{nodeIDLabelName, queryTypeLabelName, collectionName})
 
+	// ProxyCoalescedSearchCount records the searches which share the fan-out of an identical concurrent search
+	ProxyCoalescedSearchCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: milvusNamespace,
+			Subsystem: typeutil.ProxyRole,
+			Name:      "coalesced_search_cnt",
+			Help:      "counter of searches coalesced with identical concurrent searches",
+		}, []string{nodeIDLabelName, collectionName})
+
 	// ProxySearchSparseNumNonZeros records the estimated number of non-zeros in each sparse search task
 	ProxySearchSparseNumNonZeros = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -471,6 +500,8 @@ func RegisterProxy(registry *prometheus.Registry) {
 
 	registry.MustRegister(ProxyWaitForSearchResultLatency)
 	registry.MustRegister(ProxyReduceResultLatency)
+	registry.MustRegister(ProxySearchExecuteLatency)
+	registry.MustRegister(ProxySearchRequeryLatency)
 	registry.MustRegister(ProxyDecodeResultLatency)
 
 	registry.MustRegister(ProxyMsgStreamObjectsForPChan)
@@ -515,6 +546,7 @@ func RegisterProxy(registry *prometheus.Registry) {
 	registry.MustRegister(ProxyRetrySearchCount)
 	registry.MustRegister(ProxyRetrySearchResultInsufficientCount)
 	registry.MustRegister(ProxyRecallSearchCount)
+	registry.MustRegister(ProxyCoalescedSearchCount)
 
 	registry.MustRegister(ProxySearchSparseNumNonZeros)
 	registry.MustRegister(ProxyQueueTaskNum)
@@ -652,4 +684,16 @@ func CleanupProxyCollectionMetrics(nodeID int64, collection string) {
 		queryTypeLabelName: SearchLabel,
 		collectionName:     collection,
 	})
+	ProxyCoalescedSearchCount.Delete(prometheus.Labels{
+		nodeIDLabelName: strconv.FormatInt(nodeID, 10),
+		collectionName:  collection,
+	})
+	ProxySearchExecuteLatency.DeletePartialMatch(prometheus.Labels{
+		nodeIDLabelName: strconv.FormatInt(nodeID, 10),
+		collectionName:  collection,
+	})
+	ProxySearchRequeryLatency.DeletePartialMatch(prometheus.Labels{
+		nodeIDLabelName: strconv.FormatInt(nodeID, 10),
+		collectionName:  collection,
+	})
 }
@@ -1,6 +1,7 @@
 package adaptor
 
 import (
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 
 	"github.com/milvus-io/milvus/pkg/v2/log"
@@ -21,7 +22,7 @@ func (h ChanMessageHandler) Handle(param message.HandleParam) message.HandleResu
 		return message.HandleResult{Error: param.Ctx.Err()}
 	case msg, ok := <-param.Upstream:
 		if !ok {
-			panic("unreachable code: upstream should never closed")
+			return message.HandleResult{Error: message.ErrUpstreamClosed}
 		}
 		return message.HandleResult{Incoming: msg}
 	case sendingCh <- param.Message:
@@ -81,7 +82,10 @@ func (m *MsgPackAdaptorHandler) Handle(param message.HandleParam) message.Handle
 			}
 		case msg, ok := <-param.Upstream:
 			if !ok {
-				panic("unreachable code: upstream should never closed")
+				return message.HandleResult{
+					MessageHandled: messageHandled,
+					Error:          message.ErrUpstreamClosed,
+				}
 			}
 			return message.HandleResult{
 				Incoming:       msg,
@@ -97,6 +101,16 @@ func (m *MsgPackAdaptorHandler) Handle(param message.HandleParam) message.Handle
 	}
 }
 
+// SetMaxMessagesPerPack sets the max number of messages of the same time tick packed into one msgPack, 0 means no limit.
+func (m *MsgPackAdaptorHandler) SetMaxMessagesPerPack(n int) {
+	m.base.MaxMessagesPerPack = n
+}
+
+// LastTimeTick returns the time tick of the most recently generated msgPack.
+func (m *MsgPackAdaptorHandler) LastTimeTick() uint64 {
+	return m.base.LastTimeTick()
+}
+
 // Close closes the handler.
 func (m *MsgPackAdaptorHandler) Close() {
 	close(m.channel)
@@ -116,6 +130,20 @@ type BaseMsgPackAdaptorHandler struct {
 	Logger         *log.MLogger
 	Pendings       []message.ImmutableMessage                          // pendings hold the vOld message which has same time tick.
 	PendingMsgPack *typeutil.MultipartQueue[*msgstream.ConsumeMsgPack] // pendingMsgPack hold unsent msgPack.
+	lastTimeTick   atomic.Uint64                                       // lastTimeTick is the time tick of the last generated msgPack.
+
+	// MaxMessagesPerPack is the max number of vOld messages of the same time tick packed into one msgPack, 0 means no limit.
+	// Once reached, the pendings are flushed into a msgPack even if the time tick is not changed,
+	// so the downstream may receive multiple msgPacks with the same time tick, which are still kept in order.
+	MaxMessagesPerPack int
+	splitTimeTick      uint64            // splitTimeTick is the time tick of the pendings flushed by MaxMessagesPerPack.
+	lastSplitMessageID message.MessageID // lastSplitMessageID is the id of the last message flushed at splitTimeTick.
+}
+
+// LastTimeTick returns the time tick of the most recently generated msgPack, 0 if no msgPack is generated yet.
+// It's safe to be called concurrently with GenerateMsgPack.
+func (m *BaseMsgPackAdaptorHandler) LastTimeTick() uint64 {
+	return m.lastTimeTick.Load()
 }
 
 // GenerateMsgPack generate msgPack from message.
@@ -137,7 +165,13 @@ func (m *BaseMsgPackAdaptorHandler) GenerateMsgPack(msg message.ImmutableMessage
 				return
 			}
 		}
+		if m.isSplitDuplicate(msg) {
+			return
+		}
 		m.Pendings = append(m.Pendings, msg)
+		if m.MaxMessagesPerPack > 0 && len(m.Pendings) >= m.MaxMessagesPerPack {
+			m.splitPendings()
+		}
 	case message.VersionV1, message.VersionV2:
 		if len(m.Pendings) != 0 { // all previous message should be vOld.
 			m.addMsgPackIntoPending(m.Pendings...)
@@ -149,6 +183,39 @@ func (m *BaseMsgPackAdaptorHandler) GenerateMsgPack(msg message.ImmutableMessage
 	}
 }
 
+// isSplitDuplicate checks if the vOld message is stale or already flushed by splitPendings.
+// The pendings are empty after a split, so the time tick and the duplication can't be checked against them.
+// The messages are consumed in the order of the message ids, so the ones up to the last flushed one are duplicated.
+func (m *BaseMsgPackAdaptorHandler) isSplitDuplicate(msg message.ImmutableMessage) bool {
+	if m.lastSplitMessageID == nil {
+		return false
+	}
+	if msg.TimeTick() > m.splitTimeTick {
+		m.lastSplitMessageID = nil
+		return false
+	}
+	if msg.TimeTick() < m.splitTimeTick {
+		m.Logger.Warn("message time tick is less than split pendings",
+			zap.String("messageID", msg.MessageID().String()),
+			zap.Uint64("timeTick", msg.TimeTick()),
+			zap.Uint64("splitTimeTick", m.splitTimeTick))
+		return true
+	}
+	return msg.MessageID().LTE(m.lastSplitMessageID)
+}
+
+// splitPendings flushes the pendings into a msgPack before the time tick is changed.
+func (m *BaseMsgPackAdaptorHandler) splitPendings() {
+	m.splitTimeTick = m.Pendings[0].TimeTick()
+	for _, msg := range m.Pendings {
+		if m.lastSplitMessageID == nil || m.lastSplitMessageID.LT(msg.MessageID()) {
+			m.lastSplitMessageID = msg.MessageID()
+		}
+	}
+	m.addMsgPackIntoPending(m.Pendings...)
+	m.Pendings = nil
+}
+
 // addMsgPackIntoPending add message into pending msgPack.
 func (m *BaseMsgPackAdaptorHandler) addMsgPackIntoPending(msgs ...message.ImmutableMessage) {
 	// Because the old version message may have same time tick,
@@ -173,5 +240,7 @@ func (m *BaseMsgPackAdaptorHandler) addMsgPackIntoPending(msgs ...message.Immuta
 	}
 	if newPack != nil {
 		m.PendingMsgPack.AddOne(msgstream.BuildConsumeMsgPack(newPack))
+		// messages in one msgPack share the same time tick.
+		m.lastTimeTick.Store(dedupMessages[len(dedupMessages)-1].TimeTick())
 	}
 }
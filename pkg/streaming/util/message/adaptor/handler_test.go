@@ -59,6 +59,38 @@ func TestDeplicatedMessageOnMsgPackHandler(t *testing.T) {
 	<-done
 }
 
+func TestMsgPackAdaptorHandlerMaxMessagesPerPack(t *testing.T) {
+	newOldMsg := func(id int64, tt uint64) message.ImmutableMessage {
+		messageID := rmq.NewRmqID(id)
+		return message.CreateTestInsertMessage(t, 1, 10, tt, messageID).
+			WithOldVersion().
+			IntoImmutableMessage(messageID)
+	}
+
+	h := NewBaseMsgPackAdaptorHandler()
+	h.MaxMessagesPerPack = 4
+	for i := int64(1); i <= 10; i++ {
+		h.GenerateMsgPack(newOldMsg(i, 100))
+	}
+	// the duplicated messages of the flushed ones and the stale message are dropped.
+	h.GenerateMsgPack(newOldMsg(2, 100))
+	h.GenerateMsgPack(newOldMsg(6, 100))
+	h.GenerateMsgPack(newOldMsg(11, 99))
+	// the remaining pendings are flushed when the time tick is changed.
+	h.GenerateMsgPack(newOldMsg(12, 101))
+	h.GenerateMsgPack(newOldMsg(13, 102))
+
+	sizes := make([]int, 0)
+	for h.PendingMsgPack.Len() > 0 {
+		pack := h.PendingMsgPack.Next()
+		h.PendingMsgPack.UnsafeAdvance()
+		sizes = append(sizes, len(pack.Msgs))
+	}
+	assert.Equal(t, []int{4, 4, 2, 1}, sizes)
+	assert.Equal(t, uint64(101), h.LastTimeTick())
+	assert.Len(t, h.Pendings, 1)
+}
+
 func TestMsgPackAdaptorHandler(t *testing.T) {
 	messageID := rmq.NewRmqID(1)
 	tt := uint64(100)
@@ -90,6 +122,7 @@ func TestMsgPackAdaptorHandler(t *testing.T) {
 	assert.Equal(t, resp.Incoming, immutableMsg)
 	assert.False(t, resp.MessageHandled)
 	assert.NoError(t, resp.Error)
+	assert.Zero(t, h.LastTimeTick())
 
 	resp = h.Handle(message.HandleParam{
 		Ctx:      ctx,
@@ -99,6 +132,7 @@ func TestMsgPackAdaptorHandler(t *testing.T) {
 	assert.NoError(t, resp.Error)
 	assert.Nil(t, resp.Incoming)
 	assert.True(t, resp.MessageHandled)
+	assert.Equal(t, tt, h.LastTimeTick())
 
 	resp = h.Handle(message.HandleParam{
 		Ctx: ctx,
@@ -145,3 +179,27 @@ func TestDefaultHandler(t *testing.T) {
 	h.Close()
 	<-done
 }
+
+func TestHandlerUpstreamClosed(t *testing.T) {
+	upstream := make(chan message.ImmutableMessage)
+	close(upstream)
+
+	h := make(ChanMessageHandler, 1)
+	resp := h.Handle(message.HandleParam{
+		Ctx:      context.Background(),
+		Upstream: upstream,
+	})
+	assert.ErrorIs(t, resp.Error, message.ErrUpstreamClosed)
+	assert.Nil(t, resp.Incoming)
+	h.Close()
+
+	msgPackHandler := NewMsgPackAdaptorHandler()
+	resp = msgPackHandler.Handle(message.HandleParam{
+		Ctx:      context.Background(),
+		Upstream: upstream,
+	})
+	assert.ErrorIs(t, resp.Error, message.ErrUpstreamClosed)
+	assert.Nil(t, resp.Incoming)
+	assert.False(t, resp.MessageHandled)
+	msgPackHandler.Close()
+}
@@ -2,8 +2,13 @@ package message
 
 import (
 	"context"
+
+	"github.com/cockroachdb/errors"
 )
 
+// ErrUpstreamClosed is returned by the handler if the upstream is closed while handling.
+var ErrUpstreamClosed = errors.New("upstream closed")
+
 // HandleParam is the parameter for handler.
 type HandleParam struct {
 	Ctx      context.Context
@@ -15,14 +20,14 @@ type HandleParam struct {
 type HandleResult struct {
 	Incoming       ImmutableMessage // Not nil if upstream return new message.
 	MessageHandled bool             // True if Message is handled successfully.
-	Error          error            // Error is context is canceled.
+	Error          error            // Error is context is canceled or ErrUpstreamClosed.
 }
 
 // Handler is used to handle message read from log.
 type Handler interface {
 	// Handle is the callback for handling message.
 	// Return true if the message is consumed, false if the message is not consumed.
-	// Should return error if and only if ctx is done.
+	// Should return error if and only if ctx is done or upstream is closed.
 	// !!! It's a bad implementation for compatibility for msgstream,
 	// will be removed in the future.
 	Handle(param HandleParam) HandleResult
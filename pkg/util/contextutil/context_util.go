@@ -49,6 +49,24 @@ func TenantID(ctx context.Context) string {
 	return ""
 }
 
+type ctxSearchPriorityKey struct{}
+
+// WithSearchPriority creates a new context that has the search priority injected.
+// It's set by the auth or gateway middleware to tell the interactive searches from the batch ones.
+func WithSearchPriority(ctx context.Context, priority int64) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ctxSearchPriorityKey{}, priority)
+}
+
+// SearchPriority tries to retrieve the search priority from the given context.
+// If it doesn't exist, false is returned.
+func SearchPriority(ctx context.Context) (int64, bool) {
+	priority, ok := ctx.Value(ctxSearchPriorityKey{}).(int64)
+	return priority, ok
+}
+
 func AppendToIncomingContext(ctx context.Context, kv ...string) context.Context {
 	if len(kv)%2 == 1 {
 		panic(fmt.Sprintf("metadata: AppendToOutgoingContext got an odd number of input pairs for metadata: %d", len(kv)))
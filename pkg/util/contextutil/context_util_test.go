@@ -72,6 +72,15 @@ func TestGetCurUserFromContext(t *testing.T) {
 	}
 }
 
+func TestSearchPriority(t *testing.T) {
+	_, ok := SearchPriority(context.Background())
+	assert.False(t, ok)
+
+	priority, ok := SearchPriority(WithSearchPriority(context.Background(), 3))
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), priority)
+}
+
 func GetContext(ctx context.Context, originValue string) context.Context {
 	authKey := strings.ToLower(util.HeaderAuthorize)
 	authValue := crypto.Base64Encode(originValue)
@@ -42,6 +42,26 @@ func (err ErrorType) String() string {
 	return ErrorTypeName[err]
 }
 
+// ReasonCode is a stable machine-readable reason of an error, returned in the extra info of the status,
+// so that clients could handle the errors programmatically instead of matching the messages.
+type ReasonCode string
+
+// Reason codes of the search parameter errors
+const (
+	ReasonNqInvalid              ReasonCode = "NQ_INVALID"
+	ReasonNqTooLarge             ReasonCode = "NQ_TOO_LARGE"
+	ReasonNqMismatch             ReasonCode = "NQ_MISMATCH"
+	ReasonTopKInvalid            ReasonCode = "TOPK_INVALID"
+	ReasonOffsetInvalid          ReasonCode = "OFFSET_INVALID"
+	ReasonResultWindowExceeded   ReasonCode = "RESULT_WINDOW_EXCEEDED"
+	ReasonRoundDecimalInvalid    ReasonCode = "ROUND_DECIMAL_INVALID"
+	ReasonGroupSizeInvalid       ReasonCode = "GROUP_SIZE_INVALID"
+	ReasonGroupSizeExceeded      ReasonCode = "GROUP_SIZE_EXCEEDED"
+	ReasonRerankGroupUnsupported ReasonCode = "RERANK_GROUP_UNSUPPORTED"
+	ReasonMetricTypeMismatch     ReasonCode = "METRIC_TYPE_MISMATCH"
+	ReasonTooManySearchRequests  ReasonCode = "TOO_MANY_SEARCH_REQUESTS"
+)
+
 // Define leaf errors here,
 // WARN: take care to add new error,
 // check whether you can use the errors below before adding a new one.
@@ -135,6 +155,7 @@ var (
 	ErrParameterInvalid  = newMilvusError("invalid parameter", 1100, false)
 	ErrParameterMissing  = newMilvusError("missing parameter", 1101, false)
 	ErrParameterTooLarge = newMilvusError("parameter too large", 1102, false)
+	ErrParameterExpired  = newMilvusError("parameter expired", 1103, false)
 
 	// Metrics related
 	ErrMetricNotFound = newMilvusError("metric not found", 1200, false)
@@ -196,7 +217,10 @@ var (
 	ErrImportFailed = newMilvusError("importing data failed", 2100, false)
 
 	// Search/Query related
-	ErrInconsistentRequery = newMilvusError("inconsistent requery result", 2200, true)
+	ErrInconsistentRequery  = newMilvusError("inconsistent requery result", 2200, true)
+	ErrNoResults            = newMilvusError("no results", 2201, false)
+	ErrSearchResultTooLarge = newMilvusError("search result too large", 2202, false)
+	ErrSearchGrowingSkipped = newMilvusError("growing data skipped", 2203, false)
 
 	// Compaction
 	ErrCompactionReadDeltaLogErr                  = newMilvusError("fail to read delta log", 2300, false)
@@ -59,6 +59,25 @@ func (s *ErrSuite) TestStatus() {
 	s.Nil(Error(&commonpb.Status{}))
 }
 
+func (s *ErrSuite) TestReasonCode() {
+	err := WithReasonCode(WrapErrParameterInvalidMsg("nq too large"), ReasonNqTooLarge)
+	wrapped := errors.Wrap(err, "failed to search")
+	s.ErrorIs(wrapped, ErrParameterInvalid)
+	s.Equal(Code(ErrParameterInvalid), Code(wrapped))
+	reason, ok := GetReasonCode(wrapped)
+	s.True(ok)
+	s.Equal(ReasonNqTooLarge, reason)
+
+	status := Status(wrapped)
+	s.Equal(string(ReasonNqTooLarge), status.GetExtraInfo()[ReasonCodeKey])
+	s.Equal(wrapped.Error(), status.GetDetail())
+
+	_, ok = GetReasonCode(WrapErrParameterInvalidMsg("no reason"))
+	s.False(ok)
+	s.NotContains(Status(WrapErrParameterInvalidMsg("no reason")).GetExtraInfo(), ReasonCodeKey)
+	s.Nil(WithReasonCode(nil, ReasonNqTooLarge))
+}
+
 func (s *ErrSuite) TestStatusWithCode() {
 	err := WrapErrCollectionNotFound(1)
 	status := StatusWithErrorCode(err, commonpb.ErrorCode_CollectionNotExists)
@@ -139,6 +158,7 @@ func (s *ErrSuite) TestWrap() {
 	s.ErrorIs(WrapErrParameterInvalidRange(1, 1<<16, 0, "topk should be in range"), ErrParameterInvalid)
 	s.ErrorIs(WrapErrParameterMissing("alias_name", "no alias parameter"), ErrParameterMissing)
 	s.ErrorIs(WrapErrParameterTooLarge("unit test"), ErrParameterTooLarge)
+	s.ErrorIs(WrapErrParameterExpired("token", "token expired"), ErrParameterExpired)
 
 	// Metrics related
 	s.ErrorIs(WrapErrMetricNotFound("unknown", "failed to get metric"), ErrMetricNotFound)
@@ -150,6 +170,8 @@ func (s *ErrSuite) TestWrap() {
 
 	// field related
 	s.ErrorIs(WrapErrFieldNotFound("meta", "failed to get field"), ErrFieldNotFound)
+	s.ErrorIs(WrapErrFieldNotFoundWithDesc("meta", "did you mean $meta"), ErrFieldNotFound)
+	s.Equal(InputError, GetErrorType(WrapErrAsInputError(WrapErrFieldNotFoundWithDesc("meta", "did you mean $meta"))))
 
 	// alias related
 	s.ErrorIs(WrapErrAliasNotFound("alias", "failed to get collection id"), ErrAliasNotFound)
@@ -157,6 +179,9 @@ func (s *ErrSuite) TestWrap() {
 
 	// Search/Query related
 	s.ErrorIs(WrapErrInconsistentRequery("unknown"), ErrInconsistentRequery)
+	s.ErrorIs(WrapErrNoResults("no hit"), ErrNoResults)
+	s.ErrorIs(WrapErrSearchResultTooLarge(2048, 1024, "reduce"), ErrSearchResultTooLarge)
+	s.ErrorIs(WrapErrSearchGrowingSkipped([]string{"ch"}, "sealed only"), ErrSearchGrowingSkipped)
 }
 
 func (s *ErrSuite) TestOldCode() {
@@ -32,6 +32,9 @@ import (
 
 const InputErrorFlagKey string = "is_input_error"
 
+// ReasonCodeKey is the key of the reason code in the extra info of the status, set if the error carries one.
+const ReasonCodeKey string = "reason_code"
+
 // Code returns the error code of the given error,
 // WARN: DO NOT use this for now
 func Code(err error) int32 {
@@ -88,9 +91,49 @@ func Status(err error) *commonpb.Status {
 	if GetErrorType(err) == InputError {
 		status.ExtraInfo = map[string]string{InputErrorFlagKey: "true"}
 	}
+	if reason, ok := GetReasonCode(err); ok {
+		if status.ExtraInfo == nil {
+			status.ExtraInfo = make(map[string]string)
+		}
+		status.ExtraInfo[ReasonCodeKey] = string(reason)
+	}
 	return status
 }
 
+type reasonCodeError struct {
+	cause  error
+	reason ReasonCode
+}
+
+func (e *reasonCodeError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *reasonCodeError) Cause() error {
+	return e.cause
+}
+
+func (e *reasonCodeError) Unwrap() error {
+	return e.cause
+}
+
+// WithReasonCode attaches the reason code to the error, neither the message nor the error code is changed.
+func WithReasonCode(err error, reason ReasonCode) error {
+	if err == nil {
+		return nil
+	}
+	return &reasonCodeError{cause: err, reason: reason}
+}
+
+// GetReasonCode returns the reason code attached to the error, the outermost one if there are many.
+func GetReasonCode(err error) (ReasonCode, bool) {
+	var reasonErr *reasonCodeError
+	if errors.As(err, &reasonErr) {
+		return reasonErr.reason, true
+	}
+	return "", false
+}
+
 func previousLastError(err error) error {
 	lastErr := err
 	for {
@@ -975,6 +1018,16 @@ func WrapErrParameterTooLarge(name string, msg ...string) error {
 	return err
 }
 
+func WrapErrParameterExpired[T any](param T, msg ...string) error {
+	err := wrapFields(ErrParameterExpired,
+		value("expired_param", param),
+	)
+	if len(msg) > 0 {
+		err = errors.Wrap(err, strings.Join(msg, "->"))
+	}
+	return err
+}
+
 // Metrics related
 func WrapErrMetricNotFound(name string, msg ...string) error {
 	err := wrapFields(ErrMetricNotFound, value("metric", name))
@@ -1045,6 +1098,12 @@ func WrapErrFieldNotFound[T any](field T, msg ...string) error {
 	return err
 }
 
+// WrapErrFieldNotFoundWithDesc is like WrapErrFieldNotFound,
+// but the returned error is still a milvus error so that its error type could be changed.
+func WrapErrFieldNotFoundWithDesc[T any](field T, desc string) error {
+	return wrapFieldsWithDesc(ErrFieldNotFound, desc, value("field", field))
+}
+
 func WrapErrFieldNameInvalid(field any, msg ...string) error {
 	err := wrapFields(ErrFieldInvalidName, value("field", field))
 	if len(msg) > 0 {
@@ -1126,6 +1185,30 @@ func WrapErrInconsistentRequery(msg ...string) error {
 	return err
 }
 
+func WrapErrNoResults(msg ...string) error {
+	err := error(ErrNoResults)
+	if len(msg) > 0 {
+		err = errors.Wrap(err, strings.Join(msg, "->"))
+	}
+	return err
+}
+
+func WrapErrSearchResultTooLarge(size int64, budget int64, msg ...string) error {
+	err := wrapFields(ErrSearchResultTooLarge, value("size", size), value("budget", budget))
+	if len(msg) > 0 {
+		err = errors.Wrap(err, strings.Join(msg, "->"))
+	}
+	return err
+}
+
+func WrapErrSearchGrowingSkipped(channels []string, msg ...string) error {
+	err := wrapFields(ErrSearchGrowingSkipped, value("channels", channels))
+	if len(msg) > 0 {
+		err = errors.Wrap(err, strings.Join(msg, "->"))
+	}
+	return err
+}
+
 func WrapErrCompactionReadDeltaLogErr(msg ...string) error {
 	err := error(ErrCompactionReadDeltaLogErr)
 	if len(msg) > 0 {
@@ -1602,40 +1602,64 @@ type proxyConfig struct {
 	// Alias  string
 	SoPath ParamItem `refreshable:"false"`
 
-	TimeTickInterval             ParamItem `refreshable:"false"`
-	HealthCheckTimeout           ParamItem `refreshable:"true"`
-	MsgStreamTimeTickBufSize     ParamItem `refreshable:"true"`
-	MaxNameLength                ParamItem `refreshable:"true"`
-	MaxUsernameLength            ParamItem `refreshable:"true"`
-	MinPasswordLength            ParamItem `refreshable:"true"`
-	MaxPasswordLength            ParamItem `refreshable:"true"`
-	MaxFieldNum                  ParamItem `refreshable:"true"`
-	MaxVectorFieldNum            ParamItem `refreshable:"true"`
-	MaxShardNum                  ParamItem `refreshable:"true"`
-	MaxDimension                 ParamItem `refreshable:"true"`
-	GinLogging                   ParamItem `refreshable:"false"`
-	GinLogSkipPaths              ParamItem `refreshable:"false"`
-	MaxUserNum                   ParamItem `refreshable:"true"`
-	MaxRoleNum                   ParamItem `refreshable:"true"`
-	MaxTaskNum                   ParamItem `refreshable:"false"`
-	DDLConcurrency               ParamItem `refreshable:"true"`
-	DCLConcurrency               ParamItem `refreshable:"true"`
-	ShardLeaderCacheInterval     ParamItem `refreshable:"false"`
-	ReplicaSelectionPolicy       ParamItem `refreshable:"false"`
-	CheckQueryNodeHealthInterval ParamItem `refreshable:"false"`
-	CostMetricsExpireTime        ParamItem `refreshable:"false"`
-	CheckWorkloadRequestNum      ParamItem `refreshable:"false"`
-	WorkloadToleranceFactor      ParamItem `refreshable:"false"`
-	RetryTimesOnReplica          ParamItem `refreshable:"true"`
-	RetryTimesOnHealthCheck      ParamItem `refreshable:"true"`
-	PartitionNameRegexp          ParamItem `refreshable:"true"`
-	MustUsePartitionKey          ParamItem `refreshable:"true"`
-	SkipAutoIDCheck              ParamItem `refreshable:"true"`
-	SkipPartitionKeyCheck        ParamItem `refreshable:"true"`
-	MaxVarCharLength             ParamItem `refreshable:"false"`
-	MaxTextLength                ParamItem `refreshable:"false"`
-	MaxResultEntries             ParamItem `refreshable:"true"`
-	EnableCachedServiceProvider  ParamItem `refreshable:"true"`
+	TimeTickInterval              ParamItem `refreshable:"false"`
+	HealthCheckTimeout            ParamItem `refreshable:"true"`
+	MsgStreamTimeTickBufSize      ParamItem `refreshable:"true"`
+	MaxNameLength                 ParamItem `refreshable:"true"`
+	MaxUsernameLength             ParamItem `refreshable:"true"`
+	MinPasswordLength             ParamItem `refreshable:"true"`
+	MaxPasswordLength             ParamItem `refreshable:"true"`
+	MaxFieldNum                   ParamItem `refreshable:"true"`
+	MaxVectorFieldNum             ParamItem `refreshable:"true"`
+	MaxShardNum                   ParamItem `refreshable:"true"`
+	MaxDimension                  ParamItem `refreshable:"true"`
+	GinLogging                    ParamItem `refreshable:"false"`
+	GinLogSkipPaths               ParamItem `refreshable:"false"`
+	MaxUserNum                    ParamItem `refreshable:"true"`
+	MaxRoleNum                    ParamItem `refreshable:"true"`
+	MaxTaskNum                    ParamItem `refreshable:"false"`
+	DDLConcurrency                ParamItem `refreshable:"true"`
+	DCLConcurrency                ParamItem `refreshable:"true"`
+	ShardLeaderCacheInterval      ParamItem `refreshable:"false"`
+	ReplicaSelectionPolicy        ParamItem `refreshable:"false"`
+	CheckQueryNodeHealthInterval  ParamItem `refreshable:"false"`
+	CostMetricsExpireTime         ParamItem `refreshable:"false"`
+	CheckWorkloadRequestNum       ParamItem `refreshable:"false"`
+	WorkloadToleranceFactor       ParamItem `refreshable:"false"`
+	RetryTimesOnReplica           ParamItem `refreshable:"true"`
+	RetryTimesOnHealthCheck       ParamItem `refreshable:"true"`
+	PartitionNameRegexp           ParamItem `refreshable:"true"`
+	MustUsePartitionKey           ParamItem `refreshable:"true"`
+	SkipAutoIDCheck               ParamItem `refreshable:"true"`
+	SkipPartitionKeyCheck         ParamItem `refreshable:"true"`
+	MaxVarCharLength              ParamItem `refreshable:"false"`
+	MaxTextLength                 ParamItem `refreshable:"false"`
+	MaxResultEntries              ParamItem `refreshable:"true"`
+	MaxPartitionKeyFanout         ParamItem `refreshable:"true"`
+	SearchPlanCacheSize           ParamItem `refreshable:"false"`
+	SearchPlanCacheTTL            ParamItem `refreshable:"false"`
+	DropInvalidSearchScores       ParamItem `refreshable:"true"`
+	PlaceholderGroupCacheSize     ParamItem `refreshable:"false"`
+	PlaceholderGroupCacheTTL      ParamItem `refreshable:"false"`
+	PlaceholderGroupCacheMaxBytes ParamItem `refreshable:"false"`
+	CoalesceIdenticalSearch       ParamItem `refreshable:"true"`
+	ConsistencyDowngradeQueueLen  ParamItem `refreshable:"true"`
+	SearchShardRetryAttempts      ParamItem `refreshable:"true"`
+	SearchShardRetryInterval      ParamItem `refreshable:"true"`
+	MaxExprTemplateValueCount     ParamItem `refreshable:"true"`
+	MaxExprTemplateValueSize      ParamItem `refreshable:"true"`
+	MaxHybridSearchRequests       ParamItem `refreshable:"true"`
+	ApproxDistinctMaxRows         ParamItem `refreshable:"true"`
+	SlowSearchLogThreshold        ParamItem `refreshable:"true"`
+	MaxOutputFields               ParamItem `refreshable:"true"`
+	MaxSearchExprLength           ParamItem `refreshable:"true"`
+	DisableSearchRequery          ParamItem `refreshable:"true"`
+	SearchResultMemoryBudget      ParamItem `refreshable:"true"`
+	QueryVectorsURIPrefix         ParamItem `refreshable:"true"`
+	MaxQueryVectorsObjectSize     ParamItem `refreshable:"true"`
+	QueryVectorsFetchTimeout      ParamItem `refreshable:"true"`
+	DefaultMaxFieldBytes          ParamItem `refreshable:"true"`
+	EnableCachedServiceProvider   ParamItem `refreshable:"true"`
 
 	AccessLog AccessLogConfig
 
@@ -2069,6 +2093,240 @@ Disabled if the value is less or equal to 0.`,
 	}
 	p.MaxResultEntries.Init(base.mgr)
 
+	p.MaxPartitionKeyFanout = ParamItem{
+		Key:          "proxy.maxPartitionKeyFanout",
+		Version:      "2.6.0",
+		DefaultValue: "-1",
+		Doc: `maximum number of partitions a search filtered by partition key can be routed to.
+If the partition key expression resolves to more partitions than this limit, the search will be rejected.
+Disabled if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.MaxPartitionKeyFanout.Init(base.mgr)
+
+	p.SearchPlanCacheSize = ParamItem{
+		Key:          "proxy.searchPlanCache.size",
+		Version:      "2.6.0",
+		DefaultValue: "0",
+		Doc: `maximum number of compiled search plans cached in proxy, repeated identical searches skip parsing the expression.
+Disabled if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.SearchPlanCacheSize.Init(base.mgr)
+
+	p.SearchPlanCacheTTL = ParamItem{
+		Key:          "proxy.searchPlanCache.ttl",
+		Version:      "2.6.0",
+		DefaultValue: "600",
+		Doc:          "time to live of the cached search plans, in seconds",
+		Export:       true,
+	}
+	p.SearchPlanCacheTTL.Init(base.mgr)
+
+	p.DropInvalidSearchScores = ParamItem{
+		Key:          "proxy.dropInvalidSearchScores",
+		Version:      "2.6.0",
+		DefaultValue: "false",
+		Doc: `whether to drop the search results with NaN or Inf scores.
+If false, the invalid scores are replaced by the extreme finite values instead.`,
+		Export: true,
+	}
+	p.DropInvalidSearchScores.Init(base.mgr)
+
+	p.PlaceholderGroupCacheSize = ParamItem{
+		Key:          "proxy.placeholderGroupCache.size",
+		Version:      "2.6.0",
+		DefaultValue: "0",
+		Doc: `maximum number of placeholder groups cached in proxy, searches could reference the cached placeholder groups by token
+instead of sending the vectors again. Disabled if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.PlaceholderGroupCacheSize.Init(base.mgr)
+
+	p.PlaceholderGroupCacheTTL = ParamItem{
+		Key:          "proxy.placeholderGroupCache.ttl",
+		Version:      "2.6.0",
+		DefaultValue: "300",
+		Doc:          "time to live of the cached placeholder groups, in seconds",
+		Export:       true,
+	}
+	p.PlaceholderGroupCacheTTL.Init(base.mgr)
+
+	p.PlaceholderGroupCacheMaxBytes = ParamItem{
+		Key:          "proxy.placeholderGroupCache.maxSize",
+		Version:      "2.6.0",
+		DefaultValue: "256m",
+		Doc:          "max total size of the cached placeholder groups, the least recently used ones are evicted beyond it",
+		Export:       true,
+	}
+	p.PlaceholderGroupCacheMaxBytes.Init(base.mgr)
+
+	p.CoalesceIdenticalSearch = ParamItem{
+		Key:          "proxy.coalesceIdenticalSearch",
+		Version:      "2.6.0",
+		DefaultValue: "false",
+		Doc: `whether to coalesce the identical searches running concurrently, the coalesced searches share
+one fan-out to query nodes. Searches are only coalesced when they have the same guarantee timestamp.`,
+		Export: true,
+	}
+	p.CoalesceIdenticalSearch.Init(base.mgr)
+
+	p.ConsistencyDowngradeQueueLen = ParamItem{
+		Key:          "proxy.consistencyDowngradeQueueLen",
+		Version:      "2.6.0",
+		DefaultValue: "-1",
+		Doc: `new strong consistency searches are downgraded to bounded consistency if the number of queued search and query tasks
+in proxy reaches this value, the downgraded searches are tagged in the response. Disabled if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.ConsistencyDowngradeQueueLen.Init(base.mgr)
+
+	p.SearchShardRetryAttempts = ParamItem{
+		Key:          "proxy.searchShardRetryAttempts",
+		Version:      "2.6.0",
+		DefaultValue: "1",
+		Doc: `max attempts to search a shard on the same query node if the query node returns a retriable error,
+the attempts are backed off exponentially and bounded by the request deadline. No retry if the value is less or equal to 1.`,
+		Export: true,
+	}
+	p.SearchShardRetryAttempts.Init(base.mgr)
+
+	p.SearchShardRetryInterval = ParamItem{
+		Key:          "proxy.searchShardRetryInterval",
+		Version:      "2.6.0",
+		DefaultValue: "100",
+		Doc:          "ms, the initial backoff between the attempts to search a shard, doubled after each attempt up to 10 times of it",
+		Export:       true,
+	}
+	p.SearchShardRetryInterval.Init(base.mgr)
+
+	p.MaxExprTemplateValueCount = ParamItem{
+		Key:          "proxy.maxExprTemplateValueCount",
+		Version:      "2.6.0",
+		DefaultValue: "1000000",
+		Doc: `max number of the expression template values in a search request, the elements of the array values are counted one by one.
+No limit if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.MaxExprTemplateValueCount.Init(base.mgr)
+
+	p.MaxExprTemplateValueSize = ParamItem{
+		Key:          "proxy.maxExprTemplateValueSize",
+		Version:      "2.6.0",
+		DefaultValue: "64m",
+		Doc:          "max serialized size of the expression template values in a search request, no limit if the value is less or equal to 0",
+		Export:       true,
+	}
+	p.MaxExprTemplateValueSize.Init(base.mgr)
+
+	p.MaxHybridSearchRequests = ParamItem{
+		Key:          "proxy.maxHybridSearchRequests",
+		Version:      "2.6.0",
+		DefaultValue: "1024",
+		Doc: `max number of the ann search requests in a hybrid search,
+privileged users could override it for a single request by the search param max_search_requests.`,
+		Export: true,
+	}
+	p.MaxHybridSearchRequests.Init(base.mgr)
+
+	p.ApproxDistinctMaxRows = ParamItem{
+		Key:          "proxy.approxDistinctMaxRows",
+		Version:      "2.6.0",
+		DefaultValue: "100000",
+		Doc: `max number of the hits aggregated for the approximate distinct count requested by the search param approx_distinct_field,
+the hits beyond it are not counted.`,
+		Export: true,
+	}
+	p.ApproxDistinctMaxRows.Init(base.mgr)
+
+	p.SlowSearchLogThreshold = ParamItem{
+		Key:          "proxy.slowSearchLogThreshold",
+		Version:      "2.6.0",
+		DefaultValue: "0",
+		Doc: `searches whose PreExecute, Execute and PostExecute take more than it in total emit a warning log with the shape
+and the phase timings of the search, in milliseconds. Disabled if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.SlowSearchLogThreshold.Init(base.mgr)
+
+	p.MaxOutputFields = ParamItem{
+		Key:          "proxy.maxOutputFields",
+		Version:      "2.6.0",
+		DefaultValue: "1024",
+		Doc: `max number of the output fields of a search, counted after the wildcard is expanded.
+No limit if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.MaxOutputFields.Init(base.mgr)
+
+	p.MaxSearchExprLength = ParamItem{
+		Key:          "proxy.maxSearchExprLength",
+		Version:      "2.6.0",
+		DefaultValue: "16m",
+		Doc: `max length of the filter expression of a search, the longer expressions are rejected before being parsed.
+No limit if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.MaxSearchExprLength.Init(base.mgr)
+
+	p.DisableSearchRequery = ParamItem{
+		Key:          "proxy.disableSearchRequery",
+		Version:      "2.6.0",
+		DefaultValue: "false",
+		Doc: `whether to reject the searches requiring a requery to fetch the vector output fields, which doubles the load of querynodes.
+It could be overridden by the collection property collection.search.requery.disabled.`,
+		Export: true,
+	}
+	p.DisableSearchRequery.Init(base.mgr)
+
+	p.SearchResultMemoryBudget = ParamItem{
+		Key:          "proxy.searchResultMemoryBudget",
+		Version:      "2.6.0",
+		DefaultValue: "4g",
+		Doc: `max estimated size of the requeried field data a search could assemble in proxy, the search is aborted once exceeded.
+No limit if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.SearchResultMemoryBudget.Init(base.mgr)
+
+	p.QueryVectorsURIPrefix = ParamItem{
+		Key:          "proxy.queryVectors.uriPrefix",
+		Version:      "2.6.0",
+		DefaultValue: "",
+		Doc: `the path prefix in the bucket of the object storage the searches could reference the query vectors under by query_vectors_uri,
+e.g. batch-search/. Referencing the query vectors is disabled if empty.`,
+		Export: true,
+	}
+	p.QueryVectorsURIPrefix.Init(base.mgr)
+
+	p.MaxQueryVectorsObjectSize = ParamItem{
+		Key:          "proxy.queryVectors.maxObjectSize",
+		Version:      "2.6.0",
+		DefaultValue: "256m",
+		Doc:          "max size of the query vectors object referenced by query_vectors_uri",
+		Export:       true,
+	}
+	p.MaxQueryVectorsObjectSize.Init(base.mgr)
+
+	p.QueryVectorsFetchTimeout = ParamItem{
+		Key:          "proxy.queryVectors.fetchTimeout",
+		Version:      "2.6.0",
+		DefaultValue: "10",
+		Doc:          "timeout of fetching the query vectors object referenced by query_vectors_uri, in seconds",
+		Export:       true,
+	}
+	p.QueryVectorsFetchTimeout.Init(base.mgr)
+
+	p.DefaultMaxFieldBytes = ParamItem{
+		Key:          "proxy.defaultMaxFieldBytes",
+		Version:      "2.6.0",
+		DefaultValue: "0",
+		Doc: `max bytes of the varchar and JSON values returned by searches, the longer ones are truncated.
+It applies to the searches not specifying max_field_bytes, no limit if the value is less or equal to 0.`,
+		Export: true,
+	}
+	p.DefaultMaxFieldBytes.Init(base.mgr)
+
 	p.EnableCachedServiceProvider = ParamItem{
 		Key:          "proxy.enableCachedServiceProvider",
 		Version:      "2.6.0",
@@ -5860,6 +6118,9 @@ type streamingConfig struct {
 	// read ahead buffer size
 	WALReadAheadBufferLength ParamItem `refreshable:"true"`
 
+	// msgPack adaptor
+	MsgPackAdaptorMaxMessagesPerPack ParamItem `refreshable:"true"`
+
 	// logging
 	LoggingAppendSlowThreshold ParamItem `refreshable:"true"`
 	// memory usage control
@@ -6060,6 +6321,16 @@ Use the underlying wal default value if 0 is given.`,
 	}
 	p.WALReadAheadBufferLength.Init(base.mgr)
 
+	p.MsgPackAdaptorMaxMessagesPerPack = ParamItem{
+		Key:     "streaming.msgPackAdaptor.maxMessagesPerPack",
+		Version: "2.6.0",
+		Doc: `The max number of the old version messages of the same time tick packed into one msgPack consumed by the delegator, 0 by default.
+The msgPack is split once reached, so the huge msgPacks of one time tick don't block the consumption. No limit if 0 is given.`,
+		DefaultValue: "0",
+		Export:       true,
+	}
+	p.MsgPackAdaptorMaxMessagesPerPack.Init(base.mgr)
+
 	p.LoggingAppendSlowThreshold = ParamItem{
 		Key:     "streaming.logging.appendSlowThreshold",
 		Version: "2.6.0",
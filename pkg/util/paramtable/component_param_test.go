@@ -217,6 +217,49 @@ func TestComponentParam(t *testing.T) {
 		params.Save("proxy.mustUsePartitionKey", "true")
 		assert.True(t, Params.MustUsePartitionKey.GetAsBool())
 
+		assert.Equal(t, int64(-1), Params.MaxPartitionKeyFanout.GetAsInt64())
+		params.Save("proxy.maxPartitionKeyFanout", "16")
+		assert.Equal(t, int64(16), Params.MaxPartitionKeyFanout.GetAsInt64())
+
+		assert.Equal(t, 0, Params.SearchPlanCacheSize.GetAsInt())
+		assert.Equal(t, 10*time.Minute, Params.SearchPlanCacheTTL.GetAsDuration(time.Second))
+
+		assert.False(t, Params.DropInvalidSearchScores.GetAsBool())
+		params.Save("proxy.dropInvalidSearchScores", "true")
+		assert.True(t, Params.DropInvalidSearchScores.GetAsBool())
+
+		assert.Equal(t, 0, Params.PlaceholderGroupCacheSize.GetAsInt())
+		assert.Equal(t, 5*time.Minute, Params.PlaceholderGroupCacheTTL.GetAsDuration(time.Second))
+		assert.Equal(t, int64(256<<20), Params.PlaceholderGroupCacheMaxBytes.GetAsSize())
+
+		assert.False(t, Params.CoalesceIdenticalSearch.GetAsBool())
+		params.Save("proxy.coalesceIdenticalSearch", "true")
+		assert.True(t, Params.CoalesceIdenticalSearch.GetAsBool())
+
+		assert.Equal(t, -1, Params.ConsistencyDowngradeQueueLen.GetAsInt())
+		params.Save("proxy.consistencyDowngradeQueueLen", "100")
+		assert.Equal(t, 100, Params.ConsistencyDowngradeQueueLen.GetAsInt())
+
+		assert.Equal(t, 1, Params.SearchShardRetryAttempts.GetAsInt())
+		params.Save("proxy.searchShardRetryAttempts", "3")
+		assert.Equal(t, 3, Params.SearchShardRetryAttempts.GetAsInt())
+		assert.Equal(t, 100*time.Millisecond, Params.SearchShardRetryInterval.GetAsDuration(time.Millisecond))
+		assert.Equal(t, 1000000, Params.MaxExprTemplateValueCount.GetAsInt())
+		assert.Equal(t, int64(64<<20), Params.MaxExprTemplateValueSize.GetAsSize())
+		params.Save("proxy.maxExprTemplateValueSize", "1k")
+		assert.Equal(t, int64(1<<10), Params.MaxExprTemplateValueSize.GetAsSize())
+		assert.Equal(t, 1024, Params.MaxHybridSearchRequests.GetAsInt())
+		assert.Equal(t, 100000, Params.ApproxDistinctMaxRows.GetAsInt())
+		assert.Equal(t, time.Duration(0), Params.SlowSearchLogThreshold.GetAsDuration(time.Millisecond))
+		assert.Equal(t, 1024, Params.MaxOutputFields.GetAsInt())
+		assert.Equal(t, int64(16<<20), Params.MaxSearchExprLength.GetAsSize())
+		assert.False(t, Params.DisableSearchRequery.GetAsBool())
+		assert.Equal(t, int64(4<<30), Params.SearchResultMemoryBudget.GetAsSize())
+		assert.Equal(t, "", Params.QueryVectorsURIPrefix.GetValue())
+		assert.Equal(t, int64(256<<20), Params.MaxQueryVectorsObjectSize.GetAsSize())
+		assert.Equal(t, 10*time.Second, Params.QueryVectorsFetchTimeout.GetAsDuration(time.Second))
+		assert.Equal(t, 0, Params.DefaultMaxFieldBytes.GetAsInt())
+
 		assert.False(t, Params.SkipAutoIDCheck.GetAsBool())
 		params.Save("proxy.skipAutoIDCheck", "true")
 		assert.True(t, Params.SkipAutoIDCheck.GetAsBool())
@@ -659,6 +702,7 @@ func TestComponentParam(t *testing.T) {
 		assert.Equal(t, 30*time.Second, params.StreamingCfg.WALWriteAheadBufferKeepalive.GetAsDurationByParse())
 		assert.Equal(t, int64(64*1024*1024), params.StreamingCfg.WALWriteAheadBufferCapacity.GetAsSize())
 		assert.Equal(t, 128, params.StreamingCfg.WALReadAheadBufferLength.GetAsInt())
+		assert.Equal(t, 0, params.StreamingCfg.MsgPackAdaptorMaxMessagesPerPack.GetAsInt())
 		assert.Equal(t, 1*time.Second, params.StreamingCfg.LoggingAppendSlowThreshold.GetAsDurationByParse())
 		assert.Equal(t, 3*time.Second, params.StreamingCfg.WALRecoveryGracefulCloseTimeout.GetAsDurationByParse())
 		assert.Equal(t, 100, params.StreamingCfg.WALRecoveryMaxDirtyMessage.GetAsInt())
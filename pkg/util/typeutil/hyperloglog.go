@@ -0,0 +1,94 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeutil
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/spaolacci/murmur3"
+)
+
+const (
+	minHyperLogLogPrecision = 4
+	maxHyperLogLogPrecision = 16
+)
+
+// HyperLogLog estimates the number of distinct values added with 2^precision registers,
+// the standard error is about 1.04/sqrt(2^precision).
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog creates a HyperLogLog, the precision is clamped into [4, 16].
+func NewHyperLogLog(precision uint8) *HyperLogLog {
+	// the builtin max is shadowed by the field length policy of this package
+	if precision < minHyperLogLogPrecision {
+		precision = minHyperLogLogPrecision
+	}
+	if precision > maxHyperLogLogPrecision {
+		precision = maxHyperLogLogPrecision
+	}
+	return &HyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+// Add adds the serialized value.
+func (h *HyperLogLog) Add(data []byte) {
+	x := murmur3.Sum64(data)
+	idx := x >> (64 - h.precision)
+	// the guard bit bounds the rank if the remaining bits are all zero
+	rank := uint8(bits.LeadingZeros64(x<<h.precision|1<<(h.precision-1))) + 1
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// Count returns the estimated number of distinct values added.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := hyperLogLogAlpha(len(h.registers)) * m * m / sum
+	// linear counting is more accurate for the small cardinalities
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+func hyperLogLogAlpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
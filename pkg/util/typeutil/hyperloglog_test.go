@@ -0,0 +1,47 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeutil
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	hll := NewHyperLogLog(14)
+	assert.Equal(t, uint64(0), hll.Count())
+
+	for i := 0; i < 10; i++ {
+		// duplicated values are counted once
+		hll.Add([]byte("a"))
+		hll.Add([]byte(strconv.Itoa(i)))
+	}
+	assert.Equal(t, uint64(11), hll.Count())
+
+	for _, n := range []int{1000, 100000} {
+		hll := NewHyperLogLog(14)
+		for i := 0; i < n; i++ {
+			hll.Add([]byte(strconv.Itoa(i)))
+		}
+		assert.InEpsilon(t, n, hll.Count(), 0.03)
+	}
+
+	assert.Len(t, NewHyperLogLog(0).registers, 1<<minHyperLogLogPrecision)
+	assert.Len(t, NewHyperLogLog(32).registers, 1<<maxHyperLogLogPrecision)
+}